@@ -138,6 +138,35 @@ func (as *Analyser) RunAnalysis() map[string]interface{} {
 	return respAnalyser
 }
 
+// TableInfo runs the column and primary key queries for a single table, used
+// when only one table is needed instead of the whole structure.
+func (as *Analyser) TableInfo(pureName string) ([]*modules.TransformColumnInfo, []string, error) {
+	driver, ok := as.Driver.(*mysql.Source)
+	if !ok || driver == nil {
+		return nil, nil, db.ErrNotSupportedByAdapter
+	}
+
+	// 数据库名和表名都作为参数绑定，两个查询中数据库条件都在表名条件之前
+	createQuery := func(resFileName string) string {
+		res := strings.Replace(sql[resFileName], "'#DATABASE#'", "?", -1)
+		return strings.Replace(res, "=OBJECT_ID_CONDITION", "= ?", -1)
+	}
+
+	columns, err := driver.Columns(createQuery("columns"), as.DatabaseName, pureName)
+	if err != nil {
+		return nil, nil, err
+	}
+	pkColumns, err := driver.PrimaryKeys(createQuery("primaryKeys"), as.DatabaseName, pureName)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	primaryKey := lo.Map(pkColumns.Rows.([]*modules.PrimaryKey), func(key *modules.PrimaryKey, _ int) string {
+		return key.ColumnName
+	})
+	return getColumnInfo(columns.Rows.([]*modules.TableColumn)), primaryKey, nil
+}
+
 func transformTablesIndexes(table *modules.Table, indexesRows []*modules.Indexe, uniqueNamesRows []*modules.UniqueName) []map[string]interface{} {
 	filters := lo.Filter[*modules.Indexe](indexesRows, func(idx *modules.Indexe, _ int) bool {
 		existing, _ := lo.Find[*modules.UniqueName](uniqueNamesRows, func(x *modules.UniqueName) bool {
@@ -278,6 +307,7 @@ func getColumnInfo(filter []*modules.TableColumn) []*modules.TransformColumnInfo
 			ColumnName:    col.ColumnName,
 			ColumnComment: col.ColumnComment,
 			DataType:      fullDataType,
+			ColumnType:    col.ColumnType,
 			DefaultValue:  col.DefaultValue,
 			IsUnsigned:    lo.Contains(columnTypeTokens, "unsigned"),
 			IsZerofill:    lo.Contains(columnTypeTokens, "zerofill"),
@@ -8,6 +8,7 @@ import (
 	"github.com/wailsapp/wails/v3/pkg/application"
 	"tinydb/app/db/stash"
 	"tinydb/app/pkg/logger"
+	"tinydb/app/settings"
 )
 
 var applicationOnce sync.Once
@@ -25,6 +26,8 @@ func (a *AppService) ServiceStartup(ctx context.Context, options application.Ser
 	a.app.Event.On("common:WindowClosing", func(_ *application.CustomEvent) {
 		stash.GetStorageSession().Clear()
 	})
+	upgradeConnections()
+	settings.Load()
 	return nil
 }
 
@@ -3,6 +3,8 @@ package bridge
 import (
 	"github.com/wailsapp/wails/v3/pkg/application"
 	"tinydb/app/pkg/serializer"
+	"tinydb/app/settings"
+	"tinydb/app/utility"
 )
 
 type Configs struct {
@@ -12,20 +14,20 @@ func NewConfigsService(_ *application.App) *Configs {
 	return &Configs{}
 }
 
-type Settings struct {
-	UseNativeMenu bool `json:"useNativeMenu"`
-}
-
 func (cfg *Configs) GetSettings() *serializer.Response {
-	return serializer.SuccessData(serializer.SUCCESS, map[string]interface{}{
-		"app": &Settings{},
-	})
-}
-
-func loadSettings() {
-
+	return serializer.SuccessData(serializer.SUCCESS, settings.Get())
 }
 
-func fillMissingSettings() {
-
+// UpdateSettings merges values into the saved settings, unknown keys and
+// invalid values are rejected without saving anything.
+func (cfg *Configs) UpdateSettings(values map[string]interface{}) *serializer.Response {
+	if len(values) == 0 {
+		return serializer.Fail(serializer.ParamsErr)
+	}
+	updated, err := settings.Update(values)
+	if err != nil {
+		return serializer.Fail(err.Error())
+	}
+	utility.EmitChanged("settings-changed", updated)
+	return serializer.SuccessData(serializer.SUCCESS, updated)
 }
@@ -5,8 +5,15 @@ import (
 	"path"
 	"strings"
 
+	"github.com/samber/lo"
 	"github.com/wailsapp/wails/v3/pkg/application"
+	"tinydb/app/connections"
+	"tinydb/app/db"
 	"tinydb/app/db/adapter"
+	"tinydb/app/db/adapter/mongo"
+	"tinydb/app/db/stash"
+	"tinydb/app/environment"
+	"tinydb/app/guard"
 	"tinydb/app/internal"
 	"tinydb/app/pkg/serializer"
 	"tinydb/app/utility"
@@ -24,6 +31,9 @@ func init() {
 	dir = utility.DataDir()
 	filename = path.Join(dir, "connections.jsonl")
 	JsonLinesDatabase = utility.NewJsonLinesDatabase(filename)
+	JsonLinesDatabase.OnChange(func() {
+		utility.EmitChanged("connection-list-changed")
+	})
 }
 
 func NewConnectionsService(app *application.App) *ConnectionsService {
@@ -45,11 +55,42 @@ func getCore(conid string, mask bool) map[string]interface{} {
 	if conid == "" {
 		return nil
 	}
-	dir = utility.DataDir()
-	JsonLinesDatabase = utility.NewJsonLinesDatabase(filename)
 	return JsonLinesDatabase.Get(conid)
 }
 
+// databaseSession 复用 stash 中 conid/database 对应的会话，不存在时按保存的连接打开
+func databaseSession(conid, database string) (db.Session, error) {
+	connection := getCore(conid, false)
+	if connection == nil {
+		return nil, fmt.Errorf("connection '%s' not found", conid)
+	}
+	return stash.GetStorageSession().Scanner(conid, lo.Assign(connection, map[string]interface{}{databaseKey: database}))
+}
+
+// writable 只读连接拒绝一切写入操作
+func writable(conid string) error {
+	if internal.IsReadOnly(getCore(conid, false)) {
+		return db.ErrReadOnly
+	}
+	return nil
+}
+
+// confirmWrite 连接需要确认写入时（见 environment.ConfirmWrites），没有携带该语句的
+// 有效令牌就返回 confirm 响应，前端确认后带着令牌重新提交。无需确认时返回 nil
+func confirmWrite(conid, database, token string, item *guard.Item) *serializer.Response {
+	if !environment.ConfirmWrites(getCore(conid, false)) {
+		return nil
+	}
+	subject := guard.Subject(conid, database, item.Statement)
+	if token != "" && utility.Confirmations.Consume(token, subject) {
+		return nil
+	}
+	return serializer.SuccessData(serializer.SUCCESS, map[string]interface{}{
+		"msgtype":      "confirm",
+		"confirmation": &guard.Confirmation{Token: utility.Confirmations.Issue(subject), Items: []*guard.Item{item}},
+	})
+}
+
 const (
 	testTitleFailed   = "Test failed"
 	testTitleSuccess  = "Test success"
@@ -85,10 +126,10 @@ func (conn *ConnectionsService) Test(connection map[string]interface{}) *seriali
 				"连接被拒绝：%v\n\n"+
 					"这通常是 MySQL 用户权限问题。请检查：\n"+
 					"1. 用户是否有从您的 IP 地址连接的权限\n"+
-					"2. 在 MySQL 服务器上执行：\n"+
-					"   GRANT ALL PRIVILEGES ON *.* TO 'root'@'%%' IDENTIFIED BY 'your_password';\n"+
-					"   FLUSH PRIVILEGES;\n"+
-					"3. 或者创建一个新用户并授权从任何 IP 连接\n\n"+
+					"2. 使用有管理权限的连接打开「用户管理」，为该用户添加允许的主机并授予所需权限\n"+
+					"3. 或在 MySQL 服务器上执行：\n"+
+					"   CREATE USER 'user'@'%%' IDENTIFIED BY 'your_password';\n"+
+					"   GRANT SELECT, INSERT, UPDATE, DELETE ON your_db.* TO 'user'@'%%';\n\n"+
 					"连接参数：%+v",
 				err, logParams,
 			)
@@ -105,14 +146,35 @@ func (conn *ConnectionsService) Test(connection map[string]interface{}) *seriali
 		showMessageDialog(conn.app, true, testTitleFailed, err.Error())
 		return serializer.Fail(err.Error())
 	}
-	showMessageDialog(conn.app, false, testTitleSuccess, "Connected"+fmt.Sprintf(": %s", version.VersionText))
-	return serializer.SuccessData(serializer.SUCCESS, nil)
+	message := "Connected" + fmt.Sprintf(": %s", version.VersionText)
+	result := map[string]interface{}{"version": version}
+	if source, ok := driver.(*mongo.Source); ok {
+		if topology, err := source.Topology(); err == nil {
+			message += "\n" + topology.String()
+			result["topology"] = topology
+		}
+	}
+	showMessageDialog(conn.app, false, testTitleSuccess, message)
+	return serializer.SuccessData(serializer.SUCCESS, result)
+}
+
+// prepareConnection 校验分组、环境并加密密码，得到写入 connections.jsonl 的记录
+func prepareConnection(connection map[string]string) (map[string]interface{}, error) {
+	if err := environment.Normalize(connection); err != nil {
+		return nil, err
+	}
+	if err := internal.KeyStoreReady(); err != nil {
+		return nil, err
+	}
+	return utility.TransformUnknownMap(internal.EncryptConnection(connection)), nil
 }
 
 func (conn *ConnectionsService) Save(connection map[string]string) *serializer.Response {
-	encrypted := internal.EncryptConnection(connection)
+	unknownMap, err := prepareConnection(connection)
+	if err != nil {
+		return serializer.Fail(err.Error())
+	}
 	//验证obj的唯一性，除去key字段，所有key对应的值都要一致。
-	unknownMap := utility.TransformUnknownMap(encrypted)
 	if exists := utility.UnknownMapSome(JsonLinesDatabase.Find(), unknownMap); exists {
 		showMessageDialog(conn.app, true, connectionFailed, "Connection with same connection name already exists in the project.")
 		return serializer.Fail(serializer.ParamsErr)
@@ -120,7 +182,6 @@ func (conn *ConnectionsService) Save(connection map[string]string) *serializer.R
 
 	uuid, ok := connection["_id"]
 	var res map[string]interface{}
-	var err error
 
 	if ok && uuid != "" {
 		res, err = JsonLinesDatabase.Update(unknownMap)
@@ -133,13 +194,13 @@ func (conn *ConnectionsService) Save(connection map[string]string) *serializer.R
 
 		return serializer.Fail(serializer.ParamsErr)
 	}
-	utility.EmitChanged("connection-list-changed")
 	return serializer.SuccessData(serializer.SUCCESS, res)
 }
 
+// List returns the saved connections as a tree of folders.
 func (conn *ConnectionsService) List() *serializer.Response {
 	find := JsonLinesDatabase.Find()
-	return serializer.SuccessData(serializer.SUCCESS, find)
+	return serializer.SuccessData(serializer.SUCCESS, environment.Tree(find))
 }
 
 type GetConnectionsRequest struct {
@@ -168,9 +229,123 @@ func (conn *ConnectionsService) Delete(connection map[string]string) *serializer
 			showMessageDialog(conn.app, true, deleteFailed, err.Error())
 			return serializer.Fail(err.Error())
 		}
-		utility.EmitChanged("connection-list-changed")
 		return serializer.SuccessData(serializer.SUCCESS, res)
 	}
 
 	return serializer.Fail(serializer.ParamsErr)
 }
+
+type ParseUriRequest struct {
+	Uri string `json:"uri"`
+}
+
+// ParseUri fills in the connection form from a mysql:// or mongodb:// uri.
+func (conn *ConnectionsService) ParseUri(req *ParseUriRequest) *serializer.Response {
+	if req == nil || req.Uri == "" {
+		return serializer.Fail(serializer.ParamsErr)
+	}
+	connection, err := connections.ParseURI(req.Uri)
+	if err != nil {
+		return serializer.Fail(err.Error())
+	}
+	return serializer.SuccessData(serializer.SUCCESS, connection)
+}
+
+// ImportUri saves the connection of a uri unless the same connection exists.
+func (conn *ConnectionsService) ImportUri(req *ParseUriRequest) *serializer.Response {
+	if req == nil || req.Uri == "" {
+		return serializer.Fail(serializer.ParamsErr)
+	}
+	connection, err := connections.ParseURI(req.Uri)
+	if err != nil {
+		return serializer.Fail(err.Error())
+	}
+	result, err := insertConnections([]map[string]string{connection})
+	if err != nil {
+		return serializer.Fail(err.Error())
+	}
+	return serializer.SuccessData(serializer.SUCCESS, result)
+}
+
+type ExportConnectionsRequest struct {
+	// Conids 为空时导出全部连接
+	Conids     []string `json:"conids"`
+	Passphrase string   `json:"passphrase"`
+	FilePath   string   `json:"filePath"`
+}
+
+func (conn *ConnectionsService) Export(req *ExportConnectionsRequest) *serializer.Response {
+	if req == nil || req.FilePath == "" {
+		return serializer.Fail(serializer.ParamsErr)
+	}
+	if err := internal.KeyStoreReady(); err != nil {
+		return serializer.Fail(err.Error())
+	}
+	list := lo.Filter(JsonLinesDatabase.Find(), func(connection map[string]interface{}, _ int) bool {
+		return len(req.Conids) == 0 || lo.Contains(req.Conids, fmt.Sprint(connection["_id"]))
+	})
+	bundle, err := connections.Export(list, req.Passphrase)
+	if err != nil {
+		return serializer.Fail(err.Error())
+	}
+	if err := connections.WriteBundle(req.FilePath, bundle); err != nil {
+		return serializer.Fail(err.Error())
+	}
+	return serializer.SuccessData(serializer.SUCCESS, map[string]int{"exported": len(list)})
+}
+
+type ImportConnectionsRequest struct {
+	Passphrase string `json:"passphrase"`
+	FilePath   string `json:"filePath"`
+}
+
+type ImportConnectionsResult struct {
+	Imported []map[string]interface{} `json:"imported"`
+	// Skipped 已存在相同连接而跳过的数量
+	Skipped int `json:"skipped"`
+}
+
+func (conn *ConnectionsService) Import(req *ImportConnectionsRequest) *serializer.Response {
+	if req == nil || req.FilePath == "" || !utility.IsExist(req.FilePath) {
+		return serializer.Fail(serializer.ParamsErr)
+	}
+	bundle, err := connections.ReadBundle(req.FilePath)
+	if err != nil {
+		return serializer.Fail(err.Error())
+	}
+	list, err := connections.Import(bundle, req.Passphrase)
+	if err != nil {
+		return serializer.Fail(err.Error())
+	}
+	result, err := insertConnections(list)
+	if err != nil {
+		return serializer.Fail(err.Error())
+	}
+	return serializer.SuccessData(serializer.SUCCESS, result)
+}
+
+// insertConnections 先校验全部连接再写入，与 Save 相同的规则跳过已存在的连接
+func insertConnections(list []map[string]string) (*ImportConnectionsResult, error) {
+	prepared := make([]map[string]interface{}, 0, len(list))
+	for _, connection := range list {
+		record, err := prepareConnection(connection)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", connection["name"], err)
+		}
+		prepared = append(prepared, record)
+	}
+
+	result := &ImportConnectionsResult{Imported: make([]map[string]interface{}, 0, len(prepared))}
+	for _, record := range prepared {
+		if utility.UnknownMapSome(JsonLinesDatabase.Find(), record) {
+			result.Skipped++
+			continue
+		}
+		saved, err := JsonLinesDatabase.Insert(record)
+		if err != nil {
+			return nil, err
+		}
+		result.Imported = append(result.Imported, saved)
+	}
+	return result, nil
+}
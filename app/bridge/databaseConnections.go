@@ -1,14 +1,21 @@
 package bridge
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"strings"
-	"sync"
 
 	"github.com/samber/lo"
 	"github.com/wailsapp/wails/v3/pkg/application"
 	"tinydb/app/analyser"
+	"tinydb/app/db"
+	"tinydb/app/db/adapter/mongo"
+	"tinydb/app/db/script"
 	"tinydb/app/db/standard/modules"
+	"tinydb/app/environment"
+	"tinydb/app/explain"
+	"tinydb/app/guard"
 	"tinydb/app/internal/schema"
 	"tinydb/app/pkg/logger"
 	"tinydb/app/pkg/serializer"
@@ -19,15 +26,16 @@ import (
 const databaseKey = "database"
 
 type DatabaseConnections struct {
-	Opened             []*schema.OpenedDatabaseConnection
-	Closed             map[string]*schema.DatabaseConnectionClosed
+	registry           *databaseRegistry
 	DatabaseConnection *sideQuests.DatabaseConnection
 }
 
 func NewDatabaseConnections() *DatabaseConnections {
+	connection := sideQuests.NewDatabaseConnection()
+	connection.SelectToSql = selectToSql
 	return &DatabaseConnections{
-		Closed:             make(map[string]*schema.DatabaseConnectionClosed),
-		DatabaseConnection: sideQuests.NewDatabaseConnection(),
+		registry:           newDatabaseRegistry(),
+		DatabaseConnection: connection,
 	}
 }
 
@@ -35,62 +43,61 @@ func NewDatabaseConnectionsService(_ *application.App) *DatabaseConnections {
 	return NewDatabaseConnections()
 }
 
-func (dc *DatabaseConnections) handleStructure(conid, database string, structure map[string]interface{}) {
-	existing := findByDatabaseConnection(dc.Opened, conid, database)
-
-	if existing == nil {
+func (dc *DatabaseConnections) handleStructure(opened *schema.OpenedDatabaseConnection, structure map[string]interface{}) {
+	if !dc.registry.update(opened, func(existing *schema.OpenedDatabaseConnection) bool {
+		existing.Structure = structure
+		return true
+	}) {
 		return
 	}
-
-	existing.Structure = structure
-	utility.EmitChanged(fmt.Sprintf("database-structure-changed-%s-%s", conid, database))
+	utility.EmitChanged(fmt.Sprintf("database-structure-changed-%s-%s", opened.Conid, opened.Database))
 }
 
-func (dc *DatabaseConnections) handleStructureTime(conid, database string, analysedTime utility.UnixTime) {
-	existing := findByDatabaseConnection(dc.Opened, conid, database)
-
-	if existing == nil {
+func (dc *DatabaseConnections) handleStructureTime(opened *schema.OpenedDatabaseConnection, analysedTime utility.UnixTime) {
+	if !dc.registry.update(opened, func(existing *schema.OpenedDatabaseConnection) bool {
+		existing.AnalysedTime = analysedTime
+		return true
+	}) {
 		return
 	}
-
-	existing.AnalysedTime = analysedTime
-
-	utility.EmitChanged(fmt.Sprintf("database-status-changed-%s-%s", conid, database))
+	utility.EmitChanged(fmt.Sprintf("database-status-changed-%s-%s", opened.Conid, opened.Database))
 }
 
-func (dc *DatabaseConnections) handleVersion(conid, database string, version *modules.Version) {
-	existing := findByDatabaseConnection(dc.Opened, conid, database)
-	if existing == nil {
+func (dc *DatabaseConnections) handleVersion(opened *schema.OpenedDatabaseConnection, version *modules.Version) {
+	if !dc.registry.update(opened, func(existing *schema.OpenedDatabaseConnection) bool {
+		existing.ServerVersion = version
+		return true
+	}) {
 		return
 	}
-	existing.ServerVersion = version
-	utility.EmitChanged(fmt.Sprintf("database-server-version-changed-%s-%s", conid, database))
+	utility.EmitChanged(fmt.Sprintf("database-server-version-changed-%s-%s", opened.Conid, opened.Database))
 }
 
 func (dc *DatabaseConnections) handleError(conid, database string, err error) {
-	logger.Errorf("Error in database connection [%s], database [%d]: [%v]", conid, database, err)
+	logger.Errorf("Error in database connection [%s], database [%s]: [%v]", conid, database, err)
 }
 
-func (dc *DatabaseConnections) handleStatus(conid, database string, status *schema.OpenedStatus) {
-	existing := findByDatabaseConnection(dc.Opened, conid, database)
-	if existing == nil {
-		return
-	}
-	if existing.Status != nil && status != nil && existing.Status.Counter > status.Counter {
+func (dc *DatabaseConnections) handleStatus(opened *schema.OpenedDatabaseConnection, status *schema.OpenedStatus) {
+	if !dc.registry.update(opened, func(existing *schema.OpenedDatabaseConnection) bool {
+		if existing.Status != nil && status != nil && existing.Status.Counter > status.Counter {
+			return false
+		}
+		existing.Status = status
+		return true
+	}) {
 		return
 	}
-	existing.Status = status
-	utility.EmitChanged(fmt.Sprintf("database-status-changed-%s-%s", conid, database))
+	utility.EmitChanged(fmt.Sprintf("database-status-changed-%s-%s", opened.Conid, opened.Database))
 }
 
 func (dc *DatabaseConnections) handlePing() {
 
 }
 
+// ensureOpened 返回连接的副本，新打开的连接状态为 pending，连接和结构分析在
+// worker 中继续，完成后通过 database-status-changed 等事件通知前端
 func (dc *DatabaseConnections) ensureOpened(conid, database string) *schema.OpenedDatabaseConnection {
-	existing := findByDatabaseConnection(dc.Opened, conid, database)
-
-	if existing != nil {
+	if existing := dc.registry.get(conid, database); existing != nil {
 		return existing
 	}
 
@@ -99,45 +106,40 @@ func (dc *DatabaseConnections) ensureOpened(conid, database string) *schema.Open
 		return nil
 	}
 
-	lastClosed := dc.Closed[fmt.Sprintf("%s/%s", conid, database)]
-
-	newOpened := &schema.OpenedDatabaseConnection{
-		Conid:         conid,
-		Status:        &schema.OpenedStatus{Name: "pending"},
-		Database:      database,
-		Connection:    connection,
-		ServerVersion: nil,
-	}
-
-	if lastClosed == nil || lastClosed.Structure == nil {
-		newOpened.Structure = analyser.CreateEmptyStructure()
-	}
-
-	dc.Opened = append(dc.Opened, newOpened)
+	// 上次关闭时保留了结构的连接直接使用旧结构，不再显示 pending
+	var structure interface{}
+	opened, created := dc.registry.open(conid, database, func(lastClosed *schema.DatabaseConnectionClosed) *schema.OpenedDatabaseConnection {
+		newOpened := &schema.OpenedDatabaseConnection{
+			Conid:         conid,
+			Status:        &schema.OpenedStatus{Name: "pending"},
+			Database:      database,
+			Connection:    connection,
+			ServerVersion: nil,
+			Structure:     analyser.CreateEmptyStructure(),
+		}
+		if lastClosed != nil && lastClosed.Structure != nil {
+			newOpened.Structure = lastClosed.Structure
+			structure = lastClosed.Structure
+		}
+		return newOpened
+	})
 
-	var structure map[string]interface{}
-	if lastClosed != nil && lastClosed.Structure == nil {
-		structure = lastClosed.Structure
-	} else {
-		structure = nil
+	if created {
+		worker := dc.DatabaseConnection.Start(opened, structure)
+		go dc.receiver(worker.Messages(), opened)
+		// 启动期间连接已被关闭
+		if !dc.registry.attach(opened, worker) {
+			worker.Stop()
+		}
 	}
-
-	ch := make(chan *schema.EchoMessage)
-	dc.DatabaseConnection.ResetVars()
-	wg := sync.WaitGroup{}
-	wg.Add(1)
-	go dc.DatabaseConnection.Connect(ch, newOpened, structure)
-	go func() {
-		dc.receiver(ch, conid, database)
-		wg.Done()
-	}()
-	wg.Wait()
-	return newOpened
+	return dc.registry.snapshot(opened)
 }
 
 func (dc *DatabaseConnections) Refresh(req *DatabaseKeepOpenRequest) *serializer.Response {
 	if !req.KeepOpen {
 		dc.close(req.Conid, req.Database, true)
+	} else {
+		dc.DatabaseConnection.Refresh(req.Conid, req.Database)
 	}
 	dc.ensureOpened(req.Conid, req.Database)
 	return serializer.SuccessData(serializer.SUCCESS, map[string]string{"status": "ok"})
@@ -168,10 +170,10 @@ func (dc *DatabaseConnections) Ping(req *DatabaseRequest) *serializer.Response {
 		return serializer.Fail(serializer.IdNotEmpty)
 	}
 
-	existing := findByDatabaseConnection(dc.Opened, req.Conid, req.Database)
+	existing := dc.registry.get(req.Conid, req.Database)
 
 	if existing != nil {
-		dc.DatabaseConnection.Ping()
+		dc.DatabaseConnection.Ping(req.Conid, req.Database)
 	} else {
 		existing = dc.ensureOpened(req.Conid, req.Database)
 	}
@@ -185,6 +187,7 @@ func (dc *DatabaseConnections) Ping(req *DatabaseRequest) *serializer.Response {
 	return serializer.SuccessData(serializer.SUCCESS, res)
 }
 
+// Structure 连接刚打开时返回空结构，分析完成后发送 database-structure-changed
 func (dc *DatabaseConnections) Structure(req *DatabaseRequest) *serializer.Response {
 	if req.Conid == "__model" {
 		//todo  const model = await importDbModel(database);
@@ -197,28 +200,27 @@ func (dc *DatabaseConnections) Structure(req *DatabaseRequest) *serializer.Respo
 	return serializer.Fail(serializer.NilRecord)
 }
 
-func (dc *DatabaseConnections) receiver(chData <-chan *schema.EchoMessage, conid, database string) {
-	for {
-		message, ok := <-chData
-		if message != nil {
-			if message.Err != nil {
-				if existing := findByDatabaseConnection(dc.Opened, conid, database); existing != nil && !existing.Disconnected {
-					dc.close(conid, database, false)
-				}
-			}
-			switch message.MsgType {
-			case "status":
-				dc.handleStatus(conid, database, message.Payload.(*schema.OpenedStatus))
-			case "structure":
-				dc.handleStructure(conid, database, message.Payload.(map[string]interface{}))
-			case "structureTime":
-				dc.handleStructureTime(conid, database, message.Payload.(utility.UnixTime))
-			case "version":
-				dc.handleVersion(conid, database, message.Payload.(*modules.Version))
+// receiver 处理 worker 的消息直到它停止，opened 被关闭或替换后消息不再生效
+func (dc *DatabaseConnections) receiver(chData <-chan *schema.EchoMessage, opened *schema.OpenedDatabaseConnection) {
+	for message := range chData {
+		if message == nil {
+			continue
+		}
+		if message.Err != nil {
+			dc.handleError(opened.Conid, opened.Database, message.Err)
+			if worker, ok := dc.registry.removeOpened(opened); ok {
+				dc.closed(opened.Conid, opened.Database, worker)
 			}
 		}
-		if !ok {
-			break
+		switch message.MsgType {
+		case "status":
+			dc.handleStatus(opened, message.Payload.(*schema.OpenedStatus))
+		case "structure":
+			dc.handleStructure(opened, message.Payload.(map[string]interface{}))
+		case "structureTime":
+			dc.handleStructureTime(opened, message.Payload.(utility.UnixTime))
+		case "version":
+			dc.handleVersion(opened, message.Payload.(*modules.Version))
 		}
 	}
 }
@@ -236,9 +238,9 @@ func (dc *DatabaseConnections) ServerVersion(req *DatabaseRequest) *serializer.R
 }
 
 func (dc *DatabaseConnections) Status(req *DatabaseRequest) *serializer.Response {
-	existing := findByDatabaseConnection(dc.Opened, req.Conid, req.Database)
+	existing := dc.registry.get(req.Conid, req.Database)
 
-	if existing != nil {
+	if existing != nil && existing.Status != nil {
 		return serializer.SuccessData(serializer.SUCCESS, map[string]interface{}{
 			"name":         existing.Status.Name,
 			"message":      existing.Status.Message,
@@ -247,7 +249,7 @@ func (dc *DatabaseConnections) Status(req *DatabaseRequest) *serializer.Response
 		})
 	}
 
-	lastClosed := dc.Closed[fmt.Sprintf("%s/%s", req.Conid, req.Database)]
+	lastClosed := dc.registry.lastClosed(req.Conid, req.Database)
 	if lastClosed != nil {
 		return serializer.SuccessData(serializer.SUCCESS, map[string]interface{}{
 			"analysedTime": lastClosed.AnalysedTime,
@@ -282,37 +284,26 @@ type DatabaseKillRequest struct {
 }
 
 func (dc *DatabaseConnections) close(conid, database string, kill bool) {
-	existing := findByDatabaseConnection(dc.Opened, conid, database)
-	if existing != nil {
-		existing.Disconnected = true
+	if worker, ok := dc.registry.remove(conid, database); ok {
 		if kill {
 
 		}
-		dc.Opened = lo.Filter[*schema.OpenedDatabaseConnection](dc.Opened, func(item *schema.OpenedDatabaseConnection, _ int) bool {
-			return item.Conid != conid || item.Database != database
-		})
-
-		dc.Closed[fmt.Sprintf("%s/%s", conid, database)] = &schema.DatabaseConnectionClosed{
-			Structure:    existing.Structure,
-			AnalysedTime: existing.AnalysedTime,
-			Status: &schema.OpenedStatus{
-				Name:    "error",
-				Message: existing.Status.Message,
-				Counter: existing.Status.Counter,
-			},
-		}
+		dc.closed(conid, database, worker)
+	}
+}
 
-		utility.EmitChanged(fmt.Sprintf("database-status-changed-%s-%s", conid, database))
+// closed 连接注销后停止它的 worker，停止时不持有 registry 的锁。
+// worker 为 nil 时 ensureOpened 还没有登记它，会由 ensureOpened 停止
+func (dc *DatabaseConnections) closed(conid, database string, worker *sideQuests.DatabaseWorker) {
+	if worker != nil {
+		worker.Stop()
 	}
+	utility.EmitChanged(fmt.Sprintf("database-status-changed-%s-%s", conid, database))
 }
 
 func (dc *DatabaseConnections) closeAll(conid string, kill bool) {
-	list := lo.Filter[*schema.OpenedDatabaseConnection](dc.Opened, func(item *schema.OpenedDatabaseConnection, _ int) bool {
-		return item.Conid == conid
-	})
-
-	for _, v := range list {
-		dc.close(conid, v.Database, kill)
+	for _, database := range dc.registry.databases(conid) {
+		dc.close(conid, database, kill)
 	}
 }
 
@@ -321,20 +312,13 @@ func (dc *DatabaseConnections) Disconnect(req *DatabaseRequest) *serializer.Resp
 	return serializer.SuccessData(serializer.SUCCESS, &schema.OpenedStatus{Name: "ok"})
 }
 
-func findByDatabaseConnection(s []*schema.OpenedDatabaseConnection, conid, database string) *schema.OpenedDatabaseConnection {
-	existing, ok := lo.Find[*schema.OpenedDatabaseConnection](s, func(item *schema.OpenedDatabaseConnection) bool {
-		return item != nil && item.Conid != "" && item.Conid == conid && item.Database != "" && item.Database == database
-	})
-
-	if existing != nil && ok {
-		return existing
-	}
-	return nil
-}
-
 type SqlSelectRequest struct {
 	databaseConnections
 	Select interface{}
+	// Params 编辑器中 ?、:name、@name 占位符的取值，由驱动绑定
+	Params []*script.ParamValue `json:"params"`
+	// ConfirmToken 危险语句返回 confirm 后，重新提交时携带的确认令牌
+	ConfirmToken string `json:"confirmToken"`
 }
 
 func (dc *DatabaseConnections) SqlSelect(req *SqlSelectRequest) *serializer.Response {
@@ -342,18 +326,39 @@ func (dc *DatabaseConnections) SqlSelect(req *SqlSelectRequest) *serializer.Resp
 	if opened == nil {
 		return serializer.SuccessData(serializer.SUCCESS, map[string]interface{}{"msgtype": "response"})
 	}
-	response := dc.sendRequest(opened, &schema.EchoMessage{Payload: req.Select, MsgType: "sqlSelect"})
+	payload := req.Select
+	if len(req.Params) > 0 || req.ConfirmToken != "" {
+		extra := map[string]interface{}{"confirmToken": req.ConfirmToken}
+		if len(req.Params) > 0 {
+			extra["params"] = req.Params
+		}
+		switch v := req.Select.(type) {
+		case string:
+			payload = lo.Assign(map[string]interface{}{"sql": v}, extra)
+		case map[string]interface{}:
+			payload = lo.Assign(v, extra)
+		}
+	}
+	response := dc.sendRequest(opened, &schema.EchoMessage{Payload: payload, MsgType: "sqlSelect"})
 	if response == nil {
 		return serializer.Fail("Error executing SQL script")
 	}
 
 	if response.Err != nil {
-		if existing := findByDatabaseConnection(dc.Opened, req.Conid, req.Database); existing != nil && !existing.Disconnected {
+		// 只读连接拒绝写入是正常的策略结果，不需要关闭连接
+		if !errors.Is(response.Err, db.ErrReadOnly) {
 			dc.close(req.Conid, req.Database, false)
 		}
 		return serializer.Fail(response.Err.Error())
 	}
 
+	if response.MsgType == "confirm" {
+		return serializer.SuccessData(serializer.SUCCESS, map[string]interface{}{
+			"msgtype":      response.MsgType,
+			"confirmation": response.Payload,
+		})
+	}
+
 	if response.Payload != nil {
 		return serializer.SuccessData(serializer.SUCCESS, map[string]interface{}{
 			"msgtype": response.MsgType,
@@ -364,6 +369,43 @@ func (dc *DatabaseConnections) SqlSelect(req *SqlSelectRequest) *serializer.Resp
 	return serializer.Fail(serializer.NilRecord)
 }
 
+type QueryParametersRequest struct {
+	Sql string `json:"sql"`
+}
+
+// QueryParameters lists the placeholders of a statement so the editor can ask for their values.
+func (dc *DatabaseConnections) QueryParameters(req *QueryParametersRequest) *serializer.Response {
+	return serializer.SuccessData(serializer.SUCCESS, script.FindParameters(req.Sql))
+}
+
+type ExplainRequest struct {
+	databaseConnections
+	Sql     string                         `json:"sql"`
+	Analyze bool                           `json:"analyze"`
+	Mongo   *modules.CollectionDataOptions `json:"mongo"`
+}
+
+// Explain returns the normalised query plan of a statement or collection query.
+func (dc *DatabaseConnections) Explain(req *ExplainRequest) *serializer.Response {
+	if req == nil || req.Conid == "" {
+		return serializer.Fail(serializer.IdNotEmpty)
+	}
+	driver, err := databaseSession(req.Conid, req.Database)
+	if err != nil {
+		return serializer.Fail(err.Error())
+	}
+	plan, err := explain.Explain(context.Background(), driver, &explain.Options{
+		Database: req.Database,
+		Sql:      req.Sql,
+		Analyze:  req.Analyze,
+		Mongo:    req.Mongo,
+	})
+	if err != nil {
+		return serializer.Fail(err.Error())
+	}
+	return serializer.SuccessData(serializer.SUCCESS, plan)
+}
+
 type CollectionDataRequest struct {
 	databaseConnections
 	Options *modules.CollectionDataOptions
@@ -386,10 +428,10 @@ func (dc *DatabaseConnections) CollectionData(req *CollectionDataRequest) *seria
 	}
 
 	if response.Err != nil {
-		if existing := findByDatabaseConnection(dc.Opened, req.Conid, req.Database); existing != nil && !existing.Disconnected {
+		if !errors.Is(response.Err, db.ErrReadOnly) {
 			dc.close(req.Conid, req.Database, false)
 		}
-		logger.Errorf("findByDatabaseConnection response failed %v", response.Err)
+		logger.Errorf("collection data response failed %v", response.Err)
 		return serializer.Fail(response.Err.Error())
 	}
 
@@ -400,10 +442,60 @@ func (dc *DatabaseConnections) CollectionData(req *CollectionDataRequest) *seria
 	return serializer.Fail(serializer.NilRecord)
 }
 
+type DeleteDocumentsRequest struct {
+	databaseConnections
+	PureName     string                 `json:"pureName"`
+	Condition    map[string]interface{} `json:"condition"`
+	ConfirmToken string                 `json:"confirmToken"`
+}
+
+// DeleteDocuments runs deleteMany on a collection, deleting without a condition
+// first returns a confirmation with the number of documents, a filtered delete
+// needs one when the connection confirms writes.
+func (dc *DatabaseConnections) DeleteDocuments(req *DeleteDocumentsRequest) *serializer.Response {
+	if req == nil || req.Conid == "" || req.PureName == "" {
+		return serializer.Fail(serializer.IdNotEmpty)
+	}
+	if err := writable(req.Conid); err != nil {
+		return serializer.Fail(err.Error())
+	}
+	driver, err := databaseSession(req.Conid, req.Database)
+	if err != nil {
+		return serializer.Fail(err.Error())
+	}
+	source, ok := driver.(*mongo.Source)
+	if !ok {
+		return serializer.Fail(fmt.Errorf("delete documents on %s: %w", driver.Dialect(), db.ErrNotSupportedByAdapter).Error())
+	}
+
+	ctx := context.Background()
+	condition := req.Condition
+	if condition == nil {
+		condition = map[string]interface{}{}
+	}
+	if items := guard.CheckMongoDelete(ctx, driver, req.Database, req.PureName, condition, environment.ConfirmWrites(getCore(req.Conid, false))); len(items) > 0 {
+		subject := guard.Subject(req.Conid, req.Database, items[0].Statement)
+		if req.ConfirmToken == "" || !utility.Confirmations.Consume(req.ConfirmToken, subject) {
+			return serializer.SuccessData(serializer.SUCCESS, map[string]interface{}{
+				"msgtype":      "confirm",
+				"confirmation": &guard.Confirmation{Token: utility.Confirmations.Issue(subject), Items: items},
+			})
+		}
+	}
+
+	deleted, err := source.DeleteDocuments(ctx, req.Database, req.PureName, condition)
+	if err != nil {
+		return serializer.Fail(err.Error())
+	}
+	return serializer.SuccessData(serializer.SUCCESS, map[string]interface{}{"deleted": deleted})
+}
+
 type CreateTableRequest struct {
 	databaseConnections
 	TableName string                   `json:"tableName"`
 	Columns   []map[string]interface{} `json:"columns"`
+	// ConfirmToken 连接需要确认写入时，重新提交携带的确认令牌
+	ConfirmToken string `json:"confirmToken"`
 }
 
 func (dc *DatabaseConnections) CreateTable(req *CreateTableRequest) *serializer.Response {
@@ -427,7 +519,7 @@ func (dc *DatabaseConnections) CreateTable(req *CreateTableRequest) *serializer.
 
 	// Execute the SQL
 	response := dc.sendRequest(opened, &schema.EchoMessage{
-		Payload: map[string]interface{}{"sql": sql},
+		Payload: map[string]interface{}{"sql": sql, "confirmToken": req.ConfirmToken},
 		MsgType: "sqlSelect",
 	})
 
@@ -439,6 +531,13 @@ func (dc *DatabaseConnections) CreateTable(req *CreateTableRequest) *serializer.
 		return serializer.Fail(fmt.Sprintf("failed to create table: %v", response.Err))
 	}
 
+	if response.MsgType == "confirm" {
+		return serializer.SuccessData(serializer.SUCCESS, map[string]interface{}{
+			"msgtype":      response.MsgType,
+			"confirmation": response.Payload,
+		})
+	}
+
 	// Refresh database structure
 	dc.ensureOpened(req.Conid, req.Database)
 
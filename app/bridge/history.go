@@ -0,0 +1,61 @@
+package bridge
+
+import (
+	"github.com/wailsapp/wails/v3/pkg/application"
+	"tinydb/app/history"
+	"tinydb/app/pkg/serializer"
+	"tinydb/app/utility"
+)
+
+type HistoryService struct {
+	app *application.App
+}
+
+func NewHistoryService(app *application.App) *HistoryService {
+	return &HistoryService{app: app}
+}
+
+func (h *HistoryService) Search(req *history.Query) *serializer.Response {
+	if req == nil {
+		req = &history.Query{}
+	}
+	entries, err := history.Search(req)
+	if err != nil {
+		return serializer.Fail(err.Error())
+	}
+	return serializer.SuccessData(serializer.SUCCESS, entries)
+}
+
+type HistoryPinRequest struct {
+	Conid  string `json:"conid"`
+	Id     string `json:"id"`
+	Pinned bool   `json:"pinned"`
+}
+
+func (h *HistoryService) Pin(req *HistoryPinRequest) *serializer.Response {
+	if req == nil || req.Conid == "" || req.Id == "" {
+		return serializer.Fail(serializer.IdNotEmpty)
+	}
+	entry, err := history.Pin(req.Conid, req.Id, req.Pinned)
+	if err != nil {
+		return serializer.Fail(err.Error())
+	}
+	return serializer.SuccessData(serializer.SUCCESS, entry)
+}
+
+type HistoryPruneRequest struct {
+	Conid string `json:"conid"`
+	// Before 为空时删除全部未固定的记录
+	Before utility.UnixTime `json:"before"`
+}
+
+func (h *HistoryService) Prune(req *HistoryPruneRequest) *serializer.Response {
+	if req == nil {
+		return serializer.Fail(serializer.ParamsErr)
+	}
+	removed, err := history.Prune(req.Conid, req.Before)
+	if err != nil {
+		return serializer.Fail(err.Error())
+	}
+	return serializer.SuccessData(serializer.SUCCESS, map[string]int{"removed": removed})
+}
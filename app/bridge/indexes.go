@@ -0,0 +1,137 @@
+package bridge
+
+import (
+	"context"
+
+	"github.com/wailsapp/wails/v3/pkg/application"
+	"tinydb/app/guard"
+	"tinydb/app/indexes"
+	"tinydb/app/pkg/serializer"
+)
+
+type IndexesService struct {
+	app *application.App
+}
+
+func NewIndexesService(app *application.App) *IndexesService {
+	return &IndexesService{app: app}
+}
+
+type IndexesRequest struct {
+	Conid    string `json:"conid"`
+	Database string `json:"database"`
+	PureName string `json:"pureName"`
+}
+
+func (i *IndexesService) List(req *IndexesRequest) *serializer.Response {
+	if req == nil || req.Conid == "" || req.PureName == "" {
+		return serializer.Fail(serializer.IdNotEmpty)
+	}
+	driver, err := databaseSession(req.Conid, req.Database)
+	if err != nil {
+		return serializer.Fail(err.Error())
+	}
+	list, err := indexes.List(context.Background(), driver, req.Database, req.PureName)
+	if err != nil {
+		return serializer.Fail(err.Error())
+	}
+	return serializer.SuccessData(serializer.SUCCESS, list)
+}
+
+type CreateIndexRequest struct {
+	IndexesRequest
+	indexes.CreateOptions
+	ConfirmToken string `json:"confirmToken"`
+}
+
+// Create creates an index, with preview set only the statement is returned.
+func (i *IndexesService) Create(req *CreateIndexRequest) *serializer.Response {
+	if req == nil || req.Conid == "" || req.PureName == "" {
+		return serializer.Fail(serializer.IdNotEmpty)
+	}
+	driver, err := databaseSession(req.Conid, req.Database)
+	if err != nil {
+		return serializer.Fail(err.Error())
+	}
+	if !req.Preview {
+		if err = writable(req.Conid); err != nil {
+			return serializer.Fail(err.Error())
+		}
+		preview := req.CreateOptions
+		preview.Preview = true
+		statement, err := indexes.Create(context.Background(), driver, req.Database, req.PureName, &preview)
+		if err != nil {
+			return serializer.Fail(err.Error())
+		}
+		if confirm := confirmWrite(req.Conid, req.Database, req.ConfirmToken, guard.Write(req.PureName, statement)); confirm != nil {
+			return confirm
+		}
+	}
+	statement, err := indexes.Create(context.Background(), driver, req.Database, req.PureName, &req.CreateOptions)
+	if err != nil {
+		return serializer.Fail(err.Error())
+	}
+	return serializer.SuccessData(serializer.SUCCESS, map[string]interface{}{"statement": statement})
+}
+
+type DropIndexRequest struct {
+	IndexesRequest
+	Name         string `json:"name"`
+	Preview      bool   `json:"preview"`
+	ConfirmToken string `json:"confirmToken"`
+}
+
+func (i *IndexesService) Drop(req *DropIndexRequest) *serializer.Response {
+	if req == nil || req.Conid == "" || req.PureName == "" {
+		return serializer.Fail(serializer.IdNotEmpty)
+	}
+	driver, err := databaseSession(req.Conid, req.Database)
+	if err != nil {
+		return serializer.Fail(err.Error())
+	}
+	if !req.Preview {
+		if err = writable(req.Conid); err != nil {
+			return serializer.Fail(err.Error())
+		}
+		statement, err := indexes.Drop(context.Background(), driver, req.Database, req.PureName, req.Name, true)
+		if err != nil {
+			return serializer.Fail(err.Error())
+		}
+		if confirm := confirmWrite(req.Conid, req.Database, req.ConfirmToken, guard.Write(req.PureName, statement)); confirm != nil {
+			return confirm
+		}
+	}
+	statement, err := indexes.Drop(context.Background(), driver, req.Database, req.PureName, req.Name, req.Preview)
+	if err != nil {
+		return serializer.Fail(err.Error())
+	}
+	return serializer.SuccessData(serializer.SUCCESS, map[string]interface{}{"statement": statement})
+}
+
+type HideIndexRequest struct {
+	IndexesRequest
+	Name         string `json:"name"`
+	Hidden       bool   `json:"hidden"`
+	ConfirmToken string `json:"confirmToken"`
+}
+
+func (i *IndexesService) Hide(req *HideIndexRequest) *serializer.Response {
+	if req == nil || req.Conid == "" || req.PureName == "" || req.Name == "" {
+		return serializer.Fail(serializer.IdNotEmpty)
+	}
+	driver, err := databaseSession(req.Conid, req.Database)
+	if err != nil {
+		return serializer.Fail(err.Error())
+	}
+	if err = writable(req.Conid); err != nil {
+		return serializer.Fail(err.Error())
+	}
+	statement := indexes.HideStatement(req.PureName, req.Name, req.Hidden)
+	if confirm := confirmWrite(req.Conid, req.Database, req.ConfirmToken, guard.Write(req.PureName, statement)); confirm != nil {
+		return confirm
+	}
+	if err = indexes.Hide(context.Background(), driver, req.Database, req.PureName, req.Name, req.Hidden); err != nil {
+		return serializer.Fail(err.Error())
+	}
+	return serializer.SuccessData(serializer.SUCCESS, nil)
+}
@@ -0,0 +1,73 @@
+package bridge
+
+import (
+	"github.com/wailsapp/wails/v3/pkg/application"
+	"tinydb/app/internal"
+	"tinydb/app/pkg/logger"
+	"tinydb/app/pkg/serializer"
+	"tinydb/app/utility"
+)
+
+type KeyStoreService struct {
+	app *application.App
+}
+
+func NewKeyStoreService(app *application.App) *KeyStoreService {
+	return &KeyStoreService{app: app}
+}
+
+// Status returns the key store mode and whether the master password is needed.
+func (k *KeyStoreService) Status() *serializer.Response {
+	return serializer.SuccessData(serializer.SUCCESS, internal.GetKeyStoreStatus())
+}
+
+type UnlockKeyStoreRequest struct {
+	Password string `json:"password"`
+}
+
+func (k *KeyStoreService) Unlock(req *UnlockKeyStoreRequest) *serializer.Response {
+	if req == nil || req.Password == "" {
+		return serializer.Fail(serializer.ParamsErr)
+	}
+	if err := internal.UnlockKeyStore(req.Password); err != nil {
+		return serializer.Fail(err.Error())
+	}
+	upgradeConnections()
+	utility.EmitChanged("connection-list-changed")
+	return serializer.SuccessData(serializer.SUCCESS, internal.GetKeyStoreStatus())
+}
+
+type SwitchKeyStoreRequest struct {
+	// Mode file、password 或 keyring
+	Mode string `json:"mode"`
+	// Password 切换到 password 时的新主密码
+	Password string `json:"password"`
+}
+
+// Switch moves the encryption key to another store and re-encrypts the saved
+// passwords with a new key.
+func (k *KeyStoreService) Switch(req *SwitchKeyStoreRequest) *serializer.Response {
+	if req == nil || req.Mode == "" {
+		return serializer.Fail(serializer.ParamsErr)
+	}
+	if err := internal.SwitchKeyStore(req.Mode, req.Password); err != nil {
+		return serializer.Fail(err.Error())
+	}
+	utility.EmitChanged("connection-list-changed")
+	return serializer.SuccessData(serializer.SUCCESS, internal.GetKeyStoreStatus())
+}
+
+// upgradeConnections 将旧 crypt: 格式的密码改用 AES-GCM 保存，主密码未解锁时跳过
+func upgradeConnections() {
+	if internal.KeyStoreReady() != nil {
+		return
+	}
+	count, err := internal.UpgradeConnections(JsonLinesDatabase)
+	if err != nil {
+		logger.Errorf("upgrade saved passwords failed err: %v", err)
+		return
+	}
+	if count > 0 {
+		logger.Infof("re-encrypted the passwords of %d connections", count)
+	}
+}
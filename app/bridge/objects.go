@@ -0,0 +1,88 @@
+package bridge
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/wailsapp/wails/v3/pkg/application"
+	"tinydb/app/db"
+	"tinydb/app/environment"
+	"tinydb/app/objects"
+	"tinydb/app/pkg/serializer"
+	"tinydb/app/utility"
+)
+
+type ObjectsService struct {
+	app *application.App
+}
+
+func NewObjectsService(app *application.App) *ObjectsService {
+	return &ObjectsService{app: app}
+}
+
+type ObjectRequest struct {
+	Conid    string `json:"conid"`
+	Database string `json:"database"`
+	objects.Operation
+	// Token Preview 返回的确认令牌，破坏性操作和需要确认写入的连接必须携带
+	Token string `json:"token"`
+}
+
+func (req *ObjectRequest) subject() string {
+	return fmt.Sprintf("%s/%s/%s/%s/%s/%s/%t", req.Conid, req.Database, req.Action, req.ObjectType, req.Name, req.NewName, req.WithData)
+}
+
+// objectSession 删除数据库时使用服务器级会话，其余操作在当前数据库上执行
+func objectSession(req *ObjectRequest) (db.Session, error) {
+	if req.ObjectType == objects.TypeDatabase {
+		return databaseSession(req.Conid, "")
+	}
+	if req.Database == "" {
+		return nil, fmt.Errorf("database is required")
+	}
+	return databaseSession(req.Conid, req.Database)
+}
+
+// Preview is the dry-run of an operation, it returns the statements and the
+// token Apply requires for destructive operations and on connections that
+// confirm writes.
+func (o *ObjectsService) Preview(req *ObjectRequest) *serializer.Response {
+	if req == nil || req.Conid == "" {
+		return serializer.Fail(serializer.IdNotEmpty)
+	}
+	driver, err := objectSession(req)
+	if err != nil {
+		return serializer.Fail(err.Error())
+	}
+	statements, err := objects.Statements(driver, &req.Operation)
+	if err != nil {
+		return serializer.Fail(err.Error())
+	}
+	return serializer.SuccessData(serializer.SUCCESS, map[string]interface{}{
+		"statements":  statements,
+		"destructive": req.Destructive(),
+		"token":       utility.Confirmations.Issue(req.subject()),
+	})
+}
+
+func (o *ObjectsService) Apply(req *ObjectRequest) *serializer.Response {
+	if req == nil || req.Conid == "" {
+		return serializer.Fail(serializer.IdNotEmpty)
+	}
+	if err := writable(req.Conid); err != nil {
+		return serializer.Fail(err.Error())
+	}
+	confirm := req.Destructive() || environment.ConfirmWrites(getCore(req.Conid, false))
+	if confirm && !utility.Confirmations.Consume(req.Token, req.subject()) {
+		return serializer.Fail("confirmation token is missing or expired, preview the operation again")
+	}
+	driver, err := objectSession(req)
+	if err != nil {
+		return serializer.Fail(err.Error())
+	}
+	statements, err := objects.Apply(context.Background(), driver, req.Database, &req.Operation)
+	if err != nil {
+		return serializer.Fail(err.Error())
+	}
+	return serializer.SuccessData(serializer.SUCCESS, statements)
+}
@@ -0,0 +1,277 @@
+package bridge
+
+import (
+	"sort"
+	"sync"
+
+	"tinydb/app/internal/schema"
+	"tinydb/app/sideQuests"
+	"tinydb/app/utility"
+)
+
+// databaseRegistry 已打开和已关闭的数据库连接，所有读写都持有 mu。
+// 外部只拿到副本，receiver 通过 update 修改，条目被替换后旧 worker 的消息会被丢弃
+type databaseRegistry struct {
+	mu      sync.RWMutex
+	opened  map[string]*schema.OpenedDatabaseConnection
+	workers map[string]*sideQuests.DatabaseWorker
+	closed  map[string]*schema.DatabaseConnectionClosed
+}
+
+func newDatabaseRegistry() *databaseRegistry {
+	return &databaseRegistry{
+		opened:  make(map[string]*schema.OpenedDatabaseConnection),
+		workers: make(map[string]*sideQuests.DatabaseWorker),
+		closed:  make(map[string]*schema.DatabaseConnectionClosed),
+	}
+}
+
+func databaseRegistryKey(conid, database string) string {
+	return conid + "/" + database
+}
+
+// get 返回打开的连接的副本，未打开时返回 nil
+func (r *databaseRegistry) get(conid, database string) *schema.OpenedDatabaseConnection {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if existing := r.opened[databaseRegistryKey(conid, database)]; existing != nil {
+		copied := *existing
+		return &copied
+	}
+	return nil
+}
+
+func (r *databaseRegistry) lastClosed(conid, database string) *schema.DatabaseConnectionClosed {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if closed := r.closed[databaseRegistryKey(conid, database)]; closed != nil {
+		copied := *closed
+		return &copied
+	}
+	return nil
+}
+
+// open 没有打开的连接时用 create 创建并登记，create 拿到上次关闭时的状态。
+// 返回登记的条目和是否新建，新建的条目由调用方启动 worker
+func (r *databaseRegistry) open(conid, database string,
+	create func(lastClosed *schema.DatabaseConnectionClosed) *schema.OpenedDatabaseConnection) (*schema.OpenedDatabaseConnection, bool) {
+	key := databaseRegistryKey(conid, database)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if existing := r.opened[key]; existing != nil {
+		return existing, false
+	}
+	opened := create(r.closed[key])
+	r.opened[key] = opened
+	return opened, true
+}
+
+// snapshot 返回条目的副本
+func (r *databaseRegistry) snapshot(opened *schema.OpenedDatabaseConnection) *schema.OpenedDatabaseConnection {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	copied := *opened
+	return &copied
+}
+
+// update 条目仍然登记时在锁内调用 fn，fn 返回 false 表示没有修改
+func (r *databaseRegistry) update(opened *schema.OpenedDatabaseConnection, fn func(existing *schema.OpenedDatabaseConnection) bool) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.opened[databaseRegistryKey(opened.Conid, opened.Database)] != opened {
+		return false
+	}
+	return fn(opened)
+}
+
+// attach 记录条目的 worker，条目已经注销时返回 false，由调用方停止 worker
+func (r *databaseRegistry) attach(opened *schema.OpenedDatabaseConnection, worker *sideQuests.DatabaseWorker) bool {
+	key := databaseRegistryKey(opened.Conid, opened.Database)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.opened[key] != opened {
+		return false
+	}
+	r.workers[key] = worker
+	return true
+}
+
+// remove 注销连接并记录关闭时的结构和状态，返回条目的 worker，调用方在锁外停止它
+func (r *databaseRegistry) remove(conid, database string) (*sideQuests.DatabaseWorker, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.removeLocked(databaseRegistryKey(conid, database))
+}
+
+// removeOpened 只在 opened 仍然登记时注销
+func (r *databaseRegistry) removeOpened(opened *schema.OpenedDatabaseConnection) (*sideQuests.DatabaseWorker, bool) {
+	key := databaseRegistryKey(opened.Conid, opened.Database)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.opened[key] != opened {
+		return nil, false
+	}
+	return r.removeLocked(key)
+}
+
+func (r *databaseRegistry) removeLocked(key string) (*sideQuests.DatabaseWorker, bool) {
+	existing := r.opened[key]
+	if existing == nil {
+		return nil, false
+	}
+	worker := r.workers[key]
+	delete(r.opened, key)
+	delete(r.workers, key)
+	existing.Disconnected = true
+	closed := &schema.DatabaseConnectionClosed{
+		Structure:    existing.Structure,
+		AnalysedTime: existing.AnalysedTime,
+		Status:       &schema.OpenedStatus{Name: "error"},
+	}
+	if existing.Status != nil {
+		closed.Status.Message = existing.Status.Message
+		closed.Status.Counter = existing.Status.Counter
+	}
+	r.closed[key] = closed
+	return worker, true
+}
+
+// databases conid 下已打开的数据库
+func (r *databaseRegistry) databases(conid string) []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var databases []string
+	for _, opened := range r.opened {
+		if opened.Conid == conid {
+			databases = append(databases, opened.Database)
+		}
+	}
+	sort.Strings(databases)
+	return databases
+}
+
+// serverRegistry 已打开和已关闭的服务器连接以及最近的 ping 时间，所有读写都持有 mu
+type serverRegistry struct {
+	mu         sync.RWMutex
+	opened     map[string]*schema.OpenedServerConnection
+	workers    map[string]*sideQuests.ServerWorker
+	closed     map[string]interface{}
+	lastPinged map[string]utility.UnixTime
+}
+
+func newServerRegistry() *serverRegistry {
+	return &serverRegistry{
+		opened:     make(map[string]*schema.OpenedServerConnection),
+		workers:    make(map[string]*sideQuests.ServerWorker),
+		closed:     make(map[string]interface{}),
+		lastPinged: make(map[string]utility.UnixTime),
+	}
+}
+
+func (r *serverRegistry) get(conid string) *schema.OpenedServerConnection {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if existing := r.opened[conid]; existing != nil {
+		copied := *existing
+		return &copied
+	}
+	return nil
+}
+
+// open 与 databaseRegistry.open 相同，新建时清除关闭状态
+func (r *serverRegistry) open(conid string, create func() *schema.OpenedServerConnection) (*schema.OpenedServerConnection, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if existing := r.opened[conid]; existing != nil {
+		return existing, false
+	}
+	opened := create()
+	r.opened[conid] = opened
+	delete(r.closed, conid)
+	return opened, true
+}
+
+func (r *serverRegistry) snapshot(opened *schema.OpenedServerConnection) *schema.OpenedServerConnection {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	copied := *opened
+	return &copied
+}
+
+func (r *serverRegistry) update(opened *schema.OpenedServerConnection, fn func(existing *schema.OpenedServerConnection)) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.opened[opened.Conid] != opened {
+		return false
+	}
+	fn(opened)
+	return true
+}
+
+func (r *serverRegistry) attach(opened *schema.OpenedServerConnection, worker *sideQuests.ServerWorker) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.opened[opened.Conid] != opened {
+		return false
+	}
+	r.workers[opened.Conid] = worker
+	return true
+}
+
+func (r *serverRegistry) remove(conid string) (*sideQuests.ServerWorker, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.removeLocked(conid)
+}
+
+func (r *serverRegistry) removeOpened(opened *schema.OpenedServerConnection) (*sideQuests.ServerWorker, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.opened[opened.Conid] != opened {
+		return nil, false
+	}
+	return r.removeLocked(opened.Conid)
+}
+
+func (r *serverRegistry) removeLocked(conid string) (*sideQuests.ServerWorker, bool) {
+	existing := r.opened[conid]
+	if existing == nil {
+		return nil, false
+	}
+	worker := r.workers[conid]
+	delete(r.opened, conid)
+	delete(r.workers, conid)
+	existing.Disconnected = true
+	r.closed[conid] = map[string]interface{}{
+		"name":   "error",
+		"status": existing.Status,
+	}
+	r.lastPinged[conid] = 0
+	return worker, true
+}
+
+// statuses 打开的连接返回状态，关闭的连接返回关闭时的状态
+func (r *serverRegistry) statuses() map[string]interface{} {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	values := make(map[string]interface{}, len(r.opened)+len(r.closed))
+	for conid, opened := range r.opened {
+		values[conid] = opened.Status
+	}
+	for conid, closed := range r.closed {
+		values[conid] = closed
+	}
+	return values
+}
+
+// ping 距上次 ping 超过 interval 时记录当前时间并返回 true
+func (r *serverRegistry) ping(conid string, interval utility.UnixTime) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := utility.NewUnixTime()
+	if last := r.lastPinged[conid]; last > 0 && now-last < interval {
+		return false
+	}
+	r.lastPinged[conid] = now
+	return true
+}
@@ -1,36 +1,42 @@
 package bridge
 
 import (
+	"context"
 	"fmt"
-	"sync"
+	"time"
 
 	"github.com/samber/lo"
 	"github.com/wailsapp/wails/v3/pkg/application"
+	"tinydb/app/db"
 	"tinydb/app/db/adapter"
 	"tinydb/app/db/standard/modules"
 	"tinydb/app/db/stash"
+	"tinydb/app/environment"
+	"tinydb/app/guard"
 	"tinydb/app/internal/schema"
 	"tinydb/app/pkg/serializer"
 	"tinydb/app/sideQuests"
 	"tinydb/app/utility"
 )
 
-var lock sync.RWMutex
-
 const conidkey = "conid"
 
+// pingInterval 同一个连接两次 ping 之间的最短间隔
+var pingInterval = utility.GetUnixTime(30 * 1000)
+
 type ServerConnections struct {
-	Closed                  map[string]interface{}
-	Opened                  []*schema.OpenedServerConnection
-	LastPinged              map[string]utility.UnixTime
+	registry                *serverRegistry
 	ServerConnectionChannel *sideQuests.ServerConnection
+	ProcessMonitor          *sideQuests.ProcessMonitor
+	MetricsMonitor          *sideQuests.MetricsMonitor
 }
 
 func NewServerConnections() *ServerConnections {
 	return &ServerConnections{
-		Closed:                  make(map[string]interface{}),
-		LastPinged:              make(map[string]utility.UnixTime),
+		registry:                newServerRegistry(),
 		ServerConnectionChannel: sideQuests.NewServerConnection(),
+		ProcessMonitor:          sideQuests.NewProcessMonitor(),
+		MetricsMonitor:          sideQuests.NewMetricsMonitor(),
 	}
 }
 
@@ -38,49 +44,45 @@ func NewServerConnectionsService(_ *application.App) *ServerConnections {
 	return NewServerConnections()
 }
 
-func (sc *ServerConnections) handleDatabases(conid string, databases interface{}) {
-	existing := findByServerConnection(sc.Opened, conid)
-
-	if existing == nil {
+func (sc *ServerConnections) handleDatabases(opened *schema.OpenedServerConnection, databases interface{}) {
+	if !sc.registry.update(opened, func(existing *schema.OpenedServerConnection) {
+		existing.Databases = databases
+	}) {
 		return
 	}
 
-	existing.Databases = databases
-
-	utility.EmitChanged(fmt.Sprintf("database-list-changed-%s", conid))
+	utility.EmitChanged(fmt.Sprintf("database-list-changed-%s", opened.Conid))
 }
 
-func (sc *ServerConnections) handleVersion(conid string, version *modules.Version) {
-	existing := findByServerConnection(sc.Opened, conid)
-
-	if existing == nil {
+func (sc *ServerConnections) handleVersion(opened *schema.OpenedServerConnection, version *modules.Version) {
+	if !sc.registry.update(opened, func(existing *schema.OpenedServerConnection) {
+		existing.Version = version
+	}) {
 		return
 	}
-
-	existing.Version = version
-	utility.EmitChanged(fmt.Sprintf("server-version-changed-%s", conid))
+	utility.EmitChanged(fmt.Sprintf("server-version-changed-%s", opened.Conid))
 }
 
-func (sc *ServerConnections) handleStatus(conid string, status *schema.OpenedStatus) {
-	existing := findByServerConnection(sc.Opened, conid)
-
-	if existing == nil {
+func (sc *ServerConnections) handleStatus(opened *schema.OpenedServerConnection, status *schema.OpenedStatus) {
+	if !sc.registry.update(opened, func(existing *schema.OpenedServerConnection) {
+		existing.Status = status
+	}) {
 		return
 	}
 
-	existing.Status = status
-
-	utility.EmitChanged("server-status-changed")
+	utility.EmitChanged("server-status-changed", serverTag(opened.Conid))
 }
 
 func (sc *ServerConnections) handlePing() {}
 
-func (sc *ServerConnections) ensureOpened(conid string) *schema.OpenedServerConnection {
-	lock.Lock()
-	defer lock.Unlock()
-	existing := findByServerConnection(sc.Opened, conid)
+// serverTag server-status-changed 事件附带连接的分组、环境和颜色
+func serverTag(conid string) *environment.Tag {
+	return environment.TagOf(conid, getCore(conid, false))
+}
 
-	if existing != nil {
+// ensureOpened 返回连接的副本，新打开的连接状态为 pending
+func (sc *ServerConnections) ensureOpened(conid string) *schema.OpenedServerConnection {
+	if existing := sc.registry.get(conid); existing != nil {
 		return existing
 	}
 
@@ -89,30 +91,29 @@ func (sc *ServerConnections) ensureOpened(conid string) *schema.OpenedServerConn
 		return nil
 	}
 
-	newOpened := &schema.OpenedServerConnection{
-		Conid:        conid,
-		Status:       &schema.OpenedStatus{Name: "pending"},
-		Databases:    nil,
-		Connection:   connection,
-		Disconnected: false,
-		Version:      nil,
-	}
+	opened, created := sc.registry.open(conid, func() *schema.OpenedServerConnection {
+		return &schema.OpenedServerConnection{
+			Conid:        conid,
+			Status:       &schema.OpenedStatus{Name: "pending"},
+			Databases:    nil,
+			Connection:   connection,
+			Disconnected: false,
+			Version:      nil,
+		}
+	})
 
-	sc.Opened = append(sc.Opened, newOpened)
+	if created {
+		utility.EmitChanged("server-status-changed", serverTag(conid))
 
-	if sc.Closed != nil {
-		delete(sc.Closed, conid)
+		worker := sc.ServerConnectionChannel.Start(conid, connection)
+		go sc.receiver(worker.Messages(), opened)
+		// 启动期间连接已被关闭
+		if !sc.registry.attach(opened, worker) {
+			worker.Stop()
+		}
 	}
-	utility.EmitChanged("server-status-changed")
 
-	ch := make(chan *schema.EchoMessage)
-	defer func() {
-		sc.ServerConnectionChannel.ResetVars()
-		go sc.ServerConnectionChannel.Connect(ch, connection)
-		go sc.receiver(ch, conid)
-	}()
-
-	return newOpened
+	return sc.registry.snapshot(opened)
 }
 
 func (sc *ServerConnections) ListDatabases(request map[string]string) *serializer.Response {
@@ -127,14 +128,7 @@ func (sc *ServerConnections) ListDatabases(request map[string]string) *serialize
 }
 
 func (sc *ServerConnections) ServerStatus() interface{} {
-	values := map[string]interface{}{}
-	for _, driver := range sc.Opened {
-		values[driver.Conid] = driver.Status
-	}
-	for key, val := range sc.Closed {
-		values[key] = val
-	}
-	return serializer.SuccessData(serializer.SUCCESS, values)
+	return serializer.SuccessData(serializer.SUCCESS, sc.registry.statuses())
 }
 
 type ServerPingRequest struct {
@@ -143,45 +137,37 @@ type ServerPingRequest struct {
 
 func (sc *ServerConnections) Ping(req *ServerPingRequest) *serializer.Response {
 	for _, conid := range lo.Uniq[string](req.Connections) {
-		last := sc.LastPinged[conid]
-		//if driver, err := stash.GetStorageSession().GetItem(conid); err == nil {
-		//	if err = driver.Ping(); err != nil {
-		//		sc.Close(conid, true)
-		//		continue
-		//	}
-		//}
-
-		if last > 0 && utility.NewUnixTime()-last < utility.GetUnixTime(30*1000) {
+		if !sc.registry.ping(conid, pingInterval) {
 			continue
 		}
 
-		sc.LastPinged[conid] = utility.NewUnixTime()
 		sc.ensureOpened(conid)
-		sc.ServerConnectionChannel.Ping()
+		sc.ServerConnectionChannel.Ping(conid)
 	}
 
 	return serializer.SuccessData(serializer.SUCCESS, map[string]string{"status": "ok"})
 }
 
 func (sc *ServerConnections) Close(conid string, kill bool) {
-	existing := findByServerConnection(sc.Opened, conid)
-	if existing != nil {
-		existing.Disconnected = true
+	if worker, ok := sc.registry.remove(conid); ok {
 		if kill {
 		}
-		sc.Opened = lo.Filter[*schema.OpenedServerConnection](sc.Opened, func(x *schema.OpenedServerConnection, _ int) bool {
-			return x.Conid != conid
-		})
-		sc.Closed[conid] = map[string]interface{}{
-			"name":   "error",
-			"status": existing.Status,
-		}
-		sc.LastPinged[conid] = 0
-		_ = stash.GetStorageSession().RemoveItem(conid)
-		utility.EmitChanged("server-status-changed")
+		sc.closed(conid, worker)
 	}
 }
 
+// closed 连接注销后停止 worker 和监控，停止时不持有 registry 的锁。
+// worker 为 nil 时 ensureOpened 还没有登记它，会由 ensureOpened 停止
+func (sc *ServerConnections) closed(conid string, worker *sideQuests.ServerWorker) {
+	if worker != nil {
+		worker.Stop()
+	}
+	sc.ProcessMonitor.Unwatch(conid)
+	sc.MetricsMonitor.Unwatch(conid)
+	_ = stash.GetStorageSession().RemoveItem(conid)
+	utility.EmitChanged("server-status-changed", serverTag(conid))
+}
+
 type ServerRefreshRequest struct {
 	Conid    string `json:"conid"`
 	KeepOpen bool   `json:"keepOpen"`
@@ -190,6 +176,8 @@ type ServerRefreshRequest struct {
 func (sc *ServerConnections) Refresh(req *ServerRefreshRequest) *serializer.Response {
 	if !req.KeepOpen {
 		sc.Close(req.Conid, true)
+	} else {
+		sc.ServerConnectionChannel.Refresh(req.Conid)
 	}
 	sc.ensureOpened(req.Conid)
 	return serializer.SuccessData(serializer.SUCCESS, map[string]string{
@@ -209,6 +197,9 @@ func (sc *ServerConnections) CreateDatabase(req *CreateDatabaseRequest) *seriali
 	if req.Name == "" {
 		return serializer.Fail("database name is required")
 	}
+	if err := writable(req.Conid); err != nil {
+		return serializer.Fail(err.Error())
+	}
 
 	connection := getCore(req.Conid, false)
 	if connection == nil {
@@ -229,7 +220,9 @@ func (sc *ServerConnections) CreateDatabase(req *CreateDatabaseRequest) *seriali
 	}
 
 	// Refresh the database list
-	sc.ensureOpened(req.Conid)
+	if !sc.ServerConnectionChannel.Refresh(req.Conid) {
+		sc.ensureOpened(req.Conid)
+	}
 
 	return serializer.SuccessData(serializer.SUCCESS, map[string]string{
 		"status": "ok",
@@ -237,39 +230,133 @@ func (sc *ServerConnections) CreateDatabase(req *CreateDatabaseRequest) *seriali
 	})
 }
 
-func (sc *ServerConnections) receiver(chData <-chan *schema.EchoMessage, conid string) {
-	for {
-		message, ok := <-chData
-		if message != nil {
-			if message.Err != nil {
-				if existing := findByServerConnection(sc.Opened, conid); existing != nil && !existing.Disconnected {
-					sc.Close(conid, false)
-				}
-			}
-			switch message.MsgType {
-			case "status":
-				sc.handleStatus(conid, message.Payload.(*schema.OpenedStatus))
-			case "version":
-				sc.handleVersion(conid, message.Payload.(*modules.Version))
-			case "databases":
-				sc.handleDatabases(conid, message.Payload)
-			case "ping":
-				sc.handlePing()
-			}
-		}
-		if !ok {
-			break
+type ProcessListRequest struct {
+	Conid string `json:"conid"`
+	// Interval 自动刷新间隔（秒），为 0 时停止刷新
+	Interval int `json:"interval"`
+}
+
+// ProcessList returns the sessions of the server. With an interval the list is
+// polled in the background and server-process-list-changed-<conid> is emitted
+// whenever it changes.
+func (sc *ServerConnections) ProcessList(req *ProcessListRequest) *serializer.Response {
+	if req == nil || req.Conid == "" {
+		return serializer.Fail(serializer.IdNotEmpty)
+	}
+	open := func() (db.Session, error) {
+		return databaseSession(req.Conid, "")
+	}
+	if req.Interval > 0 {
+		sc.ProcessMonitor.Watch(req.Conid, time.Duration(req.Interval)*time.Second, open)
+		if processes := sc.ProcessMonitor.Latest(req.Conid); processes != nil {
+			return serializer.SuccessData(serializer.SUCCESS, processes)
 		}
+	} else {
+		sc.ProcessMonitor.Unwatch(req.Conid)
+	}
+
+	driver, err := open()
+	if err != nil {
+		return serializer.Fail(err.Error())
 	}
+	processes, err := sideQuests.ReadProcessList(context.Background(), driver)
+	if err != nil {
+		return serializer.Fail(err.Error())
+	}
+	return serializer.SuccessData(serializer.SUCCESS, processes)
+}
+
+type KillProcessRequest struct {
+	Conid string `json:"conid"`
+	Id    string `json:"id"`
+	// QueryOnly 只终止正在执行的语句，保留连接（仅 MySQL）
+	QueryOnly    bool   `json:"queryOnly"`
+	ConfirmToken string `json:"confirmToken"`
+}
+
+func (sc *ServerConnections) KillProcess(req *KillProcessRequest) *serializer.Response {
+	if req == nil || req.Conid == "" || req.Id == "" {
+		return serializer.Fail(serializer.IdNotEmpty)
+	}
+	if err := writable(req.Conid); err != nil {
+		return serializer.Fail(err.Error())
+	}
+	statement := "KILL " + req.Id
+	if req.QueryOnly {
+		statement = "KILL QUERY " + req.Id
+	}
+	if confirm := confirmWrite(req.Conid, "", req.ConfirmToken, guard.Write("", statement)); confirm != nil {
+		return confirm
+	}
+	driver, err := databaseSession(req.Conid, "")
+	if err != nil {
+		return serializer.Fail(err.Error())
+	}
+	if err = sideQuests.KillProcess(context.Background(), driver, req.Id, req.QueryOnly); err != nil {
+		return serializer.Fail(err.Error())
+	}
+	utility.EmitChanged(fmt.Sprintf("server-process-list-changed-%s", req.Conid))
+	return serializer.SuccessData(serializer.SUCCESS, map[string]string{"status": "ok"})
+}
+
+const defaultMetricsInterval = 5
+
+type ServerMetricsRequest struct {
+	Conid string `json:"conid"`
+	// Interval 采样间隔（秒），默认 5 秒
+	Interval int `json:"interval"`
+	// Since 只返回这个时间之后的采样，为 0 时返回缓冲区中的全部采样
+	Since utility.UnixTime `json:"since"`
 }
 
-func findByServerConnection(s []*schema.OpenedServerConnection, conid string) *schema.OpenedServerConnection {
-	existing, ok := lo.Find[*schema.OpenedServerConnection](s, func(x *schema.OpenedServerConnection) bool {
-		return x.Conid == conid
+// Metrics starts sampling the server status of conid if needed and returns the
+// buffered samples, server-metrics-changed-<conid> is emitted with each new sample.
+func (sc *ServerConnections) Metrics(req *ServerMetricsRequest) *serializer.Response {
+	if req == nil || req.Conid == "" {
+		return serializer.Fail(serializer.IdNotEmpty)
+	}
+	interval := req.Interval
+	if interval <= 0 {
+		interval = defaultMetricsInterval
+	}
+	sc.MetricsMonitor.Watch(req.Conid, time.Duration(interval)*time.Second, func() (db.Session, error) {
+		return databaseSession(req.Conid, "")
 	})
+	samples, variables := sc.MetricsMonitor.Samples(req.Conid, req.Since)
+	return serializer.SuccessData(serializer.SUCCESS, map[string]interface{}{
+		"samples":   samples,
+		"variables": variables,
+	})
+}
 
-	if existing != nil && ok {
-		return existing
+func (sc *ServerConnections) StopMetrics(req *ServerMetricsRequest) *serializer.Response {
+	if req == nil || req.Conid == "" {
+		return serializer.Fail(serializer.IdNotEmpty)
+	}
+	sc.MetricsMonitor.Unwatch(req.Conid)
+	return serializer.SuccessData(serializer.SUCCESS, map[string]string{"status": "ok"})
+}
+
+// receiver 处理 worker 的消息直到它停止，opened 被关闭或替换后消息不再生效
+func (sc *ServerConnections) receiver(chData <-chan *schema.EchoMessage, opened *schema.OpenedServerConnection) {
+	for message := range chData {
+		if message == nil {
+			continue
+		}
+		if message.Err != nil {
+			if worker, ok := sc.registry.removeOpened(opened); ok {
+				sc.closed(opened.Conid, worker)
+			}
+		}
+		switch message.MsgType {
+		case "status":
+			sc.handleStatus(opened, message.Payload.(*schema.OpenedStatus))
+		case "version":
+			sc.handleVersion(opened, message.Payload.(*modules.Version))
+		case "databases":
+			sc.handleDatabases(opened, message.Payload)
+		case "ping":
+			sc.handlePing()
+		}
 	}
-	return nil
 }
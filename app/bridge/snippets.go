@@ -0,0 +1,91 @@
+package bridge
+
+import (
+	"github.com/wailsapp/wails/v3/pkg/application"
+	"tinydb/app/pkg/serializer"
+	"tinydb/app/snippets"
+)
+
+type SavedQueriesService struct {
+	app *application.App
+}
+
+func NewSavedQueriesService(app *application.App) *SavedQueriesService {
+	return &SavedQueriesService{app: app}
+}
+
+func (s *SavedQueriesService) List(req *snippets.Filter) *serializer.Response {
+	return serializer.SuccessData(serializer.SUCCESS, snippets.List(req))
+}
+
+func (s *SavedQueriesService) Folders() *serializer.Response {
+	return serializer.SuccessData(serializer.SUCCESS, snippets.Folders())
+}
+
+type SavedQueryRequest struct {
+	Id string `json:"id"`
+}
+
+func (s *SavedQueriesService) Get(req *SavedQueryRequest) *serializer.Response {
+	if req == nil || req.Id == "" {
+		return serializer.Fail(serializer.IdNotEmpty)
+	}
+	query := snippets.Get(req.Id)
+	if query == nil {
+		return serializer.Fail(serializer.NilRecord)
+	}
+	return serializer.SuccessData(serializer.SUCCESS, query)
+}
+
+func (s *SavedQueriesService) Save(req *snippets.SavedQuery) *serializer.Response {
+	if req == nil {
+		return serializer.Fail(serializer.ParamsErr)
+	}
+	saved, err := snippets.Save(req)
+	if err != nil {
+		return serializer.Fail(err.Error())
+	}
+	return serializer.SuccessData(serializer.SUCCESS, saved)
+}
+
+func (s *SavedQueriesService) Delete(req *SavedQueryRequest) *serializer.Response {
+	if req == nil || req.Id == "" {
+		return serializer.Fail(serializer.IdNotEmpty)
+	}
+	if err := snippets.Delete(req.Id); err != nil {
+		return serializer.Fail(err.Error())
+	}
+	return serializer.SuccessData(serializer.SUCCESS, map[string]string{"status": "ok"})
+}
+
+type RenderSnippetRequest struct {
+	Id      string            `json:"id"`
+	Content string            `json:"content"`
+	Values  map[string]string `json:"values"`
+}
+
+// Render fills the ${var} placeholders of a saved snippet or of the given content.
+func (s *SavedQueriesService) Render(req *RenderSnippetRequest) *serializer.Response {
+	if req == nil || (req.Id == "" && req.Content == "") {
+		return serializer.Fail(serializer.ParamsErr)
+	}
+	content := req.Content
+	if req.Id != "" {
+		query := snippets.Get(req.Id)
+		if query == nil {
+			return serializer.Fail(serializer.NilRecord)
+		}
+		content = query.Content
+	}
+	rendered, err := snippets.Render(content, req.Values)
+	if err != nil {
+		return serializer.SuccessData(serializer.SUCCESS, map[string]interface{}{
+			"placeholders": snippets.Placeholders(content),
+			"error":        err.Error(),
+		})
+	}
+	return serializer.SuccessData(serializer.SUCCESS, map[string]interface{}{
+		"placeholders": snippets.Placeholders(content),
+		"content":      rendered,
+	})
+}
@@ -0,0 +1,39 @@
+package bridge
+
+import (
+	"errors"
+	"time"
+
+	"github.com/wailsapp/wails/v3/pkg/application"
+)
+
+// selectToSqlTimeout 前端没有返回 SQL 时不会一直等待
+const selectToSqlTimeout = 8 * time.Second
+
+// selectToSql 旧的查询方式：通过事件请求前端把 Select 转换成 SQL
+func selectToSql(selectParams interface{}) (string, error) {
+	app := application.Get()
+	if app == nil || app.Event == nil {
+		return "", errors.New("application not ready")
+	}
+	result := make(chan string, 1)
+	cancel := app.Event.On("handleSqlSelectReturn", func(e *application.CustomEvent) {
+		var sqlStr string
+		if e != nil && e.Data != nil {
+			sqlStr, _ = e.Data.(string)
+		}
+		select {
+		case result <- sqlStr:
+		default:
+		}
+	})
+	defer cancel()
+	app.Event.Emit("handleSqlSelect", selectParams)
+
+	select {
+	case sqlStr := <-result:
+		return sqlStr, nil
+	case <-time.After(selectToSqlTimeout):
+		return "", errors.New("sql select timeout: frontend did not return SQL")
+	}
+}
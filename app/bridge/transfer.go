@@ -0,0 +1,212 @@
+package bridge
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/wailsapp/wails/v3/pkg/application"
+	"tinydb/app/guard"
+	"tinydb/app/pkg/serializer"
+	"tinydb/app/transfer"
+	"tinydb/app/utility"
+)
+
+type TransferService struct {
+	app *application.App
+}
+
+func NewTransferService(app *application.App) *TransferService {
+	return &TransferService{app: app}
+}
+
+type RunScriptRequest struct {
+	databaseConnections
+	transfer.ScriptOptions
+	ConfirmToken string `json:"confirmToken"`
+}
+
+func (t *TransferService) RunScript(req *RunScriptRequest) *serializer.Response {
+	if req == nil || req.Conid == "" {
+		return serializer.Fail(serializer.IdNotEmpty)
+	}
+	if req.FilePath == "" || !utility.IsExist(req.FilePath) {
+		return serializer.Fail("script file not found")
+	}
+	if req.OnError != "" && req.OnError != transfer.ErrorPolicyStop && req.OnError != transfer.ErrorPolicyContinue {
+		return serializer.Fail(serializer.ParamsErr)
+	}
+	if err := writable(req.Conid); err != nil {
+		return serializer.Fail(err.Error())
+	}
+	if confirm := confirmWrite(req.Conid, req.Database, req.ConfirmToken, guard.Write("", "SOURCE "+req.FilePath)); confirm != nil {
+		return confirm
+	}
+
+	driver, err := databaseSession(req.Conid, req.Database)
+	if err != nil {
+		return serializer.Fail(err.Error())
+	}
+
+	options := req.ScriptOptions
+	job := transfer.StartJob("script", func(ctx context.Context, job *transfer.Job) error {
+		return transfer.RunScript(ctx, job, driver, &options)
+	})
+	return serializer.SuccessData(serializer.SUCCESS, job.Snapshot())
+}
+
+type ExportRequest struct {
+	databaseConnections
+	transfer.ExportOptions
+}
+
+func (t *TransferService) Export(req *ExportRequest) *serializer.Response {
+	if req == nil || req.Conid == "" {
+		return serializer.Fail(serializer.IdNotEmpty)
+	}
+	if req.FilePath == "" || req.Format == "" {
+		return serializer.Fail(serializer.ParamsErr)
+	}
+	if req.Sql == "" && req.PureName == "" {
+		return serializer.Fail("either sql or pureName is required")
+	}
+	if req.Sql != "" {
+		if err := transfer.CheckExportSql(req.Sql); err != nil {
+			return serializer.Fail(err.Error())
+		}
+	}
+
+	driver, err := databaseSession(req.Conid, req.Database)
+	if err != nil {
+		return serializer.Fail(err.Error())
+	}
+
+	options := req.ExportOptions
+	database := req.Database
+	job := transfer.StartJob("export", func(ctx context.Context, job *transfer.Job) error {
+		return transfer.Export(ctx, job, driver, database, &options)
+	})
+	return serializer.SuccessData(serializer.SUCCESS, job.Snapshot())
+}
+
+type PreviewImportRequest struct {
+	transfer.ReaderOptions
+	Limit int `json:"limit"`
+}
+
+func (t *TransferService) PreviewImport(req *PreviewImportRequest) *serializer.Response {
+	if req == nil || req.FilePath == "" || !utility.IsExist(req.FilePath) {
+		return serializer.Fail("import file not found")
+	}
+	preview, err := transfer.Preview(&req.ReaderOptions, req.Limit)
+	if err != nil {
+		return serializer.Fail(err.Error())
+	}
+	return serializer.SuccessData(serializer.SUCCESS, preview)
+}
+
+type ImportRequest struct {
+	databaseConnections
+	transfer.ImportOptions
+	ConfirmToken string `json:"confirmToken"`
+}
+
+func (t *TransferService) Import(req *ImportRequest) *serializer.Response {
+	if req == nil || req.Conid == "" || req.Database == "" {
+		return serializer.Fail(serializer.IdNotEmpty)
+	}
+	if req.FilePath == "" || !utility.IsExist(req.FilePath) {
+		return serializer.Fail("import file not found")
+	}
+	if req.PureName == "" {
+		return serializer.Fail("table name is required")
+	}
+	if req.OnError != "" && req.OnError != transfer.ErrorPolicyStop && req.OnError != transfer.ErrorPolicyContinue {
+		return serializer.Fail(serializer.ParamsErr)
+	}
+	if err := writable(req.Conid); err != nil {
+		return serializer.Fail(err.Error())
+	}
+	statement := fmt.Sprintf("IMPORT %s INTO %s", req.FilePath, req.PureName)
+	if confirm := confirmWrite(req.Conid, req.Database, req.ConfirmToken, guard.Write(req.PureName, statement)); confirm != nil {
+		return confirm
+	}
+
+	driver, err := databaseSession(req.Conid, req.Database)
+	if err != nil {
+		return serializer.Fail(err.Error())
+	}
+
+	options := req.ImportOptions
+	if options.CreateTable && len(options.Columns) > 0 {
+		options.CreateSql = buildCreateTableSQL(options.PureName, options.Columns)
+	}
+	database := req.Database
+	job := transfer.StartJob("import", func(ctx context.Context, job *transfer.Job) error {
+		return transfer.Import(ctx, job, driver, database, &options)
+	})
+	return serializer.SuccessData(serializer.SUCCESS, job.Snapshot())
+}
+
+type CopyRequest struct {
+	transfer.CopyOptions
+	ConfirmToken string `json:"confirmToken"`
+}
+
+func (t *TransferService) Copy(req *CopyRequest) *serializer.Response {
+	if req == nil || req.Source.Conid == "" || req.Target.Conid == "" {
+		return serializer.Fail(serializer.IdNotEmpty)
+	}
+	if req.Source.PureName == "" || req.Target.PureName == "" {
+		return serializer.Fail("table name is required")
+	}
+	if req.Source.Conid == req.Target.Conid && req.Source.Database == req.Target.Database && req.Source.PureName == req.Target.PureName {
+		return serializer.Fail("source and target are the same table")
+	}
+	if err := writable(req.Target.Conid); err != nil {
+		return serializer.Fail(err.Error())
+	}
+	statement := fmt.Sprintf("COPY %s.%s INTO %s", req.Source.Database, req.Source.PureName, req.Target.PureName)
+	if confirm := confirmWrite(req.Target.Conid, req.Target.Database, req.ConfirmToken, guard.Write(req.Target.PureName, statement)); confirm != nil {
+		return confirm
+	}
+
+	source, err := databaseSession(req.Source.Conid, req.Source.Database)
+	if err != nil {
+		return serializer.Fail(err.Error())
+	}
+	target, err := databaseSession(req.Target.Conid, req.Target.Database)
+	if err != nil {
+		return serializer.Fail(err.Error())
+	}
+
+	options := req.CopyOptions
+	job := transfer.StartJob("copy", func(ctx context.Context, job *transfer.Job) error {
+		return transfer.Copy(ctx, job, source, target, &options)
+	})
+	return serializer.SuccessData(serializer.SUCCESS, job.Snapshot())
+}
+
+type TransferJobRequest struct {
+	Id string `json:"id"`
+}
+
+func (t *TransferService) Job(req *TransferJobRequest) *serializer.Response {
+	if req == nil || req.Id == "" {
+		return serializer.Fail(serializer.IdNotEmpty)
+	}
+	job := transfer.GetJob(req.Id)
+	if job == nil {
+		return serializer.Fail(serializer.NilRecord)
+	}
+	return serializer.SuccessData(serializer.SUCCESS, job.Snapshot())
+}
+
+func (t *TransferService) Cancel(req *TransferJobRequest) *serializer.Response {
+	if req == nil || req.Id == "" {
+		return serializer.Fail(serializer.IdNotEmpty)
+	}
+	if !transfer.CancelJob(req.Id) {
+		return serializer.Fail(serializer.NilRecord)
+	}
+	return serializer.SuccessData(serializer.SUCCESS, map[string]string{"status": "ok"})
+}
@@ -0,0 +1,101 @@
+package bridge
+
+import (
+	"context"
+	"strings"
+
+	"github.com/wailsapp/wails/v3/pkg/application"
+	"tinydb/app/guard"
+	"tinydb/app/pkg/serializer"
+	"tinydb/app/users"
+)
+
+type UsersService struct {
+	app *application.App
+}
+
+func NewUsersService(app *application.App) *UsersService {
+	return &UsersService{app: app}
+}
+
+type UsersRequest struct {
+	Conid string `json:"conid"`
+}
+
+func (u *UsersService) List(req *UsersRequest) *serializer.Response {
+	if req == nil || req.Conid == "" {
+		return serializer.Fail(serializer.IdNotEmpty)
+	}
+	driver, err := databaseSession(req.Conid, "")
+	if err != nil {
+		return serializer.Fail(err.Error())
+	}
+	list, err := users.List(context.Background(), driver)
+	if err != nil {
+		return serializer.Fail(err.Error())
+	}
+	return serializer.SuccessData(serializer.SUCCESS, list)
+}
+
+type UserGrantsRequest struct {
+	Conid string `json:"conid"`
+	users.Account
+}
+
+func (u *UsersService) Grants(req *UserGrantsRequest) *serializer.Response {
+	if req == nil || req.Conid == "" || req.User == "" {
+		return serializer.Fail(serializer.IdNotEmpty)
+	}
+	driver, err := databaseSession(req.Conid, "")
+	if err != nil {
+		return serializer.Fail(err.Error())
+	}
+	grants, err := users.Grants(context.Background(), driver, req.Account)
+	if err != nil {
+		return serializer.Fail(err.Error())
+	}
+	return serializer.SuccessData(serializer.SUCCESS, grants)
+}
+
+type UserChangeRequest struct {
+	Conid string `json:"conid"`
+	users.Change
+	ConfirmToken string `json:"confirmToken"`
+}
+
+// Preview returns the statements a change would run, with the password masked.
+func (u *UsersService) Preview(req *UserChangeRequest) *serializer.Response {
+	if req == nil {
+		return serializer.Fail(serializer.ParamsErr)
+	}
+	statements, err := users.Statements(&req.Change, true)
+	if err != nil {
+		return serializer.Fail(err.Error())
+	}
+	return serializer.SuccessData(serializer.SUCCESS, statements)
+}
+
+func (u *UsersService) Apply(req *UserChangeRequest) *serializer.Response {
+	if req == nil || req.Conid == "" {
+		return serializer.Fail(serializer.IdNotEmpty)
+	}
+	if err := writable(req.Conid); err != nil {
+		return serializer.Fail(err.Error())
+	}
+	statements, err := users.Statements(&req.Change, true)
+	if err != nil {
+		return serializer.Fail(err.Error())
+	}
+	if confirm := confirmWrite(req.Conid, "", req.ConfirmToken, guard.Write("", strings.Join(statements, ";\n"))); confirm != nil {
+		return confirm
+	}
+	driver, err := databaseSession(req.Conid, "")
+	if err != nil {
+		return serializer.Fail(err.Error())
+	}
+	statements, err = users.Apply(context.Background(), driver, &req.Change)
+	if err != nil {
+		return serializer.Fail(err.Error())
+	}
+	return serializer.SuccessData(serializer.SUCCESS, statements)
+}
@@ -0,0 +1,122 @@
+package connections
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/samber/lo"
+	"tinydb/app/internal"
+)
+
+// BundleVersion 导出文件格式的版本，版本 1 的口令密钥没有加盐，只用于导入
+const BundleVersion = 2
+
+// Bundle is the JSON file a set of connections is shared with. Passwords are
+// stripped, or encrypted under the passphrase when Encrypted is set.
+type Bundle struct {
+	Version    int       `json:"version"`
+	Encrypted  bool      `json:"encrypted"`
+	ExportedAt time.Time `json:"exportedAt"`
+	// KeyDerivation 由口令派生密钥的 argon2id 参数和盐，只在加密时存在
+	KeyDerivation *internal.KdfParams      `json:"keyDerivation,omitempty"`
+	Connections   []map[string]interface{} `json:"connections"`
+}
+
+// 只对本机有意义的字段不导出
+var localFields = []string{"_id"}
+
+func Export(connections []map[string]interface{}, passphrase string) (*Bundle, error) {
+	bundle := &Bundle{
+		Version:     BundleVersion,
+		Encrypted:   passphrase != "",
+		ExportedAt:  time.Now(),
+		Connections: make([]map[string]interface{}, 0, len(connections)),
+	}
+	var key *internal.ShareKey
+	if bundle.Encrypted {
+		var err error
+		if key, err = internal.NewShareKey(passphrase); err != nil {
+			return nil, err
+		}
+		bundle.KeyDerivation = key.Params
+	}
+	for _, connection := range connections {
+		shared := internal.ShareConnection(lo.OmitByKeys(connection, localFields), key)
+		bundle.Connections = append(bundle.Connections, shared)
+	}
+	return bundle, nil
+}
+
+// Import returns the connections of a bundle with their passwords decrypted,
+// ready to be saved like the ones of the connection form.
+func Import(bundle *Bundle, passphrase string) ([]map[string]string, error) {
+	if bundle.Version < 1 || bundle.Version > BundleVersion {
+		return nil, fmt.Errorf("unsupported connection file version %d", bundle.Version)
+	}
+	if bundle.Encrypted && passphrase == "" {
+		return nil, fmt.Errorf("the connection file is encrypted, a passphrase is required")
+	}
+	key, err := shareKey(bundle, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]map[string]string, 0, len(bundle.Connections))
+	for i, connection := range bundle.Connections {
+		plain, err := internal.UnshareConnection(lo.OmitByKeys(connection, localFields), key)
+		if err != nil {
+			return nil, err
+		}
+		if engine, _ := plain["engine"].(string); engine == "" {
+			return nil, fmt.Errorf("connection %d has no engine", i+1)
+		}
+		result = append(result, toStrings(plain))
+	}
+	return result, nil
+}
+
+func shareKey(bundle *Bundle, passphrase string) (*internal.ShareKey, error) {
+	switch {
+	case passphrase == "":
+		return nil, nil
+	case bundle.Version < 2:
+		return internal.LegacyShareKey(passphrase), nil
+	}
+	return internal.OpenShareKey(passphrase, bundle.KeyDerivation)
+}
+
+// toStrings 保存的连接字段均为字符串
+func toStrings(connection map[string]interface{}) map[string]string {
+	result := make(map[string]string, len(connection))
+	for key, value := range connection {
+		switch v := value.(type) {
+		case nil:
+		case string:
+			result[key] = v
+		default:
+			result[key] = fmt.Sprint(v)
+		}
+	}
+	return result
+}
+
+func WriteBundle(filePath string, bundle *Bundle) error {
+	data, err := json.MarshalIndent(bundle, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filePath, data, 0600)
+}
+
+func ReadBundle(filePath string) (*Bundle, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+	bundle := &Bundle{}
+	if err := json.Unmarshal(data, bundle); err != nil {
+		return nil, fmt.Errorf("invalid connection file: %w", err)
+	}
+	return bundle, nil
+}
@@ -0,0 +1,82 @@
+package connections
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func savedConnections() []map[string]interface{} {
+	return []map[string]interface{}{
+		{"_id": "1", "engine": "mysql", "host": "db.local", "user": "root", "password": "secret", "sshPassword": ""},
+		{"_id": "2", "engine": "mongo", "host": "mongo.local", "port": "27017"},
+	}
+}
+
+func TestExportWithoutPassphrase(t *testing.T) {
+	bundle, err := Export(savedConnections(), "")
+	if err != nil {
+		t.Fatalf("export failed: %v", err)
+	}
+	if bundle.Encrypted || len(bundle.Connections) != 2 {
+		t.Fatalf("unexpected bundle %+v", bundle)
+	}
+	for _, connection := range bundle.Connections {
+		if _, ok := connection["_id"]; ok {
+			t.Fatalf("_id should not be exported: %v", connection)
+		}
+		if _, ok := connection["password"]; ok {
+			t.Fatalf("password should be stripped: %v", connection)
+		}
+	}
+	imported, err := Import(bundle, "")
+	if err != nil {
+		t.Fatalf("import failed: %v", err)
+	}
+	if imported[0]["host"] != "db.local" || imported[0]["password"] != "" {
+		t.Fatalf("unexpected import %v", imported[0])
+	}
+}
+
+func TestExportWithPassphrase(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "connections.json")
+	exported, err := Export(savedConnections(), "team secret")
+	if err != nil {
+		t.Fatalf("export failed: %v", err)
+	}
+	if err := WriteBundle(file, exported); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+	bundle, err := ReadBundle(file)
+	if err != nil {
+		t.Fatalf("read failed: %v", err)
+	}
+	password, _ := bundle.Connections[0]["password"].(string)
+	if !bundle.Encrypted || bundle.KeyDerivation == nil || len(bundle.KeyDerivation.Salt) == 0 ||
+		!strings.HasPrefix(password, "shared2:") || strings.Contains(password, "secret") {
+		t.Fatalf("password should be encrypted under the passphrase: %v", bundle.Connections[0])
+	}
+
+	if _, err := Import(bundle, ""); err == nil {
+		t.Fatalf("import without passphrase should fail")
+	}
+	if _, err := Import(bundle, "wrong"); err == nil {
+		t.Fatalf("import with a wrong passphrase should fail")
+	}
+	imported, err := Import(bundle, "team secret")
+	if err != nil {
+		t.Fatalf("import failed: %v", err)
+	}
+	if imported[0]["password"] != "secret" || imported[1]["port"] != "27017" {
+		t.Fatalf("unexpected import %v", imported)
+	}
+}
+
+func TestImportVersion(t *testing.T) {
+	if _, err := Import(&Bundle{Version: BundleVersion + 1}, ""); err == nil {
+		t.Fatalf("newer versions should be rejected")
+	}
+	if _, err := Import(&Bundle{Version: BundleVersion, Connections: []map[string]interface{}{{"host": "x"}}}, ""); err == nil {
+		t.Fatalf("connections without engine should be rejected")
+	}
+}
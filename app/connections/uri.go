@@ -0,0 +1,122 @@
+package connections
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/samber/lo"
+	"tinydb/app/db/adapter/mongo"
+	"tinydb/app/db/adapter/mysql"
+)
+
+var defaultPorts = map[string]string{
+	mysql.Adapter: "3306",
+	mongo.Adapter: "27017",
+}
+
+// ParseURI converts a mysql://, mongodb:// or mongodb+srv:// URI into the
+// fields of a saved connection. Query parameters are kept in options as a
+// query string.
+func ParseURI(uri string) (map[string]string, error) {
+	scheme, rest, ok := strings.Cut(strings.TrimSpace(uri), "://")
+	if !ok {
+		return nil, fmt.Errorf("invalid connection uri, expected scheme://host")
+	}
+	connection := map[string]string{}
+	switch strings.ToLower(scheme) {
+	case "mysql":
+		connection["engine"] = mysql.Adapter
+	case "mongodb":
+		connection["engine"] = mongo.Adapter
+	case "mongodb+srv":
+		connection["engine"] = mongo.Adapter
+		connection["srv"] = "true"
+	default:
+		return nil, fmt.Errorf("unsupported connection uri scheme '%s'", scheme)
+	}
+
+	authority, path := rest, ""
+	if i := strings.IndexAny(rest, "/?"); i >= 0 {
+		authority, path = rest[:i], rest[i:]
+	}
+	if i := strings.LastIndex(authority, "@"); i >= 0 {
+		user, password, _ := strings.Cut(authority[:i], ":")
+		var err error
+		if connection["user"], err = url.PathUnescape(user); err != nil {
+			return nil, fmt.Errorf("invalid user in connection uri: %w", err)
+		}
+		if connection["password"], err = url.PathUnescape(password); err != nil {
+			return nil, fmt.Errorf("invalid password in connection uri: %w", err)
+		}
+		authority = authority[i+1:]
+	}
+	if err := parseHosts(connection, authority); err != nil {
+		return nil, err
+	}
+
+	path, query, _ := strings.Cut(path, "?")
+	database, err := url.PathUnescape(strings.Trim(path, "/"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid database in connection uri: %w", err)
+	}
+	if database != "" {
+		connection["database"] = database
+	}
+	if query != "" {
+		values, err := url.ParseQuery(query)
+		if err != nil {
+			return nil, fmt.Errorf("invalid options in connection uri: %w", err)
+		}
+		connection["options"] = values.Encode()
+	}
+	connection["name"] = uriName(connection)
+	// 去掉空字段，与表单保存的连接保持一致
+	return lo.OmitByValues(connection, []string{""}), nil
+}
+
+func parseHosts(connection map[string]string, authority string) error {
+	hosts := strings.Split(authority, ",")
+	for _, host := range hosts {
+		if strings.TrimSpace(host) == "" {
+			return fmt.Errorf("connection uri requires a host")
+		}
+	}
+	if len(hosts) > 1 {
+		if connection["engine"] != mongo.Adapter {
+			return fmt.Errorf("only mongodb uris may list several hosts")
+		}
+		connection["host"] = authority
+		return nil
+	}
+
+	host, port := authority, ""
+	if i := strings.LastIndex(authority, ":"); i >= 0 && !strings.HasSuffix(authority, "]") {
+		host, port = authority[:i], authority[i+1:]
+	}
+	host = strings.TrimSuffix(strings.TrimPrefix(host, "["), "]")
+	if connection["srv"] == "true" {
+		if port != "" {
+			return fmt.Errorf("mongodb+srv uris cannot specify a port")
+		}
+	} else if port == "" {
+		port = defaultPorts[connection["engine"]]
+	}
+	connection["host"] = host
+	if port != "" {
+		connection["port"] = port
+	}
+	return nil
+}
+
+// uriName 默认连接名称为 user@host/database
+func uriName(connection map[string]string) string {
+	name := connection["host"]
+	if connection["user"] != "" {
+		name = connection["user"] + "@" + name
+	}
+	if connection["database"] != "" {
+		name += "/" + connection["database"]
+	}
+	return name
+}
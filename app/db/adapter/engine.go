@@ -37,7 +37,11 @@ func (s *sessionWithContext) Open(storedConnection map[string]interface{}) (db.S
 			return nil, errors.New("connections file missing")
 		}
 	}
-	return createSession(internal.DecryptConnection(loadConnection(storedConnection)))
+	connection := internal.DecryptConnection(loadConnection(storedConnection))
+	if err := internal.CheckDecrypted(connection); err != nil {
+		return nil, err
+	}
+	return createSession(connection)
 }
 
 func loadConnection(storedConnection map[string]interface{}) map[string]interface{} {
@@ -58,6 +62,7 @@ func createSession(storedConnection map[string]interface{}) (db.Session, error)
 				logger.Errorf("setting parse failed %v", err)
 				return nil, err
 			}
+			parseSetting.ReadOnly = internal.IsReadOnly(storedConnection)
 			return mysql.Open(parseSetting)
 		case mongo.Adapter:
 			parseSetting, err := mongo.ParseSetting(storedConnection)
@@ -3,43 +3,77 @@ package mongo
 import (
 	"encoding/json"
 	"fmt"
+	"net"
 	"net/url"
+	"strconv"
 	"strings"
 	"tinydb/app/db"
+
+	"github.com/samber/lo"
+)
+
+const (
+	connectionScheme    = `mongodb`
+	srvConnectionScheme = `mongodb+srv`
+	defaultPort         = `27017`
 )
 
-const connectionScheme = `mongodb`
+const (
+	AuthScramSha1   = "SCRAM-SHA-1"
+	AuthScramSha256 = "SCRAM-SHA-256"
+	AuthX509        = "MONGODB-X509"
+)
+
+var authMechanisms = []string{AuthScramSha1, AuthScramSha256, AuthX509}
+
+var readPreferences = []string{"primary", "primaryPreferred", "secondary", "secondaryPreferred", "nearest"}
 
 // ConnectionURL implements a MongoDB connection struct.
 type ConnectionURL struct {
-	User     string            `json:"username,omitempty"`
-	Password string            `json:"password,omitempty"`
-	Host     string            `json:"host"`
-	Database string            `json:"database"`
-	Port     string            `json:"port"`
-	Options  map[string]string `json:"options,omitempty"`
+	User     string `json:"username,omitempty"`
+	Password string `json:"password,omitempty"`
+	// Host 可以是逗号分隔的主机列表，解析后保存在 Hosts 中
+	Host  string   `json:"host"`
+	Hosts []string `json:"-"`
+	Port  string   `json:"port"`
+	// Srv 使用 mongodb+srv 通过 DNS 发现主机，不能指定端口
+	Srv            bool   `json:"-"`
+	Database       string `json:"database"`
+	ReplicaSet     string `json:"replicaSet,omitempty"`
+	AuthSource     string `json:"authSource,omitempty"`
+	AuthMechanism  string `json:"authMechanism,omitempty"`
+	ReadPreference string `json:"readPreference,omitempty"`
+	// TLSCertificateKeyFile X.509 认证使用的客户端证书和私钥
+	TLSCertificateKeyFile string            `json:"tlsCertificateKeyFile,omitempty"`
+	Options               map[string]string `json:"options,omitempty"`
 }
 
 func (c ConnectionURL) String() (s string) {
 	vv := url.Values{}
 
-	// Do we have any options?
-	if c.Options == nil {
-		c.Options = map[string]string{}
-	}
-
 	// Converting options into URL values.
 	for k, v := range c.Options {
 		vv.Set(k, v)
 	}
+	// 结构化字段优先于 options
+	for k, v := range map[string]string{
+		"replicaSet":            c.ReplicaSet,
+		"authSource":            c.AuthSource,
+		"authMechanism":         c.AuthMechanism,
+		"readPreference":        c.ReadPreference,
+		"tlsCertificateKeyFile": c.TLSCertificateKeyFile,
+	} {
+		if v != "" {
+			vv.Set(k, v)
+		}
+	}
+	if c.TLSCertificateKeyFile != "" {
+		vv.Set("tls", "true")
+	}
 
 	// Has user?
 	var userInfo *url.Userinfo
 
-	if c.Port == "" {
-		c.Port = "27017"
-	}
-
 	if c.User != "" {
 		if c.Password == "" {
 			userInfo = url.User(c.User)
@@ -48,25 +82,58 @@ func (c ConnectionURL) String() (s string) {
 		}
 	}
 
-	c.Host = strings.Split(c.Host, ":")[0]
-	c.Host = fmt.Sprintf("%s:%s", c.Host, c.Port)
-
-	// Building URL.
+	// Building URL. 驱动要求查询参数前必须有 /
 	u := url.URL{
 		Scheme:   connectionScheme,
-		Path:     c.Database,
-		Host:     c.Host,
+		Path:     "/" + c.Database,
+		Host:     strings.Join(c.hostList(), ","),
 		User:     userInfo,
 		RawQuery: vv.Encode(),
 	}
+	if c.Srv {
+		u.Scheme = srvConnectionScheme
+	}
 
 	return u.String()
 }
 
+// hostList 为没有端口的主机补上端口，SRV 记录只需要主机名
+func (c ConnectionURL) hostList() []string {
+	hosts := c.Hosts
+	if len(hosts) == 0 {
+		hosts = []string{c.Host}
+	}
+	if c.Srv {
+		return hosts
+	}
+	if len(hosts) == 1 && c.Port != "" {
+		// 单个主机时端口字段优先
+		host := hosts[0]
+		if h, _, err := net.SplitHostPort(host); err == nil {
+			host = h
+		}
+		return []string{net.JoinHostPort(strings.Trim(host, "[]"), c.Port)}
+	}
+	return lo.Map(hosts, func(host string, _ int) string {
+		if _, _, err := net.SplitHostPort(host); err == nil {
+			return host
+		}
+		return net.JoinHostPort(strings.Trim(host, "[]"), defaultPort)
+	})
+}
+
 func ParseSetting(connection map[string]interface{}) (*ConnectionURL, error) {
 	if connection == nil {
 		return nil, db.ErrInvalidConnection
 	}
+	connection, err := db.QueryOptions(connection)
+	if err != nil {
+		return nil, err
+	}
+	// 连接表单保存的是 user
+	if user, ok := connection["user"]; ok && connection["username"] == nil {
+		connection = lo.Assign(connection, map[string]interface{}{"username": user})
+	}
 	marshal, err := json.Marshal(&connection)
 	if err != nil {
 		return nil, err
@@ -77,8 +144,50 @@ func ParseSetting(connection map[string]interface{}) (*ConnectionURL, error) {
 		return nil, err
 	}
 
-	if urlDSN.Host == "" {
+	if srv, ok := connection["srv"]; ok && srv != nil && srv != "" {
+		if urlDSN.Srv, err = strconv.ParseBool(fmt.Sprint(srv)); err != nil {
+			return nil, fmt.Errorf("invalid srv value '%v'", srv)
+		}
+	}
+	for _, host := range strings.Split(urlDSN.Host, ",") {
+		if host = strings.TrimSpace(host); host != "" {
+			urlDSN.Hosts = append(urlDSN.Hosts, host)
+		}
+	}
+	if len(urlDSN.Hosts) == 0 {
 		return nil, fmt.Errorf("lack of host")
 	}
+	if err := urlDSN.validate(); err != nil {
+		return nil, err
+	}
 	return urlDSN, nil
 }
+
+func (c *ConnectionURL) validate() error {
+	if c.Srv {
+		if len(c.Hosts) > 1 {
+			return fmt.Errorf("mongodb+srv connections take a single host name")
+		}
+		if c.Port != "" || strings.Contains(c.Hosts[0], ":") {
+			return fmt.Errorf("mongodb+srv connections cannot specify a port")
+		}
+	}
+	if c.AuthMechanism != "" && !lo.Contains(authMechanisms, c.AuthMechanism) {
+		return fmt.Errorf("unsupported auth mechanism '%s', expected one of %s", c.AuthMechanism, strings.Join(authMechanisms, ", "))
+	}
+	if c.AuthMechanism == AuthX509 {
+		if c.TLSCertificateKeyFile == "" {
+			return fmt.Errorf("%s requires a client certificate", AuthX509)
+		}
+		// X.509 用户名来自证书，只能在 $external 中认证
+		if c.AuthSource != "" && c.AuthSource != "$external" {
+			return fmt.Errorf("%s only authenticates against $external", AuthX509)
+		}
+		c.AuthSource = "$external"
+		c.Password = ""
+	}
+	if c.ReadPreference != "" && !lo.Contains(readPreferences, c.ReadPreference) {
+		return fmt.Errorf("unsupported read preference '%s', expected one of %s", c.ReadPreference, strings.Join(readPreferences, ", "))
+	}
+	return nil
+}
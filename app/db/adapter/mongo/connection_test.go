@@ -23,3 +23,58 @@ func TestParseSetting(t *testing.T) {
 	logger.Infof("setting: %s", utility.ToJsonStr(setting))
 	logger.Infof("%s", setting.String())
 }
+
+func TestConnectionURLString(t *testing.T) {
+	cases := []struct {
+		connection map[string]interface{}
+		want       string
+	}{
+		{map[string]interface{}{"host": "localhost"}, "mongodb://localhost:27017/"},
+		{map[string]interface{}{"host": "db:27018", "port": "27019", "user": "root", "password": "p@ss"}, "mongodb://root:p%40ss@db:27019/"},
+		{map[string]interface{}{"host": "a, b:27018", "replicaSet": "rs0", "readPreference": "secondaryPreferred", "database": "app"},
+			"mongodb://a:27017,b:27018/app?readPreference=secondaryPreferred&replicaSet=rs0"},
+		{map[string]interface{}{"host": "cluster0.example.net", "srv": "true", "username": "u", "password": "p", "authSource": "admin", "options": "retryWrites=true&authSource=other"},
+			"mongodb+srv://u:p@cluster0.example.net/?authSource=admin&retryWrites=true"},
+		{map[string]interface{}{"host": "db", "authMechanism": "MONGODB-X509", "tlsCertificateKeyFile": "/certs/client.pem", "password": "ignored"},
+			"mongodb://db:27017/?authMechanism=MONGODB-X509&authSource=%24external&tls=true&tlsCertificateKeyFile=%2Fcerts%2Fclient.pem"},
+	}
+	for _, c := range cases {
+		setting, err := ParseSetting(c.connection)
+		if err != nil {
+			t.Fatalf("ParseSetting(%v) failed: %v", c.connection, err)
+		}
+		if got := setting.String(); got != c.want {
+			t.Fatalf("String() = %s, want %s", got, c.want)
+		}
+	}
+}
+
+func TestParseSettingErrors(t *testing.T) {
+	for _, connection := range []map[string]interface{}{
+		{"host": " , "},
+		{"host": "a,b", "srv": "true"},
+		{"host": "cluster0.example.net", "srv": "true", "port": "27017"},
+		{"host": "db", "srv": "maybe"},
+		{"host": "db", "authMechanism": "PLAIN"},
+		{"host": "db", "authMechanism": "MONGODB-X509"},
+		{"host": "db", "authMechanism": "MONGODB-X509", "tlsCertificateKeyFile": "c.pem", "authSource": "admin"},
+		{"host": "db", "readPreference": "fastest"},
+	} {
+		if _, err := ParseSetting(connection); err == nil {
+			t.Fatalf("ParseSetting(%v) should fail", connection)
+		}
+	}
+}
+
+func TestTopologyString(t *testing.T) {
+	replica := (&helloReply{SetName: "rs0", Primary: "a:27017", Hosts: []string{"a:27017", "b:27017"}, Arbiters: []string{"c:27017"}}).topology()
+	if got := replica.String(); got != "replica set rs0: a:27017 (primary), b:27017, c:27017 (arbiter)" {
+		t.Fatalf("unexpected topology %s", got)
+	}
+	if got := (&helloReply{Msg: "isdbgrid"}).topology().Kind; got != TopologySharded {
+		t.Fatalf("unexpected kind %s", got)
+	}
+	if got := (&helloReply{Me: "a:27017"}).topology().Kind; got != TopologyStandalone {
+		t.Fatalf("unexpected kind %s", got)
+	}
+}
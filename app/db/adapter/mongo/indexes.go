@@ -0,0 +1,97 @@
+package mongo
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"tinydb/app/pkg/logger"
+)
+
+// ListIndexes returns the raw index specifications of a collection, raw
+// documents keep the order of the index keys.
+func (s *Source) ListIndexes(ctx context.Context, database, collection string) ([]bson.Raw, error) {
+	cursor, err := s.client.Database(database).Collection(collection).Indexes().List(ctx)
+	if err != nil {
+		logger.Errorf("list indexes [database: %s, collection: %s] failed %v", database, collection, err)
+		return nil, err
+	}
+	indexes := make([]bson.Raw, 0)
+	err = cursor.All(ctx, &indexes)
+	return indexes, err
+}
+
+// IndexStats returns the $indexStats usage counters of a collection.
+func (s *Source) IndexStats(ctx context.Context, database, collection string) ([]bson.M, error) {
+	return s.aggregateAll(ctx, database, collection, mongo.Pipeline{{{Key: "$indexStats", Value: bson.M{}}}})
+}
+
+// IndexSizes returns the size in bytes of every index of a collection.
+func (s *Source) IndexSizes(ctx context.Context, database, collection string) (map[string]int64, error) {
+	stats, err := s.aggregateAll(ctx, database, collection, mongo.Pipeline{
+		{{Key: "$collStats", Value: bson.M{"storageStats": bson.M{}}}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	sizes := make(map[string]int64)
+	for _, stat := range stats {
+		storage, _ := stat["storageStats"].(bson.M)
+		indexSizes, _ := storage["indexSizes"].(bson.M)
+		for name, size := range indexSizes {
+			switch v := size.(type) {
+			case int32:
+				sizes[name] += int64(v)
+			case int64:
+				sizes[name] += v
+			case float64:
+				sizes[name] += int64(v)
+			}
+		}
+	}
+	return sizes, nil
+}
+
+// CreateIndexes runs the createIndexes command with full index specifications,
+// so every option supported by the server can be used.
+func (s *Source) CreateIndexes(ctx context.Context, database, collection string, specs []bson.D) error {
+	err := s.client.Database(database).RunCommand(ctx, bson.D{
+		{Key: "createIndexes", Value: collection},
+		{Key: "indexes", Value: specs},
+	}).Err()
+	if err != nil {
+		logger.Errorf("create indexes [database: %s, collection: %s] failed %v", database, collection, err)
+	}
+	return err
+}
+
+func (s *Source) DropIndex(ctx context.Context, database, collection, name string) error {
+	_, err := s.client.Database(database).Collection(collection).Indexes().DropOne(ctx, name)
+	if err != nil {
+		logger.Errorf("drop index %s [database: %s, collection: %s] failed %v", name, database, collection, err)
+	}
+	return err
+}
+
+// HideIndex hides an index from the query planner without dropping it (4.4+).
+func (s *Source) HideIndex(ctx context.Context, database, collection, name string, hidden bool) error {
+	err := s.client.Database(database).RunCommand(ctx, bson.D{
+		{Key: "collMod", Value: collection},
+		{Key: "index", Value: bson.D{{Key: "name", Value: name}, {Key: "hidden", Value: hidden}}},
+	}).Err()
+	if err != nil {
+		logger.Errorf("collMod index %s [database: %s, collection: %s] failed %v", name, database, collection, err)
+	}
+	return err
+}
+
+func (s *Source) aggregateAll(ctx context.Context, database, collection string, pipeline mongo.Pipeline) ([]bson.M, error) {
+	cursor, err := s.client.Database(database).Collection(collection).Aggregate(ctx, pipeline)
+	if err != nil {
+		logger.Errorf("exec aggregate [database: %s, collection: %s] failed %v", database, collection, err)
+		return nil, err
+	}
+	results := make([]bson.M, 0)
+	err = cursor.All(ctx, &results)
+	return results, err
+}
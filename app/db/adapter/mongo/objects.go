@@ -0,0 +1,107 @@
+package mongo
+
+import (
+	"context"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"tinydb/app/pkg/logger"
+)
+
+func (s *Source) DropDatabase(ctx context.Context, database string) error {
+	err := s.client.Database(database).Drop(ctx)
+	if err != nil {
+		logger.Errorf("drop database %s failed %v", database, err)
+	}
+	return err
+}
+
+func (s *Source) DropCollection(ctx context.Context, database, collection string) error {
+	err := s.client.Database(database).Collection(collection).Drop(ctx)
+	if err != nil {
+		logger.Errorf("drop collection [database: %s, collection: %s] failed %v", database, collection, err)
+	}
+	return err
+}
+
+// RenameCollection renames a collection inside the same database, the target
+// must not exist.
+func (s *Source) RenameCollection(ctx context.Context, database, from, to string) error {
+	err := s.client.Database("admin").RunCommand(ctx, bson.D{
+		{Key: "renameCollection", Value: database + "." + from},
+		{Key: "to", Value: database + "." + to},
+	}).Err()
+	if err != nil {
+		logger.Errorf("rename collection [database: %s, collection: %s] to %s failed %v", database, from, to, err)
+	}
+	return err
+}
+
+// CopyCollection creates a collection with the options and indexes of another
+// one, the documents are copied with $out when withData is set.
+func (s *Source) CopyCollection(ctx context.Context, database, from, to string, withData bool) error {
+	db := s.client.Database(database)
+	specs, err := db.ListCollectionSpecifications(ctx, bson.D{{Key: "name", Value: from}})
+	if err != nil {
+		return err
+	}
+	if len(specs) == 0 {
+		return fmt.Errorf("collection '%s' not found", from)
+	}
+
+	create := bson.D{{Key: "create", Value: to}}
+	if specs[0].Options != nil {
+		var options bson.D
+		if err = bson.Unmarshal(specs[0].Options, &options); err != nil {
+			return err
+		}
+		create = append(create, options...)
+	}
+	if err = db.RunCommand(ctx, create).Err(); err != nil {
+		logger.Errorf("create collection [database: %s, collection: %s] failed %v", database, to, err)
+		return err
+	}
+	// 视图没有数据和索引
+	if specs[0].Type == "view" {
+		return nil
+	}
+
+	if withData {
+		cursor, err := db.Collection(from).Aggregate(ctx, mongo.Pipeline{{{Key: "$out", Value: to}}})
+		if err != nil {
+			logger.Errorf("copy collection [database: %s, collection: %s] to %s failed %v", database, from, to, err)
+			return err
+		}
+		_ = cursor.Close(ctx)
+	}
+
+	indexes, err := s.ListIndexes(ctx, database, from)
+	if err != nil {
+		return err
+	}
+	copied := make([]bson.D, 0, len(indexes))
+	for _, raw := range indexes {
+		var spec bson.D
+		if err = bson.Unmarshal(raw, &spec); err != nil {
+			return err
+		}
+		index := make(bson.D, 0, len(spec))
+		for _, element := range spec {
+			if element.Key == "name" && element.Value == "_id_" {
+				index = nil
+				break
+			}
+			if element.Key != "v" && element.Key != "ns" {
+				index = append(index, element)
+			}
+		}
+		if index != nil {
+			copied = append(copied, index)
+		}
+	}
+	if len(copied) == 0 {
+		return nil
+	}
+	return s.CreateIndexes(ctx, database, to, copied)
+}
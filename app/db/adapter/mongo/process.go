@@ -0,0 +1,91 @@
+package mongo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"tinydb/app/db/standard/modules"
+	"tinydb/app/pkg/logger"
+)
+
+type currentOpResult struct {
+	Inprog []bson.M `bson:"inprog"`
+}
+
+// ProcessList returns the operations in progress reported by currentOp.
+func (s *Source) ProcessList(ctx context.Context) ([]*modules.ServerProcess, error) {
+	var result currentOpResult
+	err := s.client.Database("admin").RunCommand(ctx, bson.D{{Key: "currentOp", Value: 1}}).Decode(&result)
+	if err != nil {
+		logger.Errorf("exec currentOp failed %v", err)
+		return nil, err
+	}
+
+	processes := make([]*modules.ServerProcess, 0, len(result.Inprog))
+	for _, op := range result.Inprog {
+		process := &modules.ServerProcess{
+			Id:       fmt.Sprint(op["opid"]),
+			Host:     stringValue(op["client"]),
+			Database: stringValue(op["ns"]),
+			Command:  stringValue(op["op"]),
+			State:    "idle",
+			Info:     stringValue(op["desc"]),
+		}
+		if seconds, ok := op["secs_running"]; ok {
+			process.Time, _ = strconv.ParseInt(fmt.Sprint(seconds), 10, 64)
+		}
+		if active, _ := op["active"].(bool); active {
+			process.State = "active"
+		}
+		if waiting, _ := op["waitingForLock"].(bool); waiting {
+			process.State = "waiting for lock"
+		}
+		if users, ok := op["effectiveUsers"].(primitive.A); ok && len(users) > 0 {
+			if user, ok := users[0].(bson.M); ok {
+				process.User = fmt.Sprintf("%s@%s", stringValue(user["user"]), stringValue(user["db"]))
+			}
+		}
+		if command, ok := op["command"]; ok {
+			if marshal, err := json.Marshal(command); err == nil {
+				process.Info = string(marshal)
+			}
+		}
+		processes = append(processes, process)
+	}
+	return processes, nil
+}
+
+// KillProcess terminates an operation with killOp, opid is a number on a
+// replica set and a "shard:opid" string on mongos.
+func (s *Source) KillProcess(ctx context.Context, id string, _ bool) error {
+	var op interface{} = id
+	if number, err := strconv.ParseInt(id, 10, 64); err == nil {
+		op = number
+	}
+	err := s.client.Database("admin").RunCommand(ctx, bson.D{{Key: "killOp", Value: 1}, {Key: "op", Value: op}}).Err()
+	if err != nil {
+		logger.Errorf("exec killOp %s failed %v", id, err)
+	}
+	return err
+}
+
+func stringValue(value interface{}) string {
+	if s, ok := value.(string); ok {
+		return s
+	}
+	return ""
+}
+
+// ServerStatus returns the output of the serverStatus command.
+func (s *Source) ServerStatus(ctx context.Context) (bson.M, error) {
+	var status bson.M
+	if err := s.client.Database("admin").RunCommand(ctx, bson.D{{Key: "serverStatus", Value: 1}}).Decode(&status); err != nil {
+		logger.Errorf("exec serverStatus failed %v", err)
+		return nil, err
+	}
+	return status, nil
+}
@@ -15,8 +15,8 @@ func (s *Source) Ping() error {
 }
 
 func (s *Source) Version() (*modules.Version, error) {
-	//todo 这里是写死，有问题，需要调整。
-	db := s.client.Database("local")
+	// buildInfo 对任意库都可执行，admin 在只授权部分库的账号下也可用
+	db := s.client.Database("admin")
 	buildInfoCmd := bson.D{bson.E{Key: "buildInfo", Value: 1}}
 	var buildInfoDoc bson.M
 	if err := db.RunCommand(s.ctx, buildInfoCmd).Decode(&buildInfoDoc); err != nil {
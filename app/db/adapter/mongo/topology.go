@@ -0,0 +1,89 @@
+package mongo
+
+import (
+	"fmt"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+const (
+	TopologyStandalone = "standalone"
+	TopologyReplicaSet = "replicaSet"
+	TopologySharded    = "sharded"
+)
+
+// Topology describes the deployment the client is connected to, taken from
+// the hello command.
+type Topology struct {
+	Kind     string   `json:"kind"`
+	SetName  string   `json:"setName,omitempty"`
+	Primary  string   `json:"primary,omitempty"`
+	Me       string   `json:"me,omitempty"`
+	Hosts    []string `json:"hosts,omitempty"`
+	Passives []string `json:"passives,omitempty"`
+	Arbiters []string `json:"arbiters,omitempty"`
+}
+
+type helloReply struct {
+	SetName  string   `bson:"setName"`
+	Primary  string   `bson:"primary"`
+	Me       string   `bson:"me"`
+	Msg      string   `bson:"msg"`
+	Hosts    []string `bson:"hosts"`
+	Passives []string `bson:"passives"`
+	Arbiters []string `bson:"arbiters"`
+}
+
+func (s *Source) Topology() (*Topology, error) {
+	admin := s.client.Database("admin")
+	var reply helloReply
+	// hello 从 4.4.2 开始提供，旧版本使用 isMaster
+	if err := admin.RunCommand(s.ctx, bson.D{{Key: "hello", Value: 1}}).Decode(&reply); err != nil {
+		if err := admin.RunCommand(s.ctx, bson.D{{Key: "isMaster", Value: 1}}).Decode(&reply); err != nil {
+			return nil, err
+		}
+	}
+	return reply.topology(), nil
+}
+
+func (r *helloReply) topology() *Topology {
+	topology := &Topology{Kind: TopologyStandalone, Me: r.Me}
+	switch {
+	case r.Msg == "isdbgrid":
+		topology.Kind = TopologySharded
+	case r.SetName != "":
+		topology.Kind = TopologyReplicaSet
+		topology.SetName = r.SetName
+		topology.Primary = r.Primary
+		topology.Hosts = r.Hosts
+		topology.Passives = r.Passives
+		topology.Arbiters = r.Arbiters
+	}
+	return topology
+}
+
+// String 用于连接测试的提示，例如 replica set rs0: a:27017 (primary), b:27017
+func (t *Topology) String() string {
+	switch t.Kind {
+	case TopologySharded:
+		return "sharded cluster via mongos"
+	case TopologyReplicaSet:
+		members := make([]string, 0, len(t.Hosts)+len(t.Passives)+len(t.Arbiters))
+		for _, host := range t.Hosts {
+			if host == t.Primary {
+				host += " (primary)"
+			}
+			members = append(members, host)
+		}
+		for _, host := range t.Passives {
+			members = append(members, host+" (passive)")
+		}
+		for _, host := range t.Arbiters {
+			members = append(members, host+" (arbiter)")
+		}
+		return fmt.Sprintf("replica set %s: %s", t.SetName, strings.Join(members, ", "))
+	default:
+		return "standalone server"
+	}
+}
@@ -2,6 +2,7 @@ package mongo
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
@@ -66,8 +67,15 @@ func (s *Source) ListCollections(databaseName string) {
 	}
 }
 
+// ReadCollection 与 MySQL 查询一样受会话的 maxRows 和 queryTimeout 限制
 func (s *Source) ReadCollection(database string, opt *modules.CollectionDataOptions) (interface{}, error) {
 	ctx := context.Background()
+	if timeout := s.QueryTimeout(); timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	maxRows := int64(s.MaxRows())
 	collection := s.client.Database(database).Collection(opt.PureName)
 	if opt.CountDocuments {
 		count, err := countDocuments(ctx, collection, opt)
@@ -77,14 +85,14 @@ func (s *Source) ReadCollection(database string, opt *modules.CollectionDataOpti
 		}
 		return count, nil
 	} else if opt.Aggregate != nil {
-		rows, err := aggregate(ctx, collection, opt)
+		rows, err := aggregate(ctx, collection, opt, maxRows)
 		if err != nil {
 			logger.Errorf("exec aggregate [database: %s, collection: %s] failed %v", database, opt.PureName, err)
 			return nil, err
 		}
 		return rows, nil
 	} else {
-		rows, err := find(ctx, collection, opt)
+		rows, err := find(ctx, collection, opt, maxRows)
 		if err != nil {
 			logger.Errorf("exec find [database: %s, collection: %s] failed %v", database, opt.PureName, err)
 			return nil, err
@@ -97,20 +105,28 @@ func countDocuments(ctx context.Context, collection *mongo.Collection, opt *modu
 	return collection.CountDocuments(ctx, opt.Condition)
 }
 
-func aggregate(ctx context.Context, collection *mongo.Collection, opt *modules.CollectionDataOptions) ([]bson.M, error) {
-	results := make([]bson.M, 0)
+func aggregate(ctx context.Context, collection *mongo.Collection, opt *modules.CollectionDataOptions, maxRows int64) ([]bson.M, error) {
 	cursor, err := collection.Aggregate(ctx, []bson.D{})
 	if err != nil {
 		return nil, err
 	}
-	err = cursor.All(ctx, &results)
-	return results, err
+	defer cursor.Close(ctx)
+	results := make([]bson.M, 0)
+	for (maxRows <= 0 || int64(len(results)) < maxRows) && cursor.Next(ctx) {
+		var doc bson.M
+		if err = cursor.Decode(&doc); err != nil {
+			return nil, err
+		}
+		results = append(results, doc)
+	}
+	return results, cursor.Err()
 }
 
-func find(ctx context.Context, collection *mongo.Collection, opt *modules.CollectionDataOptions) ([]bson.M, error) {
+func find(ctx context.Context, collection *mongo.Collection, opt *modules.CollectionDataOptions, maxRows int64) ([]bson.M, error) {
 	results := make([]bson.M, 0)
+	limit := cappedLimit(opt.Limit, maxRows)
 	cursor, err := collection.Find(ctx, opt.Condition, &options.FindOptions{
-		Limit: &opt.Limit,
+		Limit: &limit,
 		Skip:  &opt.Skip,
 		Sort:  opt.Sort,
 	})
@@ -120,3 +136,160 @@ func find(ctx context.Context, collection *mongo.Collection, opt *modules.Collec
 	err = cursor.All(ctx, &results)
 	return results, err
 }
+
+// cappedLimit 请求的 limit 为 0 或超过 maxRows 时使用 maxRows，maxRows 为 0 不限制
+func cappedLimit(limit, maxRows int64) int64 {
+	if maxRows > 0 && (limit <= 0 || limit > maxRows) {
+		return maxRows
+	}
+	return limit
+}
+
+// StreamCollection iterates over every document matching the options without
+// loading the whole result set, limit and skip are honoured when set.
+func (s *Source) StreamCollection(ctx context.Context, database string, opt *modules.CollectionDataOptions, fn func(doc bson.M) error) error {
+	collection := s.client.Database(database).Collection(opt.PureName)
+	findOptions := options.Find().SetSort(opt.Sort)
+	if opt.Limit > 0 {
+		findOptions.SetLimit(opt.Limit)
+	}
+	if opt.Skip > 0 {
+		findOptions.SetSkip(opt.Skip)
+	}
+
+	condition := opt.Condition
+	if condition == nil {
+		condition = map[string]interface{}{}
+	}
+
+	cursor, err := collection.Find(ctx, condition, findOptions)
+	if err != nil {
+		logger.Errorf("exec stream find [database: %s, collection: %s] failed %v", database, opt.PureName, err)
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var doc bson.M
+		if err = cursor.Decode(&doc); err != nil {
+			return err
+		}
+		if err = fn(doc); err != nil {
+			return err
+		}
+	}
+
+	return cursor.Err()
+}
+
+// CollectionKeys returns the top-level keys of all documents matching the condition.
+// The keys are collected on the server, the documents themselves are not transferred.
+func (s *Source) CollectionKeys(ctx context.Context, database string, opt *modules.CollectionDataOptions) ([]string, error) {
+	condition := opt.Condition
+	if condition == nil {
+		condition = map[string]interface{}{}
+	}
+	results, err := s.aggregateAll(ctx, database, opt.PureName, mongo.Pipeline{
+		{{Key: "$match", Value: condition}},
+		{{Key: "$project", Value: bson.D{{Key: "keys", Value: bson.D{{Key: "$objectToArray", Value: "$$ROOT"}}}}}},
+		{{Key: "$unwind", Value: "$keys"}},
+		{{Key: "$group", Value: bson.D{{Key: "_id", Value: "$keys.k"}}}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	keys := make([]string, 0, len(results))
+	for _, result := range results {
+		if key, ok := result["_id"].(string); ok {
+			keys = append(keys, key)
+		}
+	}
+	return keys, nil
+}
+
+// CreateCollection creates an empty collection, an existing one is not an error.
+func (s *Source) CreateCollection(ctx context.Context, database, name string) error {
+	err := s.client.Database(database).CreateCollection(ctx, name)
+	var commandErr mongo.CommandError
+	if errors.As(err, &commandErr) && commandErr.Name == "NamespaceExists" {
+		return nil
+	}
+	return err
+}
+
+// InsertDocuments inserts a batch unordered, so one bad document does not stop
+// the others, failed documents are reported through mongo.BulkWriteException.
+func (s *Source) InsertDocuments(ctx context.Context, database, collection string, docs []interface{}) error {
+	_, err := s.client.Database(database).Collection(collection).InsertMany(ctx, docs, options.InsertMany().SetOrdered(false))
+	return err
+}
+
+// DeleteDocuments removes every document matching filter and returns how many were deleted.
+func (s *Source) DeleteDocuments(ctx context.Context, database, collection string, filter interface{}) (int64, error) {
+	result, err := s.client.Database(database).Collection(collection).DeleteMany(ctx, filter)
+	if err != nil {
+		return 0, err
+	}
+	return result.DeletedCount, nil
+}
+
+// UpsertDocuments replaces the documents matched by the key fields, inserting
+// the ones that do not exist yet.
+func (s *Source) UpsertDocuments(ctx context.Context, database, collection string, keys []string, docs []bson.M) error {
+	if len(docs) == 0 {
+		return nil
+	}
+	models := make([]mongo.WriteModel, 0, len(docs))
+	for _, doc := range docs {
+		filter := bson.M{}
+		for _, key := range keys {
+			filter[key] = doc[key]
+		}
+		models = append(models, mongo.NewReplaceOneModel().SetFilter(filter).SetReplacement(doc).SetUpsert(true))
+	}
+	_, err := s.client.Database(database).Collection(collection).BulkWrite(ctx, models, options.BulkWrite().SetOrdered(false))
+	return err
+}
+
+// Explain runs the find, count or aggregate described by opt through the explain
+// command with executionStats verbosity and returns the raw output.
+func (s *Source) Explain(ctx context.Context, database string, opt *modules.CollectionDataOptions) (bson.M, error) {
+	condition := opt.Condition
+	if condition == nil {
+		condition = map[string]interface{}{}
+	}
+
+	var command bson.D
+	switch {
+	case opt.Aggregate != nil:
+		command = bson.D{{Key: "aggregate", Value: opt.PureName}, {Key: "pipeline", Value: opt.Aggregate}, {Key: "cursor", Value: bson.M{}}}
+	case opt.CountDocuments:
+		command = bson.D{{Key: "count", Value: opt.PureName}, {Key: "query", Value: condition}}
+	default:
+		command = bson.D{{Key: "find", Value: opt.PureName}, {Key: "filter", Value: condition}}
+		if len(opt.Sort) > 0 {
+			command = append(command, bson.E{Key: "sort", Value: opt.Sort})
+		}
+		if opt.Skip > 0 {
+			command = append(command, bson.E{Key: "skip", Value: opt.Skip})
+		}
+		if opt.Limit > 0 {
+			command = append(command, bson.E{Key: "limit", Value: opt.Limit})
+		}
+	}
+
+	var result bson.M
+	err := s.client.Database(database).RunCommand(ctx, bson.D{
+		{Key: "explain", Value: command},
+		{Key: "verbosity", Value: "executionStats"},
+	}).Decode(&result)
+	if err != nil {
+		logger.Errorf("exec explain [database: %s, collection: %s] failed %v", database, opt.PureName, err)
+		return nil, err
+	}
+	return result, nil
+}
+
+func (s *Source) CountDocuments(ctx context.Context, database, collection string, filter interface{}) (int64, error) {
+	return s.client.Database(database).Collection(collection).CountDocuments(ctx, filter)
+}
@@ -48,3 +48,17 @@ func TestAggregate(t *testing.T) {
 		fmt.Println(documents)
 	})
 }
+
+func TestCappedLimit(t *testing.T) {
+	for _, c := range []struct{ limit, maxRows, want int64 }{
+		{0, 2000, 2000},
+		{50, 2000, 50},
+		{5000, 2000, 2000},
+		{0, 0, 0},
+		{50, 0, 50},
+	} {
+		if got := cappedLimit(c.limit, c.maxRows); got != c.want {
+			t.Fatalf("cappedLimit(%d, %d) = %d, want %d", c.limit, c.maxRows, got, c.want)
+		}
+	}
+}
@@ -1,20 +1,83 @@
 package mysql
 
 import (
+	"context"
 	"database/sql"
+	"errors"
+
+	mysqldriver "github.com/go-sql-driver/mysql"
 	"gorm.io/gorm"
+	"tinydb/app/db"
 	"tinydb/app/db/standard/modules"
 )
 
+// erUnsupportedPS ER_UNSUPPORTED_PS，该语句不支持预处理协议
+const erUnsupportedPS = 1295
+
 type Query struct {
 	Rows    *sql.Rows
 	Columns []*modules.Column `json:"columns"`
 }
 
-func execute(db *gorm.DB, sql string) (*Query, error) {
-	rows, err := db.Raw(sql).Rows()
+func execute(db *gorm.DB, sql string, args ...interface{}) (*Query, error) {
+	rows, err := db.Raw(sql, args...).Rows()
 	if err != nil {
 		return nil, err
 	}
 	return &Query{Rows: rows, Columns: getSqlColumns(rows)}, nil
 }
+
+// Conn 从连接池中取出一个独占连接，执行脚本时 USE/SET/临时表 等会话状态才能保持
+func (s *Source) Conn(ctx context.Context) (*sql.Conn, error) {
+	if s.sqlDB == nil {
+		return nil, db.ErrNotConnected
+	}
+	database, err := s.sqlDB.DB()
+	if err != nil {
+		return nil, err
+	}
+	return database.Conn(ctx)
+}
+
+// statementCache 会话的预处理语句缓存，第一次使用时创建
+func (s *Source) statementCache() *stmtCache {
+	s.sqlDBMu.Lock()
+	defer s.sqlDBMu.Unlock()
+	if s.stmts == nil {
+		s.stmts = newStmtCache(stmtCacheSize)
+	}
+	return s.stmts
+}
+
+// queryPrepared 用缓存的预处理语句执行参数化查询，MySQL 不能预处理的语句直接执行
+func (s *Source) queryPrepared(ctx context.Context, query string, args ...interface{}) (*Query, error) {
+	database, err := s.sqlDB.DB()
+	if err != nil {
+		return nil, err
+	}
+	cache := s.statementCache()
+	cached, err := cache.acquire(ctx, database, query)
+	var mysqlErr *mysqldriver.MySQLError
+	if errors.As(err, &mysqlErr) && mysqlErr.Number == erUnsupportedPS {
+		return execute(s.sqlDB.WithContext(ctx), query, args...)
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer cache.release(cached)
+	rows, err := cached.stmt.QueryContext(ctx, args...)
+	if err != nil {
+		return nil, err
+	}
+	return &Query{Rows: rows, Columns: getSqlColumns(rows)}, nil
+}
+
+func (s *Source) closeStatements() {
+	s.sqlDBMu.Lock()
+	stmts := s.stmts
+	s.stmts = nil
+	s.sqlDBMu.Unlock()
+	if stmts != nil {
+		stmts.close()
+	}
+}
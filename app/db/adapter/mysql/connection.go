@@ -5,21 +5,87 @@ import (
 	"fmt"
 	"net"
 	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
 	"tinydb/app/db"
+	"tinydb/app/db/script"
+
+	mysqldriver "github.com/go-sql-driver/mysql"
+)
+
+const defaultCharset = "utf8mb4"
+
+var (
+	charsetPattern  = regexp.MustCompile(`^[A-Za-z0-9_]+$`)
+	timeZonePattern = regexp.MustCompile(`^([+-]\d{1,2}:\d{2}|SYSTEM|[A-Za-z_]+(/[A-Za-z0-9_+-]+)*)$`)
 )
 
 // ConnectionURL implements a MSSQL connection struct.
 type ConnectionURL struct {
-	User     string            `json:"username"`
-	Password string            `json:"password"`
-	Port     string            `json:"port"`
-	Database string            `json:"database"`
-	Host     string            `json:"host"`
-	Socket   string            `json:"socket,omitempty"`
-	Options  map[string]string `json:"options,omitempty"`
+	User     string `json:"username"`
+	Password string `json:"password"`
+	Port     string `json:"port"`
+	Database string `json:"database"`
+	// DefaultDatabase 没有指定 Database 时使用，例如服务器级别的会话
+	DefaultDatabase string `json:"defaultDatabase,omitempty"`
+	Host            string `json:"host"`
+	// Socket 通过 unix socket 连接，可以配合 auth_socket 免密码登录
+	Socket string `json:"socket,omitempty"`
+	// Charset 默认 utf8mb4，Collation 必须属于该字符集
+	Charset   string `json:"charset,omitempty"`
+	Collation string `json:"collation,omitempty"`
+	// TimeZone 会话时区，例如 +08:00、Asia/Shanghai 或 SYSTEM
+	TimeZone string `json:"timeZone,omitempty"`
+	// InitSql 每个新建的连接都会执行，可以包含多条语句
+	InitSql                 string            `json:"initSql,omitempty"`
+	ConnectTimeout          time.Duration     `json:"-"`
+	ReadTimeout             time.Duration     `json:"-"`
+	WriteTimeout            time.Duration     `json:"-"`
+	AllowCleartextPasswords bool              `json:"-"`
+	Options                 map[string]string `json:"options,omitempty"`
+	// ReadOnly 由连接的 isReadOnly 设置，会话以只读事务模式打开
+	ReadOnly bool `json:"-"`
 }
 
 func (c ConnectionURL) String() (s string) {
+	return c.dsn(c.database())
+}
+
+// Config 直接构造驱动配置，数据库名不经过 DSN 解析，可以包含 / 和 ? 等字符
+func (c ConnectionURL) Config() (*mysqldriver.Config, error) {
+	cfg, err := mysqldriver.ParseDSN(c.dsn(""))
+	if err != nil {
+		return nil, err
+	}
+	cfg.DBName = c.database()
+	return cfg, nil
+}
+
+// SessionStatements 新建连接后依次执行的语句，只读模式放在 InitSql 之后，
+// InitSql 不能把会话改回读写
+func (c ConnectionURL) SessionStatements() []string {
+	statements := make([]string, 0)
+	for _, statement := range script.Split(c.InitSql) {
+		if text := strings.TrimSpace(statement.Sql); text != "" {
+			statements = append(statements, text)
+		}
+	}
+	if c.ReadOnly {
+		statements = append(statements, "SET SESSION TRANSACTION READ ONLY")
+	}
+	return statements
+}
+
+func (c ConnectionURL) database() string {
+	if c.Database != "" {
+		return c.Database
+	}
+	return c.DefaultDatabase
+}
+
+func (c ConnectionURL) dsn(database string) (s string) {
 	// Adding username.
 	if c.User != "" {
 		s = s + c.User
@@ -49,29 +115,11 @@ func (c ConnectionURL) String() (s string) {
 				port = c.Port
 			}
 		}
-		s = s + fmt.Sprintf("tcp(%s:%s)", host, port)
+		s = s + fmt.Sprintf("tcp(%s)", net.JoinHostPort(host, port))
 	}
 
 	// Adding database (use empty string if not specified)
-	if c.Database != "" {
-		s = s + "/" + c.Database
-	} else {
-		s = s + "/"
-	}
-
-	// Do we have any options?
-	if c.Options == nil {
-		c.Options = map[string]string{}
-	}
-
-	// Default options.
-	if _, ok := c.Options["charset"]; !ok {
-		c.Options["charset"] = "utf8"
-	}
-
-	if _, ok := c.Options["parseTime"]; !ok {
-		c.Options["parseTime"] = "true"
-	}
+	s = s + "/" + database
 
 	// Converting options into URL values.
 	vv := url.Values{}
@@ -80,6 +128,35 @@ func (c ConnectionURL) String() (s string) {
 		vv.Set(k, v)
 	}
 
+	// Default options.
+	if vv.Get("parseTime") == "" {
+		vv.Set("parseTime", "true")
+	}
+	// 指定排序规则时握手即使用该排序规则，再发送 charset 会执行 SET NAMES 覆盖它
+	if c.Collation != "" {
+		vv.Set("collation", c.Collation)
+		vv.Del("charset")
+	} else if c.Charset != "" {
+		vv.Set("charset", c.Charset)
+	} else if vv.Get("charset") == "" && vv.Get("collation") == "" {
+		vv.Set("charset", defaultCharset)
+	}
+	if c.TimeZone != "" {
+		vv.Set("time_zone", "'"+c.TimeZone+"'")
+	}
+	for key, timeout := range map[string]time.Duration{
+		"timeout":      c.ConnectTimeout,
+		"readTimeout":  c.ReadTimeout,
+		"writeTimeout": c.WriteTimeout,
+	} {
+		if timeout > 0 {
+			vv.Set(key, timeout.String())
+		}
+	}
+	if c.AllowCleartextPasswords {
+		vv.Set("allowCleartextPasswords", "true")
+	}
+
 	// Inserting options.
 	if p := vv.Encode(); p != "" {
 		s = s + "?" + p
@@ -92,6 +169,10 @@ func ParseSetting(connection map[string]interface{}) (*ConnectionURL, error) {
 	if connection == nil {
 		return nil, db.ErrInvalidConnection
 	}
+	connection, err := db.QueryOptions(connection)
+	if err != nil {
+		return nil, err
+	}
 
 	// Handle field name mapping: support both "user"/"username" and "server"/"host"
 	normalized := make(map[string]interface{})
@@ -136,6 +217,73 @@ func ParseSetting(connection map[string]interface{}) (*ConnectionURL, error) {
 	if urlDSN.Host == "" && urlDSN.Socket == "" {
 		return nil, fmt.Errorf("lack of host/server or socket")
 	}
+	if err := urlDSN.parseExtras(normalized); err != nil {
+		return nil, err
+	}
 
 	return urlDSN, nil
 }
+
+// parseExtras 校验字符集、时区、超时等设置，保存的连接中它们都是字符串
+func (c *ConnectionURL) parseExtras(connection map[string]interface{}) error {
+	text := func(key string) string {
+		value, _ := connection[key].(string)
+		return strings.TrimSpace(value)
+	}
+
+	if c.Port != "" {
+		if port, err := strconv.Atoi(c.Port); err != nil || port < 1 || port > 65535 {
+			return fmt.Errorf("invalid port '%s'", c.Port)
+		}
+	}
+	if c.Charset != "" && !charsetPattern.MatchString(c.Charset) {
+		return fmt.Errorf("invalid charset '%s'", c.Charset)
+	}
+	if c.Collation != "" {
+		charset := c.Charset
+		if charset == "" {
+			charset = defaultCharset
+		}
+		if !charsetPattern.MatchString(c.Collation) || !strings.HasPrefix(c.Collation, charset+"_") {
+			return fmt.Errorf("collation '%s' does not belong to charset %s", c.Collation, charset)
+		}
+	}
+	if c.TimeZone != "" && !timeZonePattern.MatchString(c.TimeZone) {
+		return fmt.Errorf("invalid time zone '%s'", c.TimeZone)
+	}
+	for key, target := range map[string]*time.Duration{
+		"connectTimeout": &c.ConnectTimeout,
+		"readTimeout":    &c.ReadTimeout,
+		"writeTimeout":   &c.WriteTimeout,
+	} {
+		value := text(key)
+		if value == "" {
+			continue
+		}
+		timeout, err := parseTimeout(value)
+		if err != nil {
+			return fmt.Errorf("invalid %s '%s'", key, value)
+		}
+		*target = timeout
+	}
+	if value := text("allowCleartextPasswords"); value != "" {
+		allow, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("invalid allowCleartextPasswords '%s'", value)
+		}
+		c.AllowCleartextPasswords = allow
+	}
+	return nil
+}
+
+// parseTimeout 接受 10s 这样的时长，纯数字按秒计算
+func parseTimeout(value string) (time.Duration, error) {
+	if seconds, err := strconv.ParseFloat(value, 64); err == nil {
+		value = strconv.FormatFloat(seconds, 'f', -1, 64) + "s"
+	}
+	timeout, err := time.ParseDuration(value)
+	if err != nil || timeout < 0 {
+		return 0, fmt.Errorf("invalid timeout")
+	}
+	return timeout, nil
+}
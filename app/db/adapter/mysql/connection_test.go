@@ -21,3 +21,77 @@ func Test_ParseSetting(t *testing.T) {
 
 	logger.Infof("%s", setting.String())
 }
+
+func TestConnectionURLDsn(t *testing.T) {
+	cases := []struct {
+		connection map[string]interface{}
+		want       string
+	}{
+		{map[string]interface{}{"user": "root", "host": "localhost", "port": "3306"},
+			"root@tcp(localhost:3306)/?charset=utf8mb4&parseTime=true"},
+		{map[string]interface{}{"user": "root", "password": "p", "host": "db", "port": "3307", "defaultDatabase": "shop",
+			"charset": "utf8mb4", "collation": "utf8mb4_unicode_ci", "timeZone": "+08:00",
+			"connectTimeout": "5", "readTimeout": "30s", "writeTimeout": "1m", "allowCleartextPasswords": "true"},
+			"root:p@tcp(db:3307)/shop?allowCleartextPasswords=true&collation=utf8mb4_unicode_ci&parseTime=true&readTimeout=30s&time_zone=%27%2B08%3A00%27&timeout=5s&writeTimeout=1m0s"},
+		{map[string]interface{}{"user": "app", "socket": "/var/run/mysqld/mysqld.sock", "database": "orders", "defaultDatabase": "shop", "charset": "latin1"},
+			"app@unix(/var/run/mysqld/mysqld.sock)/orders?charset=latin1&parseTime=true"},
+		{map[string]interface{}{"user": "root", "host": "db", "options": "charset=utf8&parseTime=false"},
+			"root@tcp(db:3306)/?charset=utf8&parseTime=false"},
+	}
+	for _, c := range cases {
+		setting, err := ParseSetting(c.connection)
+		if err != nil {
+			t.Fatalf("ParseSetting(%v) failed: %v", c.connection, err)
+		}
+		if got := setting.String(); got != c.want {
+			t.Fatalf("String() = %s, want %s", got, c.want)
+		}
+	}
+}
+
+func TestConnectionURLConfig(t *testing.T) {
+	setting, err := ParseSetting(map[string]interface{}{"user": "root", "host": "db", "database": "a/b?c", "timeZone": "Asia/Shanghai"})
+	if err != nil {
+		t.Fatalf("ParseSetting failed: %v", err)
+	}
+	cfg, err := setting.Config()
+	if err != nil {
+		t.Fatalf("Config failed: %v", err)
+	}
+	if cfg.DBName != "a/b?c" || cfg.Params["time_zone"] != "'Asia/Shanghai'" || cfg.Params["charset"] != "utf8mb4" {
+		t.Fatalf("unexpected config %+v", cfg)
+	}
+}
+
+func TestSessionStatements(t *testing.T) {
+	setting := ConnectionURL{ReadOnly: true, InitSql: "SET sql_mode='ANSI';\n-- comment\nSET SESSION TRANSACTION READ WRITE;"}
+	statements := setting.SessionStatements()
+	if len(statements) != 3 || statements[0] != "SET sql_mode='ANSI'" || statements[1] != "SET SESSION TRANSACTION READ WRITE" || statements[2] != "SET SESSION TRANSACTION READ ONLY" {
+		t.Fatalf("unexpected statements %q", statements)
+	}
+}
+
+func TestParseSettingExtrasErrors(t *testing.T) {
+	base := map[string]interface{}{"user": "root", "host": "db"}
+	for _, extra := range []map[string]interface{}{
+		{"port": "99999"},
+		{"charset": "utf8;drop"},
+		{"collation": "latin1_swedish_ci"},
+		{"charset": "latin1", "collation": "utf8mb4_bin"},
+		{"timeZone": "'; SET x=1"},
+		{"connectTimeout": "soon"},
+		{"readTimeout": "-1"},
+		{"allowCleartextPasswords": "maybe"},
+	} {
+		connection := map[string]interface{}{}
+		for k, v := range base {
+			connection[k] = v
+		}
+		for k, v := range extra {
+			connection[k] = v
+		}
+		if _, err := ParseSetting(connection); err == nil {
+			t.Fatalf("ParseSetting(%v) should fail", extra)
+		}
+	}
+}
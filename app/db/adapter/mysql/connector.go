@@ -0,0 +1,44 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+
+	mysqldriver "github.com/go-sql-driver/mysql"
+)
+
+// sessionConnector 连接池中每个新建的物理连接都先执行 statements，例如
+// SET SESSION TRANSACTION READ ONLY 和连接设置的初始化 SQL
+type sessionConnector struct {
+	driver.Connector
+	statements []string
+}
+
+func (c sessionConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := c.Connector.Connect(ctx)
+	if err != nil || len(c.statements) == 0 {
+		return conn, err
+	}
+	execer, ok := conn.(driver.ExecerContext)
+	if !ok {
+		_ = conn.Close()
+		return nil, fmt.Errorf("mysql driver connection cannot execute statements")
+	}
+	for _, statement := range c.statements {
+		if _, err = execer.ExecContext(ctx, statement, nil); err != nil {
+			_ = conn.Close()
+			return nil, fmt.Errorf("%s: %w", statement, err)
+		}
+	}
+	return conn, nil
+}
+
+func openPool(cfg *mysqldriver.Config, statements []string) (*sql.DB, error) {
+	connector, err := mysqldriver.NewConnector(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return sql.OpenDB(sessionConnector{Connector: connector, statements: statements}), nil
+}
@@ -26,6 +26,7 @@ type Source struct {
 	mu             sync.Mutex // guards ctx, txOptions
 	sqlDBMu        sync.Mutex // guards sess, baseTx
 	sqlDB          *gorm.DB
+	stmts          *stmtCache
 	sessID         uint64
 }
 
@@ -74,10 +75,23 @@ func (s *Source) open() error {
 	}
 	log.Printf("MySQL DSN: %s\n", logDSN)
 
-	_db, err := gorm.Open(mysql.New(mysql.Config{
+	config := mysql.Config{
 		DriverName: Adapter,
 		DSN:        dsn,
-	}), &gorm.Config{
+	}
+	if connURL, ok := s.connURL.(*ConnectionURL); ok {
+		cfg, err := connURL.Config()
+		if err != nil {
+			return fmt.Errorf("failed to connect to MySQL: %w", err)
+		}
+		pool, err := openPool(cfg, connURL.SessionStatements())
+		if err != nil {
+			return fmt.Errorf("failed to connect to MySQL: %w", err)
+		}
+		config.Conn = pool
+	}
+
+	_db, err := gorm.Open(mysql.New(config), &gorm.Config{
 		Logger: newLogger,
 	})
 	if err != nil {
@@ -0,0 +1,66 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+
+	"tinydb/app/db"
+	"tinydb/app/db/standard/modules"
+	"tinydb/app/pkg/logger"
+)
+
+// ProcessList returns the sessions of the server, longest running first.
+func (s *Source) ProcessList(ctx context.Context) ([]*modules.ServerProcess, error) {
+	if s.sqlDB == nil {
+		return nil, db.ErrNotConnected
+	}
+	rows, err := s.sqlDB.WithContext(ctx).Raw("SELECT ID, USER, HOST, DB, COMMAND, TIME, STATE, INFO " +
+		"FROM information_schema.PROCESSLIST ORDER BY TIME DESC, ID").Rows()
+	if err != nil {
+		logger.Errorf("get mysql process list failed: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	processes := make([]*modules.ServerProcess, 0)
+	for rows.Next() {
+		var id, seconds int64
+		var user, host, database, command, state, info sql.NullString
+		if err = rows.Scan(&id, &user, &host, &database, &command, &seconds, &state, &info); err != nil {
+			return nil, err
+		}
+		processes = append(processes, &modules.ServerProcess{
+			Id:       strconv.FormatInt(id, 10),
+			User:     user.String,
+			Host:     host.String,
+			Database: database.String,
+			Command:  command.String,
+			Time:     seconds,
+			State:    state.String,
+			Info:     info.String,
+		})
+	}
+	return processes, rows.Err()
+}
+
+// KillProcess terminates a connection, or only its running statement when queryOnly is set.
+func (s *Source) KillProcess(ctx context.Context, id string, queryOnly bool) error {
+	if s.sqlDB == nil {
+		return db.ErrNotConnected
+	}
+	processId, err := strconv.ParseUint(id, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid process id '%s'", id)
+	}
+	statement := "KILL %d"
+	if queryOnly {
+		statement = "KILL QUERY %d"
+	}
+	if err = s.sqlDB.WithContext(ctx).Exec(fmt.Sprintf(statement, processId)).Error; err != nil {
+		logger.Errorf("kill mysql process %d failed: %v", processId, err)
+		return err
+	}
+	return nil
+}
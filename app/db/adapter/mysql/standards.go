@@ -1,6 +1,7 @@
 package mysql
 
 import (
+	"context"
 	"fmt"
 	"regexp"
 	"strings"
@@ -57,6 +58,7 @@ func (s *Source) Close() error {
 	if s.sqlDB == nil {
 		return nil
 	}
+	s.closeStatements()
 	database, err := s.sqlDB.DB()
 	if err != nil {
 		return nil
@@ -97,8 +99,14 @@ func (s *Source) CreateDatabase(name string) error {
 }
 
 func (s *Source) Query(sql string) (interface{}, error) {
+	return s.QueryWithArgs(sql)
+}
+
+// QueryWithArgs runs a statement whose ? placeholders are bound to args by the
+// driver, the values never end up formatted into the SQL text.
+func (s *Source) QueryWithArgs(sql string, args ...interface{}) (interface{}, error) {
 	// Protect the app from returning huge result sets (Wails marshalling + UI rendering can hang).
-	const maxRows = 2000
+	maxRows := s.MaxRows()
 
 	// Validate SQL to prevent syntax errors from empty identifiers
 	// Trim SQL to handle trailing whitespace/newlines
@@ -182,7 +190,20 @@ func (s *Source) Query(sql string) (interface{}, error) {
 	}
 
 	resultRows := make([]map[string]interface{}, 0, 64)
-	sqlQuery, err := execute(s.sqlDB, sql)
+	ctx := s.ctx
+	if timeout := s.QueryTimeout(); timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(s.ctx, timeout)
+		defer cancel()
+	}
+	// 只有参数化查询使用预处理语句缓存，编辑器中的普通语句每次直接执行
+	var sqlQuery *Query
+	var err error
+	if len(args) > 0 && s.PreparedStatementCacheEnabled() {
+		sqlQuery, err = s.queryPrepared(ctx, sql, args...)
+	} else {
+		sqlQuery, err = execute(s.sqlDB.WithContext(ctx), sql, args...)
+	}
 	if err != nil {
 		logger.Errorf("get mysql query failed: %v", err)
 		return &modules.MysqlRowsResult{Rows: resultRows, Columns: []*modules.Column{}}, err
@@ -198,7 +219,7 @@ func (s *Source) Query(sql string) (interface{}, error) {
 		}
 		resultRows = append(resultRows, row)
 		count++
-		if count >= maxRows {
+		if maxRows > 0 && count >= maxRows {
 			break
 		}
 	}
@@ -208,3 +229,36 @@ func (s *Source) Query(sql string) (interface{}, error) {
 		Columns: sqlQuery.Columns,
 	}, nil
 }
+
+// Stream runs a query without the maxRows cap and hands every row to fn in
+// column order, so callers such as exporters can write results as they arrive.
+func (s *Source) Stream(ctx context.Context, query string, fn func(columns []string, row map[string]interface{}) error) error {
+	if s.sqlDB == nil {
+		return db.ErrNotConnected
+	}
+
+	rows, err := s.sqlDB.WithContext(ctx).Raw(query).Rows()
+	if err != nil {
+		logger.Errorf("stream mysql query failed: %v", err)
+		return err
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return err
+	}
+
+	for rows.Next() {
+		var row map[string]interface{}
+		if err = s.sqlDB.ScanRows(rows, &row); err != nil {
+			logger.Errorf("stream mysql query row scanRows failed: %v", err)
+			return err
+		}
+		if err = fn(columns, row); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
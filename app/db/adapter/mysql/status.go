@@ -0,0 +1,42 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+
+	"tinydb/app/db"
+	"tinydb/app/pkg/logger"
+)
+
+// GlobalStatus returns the server counters of SHOW GLOBAL STATUS.
+func (s *Source) GlobalStatus(ctx context.Context) (map[string]string, error) {
+	return s.showPairs(ctx, "SHOW GLOBAL STATUS")
+}
+
+// GlobalVariables returns the server configuration of SHOW GLOBAL VARIABLES.
+func (s *Source) GlobalVariables(ctx context.Context) (map[string]string, error) {
+	return s.showPairs(ctx, "SHOW GLOBAL VARIABLES")
+}
+
+func (s *Source) showPairs(ctx context.Context, statement string) (map[string]string, error) {
+	if s.sqlDB == nil {
+		return nil, db.ErrNotConnected
+	}
+	rows, err := s.sqlDB.WithContext(ctx).Raw(statement).Rows()
+	if err != nil {
+		logger.Errorf("exec %s failed: %v", statement, err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	values := make(map[string]string)
+	for rows.Next() {
+		var name string
+		var value sql.NullString
+		if err = rows.Scan(&name, &value); err != nil {
+			return nil, err
+		}
+		values[name] = value.String
+	}
+	return values, rows.Err()
+}
@@ -0,0 +1,108 @@
+package mysql
+
+import (
+	"container/list"
+	"context"
+	"database/sql"
+	"sync"
+)
+
+// stmtCacheSize 每个会话最多保留的预处理语句，远低于 max_prepared_stmt_count 的默认值
+const stmtCacheSize = 64
+
+// stmtCache 参数化查询的预处理语句，超出容量时淘汰最久未用的语句。
+// 被淘汰的语句在最后一个使用者归还后才关闭
+type stmtCache struct {
+	mu      sync.Mutex
+	size    int
+	order   *list.List // 最近使用的在前，元素为 *cachedStmt
+	entries map[string]*list.Element
+}
+
+type cachedStmt struct {
+	query   string
+	stmt    *sql.Stmt
+	refs    int
+	evicted bool
+}
+
+func newStmtCache(size int) *stmtCache {
+	return &stmtCache{size: size, order: list.New(), entries: make(map[string]*list.Element)}
+}
+
+// acquire 返回 query 的预处理语句，没有缓存时在 database 上 prepare，用完后调用 release
+func (c *stmtCache) acquire(ctx context.Context, database *sql.DB, query string) (*cachedStmt, error) {
+	if cached := c.hit(query); cached != nil {
+		return cached, nil
+	}
+	stmt, err := database.PrepareContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	// 并发的查询已经缓存了同一条语句
+	if el, ok := c.entries[query]; ok {
+		_ = stmt.Close()
+		return c.use(el), nil
+	}
+	cached := &cachedStmt{query: query, stmt: stmt, refs: 1}
+	c.entries[query] = c.order.PushFront(cached)
+	for c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		c.evict(oldest.Value.(*cachedStmt))
+	}
+	return cached, nil
+}
+
+func (c *stmtCache) hit(query string) *cachedStmt {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if el, ok := c.entries[query]; ok {
+		return c.use(el)
+	}
+	return nil
+}
+
+func (c *stmtCache) use(el *list.Element) *cachedStmt {
+	c.order.MoveToFront(el)
+	cached := el.Value.(*cachedStmt)
+	cached.refs++
+	return cached
+}
+
+func (c *stmtCache) evict(cached *cachedStmt) {
+	delete(c.entries, cached.query)
+	cached.evicted = true
+	if cached.refs == 0 {
+		_ = cached.stmt.Close()
+	}
+}
+
+// release 归还 acquire 得到的语句，结果集仍然打开时 database/sql 会推迟真正的关闭
+func (c *stmtCache) release(cached *cachedStmt) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	cached.refs--
+	if cached.evicted && cached.refs == 0 {
+		_ = cached.stmt.Close()
+	}
+}
+
+// len 缓存中的语句数
+func (c *stmtCache) len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
+
+// close 淘汰全部语句，会话关闭时调用
+func (c *stmtCache) close() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for el := c.order.Front(); el != nil; el = el.Next() {
+		c.evict(el.Value.(*cachedStmt))
+	}
+	c.order.Init()
+}
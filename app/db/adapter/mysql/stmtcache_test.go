@@ -0,0 +1,159 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"sync"
+	"testing"
+)
+
+// countingDriver 记录 prepare 和 close 的次数，不连接数据库
+type countingDriver struct {
+	mu       sync.Mutex
+	prepared map[string]int
+	closed   map[string]int
+}
+
+func (d *countingDriver) Open(string) (driver.Conn, error) {
+	return &countingConn{driver: d}, nil
+}
+
+type countingConn struct {
+	driver *countingDriver
+}
+
+func (c *countingConn) Prepare(query string) (driver.Stmt, error) {
+	c.driver.mu.Lock()
+	defer c.driver.mu.Unlock()
+	c.driver.prepared[query]++
+	return &countingStmt{driver: c.driver, query: query}, nil
+}
+
+func (c *countingConn) Close() error {
+	return nil
+}
+
+func (c *countingConn) Begin() (driver.Tx, error) {
+	return nil, fmt.Errorf("not supported")
+}
+
+type countingStmt struct {
+	driver *countingDriver
+	query  string
+}
+
+func (s *countingStmt) Close() error {
+	s.driver.mu.Lock()
+	defer s.driver.mu.Unlock()
+	s.driver.closed[s.query]++
+	return nil
+}
+
+func (s *countingStmt) NumInput() int {
+	return -1
+}
+
+func (s *countingStmt) Exec([]driver.Value) (driver.Result, error) {
+	return driver.ResultNoRows, nil
+}
+
+func (s *countingStmt) Query([]driver.Value) (driver.Rows, error) {
+	return &emptyRows{}, nil
+}
+
+type emptyRows struct{}
+
+func (r *emptyRows) Columns() []string {
+	return []string{"id"}
+}
+
+func (r *emptyRows) Close() error {
+	return nil
+}
+
+func (r *emptyRows) Next([]driver.Value) error {
+	return io.EOF
+}
+
+var registerCounting sync.Once
+var counting = &countingDriver{}
+
+func countingDB(t *testing.T) *sql.DB {
+	registerCounting.Do(func() {
+		sql.Register("counting", counting)
+	})
+	counting.mu.Lock()
+	counting.prepared, counting.closed = map[string]int{}, map[string]int{}
+	counting.mu.Unlock()
+	database, err := sql.Open("counting", "")
+	if err != nil {
+		t.Fatalf("open failed: %v", err)
+	}
+	database.SetMaxOpenConns(1)
+	t.Cleanup(func() { _ = database.Close() })
+	return database
+}
+
+func TestStmtCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	database := countingDB(t)
+	cache := newStmtCache(2)
+	ctx := context.Background()
+	use := func(query string) {
+		cached, err := cache.acquire(ctx, database, query)
+		if err != nil {
+			t.Fatalf("acquire %s failed: %v", query, err)
+		}
+		cache.release(cached)
+	}
+
+	use("select ?")
+	use("select ?, ?")
+	use("select ?")
+	use("select ?, ?, ?")
+	if cache.len() != 2 {
+		t.Fatalf("the cache should be bounded, got %d statements", cache.len())
+	}
+	counting.mu.Lock()
+	prepared, closed := counting.prepared["select ?"], counting.closed["select ?, ?"]
+	counting.mu.Unlock()
+	if prepared != 1 || closed != 1 {
+		t.Fatalf("a cached statement should be prepared once and the least recently used one closed, got %d %d", prepared, closed)
+	}
+}
+
+func TestStmtCacheClosesEvictedAfterRelease(t *testing.T) {
+	database := countingDB(t)
+	cache := newStmtCache(1)
+	ctx := context.Background()
+	held, err := cache.acquire(ctx, database, "select ?")
+	if err != nil {
+		t.Fatalf("acquire failed: %v", err)
+	}
+	other, err := cache.acquire(ctx, database, "select ?, ?")
+	if err != nil {
+		t.Fatalf("acquire failed: %v", err)
+	}
+	cache.release(other)
+	rows, err := held.stmt.Query(1)
+	if err != nil {
+		t.Fatalf("an evicted statement should stay usable until released: %v", err)
+	}
+	_ = rows.Close()
+	counting.mu.Lock()
+	closed := counting.closed["select ?"]
+	counting.mu.Unlock()
+	if closed != 0 {
+		t.Fatalf("a statement in use should not be closed")
+	}
+	cache.release(held)
+	cache.close()
+	counting.mu.Lock()
+	closed, closedOther := counting.closed["select ?"], counting.closed["select ?, ?"]
+	counting.mu.Unlock()
+	if closed != 1 || closedOther != 1 {
+		t.Fatalf("released and remaining statements should be closed, got %d %d", closed, closedOther)
+	}
+}
@@ -1,10 +1,13 @@
 package mysql
 
 import (
+	"context"
 	"database/sql"
 	"strings"
 
+	"tinydb/app/db"
 	"tinydb/app/db/standard/modules"
+	"tinydb/app/pkg/logger"
 )
 
 func (s *Source) UniqueNames(sql string) (*modules.MysqlRowsResult, error) {
@@ -97,8 +100,8 @@ func (s *Source) Tables(sql string) (*modules.MysqlRowsResult, error) {
 	}, nil
 }
 
-func (s *Source) Columns(sql string) (*modules.MysqlRowsResult, error) {
-	sqlQuery, err := execute(s.sqlDB, sql)
+func (s *Source) Columns(sql string, args ...interface{}) (*modules.MysqlRowsResult, error) {
+	sqlQuery, err := execute(s.sqlDB, sql, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -144,8 +147,8 @@ func getSqlColumns(rows *sql.Rows) (columns []*modules.Column) {
 	return columns
 }
 
-func (s *Source) PrimaryKeys(sql string) (*modules.MysqlRowsResult, error) {
-	rows, err := s.sqlDB.Raw(sql).Rows()
+func (s *Source) PrimaryKeys(sql string, args ...interface{}) (*modules.MysqlRowsResult, error) {
+	rows, err := s.sqlDB.Raw(sql, args...).Rows()
 	if err != nil {
 		return nil, err
 	}
@@ -264,3 +267,21 @@ func (s *Source) Programmables(sql string) (*modules.MysqlRowsResult, error) {
 
 	return &modules.MysqlRowsResult{Rows: programmables, Columns: sqlQuery.Columns}, nil
 }
+
+// Explain returns the plan of a statement, as JSON or, when analyze is set, as
+// the EXPLAIN ANALYZE tree which executes the statement (MySQL 8.0.18+).
+func (s *Source) Explain(ctx context.Context, query string, analyze bool) (string, error) {
+	if s.sqlDB == nil {
+		return "", db.ErrNotConnected
+	}
+	prefix := "EXPLAIN FORMAT=JSON "
+	if analyze {
+		prefix = "EXPLAIN ANALYZE "
+	}
+	var rows []string
+	if err := s.sqlDB.WithContext(ctx).Raw(prefix + query).Scan(&rows).Error; err != nil {
+		logger.Errorf("explain mysql query failed: %v", err)
+		return "", err
+	}
+	return strings.Join(rows, "\n"), nil
+}
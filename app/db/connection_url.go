@@ -1,8 +1,35 @@
 package db
 
+import (
+	"fmt"
+	"net/url"
+)
+
 // ConnectionURL represents a data source name (DSN).
 type ConnectionURL interface {
 	// String returns the connection string that is going to be passed to the
 	// adapter.
 	String() string
 }
+
+// QueryOptions 连接以字符串字典保存，options 可能是 URL 查询串，统一转换为字典
+func QueryOptions(connection map[string]interface{}) (map[string]interface{}, error) {
+	text, ok := connection["options"].(string)
+	if !ok {
+		return connection, nil
+	}
+	values, err := url.ParseQuery(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid connection options: %w", err)
+	}
+	options := make(map[string]string, len(values))
+	for key := range values {
+		options[key] = values.Get(key)
+	}
+	normalized := make(map[string]interface{}, len(connection))
+	for k, v := range connection {
+		normalized[k] = v
+	}
+	normalized["options"] = options
+	return normalized, nil
+}
@@ -12,6 +12,7 @@ var (
 	ErrInvalidConnection = errors.New(`tinydb: invalid connection`)
 	ErrInvalidDatabase   = errors.New(`tinydb: invalid database`)
 	ErrInvalidCollection = errors.New(`tinydb: invalid collection`)
+	ErrReadOnly          = errors.New(`tinydb: connection is read only`)
 
 	ErrMissingAdapter           = errors.New(`tinydb: missing adapter`)
 	ErrAlreadyWithinTransaction = errors.New(`tinydb: already within a transaction`)
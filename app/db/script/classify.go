@@ -0,0 +1,192 @@
+package script
+
+import (
+	"reflect"
+	"strings"
+
+	"github.com/samber/lo"
+)
+
+const (
+	StatementRead  = "read"
+	StatementWrite = "write"
+)
+
+// readKeywords 以这些关键字开头的语句不会修改数据
+var readKeywords = []string{"SELECT", "TABLE", "VALUES", "WITH", "SHOW", "DESCRIBE", "DESC", "EXPLAIN", "HELP", "USE", "SET"}
+
+// writeSetKeywords SET 语句中出现这些关键字时会修改服务器状态或解除只读
+var writeSetKeywords = []string{"GLOBAL", "PERSIST", "PERSIST_ONLY", "PASSWORD", "TRANSACTION",
+	"TRANSACTION_READ_ONLY", "TX_READ_ONLY", "READ_ONLY", "SUPER_READ_ONLY"}
+
+var dataKeywords = []string{"INSERT", "UPDATE", "DELETE", "REPLACE"}
+
+// Classify returns StatementWrite when any statement of the script may change
+// data, schema, privileges or server state. Unknown statements are writes.
+func Classify(sql string) string {
+	for _, statement := range Split(sql) {
+		if classifyWords(keywords(statement.Sql)) == StatementWrite {
+			return StatementWrite
+		}
+	}
+	return StatementRead
+}
+
+func classifyWords(words []string) string {
+	// (SELECT ...) UNION (SELECT ...)
+	for len(words) > 0 && words[0] == "(" {
+		words = words[1:]
+	}
+	if len(words) == 0 {
+		return StatementRead
+	}
+	if !lo.Contains(readKeywords, words[0]) {
+		return StatementWrite
+	}
+	switch words[0] {
+	case "EXPLAIN", "DESCRIBE", "DESC":
+		// EXPLAIN ANALYZE 会真正执行语句
+		if len(words) > 1 && words[1] == "ANALYZE" {
+			return classifyWords(words[2:])
+		}
+	case "SET":
+		if lo.Some(words, writeSetKeywords) {
+			return StatementWrite
+		}
+	case "SELECT", "TABLE", "VALUES", "WITH":
+		depth := 0
+		for i, word := range words {
+			switch word {
+			case "(":
+				depth++
+				continue
+			case ")":
+				depth--
+				continue
+			}
+			if word == "INTO" && i+1 < len(words) && (words[i+1] == "OUTFILE" || words[i+1] == "DUMPFILE") {
+				return StatementWrite
+			}
+			// WITH cte AS (...) DELETE ...，只看括号外的语句关键字，
+			// 排除 SELECT ... FOR UPDATE 和 REPLACE(...)、INSERT(...) 等函数
+			if depth == 0 && lo.Contains(dataKeywords, word) && !(i > 0 && words[i-1] == "FOR") &&
+				!(i+1 < len(words) && words[i+1] == "(") {
+				return StatementWrite
+			}
+		}
+	}
+	return StatementRead
+}
+
+// keywords returns the upper cased words and parentheses of a statement
+// outside of strings, quoted identifiers and comments.
+func keywords(sql string) []string {
+	words := make([]string, 0)
+	for _, t := range tokens(sql) {
+		if !t.quoted && t.text != "." {
+			words = append(words, strings.ToUpper(t.text))
+		}
+	}
+	return words
+}
+
+type token struct {
+	text   string
+	quoted bool
+}
+
+// tokens returns the words, `quoted` identifiers, dots and parentheses of a statement,
+// strings and comments are skipped. The body of /*! */ comments is kept as it
+// is executed by MySQL.
+func tokens(sql string) []token {
+	result := make([]token, 0)
+	n := len(sql)
+	for i := 0; i < n; i++ {
+		c := sql[i]
+		switch {
+		case c == '`':
+			end := skipQuoted(sql, i)
+			if end > i {
+				result = append(result, token{text: strings.ReplaceAll(sql[i+1:end], "``", "`"), quoted: true})
+			}
+			i = end
+		case c == '\'' || c == '"':
+			i = skipQuoted(sql, i)
+		case c == '#' || (c == '-' && i+1 < n && sql[i+1] == '-' && (i+2 == n || sql[i+2] == ' ' || sql[i+2] == '\t')):
+			for i < n && sql[i] != '\n' {
+				i++
+			}
+		case c == '/' && i+2 < n && sql[i+1] == '*' && sql[i+2] == '!':
+			i += 2
+			for i+1 < n && sql[i+1] >= '0' && sql[i+1] <= '9' {
+				i++
+			}
+		case c == '/' && i+1 < n && sql[i+1] == '*':
+			end := strings.Index(sql[i+2:], "*/")
+			if end < 0 {
+				return result
+			}
+			i += end + 3
+		case c == '.' || c == '(' || c == ')':
+			result = append(result, token{text: string(c)})
+		case isIdentStart(c):
+			end := i + 1
+			for end < n && isIdentChar(sql[end]) {
+				end++
+			}
+			result = append(result, token{text: sql[i:end]})
+			i = end - 1
+		case c >= '0' && c <= '9':
+			for i+1 < n && isIdentChar(sql[i+1]) {
+				i++
+			}
+		}
+	}
+	return result
+}
+
+// MongoPipelineWrites reports whether an aggregation pipeline writes with
+// $out or $merge, at any depth.
+func MongoPipelineWrites(pipeline interface{}) bool {
+	return pipelineWrites(reflect.ValueOf(pipeline))
+}
+
+func pipelineWrites(value reflect.Value) bool {
+	for value.Kind() == reflect.Interface || value.Kind() == reflect.Ptr {
+		if value.IsNil() {
+			return false
+		}
+		value = value.Elem()
+	}
+	switch value.Kind() {
+	case reflect.Map:
+		iter := value.MapRange()
+		for iter.Next() {
+			if key, ok := iter.Key().Interface().(string); ok && (key == "$out" || key == "$merge") {
+				return true
+			}
+			if pipelineWrites(iter.Value()) {
+				return true
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < value.Len(); i++ {
+			item := value.Index(i)
+			// bson.D 的元素是 {Key, Value} 结构
+			if item.Kind() == reflect.Struct {
+				if key := item.FieldByName("Key"); key.IsValid() && key.Kind() == reflect.String &&
+					(key.String() == "$out" || key.String() == "$merge") {
+					return true
+				}
+				if v := item.FieldByName("Value"); v.IsValid() && pipelineWrites(v) {
+					return true
+				}
+				continue
+			}
+			if pipelineWrites(item) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,59 @@
+package script
+
+import (
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestClassify(t *testing.T) {
+	cases := map[string]string{
+		"select * from users":                                      StatementRead,
+		"  -- comment\n/* block */ SELECT 1; show tables":          StatementRead,
+		"(select 1) union (select 2)":                              StatementRead,
+		"select * from t for update":                               StatementRead,
+		"select 'delete from t' as x":                              StatementRead,
+		"select `update` from t":                                   StatementRead,
+		"explain select * from t":                                  StatementRead,
+		"set @a = 1":                                               StatementRead,
+		"set names utf8mb4":                                        StatementRead,
+		"with c as (select 1) select * from c":                     StatementRead,
+		"select replace(name, 'a', 'b') from t":                    StatementRead,
+		"select insert('abc', 1, 1, 'x')":                          StatementRead,
+		"select * from (select 1) as `delete`":                     StatementRead,
+		"update t set a = 1":                                       StatementWrite,
+		"select 1; delete from t":                                  StatementWrite,
+		"with c as (select id from t) delete from t":               StatementWrite,
+		"explain analyze delete from t":                            StatementWrite,
+		"select * from t into outfile '/tmp/x'":                    StatementWrite,
+		"set session transaction read write":                       StatementWrite,
+		"set transaction_read_only = 0":                            StatementWrite,
+		"set session tx_read_only = off":                           StatementWrite,
+		"set @@session.transaction_read_only = 0":                  StatementWrite,
+		"SET @@global.read_only = 0":                               StatementWrite,
+		"/*!40101 DROP TABLE t */":                                 StatementWrite,
+		"call cleanup()":                                           StatementWrite,
+		"grant select on *.* to 'a'@'%'":                           StatementWrite,
+		"delimiter $$\ncreate procedure p() begin select 1; end$$": StatementWrite,
+	}
+	for sql, expected := range cases {
+		if kind := Classify(sql); kind != expected {
+			t.Fatalf("Classify(%q) = %s, want %s", sql, kind, expected)
+		}
+	}
+}
+
+func TestMongoPipelineWrites(t *testing.T) {
+	if MongoPipelineWrites([]interface{}{map[string]interface{}{"$match": map[string]interface{}{"a": 1}}}) {
+		t.Fatal("a $match pipeline does not write")
+	}
+	if !MongoPipelineWrites([]interface{}{map[string]interface{}{"$match": map[string]interface{}{}}, map[string]interface{}{"$out": "copy"}}) {
+		t.Fatal("$out should be detected")
+	}
+	if !MongoPipelineWrites(bson.A{bson.D{{Key: "$merge", Value: bson.M{"into": "copy"}}}}) {
+		t.Fatal("$merge in a bson.D stage should be detected")
+	}
+	if MongoPipelineWrites(nil) {
+		t.Fatal("a nil pipeline does not write")
+	}
+}
@@ -0,0 +1,98 @@
+package script
+
+import "strings"
+
+const (
+	// DangerNoWhere UPDATE 或 DELETE 没有 WHERE 条件，会影响整张表
+	DangerNoWhere  = "no_where"
+	DangerDrop     = "drop"
+	DangerTruncate = "truncate"
+	DangerAlter    = "alter"
+)
+
+// Danger is a statement of a script that should be confirmed before running.
+type Danger struct {
+	Kind      string `json:"kind"`
+	Table     string `json:"table"`
+	Statement string `json:"statement"`
+}
+
+// Dangers returns the UPDATE/DELETE statements without WHERE and the DROP,
+// TRUNCATE and ALTER TABLE statements of a script. Table is the first table
+// the statement touches, without its database.
+func Dangers(sql string) []*Danger {
+	result := make([]*Danger, 0)
+	for _, statement := range Split(sql) {
+		toks := tokens(statement.Sql)
+		words := keywords(statement.Sql)
+		if len(words) == 0 {
+			continue
+		}
+		danger := &Danger{Statement: strings.TrimSpace(statement.Sql)}
+		switch words[0] {
+		case "UPDATE":
+			if containsWord(words, "WHERE") {
+				continue
+			}
+			danger.Kind = DangerNoWhere
+			danger.Table = nameAfter(toks, "UPDATE", "LOW_PRIORITY", "IGNORE")
+		case "DELETE":
+			if containsWord(words, "WHERE") {
+				continue
+			}
+			danger.Kind = DangerNoWhere
+			danger.Table = nameAfter(toks, "FROM")
+		case "DROP":
+			danger.Kind = DangerDrop
+			if len(words) > 1 && (words[1] == "TABLE" || words[1] == "TEMPORARY") {
+				danger.Table = nameAfter(toks, "TABLE", "IF", "EXISTS")
+			}
+		case "TRUNCATE":
+			danger.Kind = DangerTruncate
+			danger.Table = nameAfter(toks, "TRUNCATE", "TABLE")
+		case "ALTER":
+			if !containsWord(words, "TABLE") {
+				continue
+			}
+			danger.Kind = DangerAlter
+			danger.Table = nameAfter(toks, "TABLE")
+		default:
+			continue
+		}
+		result = append(result, danger)
+	}
+	return result
+}
+
+func containsWord(words []string, word string) bool {
+	for _, w := range words {
+		if w == word {
+			return true
+		}
+	}
+	return false
+}
+
+// nameAfter 返回关键字 keyword 之后的第一个对象名，跳过 skip 中的修饰词，db.table 只取表名
+func nameAfter(toks []token, keyword string, skip ...string) string {
+	i := 0
+	for ; i < len(toks); i++ {
+		if !toks[i].quoted && strings.EqualFold(toks[i].text, keyword) {
+			break
+		}
+	}
+	for i++; i < len(toks); i++ {
+		t := toks[i]
+		if !t.quoted && containsWord(skip, strings.ToUpper(t.text)) {
+			continue
+		}
+		if !t.quoted && (t.text == "." || t.text == "(" || t.text == ")") {
+			return ""
+		}
+		if i+2 < len(toks) && toks[i+1].text == "." && !toks[i+1].quoted {
+			return toks[i+2].text
+		}
+		return t.text
+	}
+	return ""
+}
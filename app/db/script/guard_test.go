@@ -0,0 +1,48 @@
+package script
+
+import "testing"
+
+func TestDangers(t *testing.T) {
+	dangers := Dangers(`update orders set paid = 1;
+update orders set paid = 1 where id = 3;
+DELETE FROM shop.` + "`order items`" + `;
+delete from logs where created < now();
+drop table if exists tmp;
+truncate table audit;
+alter table big add column c int;
+select * from t`)
+	expected := []Danger{
+		{Kind: DangerNoWhere, Table: "orders"},
+		{Kind: DangerNoWhere, Table: "order items"},
+		{Kind: DangerDrop, Table: "tmp"},
+		{Kind: DangerTruncate, Table: "audit"},
+		{Kind: DangerAlter, Table: "big"},
+	}
+	if len(dangers) != len(expected) {
+		t.Fatalf("expected %d dangers, got %+v", len(expected), dangers)
+	}
+	for i, danger := range dangers {
+		if danger.Kind != expected[i].Kind || danger.Table != expected[i].Table {
+			t.Fatalf("danger %d: got %+v, want %+v", i, danger, expected[i])
+		}
+	}
+	if dangers[0].Statement != "update orders set paid = 1" {
+		t.Fatalf("unexpected statement %q", dangers[0].Statement)
+	}
+}
+
+func TestDangersIgnoresSafeStatements(t *testing.T) {
+	for _, sql := range []string{
+		"update t set a = 1 where id = 2",
+		"select 'drop table t'",
+		"-- drop table t\nselect 1",
+		"alter user 'a'@'%' account lock",
+	} {
+		if dangers := Dangers(sql); len(dangers) != 0 {
+			t.Fatalf("%q should be safe, got %+v", sql, dangers)
+		}
+	}
+	if dangers := Dangers("truncate low"); len(dangers) != 1 || dangers[0].Table != "low" {
+		t.Fatalf("unexpected dangers %+v", dangers)
+	}
+}
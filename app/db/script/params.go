@@ -0,0 +1,315 @@
+package script
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	ParamPositional = "positional"
+	ParamNamed      = "named"
+)
+
+const (
+	ParamTypeString   = "string"
+	ParamTypeInt      = "int"
+	ParamTypeFloat    = "float"
+	ParamTypeBool     = "bool"
+	ParamTypeDate     = "date"
+	ParamTypeDatetime = "datetime"
+	ParamTypeNull     = "null"
+)
+
+// Parameter is a placeholder found in a statement. Positional parameters are
+// named after their 1 based position.
+type Parameter struct {
+	Name    string `json:"name"`
+	Style   string `json:"style"`
+	Prefix  string `json:"prefix"`
+	Offsets []int  `json:"offsets"`
+}
+
+// ParamValue is a value typed by the user for a parameter.
+type ParamValue struct {
+	Name  string      `json:"name"`
+	Type  string      `json:"type"`
+	Value interface{} `json:"value"`
+}
+
+type placeholder struct {
+	name   string
+	prefix string
+	start  int
+	end    int
+}
+
+// FindParameters returns the ?, :name and @name placeholders of sql, skipping
+// strings, quoted identifiers, comments, @@system variables and user variables
+// that are assigned in the statement itself.
+func FindParameters(sql string) []*Parameter {
+	result := make([]*Parameter, 0)
+	named := make(map[string]*Parameter)
+	position := 0
+	for _, p := range scanPlaceholders(sql) {
+		if p.prefix == "?" {
+			position++
+			result = append(result, &Parameter{
+				Name:    strconv.Itoa(position),
+				Style:   ParamPositional,
+				Prefix:  p.prefix,
+				Offsets: []int{p.start},
+			})
+			continue
+		}
+		if existing, ok := named[p.name]; ok {
+			existing.Offsets = append(existing.Offsets, p.start)
+			continue
+		}
+		parameter := &Parameter{Name: p.name, Style: ParamNamed, Prefix: p.prefix, Offsets: []int{p.start}}
+		named[p.name] = parameter
+		result = append(result, parameter)
+	}
+	return result
+}
+
+// Bind rewrites named placeholders to ? and returns the arguments in the order
+// the driver expects them.
+func Bind(sql string, values []*ParamValue) (string, []interface{}, error) {
+	placeholders := scanPlaceholders(sql)
+	if len(placeholders) == 0 {
+		if len(values) > 0 {
+			return "", nil, fmt.Errorf("statement has no parameters but %d values were given", len(values))
+		}
+		return sql, nil, nil
+	}
+
+	positional := 0
+	for _, p := range placeholders {
+		if p.prefix == "?" {
+			positional++
+		}
+	}
+	if positional > 0 && positional != len(placeholders) {
+		return "", nil, fmt.Errorf("positional and named parameters cannot be mixed")
+	}
+
+	args := make([]interface{}, 0, len(placeholders))
+	if positional > 0 {
+		if len(values) != positional {
+			return "", nil, fmt.Errorf("statement expects %d parameters, got %d", positional, len(values))
+		}
+		for i, value := range values {
+			arg, err := value.Arg()
+			if err != nil {
+				return "", nil, fmt.Errorf("parameter %d: %w", i+1, err)
+			}
+			args = append(args, arg)
+		}
+		return sql, args, nil
+	}
+
+	byName := make(map[string]*ParamValue, len(values))
+	for _, value := range values {
+		byName[strings.TrimLeft(value.Name, ":@")] = value
+	}
+	var rewritten strings.Builder
+	last := 0
+	for _, p := range placeholders {
+		value, ok := byName[p.name]
+		if !ok {
+			return "", nil, fmt.Errorf("missing value for parameter %s%s", p.prefix, p.name)
+		}
+		arg, err := value.Arg()
+		if err != nil {
+			return "", nil, fmt.Errorf("parameter %s%s: %w", p.prefix, p.name, err)
+		}
+		args = append(args, arg)
+		rewritten.WriteString(sql[last:p.start])
+		rewritten.WriteString("?")
+		last = p.end
+	}
+	rewritten.WriteString(sql[last:])
+	return rewritten.String(), args, nil
+}
+
+// Arg converts the value to the Go type matching its declared type.
+func (p *ParamValue) Arg() (interface{}, error) {
+	if p.Type == ParamTypeNull || p.Value == nil {
+		return nil, nil
+	}
+	text := strings.TrimSpace(fmt.Sprint(p.Value))
+	switch p.Type {
+	case "", ParamTypeString:
+		if s, ok := p.Value.(string); ok {
+			return s, nil
+		}
+		return fmt.Sprint(p.Value), nil
+	case ParamTypeInt:
+		if f, ok := p.Value.(float64); ok && f == float64(int64(f)) {
+			return int64(f), nil
+		}
+		i, err := strconv.ParseInt(text, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("'%s' is not an integer", text)
+		}
+		return i, nil
+	case ParamTypeFloat:
+		if f, ok := p.Value.(float64); ok {
+			return f, nil
+		}
+		f, err := strconv.ParseFloat(text, 64)
+		if err != nil {
+			return nil, fmt.Errorf("'%s' is not a number", text)
+		}
+		return f, nil
+	case ParamTypeBool:
+		if b, ok := p.Value.(bool); ok {
+			return b, nil
+		}
+		b, err := strconv.ParseBool(text)
+		if err != nil {
+			return nil, fmt.Errorf("'%s' is not a boolean", text)
+		}
+		return b, nil
+	case ParamTypeDate:
+		return parseParamTime(text, "2006-01-02")
+	case ParamTypeDatetime:
+		return parseParamTime(text, "2006-01-02 15:04:05", "2006-01-02T15:04:05", time.RFC3339Nano)
+	default:
+		return nil, fmt.Errorf("unsupported parameter type '%s'", p.Type)
+	}
+}
+
+func parseParamTime(text string, layouts ...string) (interface{}, error) {
+	for _, layout := range layouts {
+		if t, err := time.ParseInLocation(layout, text, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	return nil, fmt.Errorf("'%s' is not a valid time", text)
+}
+
+// ParseParamValues accepts the loosely typed params sent by the frontend.
+func ParseParamValues(raw interface{}) ([]*ParamValue, error) {
+	if raw == nil {
+		return nil, nil
+	}
+	if values, ok := raw.([]*ParamValue); ok {
+		return values, nil
+	}
+	marshal, err := json.Marshal(raw)
+	if err != nil {
+		return nil, err
+	}
+	var values []*ParamValue
+	if err = json.Unmarshal(marshal, &values); err != nil {
+		return nil, fmt.Errorf("invalid query parameters: %w", err)
+	}
+	return values, nil
+}
+
+func isIdentStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isIdentChar(c byte) bool {
+	return isIdentStart(c) || (c >= '0' && c <= '9') || c == '$'
+}
+
+func scanPlaceholders(sql string) []placeholder {
+	var found []placeholder
+	assigned := make(map[string]bool)
+	n := len(sql)
+	for i := 0; i < n; i++ {
+		c := sql[i]
+		switch {
+		case c == '\'' || c == '"' || c == '`':
+			i = skipQuoted(sql, i)
+		case c == '#' || (c == '-' && i+2 < n && sql[i+1] == '-' && (sql[i+2] == ' ' || sql[i+2] == '\t')) || (c == '-' && i+2 == n && sql[i+1] == '-'):
+			for i < n && sql[i] != '\n' {
+				i++
+			}
+		case c == '/' && i+1 < n && sql[i+1] == '*':
+			end := strings.Index(sql[i+2:], "*/")
+			if end < 0 {
+				return found
+			}
+			i += end + 3
+		case c == '?':
+			found = append(found, placeholder{name: "?", prefix: "?", start: i, end: i + 1})
+		case c == ':' || c == '@':
+			if i+1 >= n || !isIdentStart(sql[i+1]) || (i > 0 && (isIdentChar(sql[i-1]) || sql[i-1] == ':' || sql[i-1] == '@')) {
+				if c == '@' && i+1 < n && sql[i+1] == '@' {
+					// @@session.var 系统变量
+					i++
+					for i+1 < n && (isIdentChar(sql[i+1]) || sql[i+1] == '.') {
+						i++
+					}
+				}
+				continue
+			}
+			end := i + 1
+			for end < n && isIdentChar(sql[end]) {
+				end++
+			}
+			name := sql[i+1 : end]
+			if c == '@' && isAssignment(sql, i, end) {
+				assigned[name] = true
+			} else {
+				found = append(found, placeholder{name: name, prefix: string(c), start: i, end: end})
+			}
+			i = end - 1
+		}
+	}
+
+	result := found[:0]
+	for _, p := range found {
+		if p.prefix == "@" && assigned[p.name] {
+			continue
+		}
+		result = append(result, p)
+	}
+	return result
+}
+
+// isAssignment 判断 @var 是否在语句内被赋值（SET @var = 或 @var :=），这种是用户变量不是参数
+func isAssignment(sql string, start, end int) bool {
+	rest := strings.TrimLeft(sql[end:], " \t\r\n")
+	if strings.HasPrefix(rest, ":=") {
+		return true
+	}
+	before := strings.TrimRight(sql[:start], " \t\r\n")
+	if strings.HasSuffix(before, ",") {
+		before = strings.TrimRight(strings.TrimSuffix(before, ","), " \t\r\n")
+	}
+	word := before
+	for j := len(before) - 1; j >= 0; j-- {
+		if !isIdentChar(before[j]) {
+			word = before[j+1:]
+			break
+		}
+	}
+	return strings.EqualFold(word, "set") && strings.HasPrefix(rest, "=")
+}
+
+func skipQuoted(sql string, i int) int {
+	quote := sql[i]
+	for j := i + 1; j < len(sql); j++ {
+		switch sql[j] {
+		case '\\':
+			if quote != '`' {
+				j++
+			}
+		case quote:
+			if j+1 < len(sql) && sql[j+1] == quote {
+				j++
+				continue
+			}
+			return j
+		}
+	}
+	return len(sql) - 1
+}
@@ -0,0 +1,81 @@
+package script
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFindParameters(t *testing.T) {
+	params := FindParameters("select * from t where a = :id and b = @name and c = :id -- :skip\n" +
+		"and d = ':quoted' and e = `:ident` and f = @@session.sql_mode and g::text = 'x' /* @hidden */")
+	if len(params) != 2 {
+		t.Fatalf("expected 2 parameters, got %d: %+v", len(params), params)
+	}
+	if params[0].Name != "id" || params[0].Prefix != ":" || len(params[0].Offsets) != 2 {
+		t.Fatalf("unexpected first parameter %+v", params[0])
+	}
+	if params[1].Name != "name" || params[1].Prefix != "@" || params[1].Style != ParamNamed {
+		t.Fatalf("unexpected second parameter %+v", params[1])
+	}
+}
+
+func TestFindParametersSkipsAssignedVariables(t *testing.T) {
+	params := FindParameters("SET @total = 0; select @total := @total + price, @limit from t where id > ?")
+	if len(params) != 2 {
+		t.Fatalf("expected 2 parameters, got %d: %+v", len(params), params)
+	}
+	if params[0].Name != "limit" || params[1].Style != ParamPositional || params[1].Name != "1" {
+		t.Fatalf("unexpected parameters %+v %+v", params[0], params[1])
+	}
+}
+
+func TestBindNamed(t *testing.T) {
+	sql, args, err := Bind("select * from t where a = :id and b = @name and c = :id", []*ParamValue{
+		{Name: "id", Type: ParamTypeInt, Value: "42"},
+		{Name: "@name", Type: ParamTypeString, Value: "x"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sql != "select * from t where a = ? and b = ? and c = ?" {
+		t.Fatalf("unexpected sql %q", sql)
+	}
+	if len(args) != 3 || args[0] != int64(42) || args[1] != "x" || args[2] != int64(42) {
+		t.Fatalf("unexpected args %#v", args)
+	}
+}
+
+func TestBindPositional(t *testing.T) {
+	sql, args, err := Bind("select * from t where a = ? and b > ? and c = ?", []*ParamValue{
+		{Type: ParamTypeBool, Value: "true"},
+		{Type: ParamTypeDate, Value: "2024-02-03"},
+		{Type: ParamTypeNull},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sql != "select * from t where a = ? and b > ? and c = ?" {
+		t.Fatalf("unexpected sql %q", sql)
+	}
+	if args[0] != true || args[2] != nil {
+		t.Fatalf("unexpected args %#v", args)
+	}
+	if date, ok := args[1].(time.Time); !ok || date.Day() != 3 {
+		t.Fatalf("unexpected date %#v", args[1])
+	}
+}
+
+func TestBindErrors(t *testing.T) {
+	if _, _, err := Bind("select ? , :id", []*ParamValue{{Value: 1}, {Name: "id", Value: 2}}); err == nil {
+		t.Fatal("expected mixed styles to fail")
+	}
+	if _, _, err := Bind("select :id", nil); err == nil {
+		t.Fatal("expected a missing value to fail")
+	}
+	if _, _, err := Bind("select ?", []*ParamValue{{Type: ParamTypeInt, Value: "abc"}}); err == nil {
+		t.Fatal("expected an invalid integer to fail")
+	}
+	if _, _, err := Bind("select ?", nil); err == nil {
+		t.Fatal("expected a wrong argument count to fail")
+	}
+}
@@ -0,0 +1,273 @@
+package script
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"strings"
+)
+
+const (
+	defaultDelimiter = ";"
+	delimiterCommand = "delimiter"
+)
+
+// Statement is a single statement cut out of a script.
+type Statement struct {
+	Sql    string `json:"sql"`
+	Offset int64  `json:"offset"`
+	End    int64  `json:"end"`
+	Line   int    `json:"line"`
+}
+
+// Splitter reads statements one by one from a MySQL script. It understands
+// the client side DELIMITER command, quoted strings / identifiers and comments,
+// so the whole script never has to be held in memory.
+type Splitter struct {
+	reader    *bufio.Reader
+	delimiter string
+	offset    int64
+	line      int
+}
+
+func NewSplitter(r io.Reader) *Splitter {
+	return &Splitter{
+		reader:    bufio.NewReaderSize(r, 64*1024),
+		delimiter: defaultDelimiter,
+		line:      1,
+	}
+}
+
+// Split is a convenience wrapper for scripts that are already in memory.
+func Split(sql string) []*Statement {
+	var statements []*Statement
+	splitter := NewSplitter(strings.NewReader(sql))
+	for {
+		statement, err := splitter.Next()
+		if err != nil {
+			break
+		}
+		statements = append(statements, statement)
+	}
+	return statements
+}
+
+// Offset returns how many bytes have been consumed so far.
+func (s *Splitter) Offset() int64 {
+	return s.offset
+}
+
+// Delimiter returns the delimiter currently in effect.
+func (s *Splitter) Delimiter() string {
+	return s.delimiter
+}
+
+// Next returns the next non-empty statement, or io.EOF when the script is exhausted.
+func (s *Splitter) Next() (*Statement, error) {
+	var buf bytes.Buffer
+	var quote byte
+	hasCode := false
+	var start int64
+	var startLine int
+	markCode := func(offset int64) {
+		if !hasCode {
+			hasCode = true
+			start, startLine = offset, s.line
+		}
+	}
+
+	for {
+		if !hasCode && quote == 0 {
+			if ok, err := s.readDelimiterCommand(); err != nil {
+				return nil, err
+			} else if ok {
+				buf.Reset()
+				continue
+			}
+		}
+
+		c, err := s.readByte()
+		if err == io.EOF {
+			if hasCode {
+				return s.statement(&buf, start, startLine), nil
+			}
+			return nil, io.EOF
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if quote != 0 {
+			buf.WriteByte(c)
+			if c == '\\' && quote != '`' {
+				if next, err := s.readByte(); err == nil {
+					buf.WriteByte(next)
+				}
+				continue
+			}
+			if c == quote {
+				quote = 0
+			}
+			continue
+		}
+
+		switch {
+		case c == '\'' || c == '"' || c == '`':
+			quote = c
+			markCode(s.offset - 1)
+			buf.WriteByte(c)
+			continue
+		case c == '#':
+			if err := s.skipLine(); err != nil && err != io.EOF {
+				return nil, err
+			}
+			buf.WriteByte('\n')
+			continue
+		case c == '-' && s.peekIs("-") && s.isLineComment():
+			if err := s.skipLine(); err != nil && err != io.EOF {
+				return nil, err
+			}
+			buf.WriteByte('\n')
+			continue
+		case c == '/' && s.peekIs("*"):
+			offset := s.offset - 1
+			comment, err := s.readBlockComment()
+			if err != nil && err != io.EOF {
+				return nil, err
+			}
+			// 保留 /*! ... */ 这类版本注释，它们在 mysqldump 中是可执行的
+			if strings.HasPrefix(comment, "/*!") {
+				markCode(offset)
+			}
+			buf.WriteString(comment)
+			continue
+		}
+
+		if c == s.delimiter[0] && (len(s.delimiter) == 1 || s.peekIs(s.delimiter[1:])) {
+			for i := 1; i < len(s.delimiter); i++ {
+				_, _ = s.readByte()
+			}
+			if hasCode {
+				return s.statement(&buf, start, startLine), nil
+			}
+			buf.Reset()
+			continue
+		}
+
+		if !isSpace(c) {
+			markCode(s.offset - 1)
+		}
+		buf.WriteByte(c)
+	}
+}
+
+func (s *Splitter) statement(buf *bytes.Buffer, start int64, line int) *Statement {
+	return &Statement{Sql: strings.TrimSpace(buf.String()), Offset: start, End: s.offset, Line: line}
+}
+
+func (s *Splitter) readByte() (byte, error) {
+	c, err := s.reader.ReadByte()
+	if err != nil {
+		return c, err
+	}
+	s.offset++
+	if c == '\n' {
+		s.line++
+	}
+	return c, nil
+}
+
+func (s *Splitter) peekIs(value string) bool {
+	peek, err := s.reader.Peek(len(value))
+	if err != nil {
+		return false
+	}
+	return string(peek) == value
+}
+
+// isLineComment 判断 "--" 后面是否紧跟空白，MySQL 只有这种写法才算注释
+func (s *Splitter) isLineComment() bool {
+	peek, err := s.reader.Peek(2)
+	if err == io.EOF && len(peek) == 1 {
+		return true
+	}
+	if err != nil {
+		return false
+	}
+	return isSpace(peek[1])
+}
+
+func (s *Splitter) skipLine() error {
+	for {
+		c, err := s.readByte()
+		if err != nil {
+			return err
+		}
+		if c == '\n' {
+			return nil
+		}
+	}
+}
+
+func (s *Splitter) readBlockComment() (string, error) {
+	var comment bytes.Buffer
+	comment.WriteByte('/')
+	var last byte
+	for {
+		c, err := s.readByte()
+		if err != nil {
+			return comment.String(), err
+		}
+		comment.WriteByte(c)
+		if last == '*' && c == '/' && comment.Len() > 3 {
+			return comment.String(), nil
+		}
+		last = c
+	}
+}
+
+// readDelimiterCommand consumes a "DELIMITER xx" line when it starts the
+// next statement and switches the active delimiter.
+func (s *Splitter) readDelimiterCommand() (bool, error) {
+	for {
+		peek, err := s.reader.Peek(1)
+		if err != nil {
+			return false, nil
+		}
+		if !isSpace(peek[0]) {
+			break
+		}
+		if _, err = s.readByte(); err != nil {
+			return false, err
+		}
+	}
+
+	peek, _ := s.reader.Peek(len(delimiterCommand) + 1)
+	if len(peek) < len(delimiterCommand)+1 ||
+		!strings.EqualFold(string(peek[:len(delimiterCommand)]), delimiterCommand) ||
+		!isSpace(peek[len(delimiterCommand)]) {
+		return false, nil
+	}
+
+	var line bytes.Buffer
+	for {
+		c, err := s.readByte()
+		if err != nil && err != io.EOF {
+			return false, err
+		}
+		if err == io.EOF || c == '\n' {
+			break
+		}
+		line.WriteByte(c)
+	}
+
+	fields := strings.Fields(line.String())
+	if len(fields) >= 2 {
+		s.delimiter = fields[1]
+	}
+	return true, nil
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f'
+}
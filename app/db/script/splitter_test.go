@@ -0,0 +1,65 @@
+package script
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSplitSimple(t *testing.T) {
+	statements := Split("select 1;\nselect 'a;b' ; \n\n  insert into `t;x` values (\"c\\\";\") ;")
+	if len(statements) != 3 {
+		t.Fatalf("expected 3 statements, got %d", len(statements))
+	}
+	if statements[1].Sql != "select 'a;b'" {
+		t.Fatalf("unexpected statement %q", statements[1].Sql)
+	}
+	if statements[2].Sql != "insert into `t;x` values (\"c\\\";\")" {
+		t.Fatalf("unexpected statement %q", statements[2].Sql)
+	}
+	if statements[2].Line != 4 {
+		t.Fatalf("expected line 4, got %d", statements[2].Line)
+	}
+}
+
+func TestSplitComments(t *testing.T) {
+	statements := Split(`-- leading comment;
+# another one;
+/* block ; comment */
+select 1; -- trailing;
+/*!40101 SET NAMES utf8mb4 */;
+--`)
+	if len(statements) != 2 {
+		t.Fatalf("expected 2 statements, got %d: %+v", len(statements), statements)
+	}
+	if !strings.HasSuffix(statements[0].Sql, "select 1") {
+		t.Fatalf("unexpected statement %q", statements[0].Sql)
+	}
+	if statements[1].Sql != "/*!40101 SET NAMES utf8mb4 */" {
+		t.Fatalf("unexpected statement %q", statements[1].Sql)
+	}
+}
+
+func TestSplitDelimiter(t *testing.T) {
+	script := `DROP PROCEDURE IF EXISTS p;
+DELIMITER $$
+CREATE PROCEDURE p()
+BEGIN
+  SELECT 1;
+  SELECT 2;
+END$$
+delimiter ;
+CALL p();`
+	statements := Split(script)
+	if len(statements) != 3 {
+		t.Fatalf("expected 3 statements, got %d: %+v", len(statements), statements)
+	}
+	if !strings.HasPrefix(statements[1].Sql, "CREATE PROCEDURE p()") || !strings.HasSuffix(statements[1].Sql, "END") {
+		t.Fatalf("unexpected procedure body %q", statements[1].Sql)
+	}
+	if statements[2].Sql != "CALL p()" {
+		t.Fatalf("unexpected statement %q", statements[2].Sql)
+	}
+	if statements[2].End != int64(len(script)) {
+		t.Fatalf("expected end offset %d, got %d", len(script), statements[2].End)
+	}
+}
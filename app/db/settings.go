@@ -13,7 +13,7 @@ type Settings interface {
 	SetPreparedStatementCache(bool)
 
 	// PreparedStatementCacheEnabled returns true if the prepared statement cache
-	// is enabled, false otherwise.
+	// is enabled, sessions that never set it follow DefaultSettings.
 	PreparedStatementCacheEnabled() bool
 
 	// SetConnMaxLifetime sets the default maximum amount of time a connection
@@ -55,6 +55,20 @@ type Settings interface {
 	// MaxTransactionRetries returns the maximum number of times a
 	// transaction can be retried.
 	MaxTransactionRetries() int
+
+	// SetMaxRows sets the maximum number of rows a query returns.
+	SetMaxRows(int)
+
+	// MaxRows returns the maximum number of rows a query returns, sessions
+	// that never set it follow DefaultSettings.
+	MaxRows() int
+
+	// SetQueryTimeout sets how long a query may run, zero means no limit.
+	SetQueryTimeout(time.Duration)
+
+	// QueryTimeout returns how long a query may run, sessions that never set
+	// it follow DefaultSettings.
+	QueryTimeout() time.Duration
 }
 
 type settings struct {
@@ -68,8 +82,15 @@ type settings struct {
 	maxIdleConns    int
 
 	maxTransactionRetries int
+
+	// maxRows 和 queryTimeout 小于 0 时使用 DefaultSettings 的值
+	maxRows      int
+	queryTimeout time.Duration
 }
 
+// optionDefault 开关没有设置过，使用 DefaultSettings 的值
+const optionDefault = 2
+
 func (c *settings) binaryOption(opt *uint32) bool {
 	return atomic.LoadUint32(opt) == 1
 }
@@ -87,6 +108,9 @@ func (c *settings) SetPreparedStatementCache(value bool) {
 }
 
 func (c *settings) PreparedStatementCacheEnabled() bool {
+	if atomic.LoadUint32(&c.preparedStatementCacheEnabled) == optionDefault && c != DefaultSettings {
+		return DefaultSettings.PreparedStatementCacheEnabled()
+	}
 	return c.binaryOption(&c.preparedStatementCacheEnabled)
 }
 
@@ -153,27 +177,63 @@ func (c *settings) MaxOpenConns() int {
 	return c.maxOpenConns
 }
 
+func (c *settings) SetMaxRows(n int) {
+	c.Lock()
+	c.maxRows = n
+	c.Unlock()
+}
+
+func (c *settings) MaxRows() int {
+	c.RLock()
+	n := c.maxRows
+	c.RUnlock()
+	if n < 0 && c != DefaultSettings {
+		return DefaultSettings.MaxRows()
+	}
+	return n
+}
+
+func (c *settings) SetQueryTimeout(t time.Duration) {
+	c.Lock()
+	c.queryTimeout = t
+	c.Unlock()
+}
+
+func (c *settings) QueryTimeout() time.Duration {
+	c.RLock()
+	t := c.queryTimeout
+	c.RUnlock()
+	if t < 0 && c != DefaultSettings {
+		return DefaultSettings.QueryTimeout()
+	}
+	return t
+}
+
 // NewSettings returns a new settings value prefilled with the current default
 // settings.
 func NewSettings() Settings {
 	def := DefaultSettings.(*settings)
 	return &settings{
-		preparedStatementCacheEnabled: def.preparedStatementCacheEnabled,
+		preparedStatementCacheEnabled: optionDefault,
 		connMaxLifetime:               def.connMaxLifetime,
 		connMaxIdleTime:               def.connMaxIdleTime,
 		maxIdleConns:                  def.maxIdleConns,
 		maxOpenConns:                  def.maxOpenConns,
 		maxTransactionRetries:         def.maxTransactionRetries,
+		maxRows:                       -1,
+		queryTimeout:                  -1,
 	}
 }
 
 // DefaultSettings provides default global configuration settings for database
 // sessions.
 var DefaultSettings Settings = &settings{
-	preparedStatementCacheEnabled: 0,
+	preparedStatementCacheEnabled: 1,
 	connMaxLifetime:               time.Duration(0),
 	connMaxIdleTime:               time.Duration(0),
 	maxIdleConns:                  10,
 	maxOpenConns:                  0,
 	maxTransactionRetries:         1,
+	maxRows:                       2000,
+	queryTimeout:                  time.Duration(0),
 }
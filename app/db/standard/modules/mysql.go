@@ -88,6 +88,7 @@ type TransformColumnInfo struct {
 	ColumnName    string      `json:"columnName"`
 	ColumnComment string      `json:"columnComment"`
 	DataType      string      `json:"dataType"`
+	ColumnType    string      `json:"columnType"`
 	DefaultValue  interface{} `json:"defaultValue"`
 	IsUnsigned    bool        `json:"isUnsigned"`
 	IsZerofill    bool        `json:"isZerofill"`
@@ -0,0 +1,15 @@
+package modules
+
+// ServerProcess is a session or running operation on the server, from the
+// MySQL process list or Mongo currentOp.
+type ServerProcess struct {
+	Id       string `json:"id"`
+	User     string `json:"user"`
+	Host     string `json:"host"`
+	Database string `json:"database"`
+	Command  string `json:"command"`
+	// Time 已运行的秒数
+	Time  int64  `json:"time"`
+	State string `json:"state"`
+	Info  string `json:"info"`
+}
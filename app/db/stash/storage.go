@@ -17,19 +17,40 @@ type databaseId string
 
 type StorageSession struct {
 	source map[repositoryId]map[databaseId]db.Session
-	mu     sync.RWMutex
+	// opening 正在打开的会话，同一个 conid/database 的其他调用等待它完成
+	opening map[repositoryId]map[databaseId]*opening
+	// open 打开新的会话，测试中可以替换
+	open func(connection map[string]interface{}) (db.Session, error)
+	mu   sync.RWMutex
+}
+
+type opening struct {
+	done    chan struct{}
+	session db.Session
+	err     error
+	// removed 打开期间连接被 RemoveItem 移除，打开的会话不再登记
+	removed bool
+}
+
+func newStorageSession(open func(connection map[string]interface{}) (db.Session, error)) *StorageSession {
+	return &StorageSession{
+		source:  make(map[repositoryId]map[databaseId]db.Session),
+		opening: make(map[repositoryId]map[databaseId]*opening),
+		open:    open,
+	}
 }
 
 func GetStorageSession() *StorageSession {
 	lookupIdOnce.Do(func() {
-		lookupIdSession = &StorageSession{
-			source: make(map[repositoryId]map[databaseId]db.Session),
-		}
+		lookupIdSession = newStorageSession(adapter.NewCompatDriver().Open)
 	})
 
 	return lookupIdSession
 }
 
+// Scanner 返回 conid/database 已登记的会话，不存在时用 connection 打开并登记。
+// 查询路径上不再 ping，失效的会话由连接池自行重连，连接出错关闭时会被 RemoveItem 移除。
+// 同一个 conid/database 同时只打开一次，其他调用等待并得到同一个会话
 func (s *StorageSession) Scanner(conid string, connection map[string]interface{}) (db.Session, error) {
 	if conid == "" {
 		return nil, db.ErrNilRecord
@@ -39,27 +60,50 @@ func (s *StorageSession) Scanner(conid string, connection map[string]interface{}
 	if connection["database"] != nil {
 		database = connection["database"].(string)
 	}
-	session, err := s.GetItem(conid, database)
-	if err != nil || session.Ping() != nil {
-		if connection == nil {
-			return nil, db.ErrNotConnected
-		}
 
-		session, err = adapter.NewCompatDriver().Open(connection)
-		if err != nil {
-			return nil, err
-		}
-
-		if err = s.SetItem(conid, database, session); err != nil {
-			return nil, err
-		}
+	s.mu.Lock()
+	if session := s.source[repositoryId(conid)][databaseId(database)]; session != nil {
+		s.mu.Unlock()
+		return session, nil
+	}
+	if connection == nil {
+		s.mu.Unlock()
+		return nil, db.ErrNotConnected
 	}
+	if pending := s.opening[repositoryId(conid)][databaseId(database)]; pending != nil {
+		s.mu.Unlock()
+		<-pending.done
+		return pending.session, pending.err
+	}
+	pending := &opening{done: make(chan struct{})}
+	if s.opening[repositoryId(conid)] == nil {
+		s.opening[repositoryId(conid)] = make(map[databaseId]*opening)
+	}
+	s.opening[repositoryId(conid)][databaseId(database)] = pending
+	s.mu.Unlock()
 
-	if session == nil {
-		return nil, db.ErrNilRecord
+	session, err := s.open(connection)
+	if err == nil && session == nil {
+		err = db.ErrNilRecord
 	}
+	var stale db.Session
+	s.mu.Lock()
+	delete(s.opening[repositoryId(conid)], databaseId(database))
+	switch {
+	case err != nil:
+	case pending.removed:
+		stale, session, err = session, nil, db.ErrNotConnected
+	default:
+		stale = s.setLocked(conid, database, session)
+	}
+	s.mu.Unlock()
+	if stale != nil {
+		_ = stale.Close()
+	}
+	pending.session, pending.err = session, err
+	close(pending.done)
 
-	return session, nil
+	return session, err
 }
 
 func (s *StorageSession) SetItem(conid, database string, driver db.Session) error {
@@ -67,15 +111,26 @@ func (s *StorageSession) SetItem(conid, database string, driver db.Session) erro
 		return fmt.Errorf("cannot set nil driver for database '%s' on connection '%s'", database, conid)
 	}
 	s.mu.Lock()
-	defer s.mu.Unlock()
+	replaced := s.setLocked(conid, database, driver)
+	s.mu.Unlock()
+	if replaced != nil {
+		_ = replaced.Close()
+	}
+
+	return nil
+}
 
+// setLocked 登记会话，返回被替换的旧会话，由调用方在锁外关闭
+func (s *StorageSession) setLocked(conid, database string, driver db.Session) db.Session {
 	if s.source[repositoryId(conid)] == nil {
 		s.source[repositoryId(conid)] = make(map[databaseId]db.Session)
 	}
-
+	replaced := s.source[repositoryId(conid)][databaseId(database)]
 	s.source[repositoryId(conid)][databaseId(database)] = driver
-
-	return nil
+	if replaced == driver {
+		return nil
+	}
+	return replaced
 }
 
 func (s *StorageSession) GetDatabaseMap(conid string) (map[databaseId]db.Session, error) {
@@ -115,6 +170,9 @@ func (s *StorageSession) RemoveItem(conid string) (err error) {
 		if err = s.closeDriver(conid); err == nil {
 			delete(s.source, repositoryId(conid))
 		}
+		for _, pending := range s.opening[repositoryId(conid)] {
+			pending.removed = true
+		}
 	}, func(e error) {
 		logger.Errorf("delete driver id failed %v", err)
 		err = e
@@ -131,6 +189,11 @@ func (s *StorageSession) Clear() {
 			session.Close()
 		}
 	}
+	for _, databases := range s.opening {
+		for _, pending := range databases {
+			pending.removed = true
+		}
+	}
 	s.source = make(map[repositoryId]map[databaseId]db.Session)
 }
 
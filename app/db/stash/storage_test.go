@@ -0,0 +1,117 @@
+package stash
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"tinydb/app/db"
+	"tinydb/app/db/standard/modules"
+)
+
+// fakeSession 不连接数据库的会话，记录 ping 和关闭
+type fakeSession struct {
+	db.Settings
+	pings  atomic.Int32
+	closed atomic.Bool
+}
+
+func (f *fakeSession) Dialect() string { return "fake" }
+func (f *fakeSession) Ping() error {
+	f.pings.Add(1)
+	return nil
+}
+func (f *fakeSession) Version() (*modules.Version, error) { return &modules.Version{}, nil }
+func (f *fakeSession) Close() error {
+	f.closed.Store(true)
+	return nil
+}
+func (f *fakeSession) ListDatabases() (interface{}, error) { return nil, nil }
+func (f *fakeSession) Query(string) (interface{}, error)   { return nil, nil }
+func (f *fakeSession) CreateDatabase(string) error         { return nil }
+
+func TestScannerOpensOnce(t *testing.T) {
+	var opened atomic.Int32
+	release := make(chan struct{})
+	storage := newStorageSession(func(map[string]interface{}) (db.Session, error) {
+		opened.Add(1)
+		<-release
+		return &fakeSession{Settings: db.NewSettings()}, nil
+	})
+
+	var wg sync.WaitGroup
+	sessions := make([]db.Session, 8)
+	for i := range sessions {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			session, err := storage.Scanner("c", map[string]interface{}{"database": "d"})
+			if err != nil {
+				t.Errorf("scanner failed: %v", err)
+			}
+			sessions[i] = session
+		}(i)
+	}
+	time.Sleep(10 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if opened.Load() != 1 {
+		t.Fatalf("concurrent scanners should open one session, opened %d", opened.Load())
+	}
+	for _, session := range sessions {
+		if session != sessions[0] {
+			t.Fatalf("every caller should get the same session")
+		}
+	}
+	if _, err := storage.Scanner("c", map[string]interface{}{"database": "d"}); err != nil {
+		t.Fatalf("scanner failed: %v", err)
+	}
+	if pings := sessions[0].(*fakeSession).pings.Load(); pings != 0 || opened.Load() != 1 {
+		t.Fatalf("a registered session should be reused without a ping, got %d pings", pings)
+	}
+}
+
+func TestScannerRemovedWhileOpening(t *testing.T) {
+	started, release := make(chan struct{}), make(chan struct{})
+	session := &fakeSession{Settings: db.NewSettings()}
+	storage := newStorageSession(func(map[string]interface{}) (db.Session, error) {
+		close(started)
+		<-release
+		return session, nil
+	})
+	done := make(chan error)
+	go func() {
+		_, err := storage.Scanner("c", map[string]interface{}{"database": "d"})
+		done <- err
+	}()
+	<-started
+	if err := storage.RemoveItem("c"); err != nil {
+		t.Fatalf("remove failed: %v", err)
+	}
+	close(release)
+	if err := <-done; err != db.ErrNotConnected {
+		t.Fatalf("expected ErrNotConnected, got %v", err)
+	}
+	if !session.closed.Load() {
+		t.Fatalf("a session opened for a removed connection should be closed")
+	}
+	if _, err := storage.GetItem("c", "d"); err == nil {
+		t.Fatalf("a session opened for a removed connection should not be registered")
+	}
+}
+
+func TestSetItemClosesReplaced(t *testing.T) {
+	storage := newStorageSession(nil)
+	first, second := &fakeSession{Settings: db.NewSettings()}, &fakeSession{Settings: db.NewSettings()}
+	_ = storage.SetItem("c", "d", first)
+	_ = storage.SetItem("c", "d", first)
+	if first.closed.Load() {
+		t.Fatalf("setting the same session again should not close it")
+	}
+	_ = storage.SetItem("c", "d", second)
+	if !first.closed.Load() || second.closed.Load() {
+		t.Fatalf("the replaced session should be closed")
+	}
+}
@@ -0,0 +1,118 @@
+package environment
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/samber/lo"
+	"tinydb/app/settings"
+	"tinydb/app/utility"
+)
+
+const (
+	Dev     = "dev"
+	Staging = "staging"
+	Prod    = "prod"
+)
+
+// 保存在 connections.jsonl 中的字段名
+const (
+	FolderKey      = "folder"
+	EnvironmentKey = "environment"
+	ColorKey       = "color"
+	readOnlyKey    = "isReadOnly"
+)
+
+var environments = []string{Dev, Staging, Prod}
+
+var colorPattern = regexp.MustCompile(`^#([0-9a-fA-F]{3}|[0-9a-fA-F]{6})$`)
+
+// Tag is the grouping information of a connection, it is sent along with the
+// server-status-changed event.
+type Tag struct {
+	Conid       string `json:"conid"`
+	Folder      string `json:"folder"`
+	Environment string `json:"environment"`
+	Color       string `json:"color"`
+}
+
+func TagOf(conid string, connection map[string]interface{}) *Tag {
+	text := func(key string) string {
+		value, _ := connection[key].(string)
+		return value
+	}
+	return &Tag{Conid: conid, Folder: text(FolderKey), Environment: text(EnvironmentKey), Color: text(ColorKey)}
+}
+
+// Normalize validates the folder, environment and color of a connection before
+// it is saved. A production connection is read only unless isReadOnly was set
+// explicitly.
+func Normalize(connection map[string]string) error {
+	environment := strings.ToLower(strings.TrimSpace(connection[EnvironmentKey]))
+	if environment != "" && !lo.Contains(environments, environment) {
+		return fmt.Errorf("unknown environment '%s', expected one of %s", environment, strings.Join(environments, ", "))
+	}
+	if environment == "" {
+		delete(connection, EnvironmentKey)
+	} else {
+		connection[EnvironmentKey] = environment
+	}
+
+	folder, err := CleanFolder(connection[FolderKey])
+	if err != nil {
+		return err
+	}
+	if folder == "" {
+		delete(connection, FolderKey)
+	} else {
+		connection[FolderKey] = folder
+	}
+
+	color := strings.TrimSpace(connection[ColorKey])
+	if color != "" && !colorPattern.MatchString(color) {
+		return fmt.Errorf("invalid color '%s', expected #rgb or #rrggbb", color)
+	}
+	if color == "" {
+		delete(connection, ColorKey)
+	} else {
+		connection[ColorKey] = strings.ToLower(color)
+	}
+
+	if _, ok := connection[readOnlyKey]; !ok && environment == Prod {
+		connection[readOnlyKey] = "true"
+	}
+	return nil
+}
+
+// CleanFolder normalises a folder path such as " Team / Prod/ " to Team/Prod.
+func CleanFolder(folder string) (string, error) {
+	parts := make([]string, 0)
+	for _, part := range strings.Split(strings.ReplaceAll(folder, "\\", "/"), "/") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		if part == "." || part == ".." {
+			return "", fmt.Errorf("invalid folder '%s'", folder)
+		}
+		parts = append(parts, part)
+	}
+	return strings.Join(parts, "/"), nil
+}
+
+// ConfirmWrites 按设置中的确认策略判断执行写入语句前是否需要确认，默认只有
+// 生产环境的可写连接需要
+func ConfirmWrites(connection map[string]interface{}) bool {
+	if utility.IsTruthy(connection[readOnlyKey]) {
+		return false
+	}
+	switch settings.Get().ConfirmWrites {
+	case settings.ConfirmAlways:
+		return true
+	case settings.ConfirmNever:
+		return false
+	}
+	value, _ := connection[EnvironmentKey].(string)
+	return value == Prod
+}
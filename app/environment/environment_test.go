@@ -0,0 +1,60 @@
+package environment
+
+import "testing"
+
+func TestNormalize(t *testing.T) {
+	connection := map[string]string{"environment": " PROD ", "folder": " Team / Orders/ ", "color": "#FF0000"}
+	if err := Normalize(connection); err != nil {
+		t.Fatal(err)
+	}
+	if connection["environment"] != Prod || connection["folder"] != "Team/Orders" || connection["color"] != "#ff0000" {
+		t.Fatalf("unexpected connection %v", connection)
+	}
+	if connection["isReadOnly"] != "true" {
+		t.Fatal("production connections should default to read only")
+	}
+
+	explicit := map[string]string{"environment": "prod", "isReadOnly": "false"}
+	if err := Normalize(explicit); err != nil || explicit["isReadOnly"] != "false" {
+		t.Fatalf("an explicit isReadOnly should be kept: %v %v", explicit, err)
+	}
+
+	for _, invalid := range []map[string]string{
+		{"environment": "qa"},
+		{"folder": "a/../b"},
+		{"color": "red"},
+	} {
+		if err := Normalize(invalid); err == nil {
+			t.Fatalf("%v should be rejected", invalid)
+		}
+	}
+}
+
+func TestConfirmWrites(t *testing.T) {
+	if !ConfirmWrites(map[string]interface{}{"environment": "prod", "isReadOnly": "false"}) {
+		t.Fatal("writable production connections should confirm writes")
+	}
+	if ConfirmWrites(map[string]interface{}{"environment": "dev"}) {
+		t.Fatal("dev connections should not confirm writes")
+	}
+}
+
+func TestTree(t *testing.T) {
+	tree := Tree([]map[string]interface{}{
+		{"_id": "1", "displayName": "orders-db", "folder": "Team/Prod"},
+		{"_id": "2", "displayName": "local"},
+		{"_id": "3", "displayName": "billing", "folder": "Team/Prod"},
+		{"_id": "4", "displayName": "cache", "folder": "Team"},
+	})
+	if len(tree) != 2 || tree[0].Type != NodeFolder || tree[0].Name != "Team" || tree[1].Name != "local" {
+		t.Fatalf("unexpected root %+v", tree)
+	}
+	team := tree[0]
+	if len(team.Children) != 2 || team.Children[0].Path != "Team/Prod" || team.Children[1].Name != "cache" {
+		t.Fatalf("unexpected team folder %+v", team.Children)
+	}
+	prod := team.Children[0]
+	if len(prod.Children) != 2 || prod.Children[0].Name != "billing" || prod.Children[1].Name != "orders-db" {
+		t.Fatalf("unexpected prod folder %+v", prod.Children)
+	}
+}
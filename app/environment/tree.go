@@ -0,0 +1,83 @@
+package environment
+
+import (
+	"sort"
+	"strings"
+)
+
+const (
+	NodeFolder     = "folder"
+	NodeConnection = "connection"
+)
+
+// Node is a folder or a connection of the connection tree.
+type Node struct {
+	Type       string                 `json:"type"`
+	Name       string                 `json:"name"`
+	Path       string                 `json:"path"`
+	Connection map[string]interface{} `json:"connection,omitempty"`
+	Children   []*Node                `json:"children,omitempty"`
+}
+
+// Tree groups connections by their folder, folders come before connections
+// and both are sorted by name.
+func Tree(connections []map[string]interface{}) []*Node {
+	root := &Node{Type: NodeFolder}
+	folders := map[string]*Node{"": root}
+	for _, connection := range connections {
+		folder, _ := connection[FolderKey].(string)
+		folder, err := CleanFolder(folder)
+		if err != nil {
+			folder = ""
+		}
+		parent := ensureFolder(folders, folder)
+		parent.Children = append(parent.Children, &Node{
+			Type:       NodeConnection,
+			Name:       connectionName(connection),
+			Path:       folder,
+			Connection: connection,
+		})
+	}
+	sortNodes(root)
+	return root.Children
+}
+
+func ensureFolder(folders map[string]*Node, path string) *Node {
+	if node, ok := folders[path]; ok {
+		return node
+	}
+	parentPath := ""
+	name := path
+	if i := strings.LastIndex(path, "/"); i >= 0 {
+		parentPath, name = path[:i], path[i+1:]
+	}
+	parent := ensureFolder(folders, parentPath)
+	node := &Node{Type: NodeFolder, Name: name, Path: path, Children: make([]*Node, 0)}
+	parent.Children = append(parent.Children, node)
+	folders[path] = node
+	return node
+}
+
+func sortNodes(node *Node) {
+	sort.SliceStable(node.Children, func(i, j int) bool {
+		a, b := node.Children[i], node.Children[j]
+		if a.Type != b.Type {
+			return a.Type == NodeFolder
+		}
+		return strings.ToLower(a.Name) < strings.ToLower(b.Name)
+	})
+	for _, child := range node.Children {
+		if child.Type == NodeFolder {
+			sortNodes(child)
+		}
+	}
+}
+
+func connectionName(connection map[string]interface{}) string {
+	for _, key := range []string{"displayName", "name", "server", "host"} {
+		if value, ok := connection[key].(string); ok && value != "" {
+			return value
+		}
+	}
+	return ""
+}
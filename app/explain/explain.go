@@ -0,0 +1,184 @@
+package explain
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"tinydb/app/db"
+	"tinydb/app/db/adapter/mongo"
+	"tinydb/app/db/adapter/mysql"
+	"tinydb/app/db/script"
+	"tinydb/app/db/standard/modules"
+)
+
+const (
+	WarningFullScan     = "full_scan"
+	WarningMissingIndex = "missing_index"
+	WarningFilesort     = "filesort"
+	WarningTemporary    = "temporary"
+	WarningInfo         = "info"
+)
+
+// PlanNode is one step of a query plan, normalised across engines so the
+// frontend can draw MySQL and Mongo plans the same way.
+type PlanNode struct {
+	NodeType   string `json:"nodeType"`
+	Table      string `json:"table,omitempty"`
+	Index      string `json:"index,omitempty"`
+	AccessType string `json:"accessType,omitempty"`
+	// RowsEstimated 优化器估算的行数，RowsExamined/RowsReturned 只在实际执行后才有
+	RowsEstimated float64 `json:"rowsEstimated"`
+	RowsExamined  float64 `json:"rowsExamined"`
+	RowsReturned  float64 `json:"rowsReturned"`
+	Cost          float64 `json:"cost"`
+	// ActualTime 实际耗时，单位毫秒
+	ActualTime float64     `json:"actualTime"`
+	Detail     string      `json:"detail,omitempty"`
+	FullScan   bool        `json:"fullScan"`
+	Children   []*PlanNode `json:"children"`
+}
+
+type Warning struct {
+	Kind    string `json:"kind"`
+	Table   string `json:"table,omitempty"`
+	Message string `json:"message"`
+}
+
+type Plan struct {
+	Root     *PlanNode  `json:"root"`
+	Warnings []*Warning `json:"warnings"`
+	Analyzed bool       `json:"analyzed"`
+	// Raw 驱动返回的原始计划，JSON 文本、EXPLAIN ANALYZE 文本或 Mongo 文档
+	Raw interface{} `json:"raw"`
+}
+
+type Options struct {
+	Database string `json:"database"`
+	Sql      string `json:"sql"`
+	// Analyze 会真正执行语句以获取实际行数和耗时
+	Analyze bool                           `json:"analyze"`
+	Mongo   *modules.CollectionDataOptions `json:"mongo"`
+}
+
+func newPlan(root *PlanNode, raw interface{}) *Plan {
+	return &Plan{Root: root, Raw: raw, Warnings: make([]*Warning, 0)}
+}
+
+func (p *Plan) warn(kind, table, message string) {
+	p.Warnings = append(p.Warnings, &Warning{Kind: kind, Table: table, Message: message})
+}
+
+// ErrAnalyzeWrite EXPLAIN ANALYZE 和 Mongo 的 executionStats 会真正执行语句，只允许分析读语句
+var ErrAnalyzeWrite = errors.New("only read statements can be analysed, the statement would be executed")
+
+// Explain returns the normalised plan of a SQL statement or a Mongo query.
+func Explain(ctx context.Context, session db.Session, opt *Options) (*Plan, error) {
+	switch s := session.(type) {
+	case *mysql.Source:
+		return explainMysql(ctx, s, opt)
+	case *mongo.Source:
+		if opt.Mongo == nil || opt.Mongo.PureName == "" {
+			return nil, fmt.Errorf("collection is required")
+		}
+		if opt.Mongo.Aggregate != nil && script.MongoPipelineWrites(opt.Mongo.Aggregate) {
+			return nil, ErrAnalyzeWrite
+		}
+		raw, err := s.Explain(ctx, opt.Database, opt.Mongo)
+		if err != nil {
+			return nil, err
+		}
+		return MongoPlan(raw), nil
+	default:
+		return nil, fmt.Errorf("explain on %s: %w", session.Dialect(), db.ErrNotSupportedByAdapter)
+	}
+}
+
+func explainMysql(ctx context.Context, s *mysql.Source, opt *Options) (*Plan, error) {
+	query := strings.TrimRight(strings.TrimSpace(opt.Sql), "; \t\r\n")
+	if query == "" {
+		return nil, fmt.Errorf("statement is required")
+	}
+
+	analyze := false
+	var notice string
+	if opt.Analyze {
+		if script.Classify(query) != script.StatementRead {
+			return nil, ErrAnalyzeWrite
+		}
+		version, err := s.Version()
+		if err != nil {
+			return nil, err
+		}
+		if supportsAnalyze(version.Version) {
+			analyze = true
+		} else {
+			notice = "EXPLAIN ANALYZE requires MySQL 8.0.18 or later, showing the estimated plan"
+		}
+	}
+
+	raw, err := s.Explain(ctx, query, analyze)
+	if err != nil {
+		return nil, err
+	}
+	var plan *Plan
+	if analyze {
+		plan = MysqlAnalyzePlan(raw)
+	} else if plan, err = MysqlJsonPlan(raw); err != nil {
+		return nil, err
+	}
+	if notice != "" {
+		plan.warn(WarningInfo, "", notice)
+	}
+	return plan, nil
+}
+
+var versionPattern = regexp.MustCompile(`^(\d+)\.(\d+)\.(\d+)`)
+
+// supportsAnalyze 只有 MySQL 8.0.18 及以上支持 EXPLAIN ANALYZE，MariaDB 的 ANALYZE 语法不同
+func supportsAnalyze(version string) bool {
+	if strings.Contains(strings.ToLower(version), "mariadb") {
+		return false
+	}
+	match := versionPattern.FindStringSubmatch(version)
+	if match == nil {
+		return false
+	}
+	parts := make([]int, 3)
+	for i := range parts {
+		parts[i], _ = strconv.Atoi(match[i+1])
+	}
+	if parts[0] != 8 {
+		return parts[0] > 8
+	}
+	return parts[1] > 0 || parts[2] >= 18
+}
+
+func toFloat(value interface{}) float64 {
+	switch v := value.(type) {
+	case float64:
+		return v
+	case float32:
+		return float64(v)
+	case int:
+		return float64(v)
+	case int32:
+		return float64(v)
+	case int64:
+		return float64(v)
+	case string:
+		f, _ := strconv.ParseFloat(v, 64)
+		return f
+	}
+	return 0
+}
+
+func toString(value interface{}) string {
+	if s, ok := value.(string); ok {
+		return s
+	}
+	return ""
+}
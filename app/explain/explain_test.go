@@ -0,0 +1,122 @@
+package explain
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestMysqlJsonPlan(t *testing.T) {
+	plan, err := MysqlJsonPlan(`{
+  "query_block": {
+    "select_id": 1,
+    "cost_info": {"query_cost": "12.50"},
+    "ordering_operation": {
+      "using_filesort": true,
+      "nested_loop": [
+        {"table": {"table_name": "orders", "access_type": "ALL", "rows_examined_per_scan": 100,
+          "cost_info": {"prefix_cost": "10.25"}, "attached_condition": "(orders.total > 10)"}},
+        {"table": {"table_name": "users", "access_type": "eq_ref", "possible_keys": ["PRIMARY"],
+          "key": "PRIMARY", "rows_examined_per_scan": 1, "cost_info": {"prefix_cost": "12.50"}}}
+      ]
+    }
+  }
+}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if plan.Root.Cost != 12.5 || len(plan.Root.Children) != 1 {
+		t.Fatalf("unexpected root %+v", plan.Root)
+	}
+	join := plan.Root.Children[0].Children[0]
+	if join.NodeType != "nested_loop" || len(join.Children) != 2 {
+		t.Fatalf("unexpected join %+v", join)
+	}
+	orders, users := join.Children[0], join.Children[1]
+	if !orders.FullScan || orders.RowsEstimated != 100 || orders.Cost != 10.25 {
+		t.Fatalf("unexpected orders node %+v", orders)
+	}
+	if users.FullScan || users.Index != "PRIMARY" {
+		t.Fatalf("unexpected users node %+v", users)
+	}
+	if len(plan.Warnings) != 2 || plan.Warnings[0].Kind != WarningFilesort || plan.Warnings[1].Kind != WarningMissingIndex {
+		t.Fatalf("unexpected warnings %+v", plan.Warnings)
+	}
+}
+
+func TestMysqlAnalyzePlan(t *testing.T) {
+	plan := MysqlAnalyzePlan(`-> Nested loop inner join  (cost=4.70 rows=3) (actual time=0.05..0.08 rows=2 loops=1)
+    -> Filter: (o.total > 10)  (cost=1.55 rows=3) (actual time=0.03..0.04 rows=2 loops=1)
+        -> Table scan on o  (cost=1.55 rows=10) (actual time=0.02..0.03 rows=10 loops=1)
+    -> Single-row index lookup on u using PRIMARY (id=o.user_id)  (cost=0.28 rows=1) (actual time=0.01..0.01 rows=1 loops=2)`)
+	if !plan.Analyzed || plan.Root.NodeType != "Nested loop inner join" || len(plan.Root.Children) != 2 {
+		t.Fatalf("unexpected root %+v", plan.Root)
+	}
+	scan := plan.Root.Children[0].Children[0]
+	if !scan.FullScan || scan.Table != "o" || scan.RowsEstimated != 10 || scan.RowsReturned != 10 {
+		t.Fatalf("unexpected scan %+v", scan)
+	}
+	lookup := plan.Root.Children[1]
+	if lookup.NodeType != "Single-row index lookup" || lookup.Table != "u" || lookup.Index != "PRIMARY" || lookup.RowsReturned != 2 {
+		t.Fatalf("unexpected lookup %+v", lookup)
+	}
+	if len(plan.Warnings) != 1 || plan.Warnings[0].Kind != WarningFullScan {
+		t.Fatalf("unexpected warnings %+v", plan.Warnings)
+	}
+}
+
+func TestMongoPlan(t *testing.T) {
+	plan := MongoPlan(bson.M{
+		"queryPlanner": bson.M{"namespace": "shop.orders"},
+		"executionStats": bson.M{
+			"nReturned":           int32(3),
+			"executionTimeMillis": int32(5),
+			"totalDocsExamined":   int32(120),
+			"executionStages": bson.M{
+				"stage":     "SORT",
+				"nReturned": int32(3),
+				"inputStage": bson.M{
+					"stage":        "COLLSCAN",
+					"filter":       bson.M{"status": bson.M{"$eq": "paid"}},
+					"docsExamined": int32(120),
+				},
+			},
+		},
+	})
+	if plan.Root.NodeType != "SORT" || plan.Root.RowsExamined != 120 || plan.Root.ActualTime != 5 {
+		t.Fatalf("unexpected root %+v", plan.Root)
+	}
+	scan := plan.Root.Children[0]
+	if !scan.FullScan || scan.Table != "shop.orders" || scan.RowsExamined != 120 {
+		t.Fatalf("unexpected scan %+v", scan)
+	}
+	if len(plan.Warnings) != 2 || plan.Warnings[1].Kind != WarningMissingIndex {
+		t.Fatalf("unexpected warnings %+v", plan.Warnings)
+	}
+}
+
+func TestSupportsAnalyze(t *testing.T) {
+	cases := map[string]bool{
+		"8.0.17":                    false,
+		"8.0.18":                    true,
+		"8.4.0":                     true,
+		"9.1.0":                     true,
+		"5.7.44-log":                false,
+		"10.11.6-MariaDB-0+deb12u1": false,
+	}
+	for version, expected := range cases {
+		if supportsAnalyze(version) != expected {
+			t.Fatalf("supportsAnalyze(%q) should be %v", version, expected)
+		}
+	}
+}
+
+func TestAnalyzeRejectsWrites(t *testing.T) {
+	for _, sql := range []string{"delete from t", "update t set a = 1 where id = 2", "select 1; drop table t"} {
+		if _, err := explainMysql(context.Background(), nil, &Options{Sql: sql, Analyze: true}); !errors.Is(err, ErrAnalyzeWrite) {
+			t.Fatalf("analysing %q should be rejected, got %v", sql, err)
+		}
+	}
+}
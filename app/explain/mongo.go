@@ -0,0 +1,142 @@
+package explain
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// MongoPlan converts the output of the explain command with executionStats
+// verbosity, for find, count and aggregate.
+func MongoPlan(raw bson.M) *Plan {
+	plan := newPlan(nil, raw)
+	plan.Root = mongoExplain(plan, raw)
+	return plan
+}
+
+func mongoExplain(plan *Plan, doc map[string]interface{}) *PlanNode {
+	// 聚合管道无法整体下推时，每个阶段单独列出，$cursor 中才是查询计划
+	if stages := asSlice(doc["stages"]); stages != nil {
+		root := &PlanNode{NodeType: "aggregate", Children: make([]*PlanNode, 0, len(stages))}
+		for _, item := range stages {
+			stage := asMap(item)
+			for name, value := range stage {
+				if name == "$cursor" {
+					root.Children = append(root.Children, mongoExplain(plan, asMap(value)))
+				} else if name != "nReturned" && name != "executionTimeMillisEstimate" {
+					root.Children = append(root.Children, &PlanNode{
+						NodeType:     name,
+						RowsReturned: toFloat(stage["nReturned"]),
+						ActualTime:   toFloat(stage["executionTimeMillisEstimate"]),
+						Detail:       mongoDetail(value),
+						Children:     make([]*PlanNode, 0),
+					})
+				}
+			}
+		}
+		plan.Analyzed = true
+		return root
+	}
+
+	planner := asMap(doc["queryPlanner"])
+	namespace := toString(planner["namespace"])
+	winning := asMap(planner["winningPlan"])
+	// 6.0 之后的 SBE 引擎 executionStages 是槽位执行树，展示 queryPlan 更直观
+	sbe := false
+	if queryPlan := asMap(winning["queryPlan"]); queryPlan != nil {
+		winning, sbe = queryPlan, true
+	}
+	stats := asMap(doc["executionStats"])
+	tree := winning
+	if stages := asMap(stats["executionStages"]); stages != nil && !sbe {
+		tree = stages
+	}
+	if tree == nil {
+		return &PlanNode{NodeType: "query", Table: namespace, Children: make([]*PlanNode, 0)}
+	}
+
+	root := mongoStage(plan, namespace, tree)
+	if stats != nil {
+		plan.Analyzed = true
+		root.RowsReturned = toFloat(stats["nReturned"])
+		root.ActualTime = toFloat(stats["executionTimeMillis"])
+		root.RowsExamined = toFloat(stats["totalDocsExamined"])
+		if root.RowsExamined == 0 {
+			root.RowsExamined = toFloat(stats["totalKeysExamined"])
+		}
+	}
+	return root
+}
+
+func mongoStage(plan *Plan, namespace string, stage map[string]interface{}) *PlanNode {
+	node := &PlanNode{
+		NodeType:     toString(stage["stage"]),
+		Table:        namespace,
+		Index:        toString(stage["indexName"]),
+		RowsReturned: toFloat(stage["nReturned"]),
+		RowsExamined: toFloat(stage["docsExamined"]),
+		ActualTime:   toFloat(stage["executionTimeMillisEstimate"]),
+		Children:     make([]*PlanNode, 0),
+	}
+	if node.RowsExamined == 0 {
+		node.RowsExamined = toFloat(stage["keysExamined"])
+	}
+	if filter, ok := stage["filter"]; ok {
+		node.Detail = mongoDetail(filter)
+	} else if keyPattern, ok := stage["keyPattern"]; ok {
+		node.Detail = mongoDetail(keyPattern)
+	}
+
+	switch node.NodeType {
+	case "COLLSCAN":
+		node.FullScan = true
+		if node.Detail != "" {
+			plan.warn(WarningMissingIndex, namespace, fmt.Sprintf("collection scan on %s, no index is used for filter %s", namespace, node.Detail))
+		} else {
+			plan.warn(WarningFullScan, namespace, fmt.Sprintf("collection scan on %s", namespace))
+		}
+	case "SORT":
+		plan.warn(WarningFilesort, namespace, fmt.Sprintf("in-memory sort on %s, an index on the sort fields may avoid it", namespace))
+	}
+
+	if input := asMap(stage["inputStage"]); input != nil {
+		node.Children = append(node.Children, mongoStage(plan, namespace, input))
+	}
+	for _, input := range asSlice(stage["inputStages"]) {
+		if child := asMap(input); child != nil {
+			node.Children = append(node.Children, mongoStage(plan, namespace, child))
+		}
+	}
+	return node
+}
+
+func mongoDetail(value interface{}) string {
+	if marshal, err := json.Marshal(value); err == nil {
+		return string(marshal)
+	}
+	return fmt.Sprint(value)
+}
+
+func asMap(value interface{}) map[string]interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		return v
+	case primitive.M:
+		return v
+	case primitive.D:
+		return v.Map()
+	}
+	return nil
+}
+
+func asSlice(value interface{}) []interface{} {
+	switch v := value.(type) {
+	case []interface{}:
+		return v
+	case primitive.A:
+		return v
+	}
+	return nil
+}
@@ -0,0 +1,249 @@
+package explain
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// 这些节点只是包装了下层的 query_block/table，在计划树中作为一个操作节点展示
+var mysqlOperations = []string{
+	"ordering_operation",
+	"grouping_operation",
+	"duplicates_removal",
+	"windowing",
+	"buffer_result",
+}
+
+// MysqlJsonPlan converts the output of EXPLAIN FORMAT=JSON to a plan tree.
+func MysqlJsonPlan(raw string) (*Plan, error) {
+	var doc map[string]interface{}
+	if err := json.Unmarshal([]byte(raw), &doc); err != nil {
+		return nil, fmt.Errorf("unexpected EXPLAIN output: %w", err)
+	}
+	block, ok := doc["query_block"].(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("unexpected EXPLAIN output: query_block is missing")
+	}
+	plan := newPlan(nil, raw)
+	plan.Root = mysqlBlock(plan, "query_block", block)
+	return plan, nil
+}
+
+func mysqlBlock(plan *Plan, nodeType string, block map[string]interface{}) *PlanNode {
+	node := &PlanNode{NodeType: nodeType, Children: make([]*PlanNode, 0)}
+	if cost, ok := block["cost_info"].(map[string]interface{}); ok {
+		node.Cost = toFloat(cost["query_cost"])
+		if node.Cost == 0 {
+			node.Cost = toFloat(cost["sort_cost"])
+		}
+	}
+	if selectId, ok := block["select_id"]; ok {
+		node.Detail = fmt.Sprintf("select #%v", selectId)
+	}
+	if message := toString(block["message"]); message != "" {
+		node.Detail = strings.TrimSpace(node.Detail + " " + message)
+	}
+	if block["using_filesort"] == true {
+		plan.warn(WarningFilesort, "", "result is sorted with a filesort, an index on the ORDER BY columns may avoid it")
+	}
+	if block["using_temporary_table"] == true {
+		plan.warn(WarningTemporary, "", "a temporary table is used for "+strings.ReplaceAll(nodeType, "_", " "))
+	}
+
+	if table, ok := block["table"].(map[string]interface{}); ok {
+		node.Children = append(node.Children, mysqlTable(plan, table))
+	}
+	if loop, ok := block["nested_loop"].([]interface{}); ok {
+		join := &PlanNode{NodeType: "nested_loop", Children: make([]*PlanNode, 0, len(loop))}
+		for _, item := range loop {
+			if entry, ok := item.(map[string]interface{}); ok {
+				if table, ok := entry["table"].(map[string]interface{}); ok {
+					join.Children = append(join.Children, mysqlTable(plan, table))
+				}
+			}
+		}
+		node.Children = append(node.Children, join)
+	}
+	for _, operation := range mysqlOperations {
+		if child, ok := block[operation].(map[string]interface{}); ok {
+			node.Children = append(node.Children, mysqlBlock(plan, operation, child))
+		}
+	}
+	if union, ok := block["union_result"].(map[string]interface{}); ok {
+		unionNode := mysqlBlock(plan, "union_result", union)
+		if specs, ok := union["query_specifications"].([]interface{}); ok {
+			unionNode.Children = append(unionNode.Children, mysqlSubqueries(plan, specs)...)
+		}
+		node.Children = append(node.Children, unionNode)
+	}
+	if nested, ok := block["query_block"].(map[string]interface{}); ok {
+		node.Children = append(node.Children, mysqlBlock(plan, "query_block", nested))
+	}
+	for _, key := range []string{"attached_subqueries", "optimized_away_subqueries", "order_by_subqueries", "group_by_subqueries"} {
+		if subqueries, ok := block[key].([]interface{}); ok {
+			node.Children = append(node.Children, mysqlSubqueries(plan, subqueries)...)
+		}
+	}
+	return node
+}
+
+func mysqlSubqueries(plan *Plan, items []interface{}) []*PlanNode {
+	nodes := make([]*PlanNode, 0, len(items))
+	for _, item := range items {
+		if entry, ok := item.(map[string]interface{}); ok {
+			if block, ok := entry["query_block"].(map[string]interface{}); ok {
+				nodes = append(nodes, mysqlBlock(plan, "subquery", block))
+			}
+		}
+	}
+	return nodes
+}
+
+func mysqlTable(plan *Plan, table map[string]interface{}) *PlanNode {
+	node := &PlanNode{
+		NodeType:      "table",
+		Table:         toString(table["table_name"]),
+		Index:         toString(table["key"]),
+		AccessType:    toString(table["access_type"]),
+		RowsEstimated: toFloat(table["rows_examined_per_scan"]),
+		Detail:        toString(table["attached_condition"]),
+		Children:      make([]*PlanNode, 0),
+	}
+	if cost, ok := table["cost_info"].(map[string]interface{}); ok {
+		node.Cost = toFloat(cost["prefix_cost"])
+		if node.Cost == 0 {
+			node.Cost = toFloat(cost["read_cost"]) + toFloat(cost["eval_cost"])
+		}
+	}
+	if message := toString(table["message"]); message != "" && node.Detail == "" {
+		node.Detail = message
+	}
+
+	var possibleKeys []string
+	if keys, ok := table["possible_keys"].([]interface{}); ok {
+		for _, key := range keys {
+			possibleKeys = append(possibleKeys, toString(key))
+		}
+	}
+	switch node.AccessType {
+	case "ALL":
+		node.FullScan = true
+		if len(possibleKeys) == 0 {
+			plan.warn(WarningMissingIndex, node.Table, fmt.Sprintf("full table scan on %s, no index can be used", node.Table))
+		} else {
+			plan.warn(WarningFullScan, node.Table, fmt.Sprintf("full table scan on %s, possible keys %s were not used", node.Table, strings.Join(possibleKeys, ", ")))
+		}
+	case "index":
+		node.FullScan = true
+		plan.warn(WarningFullScan, node.Table, fmt.Sprintf("full index scan on %s using %s", node.Table, node.Index))
+	}
+	if table["using_filesort"] == true {
+		plan.warn(WarningFilesort, node.Table, fmt.Sprintf("filesort on %s", node.Table))
+	}
+	if table["using_temporary_table"] == true {
+		plan.warn(WarningTemporary, node.Table, fmt.Sprintf("temporary table used for %s", node.Table))
+	}
+
+	if materialized, ok := table["materialized_from_subquery"].(map[string]interface{}); ok {
+		if block, ok := materialized["query_block"].(map[string]interface{}); ok {
+			node.Children = append(node.Children, mysqlBlock(plan, "materialized", block))
+		}
+	}
+	if subqueries, ok := table["attached_subqueries"].([]interface{}); ok {
+		node.Children = append(node.Children, mysqlSubqueries(plan, subqueries)...)
+	}
+	return node
+}
+
+var (
+	analyzeLinePattern   = regexp.MustCompile(`^(\s*)-> (.*)$`)
+	analyzeCostPattern   = regexp.MustCompile(`\(cost=([\d.e+]+)(?:\.\.([\d.e+]+))? rows=([\d.e+]+)\)`)
+	analyzeActualPattern = regexp.MustCompile(`\(actual time=([\d.e+]+)\.\.([\d.e+]+) rows=([\d.e+]+) loops=(\d+)\)`)
+	analyzeTablePattern  = regexp.MustCompile(`^(.*?) on (\S+?)(?: using (\S+?))?(?:\s|$)`)
+)
+
+// MysqlAnalyzePlan converts the indented tree printed by EXPLAIN ANALYZE.
+func MysqlAnalyzePlan(raw string) *Plan {
+	plan := newPlan(nil, raw)
+	plan.Analyzed = true
+
+	type level struct {
+		indent int
+		node   *PlanNode
+	}
+	var stack []level
+	var roots []*PlanNode
+	for _, line := range strings.Split(raw, "\n") {
+		match := analyzeLinePattern.FindStringSubmatch(line)
+		if match == nil {
+			continue
+		}
+		node := analyzeNode(plan, match[2])
+		indent := len(match[1])
+		for len(stack) > 0 && stack[len(stack)-1].indent >= indent {
+			stack = stack[:len(stack)-1]
+		}
+		if len(stack) == 0 {
+			roots = append(roots, node)
+		} else {
+			parent := stack[len(stack)-1].node
+			parent.Children = append(parent.Children, node)
+		}
+		stack = append(stack, level{indent: indent, node: node})
+	}
+
+	switch len(roots) {
+	case 0:
+		plan.Root = &PlanNode{NodeType: "query", Detail: strings.TrimSpace(raw), Children: make([]*PlanNode, 0)}
+	case 1:
+		plan.Root = roots[0]
+	default:
+		plan.Root = &PlanNode{NodeType: "query", Children: roots}
+	}
+	return plan
+}
+
+func analyzeNode(plan *Plan, text string) *PlanNode {
+	description := text
+	if index := strings.Index(text, "  ("); index >= 0 {
+		description = text[:index]
+	}
+	node := &PlanNode{NodeType: description, Detail: text, Children: make([]*PlanNode, 0)}
+	if match := analyzeCostPattern.FindStringSubmatch(text); match != nil {
+		node.Cost = toFloat(match[1])
+		if match[2] != "" {
+			node.Cost = toFloat(match[2])
+		}
+		node.RowsEstimated = toFloat(match[3])
+	}
+	if match := analyzeActualPattern.FindStringSubmatch(text); match != nil {
+		loops := toFloat(match[4])
+		node.ActualTime = toFloat(match[2]) * loops
+		node.RowsReturned = toFloat(match[3]) * loops
+		node.RowsExamined = node.RowsReturned
+	}
+	if match := analyzeTablePattern.FindStringSubmatch(description); match != nil && !strings.ContainsAny(match[1], ":(") {
+		node.NodeType = match[1]
+		node.Table = match[2]
+		node.Index = match[3]
+	}
+
+	lower := strings.ToLower(node.NodeType)
+	switch {
+	case lower == "table scan":
+		node.FullScan = true
+		node.AccessType = "ALL"
+		plan.warn(WarningFullScan, node.Table, fmt.Sprintf("full table scan on %s", node.Table))
+	case lower == "index scan" || lower == "covering index scan":
+		node.FullScan = true
+		node.AccessType = "index"
+		plan.warn(WarningFullScan, node.Table, fmt.Sprintf("full index scan on %s using %s", node.Table, node.Index))
+	case strings.HasPrefix(lower, "sort"):
+		plan.warn(WarningFilesort, "", "result is sorted with a filesort, an index on the ORDER BY columns may avoid it")
+	case strings.HasPrefix(lower, "materialize") || strings.HasPrefix(lower, "temporary table"):
+		plan.warn(WarningTemporary, node.Table, "a temporary table is used")
+	}
+	return node
+}
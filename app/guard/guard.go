@@ -0,0 +1,177 @@
+package guard
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/samber/lo"
+	"tinydb/app/db"
+	"tinydb/app/db/adapter/mongo"
+	"tinydb/app/db/adapter/mysql"
+	"tinydb/app/db/script"
+	"tinydb/app/settings"
+)
+
+// LargeTableRows ALTER TABLE 默认只有在表的行数超过该值时才需要确认，
+// 实际阈值来自设置中的 confirmAlterRows
+const LargeTableRows = settings.DefaultConfirmAlterRows
+
+const (
+	// DangerDeleteAll Mongo deleteMany 没有过滤条件
+	DangerDeleteAll = "delete_all"
+	// DangerWrite 生产环境中的普通写入语句
+	DangerWrite = "write"
+)
+
+// Item is a statement waiting for confirmation, EstimatedRows is -1 when the
+// number of affected rows is unknown.
+type Item struct {
+	script.Danger
+	EstimatedRows int64 `json:"estimatedRows"`
+}
+
+// Confirmation is returned instead of a result, the statement only runs when
+// it is sent again with Token.
+type Confirmation struct {
+	Token string  `json:"token"`
+	Items []*Item `json:"items"`
+}
+
+// CheckSql returns the statements of sql that need a confirmation. tableRows
+// looks a table up in the analysed structure, with confirmWrites every writing
+// statement needs a confirmation.
+func CheckSql(ctx context.Context, session db.Session, sql string, tableRows func(table string) int64, confirmWrites bool) []*Item {
+	items := make([]*Item, 0)
+	dangers := script.Dangers(sql)
+	if confirmWrites {
+		for _, statement := range script.Split(sql) {
+			text := strings.TrimSpace(statement.Sql)
+			if script.Classify(text) == script.StatementWrite && !lo.ContainsBy(dangers, func(d *script.Danger) bool {
+				return d.Statement == text
+			}) {
+				items = append(items, &Item{Danger: script.Danger{Kind: DangerWrite, Statement: text}, EstimatedRows: -1})
+			}
+		}
+	}
+	for _, danger := range dangers {
+		item := &Item{Danger: *danger, EstimatedRows: -1}
+		if danger.Table != "" && tableRows != nil {
+			item.EstimatedRows = tableRows(danger.Table)
+		}
+		switch danger.Kind {
+		case script.DangerAlter:
+			if item.EstimatedRows < settings.Get().ConfirmAlterRows {
+				continue
+			}
+		case script.DangerNoWhere:
+			if rows, ok := explainRows(ctx, session, danger.Statement); ok {
+				item.EstimatedRows = rows
+			}
+		}
+		items = append(items, item)
+	}
+	return items
+}
+
+// Write is the confirmation item of a write that does not come from the SQL
+// editor, such as an import or an index change.
+func Write(table, statement string) *Item {
+	return &Item{Danger: script.Danger{Kind: DangerWrite, Table: table, Statement: statement}, EstimatedRows: -1}
+}
+
+// CheckMongoDelete requires a confirmation for a deleteMany without filter,
+// the estimate comes from countDocuments. With confirmWrites a filtered delete
+// is confirmed as an ordinary write.
+func CheckMongoDelete(ctx context.Context, session db.Session, database, collection string, filter map[string]interface{}, confirmWrites bool) []*Item {
+	if len(filter) > 0 {
+		if !confirmWrites {
+			return nil
+		}
+		condition, _ := json.Marshal(filter)
+		return []*Item{Write(collection, fmt.Sprintf("db.getCollection(%q).deleteMany(%s)", collection, condition))}
+	}
+	item := &Item{
+		Danger: script.Danger{
+			Kind:      DangerDeleteAll,
+			Table:     collection,
+			Statement: fmt.Sprintf("db.getCollection(%q).deleteMany({})", collection),
+		},
+		EstimatedRows: -1,
+	}
+	if s, ok := session.(*mongo.Source); ok {
+		if count, err := s.CountDocuments(ctx, database, collection, map[string]interface{}{}); err == nil {
+			item.EstimatedRows = count
+		}
+	}
+	return []*Item{item}
+}
+
+// Subject binds a confirmation token to one statement on one database.
+func Subject(conid, database, statement string) string {
+	return conid + "/" + database + "/" + strings.TrimSpace(statement)
+}
+
+// explainRows 累加 EXPLAIN 输出的 rows 列，作为影响行数的估计
+func explainRows(ctx context.Context, session db.Session, statement string) (int64, bool) {
+	s, ok := session.(*mysql.Source)
+	if !ok {
+		return 0, false
+	}
+	var total int64
+	found := false
+	err := s.Stream(ctx, "EXPLAIN "+statement, func(_ []string, row map[string]interface{}) error {
+		if rows, ok := toInt64(row["rows"]); ok {
+			total += rows
+			found = true
+		}
+		return nil
+	})
+	return total, err == nil && found
+}
+
+// TableRows reads tableRowCount of a table from an analysed structure.
+func TableRows(structure map[string]interface{}, table string) int64 {
+	var tables []map[string]interface{}
+	switch v := structure["tables"].(type) {
+	case []map[string]interface{}:
+		tables = v
+	case []interface{}:
+		for _, item := range v {
+			if m, ok := item.(map[string]interface{}); ok {
+				tables = append(tables, m)
+			}
+		}
+	}
+	for _, t := range tables {
+		if name, _ := t["pureName"].(string); strings.EqualFold(name, table) {
+			if rows, ok := toInt64(t["tableRowCount"]); ok {
+				return rows
+			}
+		}
+	}
+	return -1
+}
+
+func toInt64(value interface{}) (int64, bool) {
+	switch v := value.(type) {
+	case int:
+		return int64(v), true
+	case int32:
+		return int64(v), true
+	case int64:
+		return v, true
+	case uint64:
+		return int64(v), true
+	case float64:
+		return int64(v), true
+	case []byte:
+		return toInt64(string(v))
+	case string:
+		i, err := strconv.ParseInt(strings.TrimSpace(v), 10, 64)
+		return i, err == nil
+	}
+	return 0, false
+}
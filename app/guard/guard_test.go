@@ -0,0 +1,58 @@
+package guard
+
+import (
+	"context"
+	"testing"
+
+	"tinydb/app/db/adapter/mongo"
+)
+
+func TestTableRows(t *testing.T) {
+	structure := map[string]interface{}{
+		"tables": []interface{}{
+			map[string]interface{}{"pureName": "orders", "tableRowCount": "250000"},
+			map[string]interface{}{"pureName": "users", "tableRowCount": float64(12)},
+		},
+	}
+	if rows := TableRows(structure, "ORDERS"); rows != 250000 {
+		t.Fatalf("unexpected rows %d", rows)
+	}
+	if rows := TableRows(structure, "users"); rows != 12 {
+		t.Fatalf("unexpected rows %d", rows)
+	}
+	if rows := TableRows(structure, "missing"); rows != -1 {
+		t.Fatalf("unknown tables should be -1, got %d", rows)
+	}
+}
+
+func TestCheckSqlAlterOnlyOnLargeTables(t *testing.T) {
+	rows := map[string]int64{"big": LargeTableRows + 1, "small": 10}
+	items := CheckSql(context.Background(), &mongo.Source{}, "alter table small add c int; alter table big add c int; truncate small", func(table string) int64 {
+		return rows[table]
+	}, false)
+	if len(items) != 2 || items[0].Table != "big" || items[1].Kind != "truncate" || items[1].EstimatedRows != 10 {
+		t.Fatalf("unexpected items %+v", items)
+	}
+}
+
+func TestCheckSqlConfirmWrites(t *testing.T) {
+	sql := "select 1; insert into t values (1); drop table t"
+	if items := CheckSql(context.Background(), &mongo.Source{}, sql, nil, false); len(items) != 1 || items[0].Kind != "drop" {
+		t.Fatalf("unexpected items %+v", items)
+	}
+	items := CheckSql(context.Background(), &mongo.Source{}, sql, nil, true)
+	if len(items) != 2 || items[0].Kind != DangerWrite || items[0].Statement != "insert into t values (1)" || items[1].Kind != "drop" {
+		t.Fatalf("unexpected items %+v", items)
+	}
+}
+
+func TestCheckMongoDelete(t *testing.T) {
+	filter := map[string]interface{}{"status": "old"}
+	if items := CheckMongoDelete(context.Background(), nil, "shop", "orders", filter, false); len(items) != 0 {
+		t.Fatalf("a filtered delete should not need a confirmation: %+v", items)
+	}
+	items := CheckMongoDelete(context.Background(), nil, "shop", "orders", filter, true)
+	if len(items) != 1 || items[0].Kind != DangerWrite || items[0].Statement != `db.getCollection("orders").deleteMany({"status":"old"})` {
+		t.Fatalf("a filtered delete should be confirmed as a write: %+v", items)
+	}
+}
@@ -0,0 +1,341 @@
+package history
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	uuid "github.com/satori/go.uuid"
+	"tinydb/app/pkg/logger"
+	"tinydb/app/utility"
+)
+
+const historyDir = "history"
+
+// maxEntries 每个连接最多保留的未固定记录数，超出时删除最旧的
+const maxEntries = 5000
+
+const defaultSearchLimit = 200
+
+// Entry is a statement that was executed through the query editor or the collection viewer.
+type Entry struct {
+	Id       string           `json:"_id"`
+	Conid    string           `json:"conid"`
+	Database string           `json:"database"`
+	Sql      string           `json:"sql"`
+	Time     utility.UnixTime `json:"time"`
+	// Duration 执行耗时，单位毫秒
+	Duration int64  `json:"duration"`
+	RowCount int    `json:"rowCount"`
+	Error    string `json:"error,omitempty"`
+	Pinned   bool   `json:"pinned"`
+}
+
+// Query filters history entries, zero values match everything.
+type Query struct {
+	Conid      string           `json:"conid"`
+	Database   string           `json:"database"`
+	Text       string           `json:"text"`
+	From       utility.UnixTime `json:"from"`
+	To         utility.UnixTime `json:"to"`
+	PinnedOnly bool             `json:"pinnedOnly"`
+	Limit      int              `json:"limit"`
+}
+
+var (
+	mu     sync.Mutex
+	stores = make(map[string]*utility.JsonLinesDatabase)
+)
+
+func historyPath() string {
+	return filepath.Join(utility.DataDir(), historyDir)
+}
+
+func store(conid string) (*utility.JsonLinesDatabase, error) {
+	dir := historyPath()
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		return nil, err
+	}
+	filename := filepath.Join(dir, filepath.Base(conid)+".jsonl")
+	if existing, ok := stores[filename]; ok {
+		return existing, nil
+	}
+	database := utility.NewJsonLinesDatabase(filename)
+	stores[filename] = database
+	return database, nil
+}
+
+// conids lists the connections that have a history file.
+func conids() []string {
+	files, err := os.ReadDir(historyPath())
+	if err != nil {
+		return nil
+	}
+	var result []string
+	for _, file := range files {
+		if !file.IsDir() && strings.HasSuffix(file.Name(), ".jsonl") {
+			result = append(result, strings.TrimSuffix(file.Name(), ".jsonl"))
+		}
+	}
+	return result
+}
+
+// Record queues an executed statement for the history of its connection, the
+// entry is written in the background so the query path never waits for the file.
+func Record(conid, database, sql string, started time.Time, rowCount int, err error) {
+	if conid == "" || strings.TrimSpace(sql) == "" {
+		return
+	}
+	entry := &Entry{
+		Conid:    conid,
+		Database: database,
+		Sql:      sql,
+		Time:     utility.GetUnixTime(started.Unix()),
+		Duration: time.Since(started).Milliseconds(),
+		RowCount: rowCount,
+	}
+	if err != nil {
+		entry.Error = err.Error()
+	}
+
+	startWriter()
+	select {
+	case queue <- entry:
+	default:
+		logger.Errorf("query history queue is full, dropped a statement of %s", conid)
+	}
+}
+
+// queueSize 等待写入的记录上限，写入跟不上时丢弃新的记录而不是阻塞查询
+const queueSize = 1024
+
+// trimMargin 未固定记录超过 maxEntries 这么多条后才删除旧记录，避免每次写入都重写整个文件
+const trimMargin = 500
+
+var (
+	// queue 中是 *Entry 或 flush 放入的 chan struct{}
+	queue       = make(chan interface{}, queueSize)
+	writerStart sync.Once
+)
+
+func startWriter() {
+	writerStart.Do(func() {
+		go writer()
+	})
+}
+
+// writer 每次取出队列中已有的全部记录，每个连接一次写入
+func writer() {
+	for item := range queue {
+		batch := map[string][]*Entry{}
+		var flushed []chan struct{}
+		add := func(item interface{}) {
+			switch v := item.(type) {
+			case *Entry:
+				batch[v.Conid] = append(batch[v.Conid], v)
+			case chan struct{}:
+				flushed = append(flushed, v)
+			}
+		}
+		add(item)
+	drain:
+		for {
+			select {
+			case item := <-queue:
+				add(item)
+			default:
+				break drain
+			}
+		}
+		write(batch)
+		for _, done := range flushed {
+			close(done)
+		}
+	}
+}
+
+func write(batch map[string][]*Entry) {
+	mu.Lock()
+	defer mu.Unlock()
+	for conid, entries := range batch {
+		records, err := store(conid)
+		if err != nil {
+			logger.Errorf("open query history of %s failed: %v", conid, err)
+			continue
+		}
+		err = records.Rewrite(func(data []map[string]interface{}) ([]map[string]interface{}, error) {
+			for _, entry := range entries {
+				obj := toMap(entry)
+				obj["_id"] = uuid.NewV4().String()
+				data = append(data, obj)
+			}
+			return trim(data), nil
+		})
+		if err != nil {
+			logger.Errorf("record query history of %s failed: %v", conid, err)
+			continue
+		}
+		utility.EmitChanged(fmt.Sprintf("query-history-changed-%s", conid))
+	}
+}
+
+// flush 等待已经 Record 的记录写入文件
+func flush() {
+	startWriter()
+	done := make(chan struct{})
+	queue <- done
+	<-done
+}
+
+// trim 未固定记录超过 maxEntries+trimMargin 时删除最旧的，保留 maxEntries 条。
+// 写入总是追加到末尾，所以文件顺序就是时间顺序
+func trim(data []map[string]interface{}) []map[string]interface{} {
+	unpinned := 0
+	for _, obj := range data {
+		if pinned, _ := obj["pinned"].(bool); !pinned {
+			unpinned++
+		}
+	}
+	if unpinned <= maxEntries+trimMargin {
+		return data
+	}
+	stale := unpinned - maxEntries
+	kept := make([]map[string]interface{}, 0, len(data)-stale)
+	for _, obj := range data {
+		if pinned, _ := obj["pinned"].(bool); !pinned && stale > 0 {
+			stale--
+			continue
+		}
+		kept = append(kept, obj)
+	}
+	return kept
+}
+
+// Search returns the newest entries matching the query.
+func Search(q *Query) ([]*Entry, error) {
+	flush()
+	mu.Lock()
+	defer mu.Unlock()
+
+	targets := []string{q.Conid}
+	if q.Conid == "" {
+		targets = conids()
+	}
+
+	text := strings.ToLower(strings.TrimSpace(q.Text))
+	result := make([]*Entry, 0)
+	for _, conid := range targets {
+		database, err := store(conid)
+		if err != nil {
+			return nil, err
+		}
+		for _, obj := range database.Find() {
+			entry := toEntry(obj)
+			if q.Database != "" && entry.Database != q.Database {
+				continue
+			}
+			if q.PinnedOnly && !entry.Pinned {
+				continue
+			}
+			if q.From > 0 && entry.Time < q.From {
+				continue
+			}
+			if q.To > 0 && entry.Time > q.To {
+				continue
+			}
+			if text != "" && !strings.Contains(strings.ToLower(entry.Sql), text) {
+				continue
+			}
+			result = append(result, entry)
+		}
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].Time > result[j].Time
+	})
+	limit := q.Limit
+	if limit <= 0 {
+		limit = defaultSearchLimit
+	}
+	if len(result) > limit {
+		result = result[:limit]
+	}
+	return result, nil
+}
+
+// Pin marks an entry as favourite, pinned entries are never pruned.
+func Pin(conid, id string, pinned bool) (*Entry, error) {
+	flush()
+	mu.Lock()
+	defer mu.Unlock()
+
+	database, err := store(conid)
+	if err != nil {
+		return nil, err
+	}
+	obj := database.Get(id)
+	if obj == nil {
+		return nil, fmt.Errorf("history entry '%s' not found", id)
+	}
+	entry := toEntry(obj)
+	entry.Pinned = pinned
+	if _, err = database.Update(toMap(entry)); err != nil {
+		return nil, err
+	}
+	utility.EmitChanged(fmt.Sprintf("query-history-changed-%s", conid))
+	return entry, nil
+}
+
+// Prune removes the unpinned entries executed before the given time, on every
+// connection when conid is empty, and returns how many were removed.
+func Prune(conid string, before utility.UnixTime) (int, error) {
+	flush()
+	mu.Lock()
+	defer mu.Unlock()
+
+	targets := []string{conid}
+	if conid == "" {
+		targets = conids()
+	}
+
+	total := 0
+	for _, target := range targets {
+		database, err := store(target)
+		if err != nil {
+			return total, err
+		}
+		removed, err := database.RemoveBy(func(obj map[string]interface{}) bool {
+			entry := toEntry(obj)
+			return !entry.Pinned && (before <= 0 || entry.Time < before)
+		})
+		if err != nil {
+			return total, err
+		}
+		if len(removed) > 0 {
+			total += len(removed)
+			utility.EmitChanged(fmt.Sprintf("query-history-changed-%s", target))
+		}
+	}
+	return total, nil
+}
+
+func toEntry(obj map[string]interface{}) *Entry {
+	entry := &Entry{}
+	if marshal, err := json.Marshal(obj); err == nil {
+		_ = json.Unmarshal(marshal, entry)
+	}
+	return entry
+}
+
+func toMap(entry *Entry) map[string]interface{} {
+	obj := make(map[string]interface{})
+	if marshal, err := json.Marshal(entry); err == nil {
+		_ = json.Unmarshal(marshal, &obj)
+	}
+	return obj
+}
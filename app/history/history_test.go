@@ -0,0 +1,68 @@
+package history
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"tinydb/app/utility"
+)
+
+func TestRecordSearchPinPrune(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("USERPROFILE", t.TempDir())
+
+	old := time.Now().Add(-48 * time.Hour)
+	Record("c1", "shop", "select * from orders", old, 3, nil)
+	Record("c1", "shop", "select * from users", time.Now(), 1, nil)
+	Record("c2", "crm", "delete from Users", time.Now(), 0, errors.New("denied"))
+
+	entries, err := Search(&Query{Text: "USERS"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 entries, got %d", len(entries))
+	}
+
+	entries, _ = Search(&Query{Conid: "c1", To: utility.GetUnixTime(time.Now().Add(-time.Hour).Unix())})
+	if len(entries) != 1 || entries[0].RowCount != 3 || entries[0].Database != "shop" {
+		t.Fatalf("unexpected entries %+v", entries)
+	}
+	if _, err = Pin("c1", entries[0].Id, true); err != nil {
+		t.Fatal(err)
+	}
+
+	removed, err := Prune("", utility.GetUnixTime(time.Now().Add(time.Hour).Unix()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if removed != 2 {
+		t.Fatalf("expected 2 removed, got %d", removed)
+	}
+	entries, _ = Search(&Query{})
+	if len(entries) != 1 || !entries[0].Pinned || entries[0].Sql != "select * from orders" {
+		t.Fatalf("unexpected entries after prune %+v", entries)
+	}
+}
+
+func TestTrim(t *testing.T) {
+	var data []map[string]interface{}
+	data = append(data, map[string]interface{}{"_id": "pinned", "pinned": true})
+	for i := 0; i < maxEntries+trimMargin; i++ {
+		data = append(data, map[string]interface{}{"_id": fmt.Sprint(i), "pinned": false})
+	}
+	if trimmed := trim(data); len(trimmed) != len(data) {
+		t.Fatalf("entries within the margin should be kept, got %d", len(trimmed))
+	}
+
+	data = append(data, map[string]interface{}{"_id": "newest", "pinned": false})
+	trimmed := trim(data)
+	if len(trimmed) != maxEntries+1 {
+		t.Fatalf("expected %d entries, got %d", maxEntries+1, len(trimmed))
+	}
+	if trimmed[0]["_id"] != "pinned" || trimmed[1]["_id"] != fmt.Sprint(trimMargin+1) || trimmed[len(trimmed)-1]["_id"] != "newest" {
+		t.Fatalf("the oldest unpinned entries should be removed, got %v ... %v", trimmed[:2], trimmed[len(trimmed)-1])
+	}
+}
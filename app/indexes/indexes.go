@@ -0,0 +1,353 @@
+package indexes
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/samber/lo"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"tinydb/app/db"
+	"tinydb/app/db/adapter/mongo"
+	"tinydb/app/db/adapter/mysql"
+)
+
+// mongoIndexOptions 创建索引时允许出现在 spec 中的字段
+var mongoIndexOptions = []string{
+	"key", "name", "unique", "sparse", "hidden", "expireAfterSeconds", "partialFilterExpression",
+	"collation", "weights", "default_language", "language_override", "textIndexVersion",
+	"2dsphereIndexVersion", "bits", "min", "max", "wildcardProjection",
+}
+
+var mongoIndexTypes = []string{"text", "2dsphere", "2d", "hashed"}
+
+type IndexKey struct {
+	Field string      `json:"field"`
+	Value interface{} `json:"value"`
+}
+
+// Index is a Mongo index with its size and $indexStats usage.
+type Index struct {
+	Name               string      `json:"name"`
+	Keys               []*IndexKey `json:"keys"`
+	Unique             bool        `json:"unique"`
+	Sparse             bool        `json:"sparse"`
+	Hidden             bool        `json:"hidden"`
+	ExpireAfterSeconds *int64      `json:"expireAfterSeconds,omitempty"`
+	// Options 其余选项原样返回，例如 partialFilterExpression、collation、weights
+	Options  map[string]interface{} `json:"options"`
+	Size     int64                  `json:"size"`
+	Accesses int64                  `json:"accesses"`
+	Since    *time.Time             `json:"since,omitempty"`
+}
+
+type CreateOptions struct {
+	// Spec Mongo 索引定义的 Extended JSON 文本，与 createIndexes 命令中的单个索引相同
+	Spec string `json:"spec"`
+	// Index 与 Unique 用于 MySQL，Index 为分析器生成的 indexes/uniques 结构
+	Index  map[string]interface{} `json:"index"`
+	Unique bool                   `json:"unique"`
+	// Preview 只返回将要执行的语句
+	Preview bool `json:"preview"`
+}
+
+// List returns the indexes of a Mongo collection, largest first.
+func List(ctx context.Context, session db.Session, database, collection string) ([]*Index, error) {
+	s, ok := session.(*mongo.Source)
+	if !ok {
+		return nil, fmt.Errorf("list indexes on %s: %w", session.Dialect(), db.ErrNotSupportedByAdapter)
+	}
+	specs, err := s.ListIndexes(ctx, database, collection)
+	if err != nil {
+		return nil, err
+	}
+	// 统计信息需要额外权限，读取失败时仍然返回索引列表
+	sizes, _ := s.IndexSizes(ctx, database, collection)
+	stats, _ := s.IndexStats(ctx, database, collection)
+
+	result := make([]*Index, 0, len(specs))
+	for _, spec := range specs {
+		index, err := toIndex(spec)
+		if err != nil {
+			return nil, err
+		}
+		index.Size = sizes[index.Name]
+		for _, stat := range stats {
+			if stat["name"] != index.Name {
+				continue
+			}
+			if accesses, ok := stat["accesses"].(bson.M); ok {
+				index.Accesses = toInt64(accesses["ops"])
+				if since, ok := accesses["since"].(primitive.DateTime); ok {
+					t := since.Time()
+					index.Since = &t
+				}
+			}
+		}
+		result = append(result, index)
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].Size > result[j].Size
+	})
+	return result, nil
+}
+
+func toIndex(raw bson.Raw) (*Index, error) {
+	var spec bson.M
+	if err := bson.Unmarshal(raw, &spec); err != nil {
+		return nil, err
+	}
+	index := &Index{Name: fmt.Sprint(spec["name"]), Keys: make([]*IndexKey, 0), Options: make(map[string]interface{})}
+	var keys bson.D
+	if doc, ok := raw.Lookup("key").DocumentOK(); ok {
+		if err := bson.Unmarshal(doc, &keys); err != nil {
+			return nil, err
+		}
+	}
+	for _, key := range keys {
+		index.Keys = append(index.Keys, &IndexKey{Field: key.Key, Value: key.Value})
+	}
+	index.Unique, _ = spec["unique"].(bool)
+	index.Sparse, _ = spec["sparse"].(bool)
+	index.Hidden, _ = spec["hidden"].(bool)
+	if expire, ok := spec["expireAfterSeconds"]; ok {
+		seconds := toInt64(expire)
+		index.ExpireAfterSeconds = &seconds
+	}
+	for key, value := range spec {
+		switch key {
+		case "name", "key", "unique", "sparse", "hidden", "expireAfterSeconds", "v", "ns":
+		default:
+			index.Options[key] = value
+		}
+	}
+	return index, nil
+}
+
+// ParseMongoSpec validates an index specification and fills in the default
+// name, the key order of the Extended JSON text is preserved.
+func ParseMongoSpec(spec string) (bson.D, error) {
+	var doc bson.D
+	if err := bson.UnmarshalExtJSON([]byte(spec), false, &doc); err != nil {
+		return nil, fmt.Errorf("invalid index spec: %w", err)
+	}
+
+	var keys bson.D
+	hasName := false
+	for _, element := range doc {
+		if !lo.Contains(mongoIndexOptions, element.Key) {
+			return nil, fmt.Errorf("unsupported index option '%s'", element.Key)
+		}
+		switch element.Key {
+		case "key":
+			keys, _ = element.Value.(bson.D)
+		case "name":
+			name, ok := element.Value.(string)
+			if !ok || strings.TrimSpace(name) == "" {
+				return nil, fmt.Errorf("index name must be a non empty string")
+			}
+			hasName = true
+		}
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("index spec requires a non empty key document")
+	}
+	for _, key := range keys {
+		switch v := key.Value.(type) {
+		case int32, int64, float64:
+			if toInt64(v) == 0 {
+				return nil, fmt.Errorf("index direction of '%s' must be 1 or -1", key.Key)
+			}
+		case string:
+			if !lo.Contains(mongoIndexTypes, v) {
+				return nil, fmt.Errorf("unsupported index type '%s' on '%s'", v, key.Key)
+			}
+		default:
+			return nil, fmt.Errorf("invalid index value for '%s'", key.Key)
+		}
+	}
+	if !hasName {
+		doc = append(doc, bson.E{Key: "name", Value: MongoIndexName(keys)})
+	}
+	return doc, nil
+}
+
+// MongoIndexName follows the server naming convention, for example a_1_b_-1.
+func MongoIndexName(keys bson.D) string {
+	parts := make([]string, 0, len(keys)*2)
+	for _, key := range keys {
+		parts = append(parts, key.Key, fmt.Sprint(key.Value))
+	}
+	return strings.Join(parts, "_")
+}
+
+// Create creates an index and returns the executed statement.
+func Create(ctx context.Context, session db.Session, database, table string, opt *CreateOptions) (string, error) {
+	switch s := session.(type) {
+	case *mongo.Source:
+		spec, err := ParseMongoSpec(opt.Spec)
+		if err != nil {
+			return "", err
+		}
+		statement := mongoStatement(table, "createIndex", spec)
+		if opt.Preview {
+			return statement, nil
+		}
+		return statement, s.CreateIndexes(ctx, database, table, []bson.D{spec})
+	case *mysql.Source:
+		statement, err := MysqlCreateIndexSql(table, opt.Index, opt.Unique)
+		if err != nil || opt.Preview {
+			return statement, err
+		}
+		return statement, execMysql(ctx, s, statement)
+	default:
+		return "", fmt.Errorf("create index on %s: %w", session.Dialect(), db.ErrNotSupportedByAdapter)
+	}
+}
+
+// Drop removes an index and returns the executed statement.
+func Drop(ctx context.Context, session db.Session, database, table, name string, preview bool) (string, error) {
+	if name == "" {
+		return "", fmt.Errorf("index name is required")
+	}
+	switch s := session.(type) {
+	case *mongo.Source:
+		if name == "_id_" {
+			return "", fmt.Errorf("the _id index cannot be dropped")
+		}
+		statement := mongoStatement(table, "dropIndex", name)
+		if preview {
+			return statement, nil
+		}
+		return statement, s.DropIndex(ctx, database, table, name)
+	case *mysql.Source:
+		statement := MysqlDropIndexSql(table, name)
+		if preview {
+			return statement, nil
+		}
+		return statement, execMysql(ctx, s, statement)
+	default:
+		return "", fmt.Errorf("drop index on %s: %w", session.Dialect(), db.ErrNotSupportedByAdapter)
+	}
+}
+
+// Hide hides or unhides a Mongo index from the query planner.
+func Hide(ctx context.Context, session db.Session, database, collection, name string, hidden bool) error {
+	s, ok := session.(*mongo.Source)
+	if !ok {
+		return fmt.Errorf("hide index on %s: %w", session.Dialect(), db.ErrNotSupportedByAdapter)
+	}
+	return s.HideIndex(ctx, database, collection, name, hidden)
+}
+
+// HideStatement is the shell form of Hide, shown when the change needs confirmation.
+func HideStatement(collection, name string, hidden bool) string {
+	if hidden {
+		return mongoStatement(collection, "hideIndex", name)
+	}
+	return mongoStatement(collection, "unhideIndex", name)
+}
+
+func mongoStatement(collection, method string, argument interface{}) string {
+	text := fmt.Sprintf("%q", argument)
+	if doc, ok := argument.(bson.D); ok {
+		if marshal, err := bson.MarshalExtJSON(doc, false, false); err == nil {
+			text = string(marshal)
+		}
+	}
+	return fmt.Sprintf("db.getCollection(%q).%s(%s)", collection, method, text)
+}
+
+// MysqlCreateIndexSql builds CREATE INDEX from the indexes/uniques shape the
+// analyser produces: constraintName, indexType and columns[].columnName.
+func MysqlCreateIndexSql(table string, index map[string]interface{}, unique bool) (string, error) {
+	if table == "" {
+		return "", fmt.Errorf("table is required")
+	}
+	name, _ := index["constraintName"].(string)
+	if strings.TrimSpace(name) == "" {
+		return "", fmt.Errorf("index name is required")
+	}
+	columns, _ := index["columns"].([]interface{})
+	if len(columns) == 0 {
+		return "", fmt.Errorf("index requires at least one column")
+	}
+	parts := make([]string, 0, len(columns))
+	for _, item := range columns {
+		column, _ := item.(map[string]interface{})
+		columnName, _ := column["columnName"].(string)
+		if columnName == "" {
+			return "", fmt.Errorf("index column name is required")
+		}
+		part := quoteIdentifier(columnName)
+		if length := toInt64(column["length"]); length > 0 {
+			part += fmt.Sprintf("(%d)", length)
+		}
+		if descending, _ := column["isDescending"].(bool); descending {
+			part += " DESC"
+		}
+		parts = append(parts, part)
+	}
+
+	if isUnique, ok := index["isUnique"].(bool); ok && isUnique {
+		unique = true
+	}
+	kind := ""
+	using := ""
+	indexType, _ := index["indexType"].(string)
+	switch indexType = strings.ToUpper(indexType); indexType {
+	case "FULLTEXT", "SPATIAL":
+		kind = indexType + " "
+	case "BTREE", "HASH":
+		using = " USING " + indexType
+	case "":
+	default:
+		return "", fmt.Errorf("unsupported index type '%s'", indexType)
+	}
+	if unique {
+		if kind != "" {
+			return "", fmt.Errorf("%sindexes cannot be unique", strings.ToLower(kind))
+		}
+		kind = "UNIQUE "
+	}
+	return fmt.Sprintf("CREATE %sINDEX %s ON %s (%s)%s", kind, quoteIdentifier(name), quoteIdentifier(table),
+		strings.Join(parts, ", "), using), nil
+}
+
+func MysqlDropIndexSql(table, name string) string {
+	if strings.EqualFold(name, "PRIMARY") {
+		return fmt.Sprintf("ALTER TABLE %s DROP PRIMARY KEY", quoteIdentifier(table))
+	}
+	return fmt.Sprintf("DROP INDEX %s ON %s", quoteIdentifier(name), quoteIdentifier(table))
+}
+
+func execMysql(ctx context.Context, s *mysql.Source, statement string) error {
+	conn, err := s.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	_, err = conn.ExecContext(ctx, statement)
+	return err
+}
+
+func quoteIdentifier(name string) string {
+	return "`" + strings.ReplaceAll(name, "`", "``") + "`"
+}
+
+func toInt64(value interface{}) int64 {
+	switch v := value.(type) {
+	case int:
+		return int64(v)
+	case int32:
+		return int64(v)
+	case int64:
+		return v
+	case float64:
+		return int64(v)
+	}
+	return 0
+}
@@ -0,0 +1,95 @@
+package indexes
+
+import (
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestParseMongoSpec(t *testing.T) {
+	spec, err := ParseMongoSpec(`{"key": {"b": 1, "a": -1}, "unique": true, "partialFilterExpression": {"a": {"$gt": 5}}}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys, _ := spec[0].Value.(bson.D)
+	if len(keys) != 2 || keys[0].Key != "b" || keys[1].Key != "a" {
+		t.Fatalf("key order should be preserved %v", keys)
+	}
+	last := spec[len(spec)-1]
+	if last.Key != "name" || last.Value != "b_1_a_-1" {
+		t.Fatalf("unexpected default name %v", last)
+	}
+
+	ttl, err := ParseMongoSpec(`{"key": {"createdAt": 1}, "name": "ttl", "expireAfterSeconds": 3600}`)
+	if err != nil || len(ttl) != 3 {
+		t.Fatalf("unexpected ttl spec %v %v", ttl, err)
+	}
+	if _, err = ParseMongoSpec(`{"key": {"body": "text", "loc": "2dsphere"}}`); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestParseMongoSpecRejectsInvalidInput(t *testing.T) {
+	cases := []string{
+		`{"key": {}}`,
+		`{"unique": true}`,
+		`{"key": {"a": 0}}`,
+		`{"key": {"a": "btree"}}`,
+		`{"key": {"a": 1}, "background": true, "dropDups": true, "$where": 1}`,
+		`{"key": {"a": 1}, "name": ""}`,
+		`not json`,
+	}
+	for i, spec := range cases {
+		if _, err := ParseMongoSpec(spec); err == nil {
+			t.Fatalf("case %d should fail", i)
+		}
+	}
+}
+
+func TestMysqlCreateIndexSql(t *testing.T) {
+	index := map[string]interface{}{
+		"constraintName": "idx_name",
+		"indexType":      "BTREE",
+		"columns": []interface{}{
+			map[string]interface{}{"columnName": "last`name", "length": float64(10)},
+			map[string]interface{}{"columnName": "created", "isDescending": true},
+		},
+	}
+	statement, err := MysqlCreateIndexSql("users", index, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if statement != "CREATE UNIQUE INDEX `idx_name` ON `users` (`last``name`(10), `created` DESC) USING BTREE" {
+		t.Fatalf("unexpected statement %q", statement)
+	}
+
+	index["indexType"] = "FULLTEXT"
+	statement, err = MysqlCreateIndexSql("users", index, false)
+	if err != nil || statement != "CREATE FULLTEXT INDEX `idx_name` ON `users` (`last``name`(10), `created` DESC)" {
+		t.Fatalf("unexpected statement %q %v", statement, err)
+	}
+	if _, err = MysqlCreateIndexSql("users", index, true); err == nil {
+		t.Fatal("fulltext indexes cannot be unique")
+	}
+	if _, err = MysqlCreateIndexSql("users", map[string]interface{}{"constraintName": "x"}, false); err == nil {
+		t.Fatal("an index without columns should fail")
+	}
+}
+
+func TestMysqlDropIndexSql(t *testing.T) {
+	if s := MysqlDropIndexSql("users", "PRIMARY"); s != "ALTER TABLE `users` DROP PRIMARY KEY" {
+		t.Fatalf("unexpected statement %q", s)
+	}
+	if s := MysqlDropIndexSql("users", "idx_name"); s != "DROP INDEX `idx_name` ON `users`" {
+		t.Fatalf("unexpected statement %q", s)
+	}
+}
+
+func TestHideStatement(t *testing.T) {
+	if statement := HideStatement("users", "name_1", true); statement != `db.getCollection("users").hideIndex("name_1")` {
+		t.Fatalf("unexpected statement %s", statement)
+	}
+	if statement := HideStatement("users", "name_1", false); statement != `db.getCollection("users").unhideIndex("name_1")` {
+		t.Fatalf("unexpected statement %s", statement)
+	}
+}
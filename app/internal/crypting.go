@@ -1,16 +1,27 @@
 package internal
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"github.com/samber/lo"
-	"os"
-	"path/filepath"
 	"reflect"
 	"strings"
+	"sync"
 	"tinydb/app/pkg/logger"
 	"tinydb/app/utility"
 )
 
+// 加密字段的前缀，带版本号的为 AES-GCM，旧格式只用于解密
+const (
+	cryptPrefix        = "crypt2:"
+	legacyCryptPrefix  = "crypt:"
+	sharedPrefix       = "shared2:"
+	legacySharedPrefix = "shared:"
+)
+
 const (
 	defaultEncryptionKey = "mQAUaXhavRGJDxDTXSCg7Ej0xMmGCrx6OKA07DIMBiDcYYkvkaXjTAzPUEHEHEf9"
 	encryptionKeyKey     = "encryptionKey"
@@ -18,70 +29,103 @@ const (
 
 var _encryptionKey string
 
+// passwordFields 连接中加密保存的字段
+var passwordFields = []string{"password", "sshPassword", "sshKeyfilePassword"}
+
+var ErrWrongPassphrase = errors.New("wrong passphrase or damaged connection file")
+
+var keyMu sync.Mutex
+
 func loadEncryptionKey() string {
 	if _encryptionKey != "" {
 		return _encryptionKey
 	}
-	defaultFile := utility.DataDirCore()
-	keyFile := filepath.Join(defaultFile, ".key")
-	encryptor := createEncryptor(defaultEncryptionKey)
-	if !utility.IsExist(keyFile) {
-		if err := os.MkdirAll(filepath.Dir(keyFile), os.ModePerm); err != nil {
-			logger.Fatalf("os.MkdirAll failed err: %v", err)
-			return ""
-		}
-
-		generatedKey := randomBytes(32)
-		newKey := string(generatedKey)
-		result := map[string]string{
-			encryptionKeyKey: newKey,
-		}
-		encrypt := encryptor.encrypt(result)
-
-		if err := os.WriteFile(keyFile, []byte(encrypt), os.ModePerm); err != nil {
-			logger.Fatalf("os.WriteFile failed err: %v", err)
-			return ""
-		}
-	}
-
-	encryptedData, err := os.ReadFile(keyFile)
+	key, err := loadOrCreateKey(CurrentKeyStore())
 	if err != nil {
-		logger.Errorf("os.ReadFile failed err: %v", err)
-		return ""
-	}
-
-	decrypt := encryptor.decrypt(string(encryptedData))
-	if decrypt == "" {
+		if !errors.Is(err, ErrKeyStoreLocked) {
+			logger.Errorf("load encryption key failed err: %v", err)
+		}
 		return ""
 	}
+	_encryptionKey = key
+	return _encryptionKey
+}
 
-	data := map[string]string{}
-	err = json.Unmarshal([]byte(decrypt), &data)
-	if err != nil {
-		logger.Errorf("json.Unmarshal failed err: %v", err)
-		return ""
+func loadOrCreateKey(store KeyStore) (string, error) {
+	key, err := store.Load()
+	if errors.Is(err, ErrKeyNotFound) {
+		if key, err = newEncryptionKey(); err == nil {
+			err = store.Save(key)
+		}
 	}
-	_encryptionKey = data[encryptionKeyKey]
-	return _encryptionKey
+	return key, err
 }
 
 var _encryptor *SimpleEncryptor
 
+// getEncryptor 主密码未解锁时返回 nil
 func getEncryptor() *SimpleEncryptor {
+	keyMu.Lock()
+	defer keyMu.Unlock()
 	if _encryptor != nil {
 		return _encryptor
 	}
-
-	_encryptor = createEncryptor(loadEncryptionKey())
+	key := loadEncryptionKey()
+	if key == "" {
+		return nil
+	}
+	_encryptor = createEncryptor(key)
 	return _encryptor
 }
 
+// KeyStoreReady 返回保存的密码当前是否可以加解密
+func KeyStoreReady() error {
+	if getEncryptor() != nil {
+		return nil
+	}
+	keyMu.Lock()
+	defer keyMu.Unlock()
+	if store, ok := CurrentKeyStore().(*PasswordKeyStore); ok && store.Locked() {
+		return ErrKeyStoreLocked
+	}
+	return errors.New("the encryption key is not available")
+}
+
+func isEncrypted(value string) bool {
+	return strings.HasPrefix(value, cryptPrefix) || strings.HasPrefix(value, legacyCryptPrefix)
+}
+
+// decryptValue 解密 crypt2: 和旧的 crypt: 字段
+func decryptValue(encryptor *SimpleEncryptor, value string) (string, error) {
+	return openValue(encryptor, value, cryptPrefix, legacyCryptPrefix)
+}
+
+func openValue(encryptor *SimpleEncryptor, value, prefix, legacyPrefix string) (string, error) {
+	switch {
+	case strings.HasPrefix(value, prefix):
+		return encryptor.open(strings.TrimPrefix(value, prefix))
+	case strings.HasPrefix(value, legacyPrefix):
+		// 旧格式加密的是 JSON 字符串
+		var plain string
+		if err := json.Unmarshal([]byte(encryptor.decrypt(strings.TrimPrefix(value, legacyPrefix))), &plain); err != nil {
+			return "", errors.New("cannot decrypt value")
+		}
+		return plain, nil
+	}
+	return "", errors.New("value is not encrypted")
+}
+
 func encryptPasswordField(connection map[string]string, field string) map[string]string {
 	if connection != nil &&
 		connection[field] != "" &&
-		!strings.HasPrefix(connection[field], "crypt:") &&
+		!isEncrypted(connection[field]) &&
 		connection["passwordMode"] != "saveRaw" {
-		connection[field] = "crypt:" + getEncryptor().encrypt(connection[field])
+		// 无法加密时不保存明文密码
+		if encryptor := getEncryptor(); encryptor != nil {
+			connection[field] = cryptPrefix + encryptor.seal(connection[field])
+		} else {
+			delete(connection, field)
+		}
 	}
 	return connection
 }
@@ -89,10 +133,9 @@ func encryptPasswordField(connection map[string]string, field string) map[string
 func decryptPasswordField(connection map[string]interface{}, field string) map[string]interface{} {
 	if connection != nil && connection[field] != nil && reflect.ValueOf(connection[field]).Kind() == reflect.String {
 		value := connection[field].(string)
-		if field != "" && strings.HasPrefix(value, "crypt:") {
-			decrypt := getEncryptor().decrypt(strings.Split(value, "crypt:")[1])
-			if decrypt != "" && len(decrypt) > 1 {
-				connection[field] = strings.Trim(decrypt[1:len(decrypt)-1], "")
+		if encryptor := getEncryptor(); encryptor != nil && isEncrypted(value) {
+			if plain, err := decryptValue(encryptor, value); err == nil {
+				connection[field] = plain
 			}
 		}
 	}
@@ -101,9 +144,9 @@ func decryptPasswordField(connection map[string]interface{}, field string) map[s
 }
 
 func EncryptConnection(connection map[string]string) map[string]string {
-	connection = encryptPasswordField(connection, "password")
-	connection = encryptPasswordField(connection, "sshPassword")
-	connection = encryptPasswordField(connection, "sshKeyfilePassword")
+	for _, field := range passwordFields {
+		connection = encryptPasswordField(connection, field)
+	}
 	return connection
 }
 
@@ -112,16 +155,32 @@ func MaskConnection(connection map[string]string) map[string]string {
 		return connection
 	}
 
-	return utility.MapOmit(connection, []string{"password", "sshPassword", "sshKeyfilePassword"})
+	return utility.MapOmit(connection, passwordFields)
 }
 
 func DecryptConnection(connection map[string]interface{}) map[string]interface{} {
-	connection = decryptPasswordField(connection, "password")
-	connection = decryptPasswordField(connection, "sshPassword")
-	connection = decryptPasswordField(connection, "sshKeyfilePassword")
+	for _, field := range passwordFields {
+		connection = decryptPasswordField(connection, field)
+	}
 	return connection
 }
 
+// CheckDecrypted 在打开连接前确认密码字段都已解密，主密码未解锁时返回
+// ErrKeyStoreLocked，不能把密文当作密码发送给服务器
+func CheckDecrypted(connection map[string]interface{}) error {
+	for _, field := range passwordFields {
+		value, ok := connection[field].(string)
+		if !ok || !isEncrypted(value) {
+			continue
+		}
+		if err := KeyStoreReady(); err != nil {
+			return err
+		}
+		return fmt.Errorf("cannot decrypt %s of the connection", field)
+	}
+	return nil
+}
+
 func pickSafeConnectionInfo(connection map[string]interface{}) map[string]interface{} {
 	return lo.MapValues(connection, func(v interface{}, k string) interface{} {
 		if k == "engine" || k == "port" || k == "authType" || k == "sshMode" || k == "passwordMode" {
@@ -133,3 +192,76 @@ func pickSafeConnectionInfo(connection map[string]interface{}) map[string]interf
 		return nil
 	})
 }
+
+// ShareKey encrypts the passwords of exported connections. It is derived
+// from the passphrase with salted argon2id, the parameters are saved in the
+// header of the exported file.
+type ShareKey struct {
+	Params    *KdfParams
+	encryptor *SimpleEncryptor
+}
+
+// NewShareKey derives a key for a new export with a random salt.
+func NewShareKey(passphrase string) (*ShareKey, error) {
+	params, err := newKdfParams()
+	if err != nil {
+		return nil, err
+	}
+	return OpenShareKey(passphrase, &params)
+}
+
+// OpenShareKey derives the key of an exported file from its header.
+func OpenShareKey(passphrase string, params *KdfParams) (*ShareKey, error) {
+	if params == nil {
+		return nil, errors.New("the connection file has no key derivation parameters")
+	}
+	if err := params.validate(); err != nil {
+		return nil, err
+	}
+	return &ShareKey{Params: params, encryptor: createEncryptor(hex.EncodeToString(params.derive(passphrase)))}, nil
+}
+
+// LegacyShareKey 旧版本导出文件的密钥直接取口令的 sha256，只用于导入
+func LegacyShareKey(passphrase string) *ShareKey {
+	return &ShareKey{encryptor: passphraseEncryptor(passphrase)}
+}
+
+func passphraseEncryptor(passphrase string) *SimpleEncryptor {
+	return createEncryptor(fmt.Sprintf("%x", sha256.Sum256([]byte(passphrase))))
+}
+
+// ShareConnection prepares a saved connection for export. The local secrets are
+// decrypted and then removed, or re-encrypted with key when it is set.
+func ShareConnection(connection map[string]interface{}, key *ShareKey) map[string]interface{} {
+	shared := DecryptConnection(lo.Assign(connection))
+	for _, field := range passwordFields {
+		value, ok := shared[field].(string)
+		if !ok || value == "" || key == nil {
+			delete(shared, field)
+			continue
+		}
+		shared[field] = sharedPrefix + key.encryptor.seal(value)
+	}
+	return shared
+}
+
+// UnshareConnection decrypts the secrets of an exported connection, the
+// result still has to be encrypted with EncryptConnection before saving.
+func UnshareConnection(connection map[string]interface{}, key *ShareKey) (map[string]interface{}, error) {
+	plain := lo.Assign(connection)
+	for _, field := range passwordFields {
+		value, ok := plain[field].(string)
+		if !ok || !strings.HasPrefix(value, sharedPrefix) && !strings.HasPrefix(value, legacySharedPrefix) {
+			continue
+		}
+		if key == nil {
+			return nil, ErrWrongPassphrase
+		}
+		decrypted, err := openValue(key.encryptor, value, sharedPrefix, legacySharedPrefix)
+		if err != nil {
+			return nil, ErrWrongPassphrase
+		}
+		plain[field] = decrypted
+	}
+	return plain, nil
+}
@@ -2,10 +2,12 @@ package internal
 
 import (
 	"bufio"
+	"errors"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"tinydb/app/utility"
 )
@@ -82,3 +84,129 @@ func Test_Read2(t *testing.T) {
 		fmt.Println(string(data))
 	}
 }
+
+func TestCrypt2RoundTrip(t *testing.T) {
+	encryptor := createEncryptor("0123456789abcdef0")
+	first, second := encryptor.seal("p@ss\"word"), encryptor.seal("p@ss\"word")
+	if first == second {
+		t.Fatalf("the same password should get a different nonce")
+	}
+	if plain, err := decryptValue(encryptor, cryptPrefix+first); err != nil || plain != "p@ss\"word" {
+		t.Fatalf("unexpected password %q %v", plain, err)
+	}
+	if _, err := decryptValue(createEncryptor("fedcba98765432100"), cryptPrefix+first); err == nil {
+		t.Fatalf("another key should not decrypt the password")
+	}
+	tampered := []byte(first)
+	tampered[len(tampered)-1] ^= 1
+	if _, err := decryptValue(encryptor, cryptPrefix+string(tampered)); err == nil {
+		t.Fatalf("a modified ciphertext should be rejected")
+	}
+	if _, err := decryptValue(encryptor, cryptPrefix+"AA"); err == nil {
+		t.Fatalf("a short ciphertext should be rejected")
+	}
+}
+
+func TestCheckDecrypted(t *testing.T) {
+	savedEncryptor, savedKey, savedStore := _encryptor, _encryptionKey, activeKeyStore
+	defer func() { _encryptor, _encryptionKey, activeKeyStore = savedEncryptor, savedKey, savedStore }()
+	sealed := cryptPrefix + createEncryptor("fedcba98765432100").seal("secret")
+
+	if err := CheckDecrypted(map[string]interface{}{"password": "plain", "sshPassword": nil}); err != nil {
+		t.Fatalf("plain passwords should pass, got %v", err)
+	}
+	_encryptor = createEncryptor("0123456789abcdef0")
+	if err := CheckDecrypted(DecryptConnection(map[string]interface{}{"password": sealed})); err == nil {
+		t.Fatalf("a password encrypted with another key should be rejected")
+	}
+	_encryptor, _encryptionKey, activeKeyStore = nil, "", &PasswordKeyStore{Path: filepath.Join(t.TempDir(), ".key.password")}
+	if err := CheckDecrypted(DecryptConnection(map[string]interface{}{"password": sealed})); !errors.Is(err, ErrKeyStoreLocked) {
+		t.Fatalf("expected ErrKeyStoreLocked, got %v", err)
+	}
+}
+
+func TestDecryptLegacyCrypt(t *testing.T) {
+	encryptor := createEncryptor("0123456789abcdef0")
+	for _, password := range []string{"secret", `quo"te\back`, "密码"} {
+		legacy := legacyCryptPrefix + encryptor.encrypt(password)
+		if plain, err := decryptValue(encryptor, legacy); err != nil || plain != password {
+			t.Fatalf("unexpected password %q %v, want %q", plain, err, password)
+		}
+	}
+	if _, err := decryptValue(encryptor, "plain"); err == nil {
+		t.Fatalf("a plain value should not be decrypted")
+	}
+}
+
+func TestUnshareLegacyConnection(t *testing.T) {
+	connection := map[string]interface{}{"host": "db", "password": legacySharedPrefix + passphraseEncryptor("pass phrase").encrypt("secret")}
+	unshared, err := UnshareConnection(connection, LegacyShareKey("pass phrase"))
+	if err != nil || unshared["password"] != "secret" {
+		t.Fatalf("unexpected connection %v %v", unshared, err)
+	}
+}
+
+func TestShareKey(t *testing.T) {
+	key, err := NewShareKey("pass phrase")
+	if err != nil {
+		t.Fatalf("derive failed: %v", err)
+	}
+	again, err := NewShareKey("pass phrase")
+	if err != nil || string(again.Params.Salt) == string(key.Params.Salt) {
+		t.Fatalf("every export should get a new salt")
+	}
+	shared := ShareConnection(map[string]interface{}{"host": "db", "password": "secret"}, key)
+	reopened, err := OpenShareKey("pass phrase", key.Params)
+	if err != nil {
+		t.Fatalf("open failed: %v", err)
+	}
+	if unshared, err := UnshareConnection(shared, reopened); err != nil || unshared["password"] != "secret" {
+		t.Fatalf("unexpected connection %v %v", unshared, err)
+	}
+	if _, err := UnshareConnection(shared, LegacyShareKey("pass phrase")); !errors.Is(err, ErrWrongPassphrase) {
+		t.Fatalf("the unsalted key should not decrypt, got %v", err)
+	}
+	huge := *key.Params
+	huge.Memory = 1 << 30
+	if _, err := OpenShareKey("pass phrase", &huge); err == nil {
+		t.Fatalf("excessive parameters should be rejected")
+	}
+}
+
+func TestUpgradeConnections(t *testing.T) {
+	saved := _encryptor
+	defer func() { _encryptor = saved }()
+	_encryptor = createEncryptor("0123456789abcdef0")
+
+	db := utility.NewJsonLinesDatabase(filepath.Join(t.TempDir(), "connections.jsonl"))
+	legacy, _ := db.Insert(map[string]interface{}{"host": "a", "password": legacyCryptPrefix + _encryptor.encrypt("secret"), "sshPassword": "plain"})
+	current := cryptPrefix + _encryptor.seal("other")
+	if _, err := db.Insert(map[string]interface{}{"host": "b", "password": current}); err != nil {
+		t.Fatalf("insert failed: %v", err)
+	}
+
+	count, err := UpgradeConnections(db)
+	if err != nil || count != 1 {
+		t.Fatalf("expected one upgraded connection, got %d %v", count, err)
+	}
+	reloaded := db.Get(legacy["_id"].(string))
+	password := reloaded["password"].(string)
+	if !strings.HasPrefix(password, cryptPrefix) {
+		t.Fatalf("the password should use %s: %s", cryptPrefix, password)
+	}
+	if plain, err := decryptValue(_encryptor, password); err != nil || plain != "secret" {
+		t.Fatalf("unexpected password %q %v", plain, err)
+	}
+	if reloaded["sshPassword"] != "plain" {
+		t.Fatalf("plain fields should be kept: %v", reloaded)
+	}
+	if count, err := UpgradeConnections(db); err != nil || count != 0 {
+		t.Fatalf("a second upgrade should do nothing, got %d %v", count, err)
+	}
+}
+
+func TestRandomBytes(t *testing.T) {
+	if a, b := randomBytes(16), randomBytes(16); len(a) != 16 || string(a) == string(b) {
+		t.Fatalf("unexpected random bytes %x %x", a, b)
+	}
+}
@@ -27,6 +27,11 @@ func GetCore(conid string, mask bool) map[string]interface{} {
 	return JsonLinesDatabase.Get(conid)
 }
 
+// IsReadOnly 连接设置了 isReadOnly 时后端拒绝一切写入
+func IsReadOnly(connection map[string]interface{}) bool {
+	return utility.IsTruthy(connection["isReadOnly"])
+}
+
 func CreateEngineDriver(connection map[string]interface{}) (driver db.Session, err error) {
 	logger.Infof("%s", utility.ToJsonStr(connection))
 	utility.WithRecover(func() {
@@ -0,0 +1,243 @@
+package internal
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"tinydb/app/pkg/logger"
+	"tinydb/app/utility"
+)
+
+const keyStoreModeFile = "keystore.json"
+
+var activeKeyStore KeyStore
+
+type KeyStoreStatus struct {
+	Mode             string `json:"mode"`
+	Locked           bool   `json:"locked"`
+	KeyringAvailable bool   `json:"keyringAvailable"`
+}
+
+func keyStoreFor(dir, mode string) (KeyStore, error) {
+	switch mode {
+	case "", KeyStoreFile:
+		return &FileKeyStore{Path: filepath.Join(dir, ".key")}, nil
+	case KeyStorePassword:
+		return &PasswordKeyStore{Path: filepath.Join(dir, ".key.password")}, nil
+	case KeyStoreKeyring:
+		return &KeyringKeyStore{Keyring: SystemKeyring{}}, nil
+	}
+	return nil, fmt.Errorf("%w '%s'", ErrUnknownKeyStore, mode)
+}
+
+// CurrentKeyStore 由数据目录下的 keystore.json 决定，没有时使用原有的文件方式
+func CurrentKeyStore() KeyStore {
+	if activeKeyStore != nil {
+		return activeKeyStore
+	}
+	dir := utility.DataDirCore()
+	setting := map[string]string{}
+	if data, err := os.ReadFile(filepath.Join(dir, keyStoreModeFile)); err == nil {
+		if err := json.Unmarshal(data, &setting); err != nil {
+			logger.Errorf("invalid %s err: %v", keyStoreModeFile, err)
+		}
+	}
+	store, err := keyStoreFor(dir, setting["mode"])
+	if err != nil {
+		logger.Errorf("%v, falling back to the key file", err)
+		store, _ = keyStoreFor(dir, KeyStoreFile)
+	}
+	activeKeyStore = store
+	return activeKeyStore
+}
+
+func GetKeyStoreStatus() *KeyStoreStatus {
+	keyMu.Lock()
+	defer keyMu.Unlock()
+	store := CurrentKeyStore()
+	status := &KeyStoreStatus{
+		Mode:             store.Mode(),
+		KeyringAvailable: (&KeyringKeyStore{Keyring: SystemKeyring{}}).Available(),
+	}
+	if passwordStore, ok := store.(*PasswordKeyStore); ok {
+		status.Locked = passwordStore.Locked()
+	}
+	return status
+}
+
+// UnlockKeyStore 输入主密码，本次运行期间不再需要
+func UnlockKeyStore(password string) error {
+	keyMu.Lock()
+	defer keyMu.Unlock()
+	store, ok := CurrentKeyStore().(*PasswordKeyStore)
+	if !ok {
+		return nil
+	}
+	store.Unlock(password)
+	key, err := loadOrCreateKey(store)
+	if err != nil {
+		store.Unlock("")
+		return err
+	}
+	_encryptionKey = key
+	_encryptor = createEncryptor(key)
+	return nil
+}
+
+// SwitchKeyStore moves the encryption key to another store. A new key is
+// generated and every saved password is re-encrypted with it, so a copy of
+// the old key file no longer decrypts anything. Switching to the password
+// store again changes the master password.
+func SwitchKeyStore(mode, password string) error {
+	keyMu.Lock()
+	defer keyMu.Unlock()
+	current := CurrentKeyStore()
+	if current.Mode() == mode && mode != KeyStorePassword {
+		return nil
+	}
+	oldKey := loadEncryptionKey()
+	if oldKey == "" {
+		return ErrKeyStoreLocked
+	}
+
+	dir := utility.DataDirCore()
+	target, err := keyStoreFor(dir, mode)
+	if err != nil {
+		return err
+	}
+	switch store := target.(type) {
+	case *PasswordKeyStore:
+		if len(password) < MinMasterPasswordLength {
+			return fmt.Errorf("the master password needs at least %d characters", MinMasterPasswordLength)
+		}
+		store.Unlock(password)
+	case *KeyringKeyStore:
+		if !store.Available() {
+			return ErrKeyringMissing
+		}
+	}
+
+	db := utility.NewJsonLinesDatabase(filepath.Join(utility.DataDir(), "connections.jsonl"))
+	newKey, err := migrateKey(current, target, oldKey, db, func() error {
+		data, _ := json.Marshal(map[string]string{"mode": target.Mode()})
+		return writeKeyFile(filepath.Join(dir, keyStoreModeFile), data)
+	})
+	if err != nil {
+		return err
+	}
+	activeKeyStore = target
+	_encryptionKey = newKey
+	_encryptor = createEncryptor(newKey)
+	return nil
+}
+
+// migrateKey saves a new key in target, re-encrypts every password field of db
+// in a single rewrite and then calls activate to make target the current store.
+// The old key is deleted last, a failed step restores the records and the key
+// so that the active store always decrypts the saved passwords.
+func migrateKey(from, to KeyStore, oldKey string, db *utility.JsonLinesDatabase, activate func() error) (string, error) {
+	newKey, err := newEncryptionKey()
+	if err != nil {
+		return "", err
+	}
+	oldEncryptor, newEncryptor := createEncryptor(oldKey), createEncryptor(newKey)
+	if err := to.Save(newKey); err != nil {
+		return "", err
+	}
+	if _, err := reencryptDatabase(db, oldEncryptor, newEncryptor, false); err != nil {
+		restoreKey(from, to, oldKey)
+		return "", err
+	}
+	if err := activate(); err != nil {
+		if _, rollbackErr := reencryptDatabase(db, newEncryptor, oldEncryptor, false); rollbackErr != nil {
+			logger.Errorf("restore saved passwords failed err: %v", rollbackErr)
+		}
+		restoreKey(from, to, oldKey)
+		return "", err
+	}
+	if from.Mode() != to.Mode() {
+		if err := from.Delete(); err != nil {
+			logger.Errorf("remove the old encryption key failed err: %v", err)
+		}
+	}
+	return newKey, nil
+}
+
+// restoreKey 迁移失败时撤销 to 中保存的新密钥，同一种方式时 to 与 from 是同一个
+// 位置，用 from 写回旧密钥，主密码也恢复为原来的
+func restoreKey(from, to KeyStore, oldKey string) {
+	var err error
+	if from.Mode() == to.Mode() {
+		err = from.Save(oldKey)
+	} else {
+		err = to.Delete()
+	}
+	if err != nil {
+		logger.Errorf("restore the encryption key failed err: %v", err)
+	}
+}
+
+// reencryptDatabase 在一次写入中重新加密 db 的密码字段，返回改写的记录数
+func reencryptDatabase(db *utility.JsonLinesDatabase, from, to *SimpleEncryptor, legacyOnly bool) (int, error) {
+	count := 0
+	err := db.Rewrite(func(data []map[string]interface{}) ([]map[string]interface{}, error) {
+		records, err := reencryptConnections(data, from, to, legacyOnly)
+		if err != nil || len(records) == 0 {
+			return nil, err
+		}
+		updated := make(map[interface{}]map[string]interface{}, len(records))
+		for _, record := range records {
+			updated[record["_id"]] = record
+		}
+		for i, connection := range data {
+			if record, ok := updated[connection["_id"]]; ok {
+				data[i] = record
+			}
+		}
+		count = len(records)
+		return data, nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// reencryptConnections 返回需要更新的记录，legacyOnly 时只处理旧的 crypt: 字段
+func reencryptConnections(connections []map[string]interface{}, from, to *SimpleEncryptor, legacyOnly bool) ([]map[string]interface{}, error) {
+	records := make([]map[string]interface{}, 0)
+	for _, connection := range connections {
+		var record map[string]interface{}
+		for _, field := range passwordFields {
+			value, ok := connection[field].(string)
+			if !ok || !isEncrypted(value) || legacyOnly && strings.HasPrefix(value, cryptPrefix) {
+				continue
+			}
+			plain, err := decryptValue(from, value)
+			if err != nil {
+				return nil, fmt.Errorf("cannot decrypt %s of connection %v", field, connection["_id"])
+			}
+			if record == nil {
+				record = utility.DeepCopyUnknownMap(connection)
+			}
+			record[field] = cryptPrefix + to.seal(plain)
+		}
+		if record != nil {
+			records = append(records, record)
+		}
+	}
+	return records, nil
+}
+
+// UpgradeConnections re-encrypts the fields still in the legacy crypt: format
+// with AES-GCM and returns how many connections were rewritten.
+func UpgradeConnections(db *utility.JsonLinesDatabase) (int, error) {
+	encryptor := getEncryptor()
+	if encryptor == nil {
+		return 0, KeyStoreReady()
+	}
+	return reencryptDatabase(db, encryptor, encryptor, true)
+}
@@ -0,0 +1,353 @@
+package internal
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/zalando/go-keyring"
+	"golang.org/x/crypto/argon2"
+	"tinydb/app/utility"
+)
+
+const (
+	KeyStoreFile     = "file"
+	KeyStorePassword = "password"
+	KeyStoreKeyring  = "keyring"
+)
+
+const (
+	keyringService = "tinydb"
+	keyringUser    = "encryption-key"
+	// MinMasterPasswordLength 主密码的最小长度
+	MinMasterPasswordLength = 8
+)
+
+var (
+	ErrKeyNotFound     = errors.New("encryption key not found")
+	ErrKeyStoreLocked  = errors.New("the master password is required to unlock saved passwords")
+	ErrWrongPassword   = errors.New("wrong master password")
+	ErrKeyringMissing  = errors.New("no OS keyring is available")
+	ErrUnknownKeyStore = errors.New("unknown key store")
+)
+
+// KeyStore keeps the key the saved passwords are encrypted with.
+type KeyStore interface {
+	Mode() string
+	// Load returns ErrKeyNotFound before the first Save.
+	Load() (string, error)
+	Save(key string) error
+	Delete() error
+}
+
+// FileKeyStore 原有方式：密钥保存在 .key 中，用内置常量加密
+type FileKeyStore struct {
+	Path string
+}
+
+func (f *FileKeyStore) Mode() string {
+	return KeyStoreFile
+}
+
+func (f *FileKeyStore) Load() (string, error) {
+	encrypted, err := os.ReadFile(f.Path)
+	if os.IsNotExist(err) {
+		return "", ErrKeyNotFound
+	}
+	if err != nil {
+		return "", err
+	}
+	decrypted := createEncryptor(defaultEncryptionKey).decrypt(string(encrypted))
+	data := map[string]string{}
+	if err := json.Unmarshal([]byte(decrypted), &data); err != nil || data[encryptionKeyKey] == "" {
+		return "", fmt.Errorf("damaged key file %s", f.Path)
+	}
+	return data[encryptionKeyKey], nil
+}
+
+func (f *FileKeyStore) Save(key string) error {
+	encrypted := createEncryptor(defaultEncryptionKey).encrypt(map[string]string{encryptionKeyKey: key})
+	return writeKeyFile(f.Path, []byte(encrypted))
+}
+
+func (f *FileKeyStore) Delete() error {
+	return removeKeyFile(f.Path)
+}
+
+// PasswordKeyStore 密钥用主密码经 Argon2id 派生的密钥以 AES-GCM 加密保存，
+// 每次启动后需要 Unlock 一次
+type PasswordKeyStore struct {
+	Path     string
+	mu       sync.Mutex
+	password string
+}
+
+// KdfParams argon2id 的参数和盐，与派生出的密文保存在一起
+type KdfParams struct {
+	Kdf     string `json:"kdf"`
+	Time    uint32 `json:"time"`
+	Memory  uint32 `json:"memory"`
+	Threads uint8  `json:"threads"`
+	Salt    []byte `json:"salt"`
+}
+
+func newKdfParams() (KdfParams, error) {
+	params := KdfParams{Kdf: "argon2id", Time: 1, Memory: 64 * 1024, Threads: 4, Salt: make([]byte, 16)}
+	_, err := rand.Read(params.Salt)
+	return params, err
+}
+
+// validate 拒绝来自文件的异常参数，避免导入时占用过多内存或时间
+func (k KdfParams) validate() error {
+	if k.Kdf != "argon2id" || k.Time < 1 || k.Time > 16 || k.Memory < 8*1024 || k.Memory > 1024*1024 ||
+		k.Threads < 1 || len(k.Salt) < 16 {
+		return fmt.Errorf("unsupported key derivation %s", k.Kdf)
+	}
+	return nil
+}
+
+func (k KdfParams) derive(password string) []byte {
+	return argon2.IDKey([]byte(password), k.Salt, k.Time, k.Memory, k.Threads, 32)
+}
+
+type passwordKeyFile struct {
+	KdfParams
+	Nonce []byte `json:"nonce"`
+	Key   []byte `json:"key"`
+}
+
+func (p *PasswordKeyStore) Mode() string {
+	return KeyStorePassword
+}
+
+func (p *PasswordKeyStore) Unlock(password string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.password = password
+}
+
+func (p *PasswordKeyStore) Locked() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.password == ""
+}
+
+func (p *PasswordKeyStore) Load() (string, error) {
+	data, err := os.ReadFile(p.Path)
+	if os.IsNotExist(err) {
+		return "", ErrKeyNotFound
+	}
+	if err != nil {
+		return "", err
+	}
+	if p.Locked() {
+		return "", ErrKeyStoreLocked
+	}
+	file := &passwordKeyFile{}
+	if err := json.Unmarshal(data, file); err != nil || file.Kdf != "argon2id" {
+		return "", fmt.Errorf("damaged key file %s", p.Path)
+	}
+	aead, err := p.cipher(file)
+	if err != nil {
+		return "", err
+	}
+	key, err := aead.Open(nil, file.Nonce, file.Key, nil)
+	if err != nil {
+		return "", ErrWrongPassword
+	}
+	return string(key), nil
+}
+
+func (p *PasswordKeyStore) Save(key string) error {
+	if p.Locked() {
+		return ErrKeyStoreLocked
+	}
+	params, err := newKdfParams()
+	if err != nil {
+		return err
+	}
+	file := &passwordKeyFile{KdfParams: params}
+	aead, err := p.cipher(file)
+	if err != nil {
+		return err
+	}
+	file.Nonce = make([]byte, aead.NonceSize())
+	if _, err := rand.Read(file.Nonce); err != nil {
+		return err
+	}
+	file.Key = aead.Seal(nil, file.Nonce, []byte(key), nil)
+	data, err := json.Marshal(file)
+	if err != nil {
+		return err
+	}
+	return writeKeyFile(p.Path, data)
+}
+
+func (p *PasswordKeyStore) Delete() error {
+	return removeKeyFile(p.Path)
+}
+
+func (p *PasswordKeyStore) cipher(file *passwordKeyFile) (cipher.AEAD, error) {
+	p.mu.Lock()
+	kek := file.derive(p.password)
+	p.mu.Unlock()
+	block, err := aes.NewCipher(kek)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// Keyring is the part of an OS keyring the key store needs.
+type Keyring interface {
+	Get(service, user string) (string, error)
+	Set(service, user, secret string) error
+	Delete(service, user string) error
+}
+
+// SystemKeyring 使用 macOS 钥匙串、Windows 凭据管理器或 Secret Service
+type SystemKeyring struct{}
+
+func (SystemKeyring) Get(service, user string) (string, error) {
+	return keyring.Get(service, user)
+}
+
+func (SystemKeyring) Set(service, user, secret string) error {
+	return keyring.Set(service, user, secret)
+}
+
+func (SystemKeyring) Delete(service, user string) error {
+	return keyring.Delete(service, user)
+}
+
+// FileKeyring 以明文 JSON 文件模拟系统钥匙串，仅用于测试
+type FileKeyring struct {
+	Path string
+	mu   sync.Mutex
+}
+
+func (f *FileKeyring) read() (map[string]string, error) {
+	secrets := map[string]string{}
+	data, err := os.ReadFile(f.Path)
+	if os.IsNotExist(err) {
+		return secrets, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return secrets, json.Unmarshal(data, &secrets)
+}
+
+func (f *FileKeyring) update(fn func(secrets map[string]string) error) error {
+	secrets, err := f.read()
+	if err != nil {
+		return err
+	}
+	if err := fn(secrets); err != nil {
+		return err
+	}
+	data, err := json.Marshal(secrets)
+	if err != nil {
+		return err
+	}
+	return writeKeyFile(f.Path, data)
+}
+
+func (f *FileKeyring) Get(service, user string) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	secrets, err := f.read()
+	if err != nil {
+		return "", err
+	}
+	secret, ok := secrets[service+"/"+user]
+	if !ok {
+		return "", keyring.ErrNotFound
+	}
+	return secret, nil
+}
+
+func (f *FileKeyring) Set(service, user, secret string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.update(func(secrets map[string]string) error {
+		secrets[service+"/"+user] = secret
+		return nil
+	})
+}
+
+func (f *FileKeyring) Delete(service, user string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.update(func(secrets map[string]string) error {
+		if _, ok := secrets[service+"/"+user]; !ok {
+			return keyring.ErrNotFound
+		}
+		delete(secrets, service+"/"+user)
+		return nil
+	})
+}
+
+// KeyringKeyStore 密钥保存在系统钥匙串中
+type KeyringKeyStore struct {
+	Keyring Keyring
+}
+
+func (k *KeyringKeyStore) Mode() string {
+	return KeyStoreKeyring
+}
+
+// Available 钥匙串可以读取时返回 true，不存在的条目不算错误
+func (k *KeyringKeyStore) Available() bool {
+	_, err := k.Keyring.Get(keyringService, keyringUser)
+	return err == nil || errors.Is(err, keyring.ErrNotFound)
+}
+
+func (k *KeyringKeyStore) Load() (string, error) {
+	key, err := k.Keyring.Get(keyringService, keyringUser)
+	if errors.Is(err, keyring.ErrNotFound) {
+		return "", ErrKeyNotFound
+	}
+	return key, err
+}
+
+func (k *KeyringKeyStore) Save(key string) error {
+	return k.Keyring.Set(keyringService, keyringUser, key)
+}
+
+func (k *KeyringKeyStore) Delete() error {
+	if err := k.Keyring.Delete(keyringService, keyringUser); err != nil && !errors.Is(err, keyring.ErrNotFound) {
+		return err
+	}
+	return nil
+}
+
+// newEncryptionKey 32 字节随机密钥，十六进制保存
+func newEncryptionKey() (string, error) {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(key), nil
+}
+
+func writeKeyFile(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	return utility.WriteFileAtomic(path, data)
+}
+
+func removeKeyFile(path string) error {
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,155 @@
+package internal
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"tinydb/app/utility"
+)
+
+func TestFileKeyStore(t *testing.T) {
+	store := &FileKeyStore{Path: filepath.Join(t.TempDir(), ".key")}
+	if _, err := store.Load(); !errors.Is(err, ErrKeyNotFound) {
+		t.Fatalf("expected ErrKeyNotFound, got %v", err)
+	}
+	key, err := loadOrCreateKey(store)
+	if err != nil || len(key) != 64 {
+		t.Fatalf("unexpected key %q %v", key, err)
+	}
+	if loaded, err := store.Load(); err != nil || loaded != key {
+		t.Fatalf("reload returned %q %v", loaded, err)
+	}
+}
+
+func TestPasswordKeyStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".key.password")
+	store := &PasswordKeyStore{Path: path}
+	if err := store.Save("secret key"); !errors.Is(err, ErrKeyStoreLocked) {
+		t.Fatalf("saving a locked store should fail, got %v", err)
+	}
+	store.Unlock("correct horse")
+	if err := store.Save("secret key"); err != nil {
+		t.Fatalf("save failed: %v", err)
+	}
+
+	reopened := &PasswordKeyStore{Path: path}
+	if _, err := reopened.Load(); !errors.Is(err, ErrKeyStoreLocked) {
+		t.Fatalf("expected ErrKeyStoreLocked, got %v", err)
+	}
+	reopened.Unlock("wrong horse")
+	if _, err := reopened.Load(); !errors.Is(err, ErrWrongPassword) {
+		t.Fatalf("expected ErrWrongPassword, got %v", err)
+	}
+	reopened.Unlock("correct horse")
+	if key, err := reopened.Load(); err != nil || key != "secret key" {
+		t.Fatalf("unexpected key %q %v", key, err)
+	}
+}
+
+func TestKeyringKeyStore(t *testing.T) {
+	store := &KeyringKeyStore{Keyring: &FileKeyring{Path: filepath.Join(t.TempDir(), "keyring.json")}}
+	if !store.Available() {
+		t.Fatalf("the file keyring should be available")
+	}
+	if _, err := store.Load(); !errors.Is(err, ErrKeyNotFound) {
+		t.Fatalf("expected ErrKeyNotFound, got %v", err)
+	}
+	if err := store.Save("k"); err != nil {
+		t.Fatalf("save failed: %v", err)
+	}
+	if key, err := store.Load(); err != nil || key != "k" {
+		t.Fatalf("unexpected key %q %v", key, err)
+	}
+	if err := store.Delete(); err != nil {
+		t.Fatalf("delete failed: %v", err)
+	}
+	if err := store.Delete(); err != nil {
+		t.Fatalf("deleting a missing key should succeed: %v", err)
+	}
+}
+
+func TestMigrateKey(t *testing.T) {
+	dir := t.TempDir()
+	from := &FileKeyStore{Path: filepath.Join(dir, ".key")}
+	oldKey, err := loadOrCreateKey(from)
+	if err != nil {
+		t.Fatalf("create key failed: %v", err)
+	}
+	old := createEncryptor(oldKey)
+	db := utility.NewJsonLinesDatabase(filepath.Join(dir, "connections.jsonl"))
+	saved, err := db.Insert(map[string]interface{}{"host": "db", "password": "crypt:" + old.encrypt("secret"), "sshPassword": "plain"})
+	if err != nil {
+		t.Fatalf("insert failed: %v", err)
+	}
+	if _, err := db.Insert(map[string]interface{}{"host": "other"}); err != nil {
+		t.Fatalf("insert failed: %v", err)
+	}
+
+	to := &KeyringKeyStore{Keyring: &FileKeyring{Path: filepath.Join(dir, "keyring.json")}}
+	newKey, err := migrateKey(from, to, oldKey, db, func() error { return nil })
+	if err != nil {
+		t.Fatalf("migrate failed: %v", err)
+	}
+	if stored, _ := to.Load(); stored != newKey || newKey == oldKey {
+		t.Fatalf("the new key should be in the keyring")
+	}
+	if _, err := from.Load(); !errors.Is(err, ErrKeyNotFound) {
+		t.Fatalf("the old key file should be removed, got %v", err)
+	}
+
+	reloaded := utility.NewJsonLinesDatabase(filepath.Join(dir, "connections.jsonl")).Get(saved["_id"].(string))
+	password := reloaded["password"].(string)
+	if !strings.HasPrefix(password, cryptPrefix) {
+		t.Fatalf("the password should be re-encrypted with AES-GCM: %s", password)
+	}
+	if _, err := old.open(strings.TrimPrefix(password, cryptPrefix)); err == nil {
+		t.Fatalf("the old key should not decrypt the password any more")
+	}
+	if plain, err := createEncryptor(newKey).open(strings.TrimPrefix(password, cryptPrefix)); err != nil || plain != "secret" {
+		t.Fatalf("unexpected password %q %v", plain, err)
+	}
+	if reloaded["sshPassword"] != "plain" {
+		t.Fatalf("plain fields should be kept: %v", reloaded)
+	}
+}
+
+func TestMigrateKeyRollback(t *testing.T) {
+	dir := t.TempDir()
+	from := &FileKeyStore{Path: filepath.Join(dir, ".key")}
+	oldKey, err := loadOrCreateKey(from)
+	if err != nil {
+		t.Fatalf("create key failed: %v", err)
+	}
+	old := createEncryptor(oldKey)
+	db := utility.NewJsonLinesDatabase(filepath.Join(dir, "connections.jsonl"))
+	saved, err := db.Insert(map[string]interface{}{"host": "db", "password": cryptPrefix + old.seal("secret")})
+	if err != nil {
+		t.Fatalf("insert failed: %v", err)
+	}
+
+	to := &KeyringKeyStore{Keyring: &FileKeyring{Path: filepath.Join(dir, "keyring.json")}}
+	failed := errors.New("write keystore.json failed")
+	if _, err := migrateKey(from, to, oldKey, db, func() error { return failed }); !errors.Is(err, failed) {
+		t.Fatalf("expected the activate error, got %v", err)
+	}
+	if key, err := from.Load(); err != nil || key != oldKey {
+		t.Fatalf("the old key should be kept, got %q %v", key, err)
+	}
+	if _, err := to.Load(); !errors.Is(err, ErrKeyNotFound) {
+		t.Fatalf("the new key should be removed, got %v", err)
+	}
+	password := db.Get(saved["_id"].(string))["password"].(string)
+	if plain, err := decryptValue(old, password); err != nil || plain != "secret" {
+		t.Fatalf("the password should be restored to the old key: %q %v", plain, err)
+	}
+}
+
+func TestReencryptConnectionsBroken(t *testing.T) {
+	from, to := createEncryptor("0123456789abcdef0"), createEncryptor("fedcba98765432100")
+	connections := []map[string]interface{}{{"_id": "1", "password": "crypt:" + createEncryptor("another key 12345").encrypt("x")}}
+	if _, err := reencryptConnections(connections, from, to, false); err == nil {
+		t.Fatalf("a password encrypted with another key should abort the migration")
+	}
+}
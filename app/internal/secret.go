@@ -2,20 +2,24 @@ package internal
 
 import (
 	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
 	"crypto/hmac"
+	"crypto/rand"
 	"crypto/sha256"
 	"encoding/base64"
 	"encoding/hex"
 	json2 "encoding/json"
+	"errors"
 	"fmt"
 	"github.com/Luzifer/go-openssl/v4"
-	"math/rand"
 	"tinydb/app/pkg/logger"
 )
 
 func randomBytes(size int) []byte {
 	iv := make([]byte, size)
-	rand.Read(iv)
+	// crypto/rand 读取失败时直接终止程序，不会返回可预测的数据
+	_, _ = rand.Read(iv)
 	return iv
 }
 
@@ -32,6 +36,8 @@ type SimpleEncryptor struct {
 	SSL       *openssl.OpenSSL
 	CryptoKey string
 	Opts      *Option
+	// aead 用于 crypt2: 格式，密钥为 Key 的 SHA-256
+	aead cipher.AEAD
 }
 
 func createEncryptor(opts interface{}) *SimpleEncryptor {
@@ -58,14 +64,40 @@ func createEncryptor(opts interface{}) *SimpleEncryptor {
 
 	h := sha256.New()
 	h.Write([]byte(o.Key))
+	sum := h.Sum(nil)
 
+	block, _ := aes.NewCipher(sum)
+	aead, _ := cipher.NewGCM(block)
 	return &SimpleEncryptor{
 		SSL:       openssl.New(),
-		CryptoKey: fmt.Sprintf("%x\n", h.Sum(nil)),
+		CryptoKey: fmt.Sprintf("%x\n", sum),
 		Opts:      o,
+		aead:      aead,
 	}
 }
 
+// seal 使用 AES-GCM 加密，结果为 base64(nonce || 密文)
+func (simple *SimpleEncryptor) seal(plain string) string {
+	nonce := randomBytes(simple.aead.NonceSize())
+	return base64.RawStdEncoding.EncodeToString(simple.aead.Seal(nonce, nonce, []byte(plain), nil))
+}
+
+func (simple *SimpleEncryptor) open(sealed string) (string, error) {
+	data, err := base64.RawStdEncoding.DecodeString(sealed)
+	if err != nil {
+		return "", err
+	}
+	if len(data) < simple.aead.NonceSize() {
+		return "", errors.New("ciphertext too short")
+	}
+	nonce, ciphertext := data[:simple.aead.NonceSize()], data[simple.aead.NonceSize():]
+	plain, err := simple.aead.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return "", err
+	}
+	return string(plain), nil
+}
+
 func (simple *SimpleEncryptor) encrypt(obj interface{}) string {
 	json, err := json2.Marshal(&obj)
 	if err != nil {
@@ -88,7 +120,7 @@ func (simple *SimpleEncryptor) encrypt(obj interface{}) string {
 }
 
 func (simple *SimpleEncryptor) decrypt(opensslEncrypted string) string {
-	if opensslEncrypted == "" {
+	if len(opensslEncrypted) < 64 {
 		return ""
 	}
 	expectedHmac := opensslEncrypted[:64]
@@ -99,6 +131,9 @@ func (simple *SimpleEncryptor) decrypt(opensslEncrypted string) string {
 		return ""
 	}
 
+	if len(encryptedJson) < 16 {
+		return ""
+	}
 	passphrase := encryptedJson[:16]
 	cipher := encryptedJson[16:]
 	if !hmacCheck(cipher, []byte(expectedHmac), []byte(simple.CryptoKey)) {
@@ -0,0 +1,246 @@
+package metrics
+
+import (
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"tinydb/app/utility"
+)
+
+// Sample is one point of the dashboard, rates are per second and ratios in percent.
+type Sample struct {
+	Time   utility.UnixTime   `json:"time"`
+	Values map[string]float64 `json:"values"`
+}
+
+// Counters are the raw numeric values read from the server, rates are
+// computed from two consecutive reads.
+type Counters map[string]float64
+
+// MysqlCounters keeps the numeric entries of SHOW GLOBAL STATUS.
+func MysqlCounters(status map[string]string) Counters {
+	counters := make(Counters, len(status))
+	for name, value := range status {
+		if number, err := strconv.ParseFloat(value, 64); err == nil {
+			counters[name] = number
+		}
+	}
+	return counters
+}
+
+// mongoCounterDepth serverStatus 嵌套很深，只展开到 wiredTiger.cache.xxx 这一层
+const mongoCounterDepth = 3
+
+// MongoCounters flattens the numeric fields of serverStatus into dotted names
+// such as opcounters.insert.
+func MongoCounters(status map[string]interface{}) Counters {
+	counters := make(Counters)
+	flatten(counters, "", status, 1)
+	return counters
+}
+
+func flatten(counters Counters, prefix string, doc map[string]interface{}, depth int) {
+	for key, value := range doc {
+		name := key
+		if prefix != "" {
+			name = prefix + "." + key
+		}
+		if number, ok := toNumber(value); ok {
+			counters[name] = number
+			continue
+		}
+		if depth >= mongoCounterDepth {
+			continue
+		}
+		switch v := value.(type) {
+		case map[string]interface{}:
+			flatten(counters, name, v, depth+1)
+		case primitive.M:
+			flatten(counters, name, v, depth+1)
+		case primitive.D:
+			flatten(counters, name, v.Map(), depth+1)
+		}
+	}
+}
+
+func toNumber(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case int:
+		return float64(v), true
+	case int32:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case float64:
+		return v, true
+	case primitive.Decimal128:
+		number, err := strconv.ParseFloat(v.String(), 64)
+		return number, err == nil
+	}
+	return 0, false
+}
+
+type delta struct {
+	prev, cur Counters
+	seconds   float64
+	values    map[string]float64
+}
+
+func newDelta(prev, cur Counters, elapsed time.Duration) *delta {
+	return &delta{prev: prev, cur: cur, seconds: elapsed.Seconds(), values: make(map[string]float64)}
+}
+
+// diff 计数器在两次采样间的增量，服务重启导致计数器变小时视为无效
+func (d *delta) diff(name string) (float64, bool) {
+	if d.prev == nil {
+		return 0, false
+	}
+	cur, ok := d.cur[name]
+	if !ok {
+		return 0, false
+	}
+	prev, ok := d.prev[name]
+	if !ok || cur < prev {
+		return 0, false
+	}
+	return cur - prev, true
+}
+
+func (d *delta) rate(key, name string) {
+	if d.seconds <= 0 {
+		return
+	}
+	if diff, ok := d.diff(name); ok {
+		d.values[key] = diff / d.seconds
+	}
+}
+
+func (d *delta) gauge(key, name string) {
+	if value, ok := d.cur[name]; ok {
+		d.values[key] = value
+	}
+}
+
+// hitRatio 返回 1 - misses/requests 的百分比，两次采样间没有请求时使用累计值
+func (d *delta) hitRatio(key, misses, requests string) {
+	missDiff, ok1 := d.diff(misses)
+	requestDiff, ok2 := d.diff(requests)
+	if !ok1 || !ok2 || requestDiff == 0 {
+		missDiff, requestDiff = d.cur[misses], d.cur[requests]
+	}
+	if requestDiff > 0 {
+		d.values[key] = (1 - missDiff/requestDiff) * 100
+	}
+}
+
+// MysqlSample computes the dashboard values from two reads of SHOW GLOBAL
+// STATUS, prev is nil for the first read and only gauges are returned then.
+func MysqlSample(prev, cur Counters, variables map[string]string, elapsed time.Duration) map[string]float64 {
+	d := newDelta(prev, cur, elapsed)
+	d.rate("qps", "Questions")
+	d.rate("selectRate", "Com_select")
+	d.rate("insertRate", "Com_insert")
+	d.rate("updateRate", "Com_update")
+	d.rate("deleteRate", "Com_delete")
+	d.rate("slowQueryRate", "Slow_queries")
+	d.rate("connectionRate", "Connections")
+	d.rate("abortedConnectRate", "Aborted_connects")
+	d.rate("bytesReceivedRate", "Bytes_received")
+	d.rate("bytesSentRate", "Bytes_sent")
+	d.rate("rowLockWaitRate", "Innodb_row_lock_waits")
+	d.gauge("connections", "Threads_connected")
+	d.gauge("threadsRunning", "Threads_running")
+	d.gauge("uptime", "Uptime")
+	d.hitRatio("bufferPoolHitRatio", "Innodb_buffer_pool_reads", "Innodb_buffer_pool_read_requests")
+
+	if maxConnections, err := strconv.ParseFloat(variables["max_connections"], 64); err == nil && maxConnections > 0 {
+		if connected, ok := cur["Threads_connected"]; ok {
+			d.values["connectionUsage"] = connected / maxConnections * 100
+		}
+	}
+	return d.values
+}
+
+var mongoOpcounters = []string{"insert", "query", "update", "delete", "getmore", "command"}
+
+// MongoSample computes the dashboard values from two reads of serverStatus.
+func MongoSample(prev, cur Counters, elapsed time.Duration) map[string]float64 {
+	d := newDelta(prev, cur, elapsed)
+	for _, name := range mongoOpcounters {
+		d.rate(name+"Rate", "opcounters."+name)
+	}
+	if _, ok := d.values["queryRate"]; ok {
+		total := 0.0
+		for _, name := range mongoOpcounters {
+			total += d.values[name+"Rate"]
+		}
+		d.values["ops"] = total
+	}
+	d.rate("bytesReceivedRate", "network.bytesIn")
+	d.rate("bytesSentRate", "network.bytesOut")
+	d.rate("connectionRate", "connections.totalCreated")
+	d.gauge("connections", "connections.current")
+	d.gauge("availableConnections", "connections.available")
+	d.gauge("residentMemory", "mem.resident")
+	d.gauge("queued", "globalLock.currentQueue.total")
+	d.gauge("uptime", "uptime")
+	d.hitRatio("cacheHitRatio", "wiredTiger.cache.pages read into cache", "wiredTiger.cache.pages requested from the cache")
+
+	current, ok1 := cur["connections.current"]
+	available, ok2 := cur["connections.available"]
+	if ok1 && ok2 && current+available > 0 {
+		d.values["connectionUsage"] = current / (current + available) * 100
+	}
+	return d.values
+}
+
+// MongoVariables picks the descriptive fields of serverStatus shown next to the charts.
+func MongoVariables(status map[string]interface{}) map[string]string {
+	variables := make(map[string]string)
+	for _, key := range []string{"host", "version", "process"} {
+		if value, ok := status[key]; ok {
+			variables[key] = fmt.Sprint(value)
+		}
+	}
+	if engine, ok := status["storageEngine"].(primitive.M); ok {
+		variables["storageEngine"] = fmt.Sprint(engine["name"])
+	}
+	return variables
+}
+
+// Ring keeps the latest samples, the oldest are dropped once it is full.
+type Ring struct {
+	mu      sync.RWMutex
+	size    int
+	samples []*Sample
+}
+
+func NewRing(size int) *Ring {
+	return &Ring{size: size, samples: make([]*Sample, 0, size)}
+}
+
+func (r *Ring) Add(sample *Sample) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.samples) >= r.size {
+		copy(r.samples, r.samples[1:])
+		r.samples = r.samples[:len(r.samples)-1]
+	}
+	r.samples = append(r.samples, sample)
+}
+
+// Since returns the samples taken after the given time, all of them when it is zero.
+func (r *Ring) Since(since utility.UnixTime) []*Sample {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	result := make([]*Sample, 0, len(r.samples))
+	for _, sample := range r.samples {
+		if sample.Time > since {
+			result = append(result, sample)
+		}
+	}
+	return result
+}
@@ -0,0 +1,84 @@
+package metrics
+
+import (
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"tinydb/app/utility"
+)
+
+func TestMysqlSample(t *testing.T) {
+	prev := MysqlCounters(map[string]string{
+		"Questions":                        "1000",
+		"Threads_connected":                "4",
+		"Innodb_buffer_pool_read_requests": "10000",
+		"Innodb_buffer_pool_reads":         "100",
+		"Version_comment":                  "MySQL Community Server",
+	})
+	if _, ok := prev["Version_comment"]; ok {
+		t.Fatal("non numeric status should be skipped")
+	}
+
+	first := MysqlSample(nil, prev, nil, 0)
+	if _, ok := first["qps"]; ok {
+		t.Fatal("the first sample should not have rates")
+	}
+	if first["connections"] != 4 || first["bufferPoolHitRatio"] != 99 {
+		t.Fatalf("unexpected first sample %v", first)
+	}
+
+	cur := MysqlCounters(map[string]string{
+		"Questions":                        "1500",
+		"Threads_connected":                "5",
+		"Innodb_buffer_pool_read_requests": "11000",
+		"Innodb_buffer_pool_reads":         "150",
+	})
+	values := MysqlSample(prev, cur, map[string]string{"max_connections": "100"}, 5*time.Second)
+	if values["qps"] != 100 || values["connectionUsage"] != 5 || values["bufferPoolHitRatio"] != 95 {
+		t.Fatalf("unexpected sample %v", values)
+	}
+
+	// 服务重启后计数器变小，不能算出负的速率
+	restarted := MysqlSample(cur, MysqlCounters(map[string]string{"Questions": "10"}), nil, 5*time.Second)
+	if _, ok := restarted["qps"]; ok {
+		t.Fatalf("unexpected rate after restart %v", restarted)
+	}
+}
+
+func TestMongoSample(t *testing.T) {
+	status := func(inserts, queries int64) bson.M {
+		return bson.M{
+			"host":        "db-1",
+			"opcounters":  bson.M{"insert": inserts, "query": queries},
+			"connections": bson.M{"current": int32(10), "available": int32(90)},
+			"globalLock":  bson.M{"currentQueue": bson.M{"total": int32(2)}},
+		}
+	}
+	prev := MongoCounters(status(100, 200))
+	cur := MongoCounters(status(150, 400))
+	values := MongoSample(prev, cur, 10*time.Second)
+	if values["insertRate"] != 5 || values["queryRate"] != 20 || values["ops"] != 25 {
+		t.Fatalf("unexpected rates %v", values)
+	}
+	if values["connections"] != 10 || values["connectionUsage"] != 10 || values["queued"] != 2 {
+		t.Fatalf("unexpected gauges %v", values)
+	}
+	if MongoVariables(status(0, 0))["host"] != "db-1" {
+		t.Fatal("host should be reported as a variable")
+	}
+}
+
+func TestRing(t *testing.T) {
+	ring := NewRing(3)
+	for i := 1; i <= 5; i++ {
+		ring.Add(&Sample{Time: utility.GetUnixTime(int64(i))})
+	}
+	samples := ring.Since(0)
+	if len(samples) != 3 || samples[0].Time != 3 || samples[2].Time != 5 {
+		t.Fatalf("unexpected samples %+v", samples)
+	}
+	if len(ring.Since(4)) != 1 {
+		t.Fatal("expected one sample after 4")
+	}
+}
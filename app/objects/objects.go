@@ -0,0 +1,176 @@
+package objects
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"tinydb/app/db"
+	"tinydb/app/db/adapter/mongo"
+	"tinydb/app/db/adapter/mysql"
+)
+
+const (
+	ActionDrop      = "drop"
+	ActionTruncate  = "truncate"
+	ActionRename    = "rename"
+	ActionDuplicate = "duplicate"
+)
+
+const (
+	TypeDatabase   = "database"
+	TypeTable      = "table"
+	TypeView       = "view"
+	TypeProcedure  = "procedure"
+	TypeFunction   = "function"
+	TypeCollection = "collection"
+)
+
+// Operation describes one object operation, Name is the database itself for
+// TypeDatabase and an object of the current database otherwise.
+type Operation struct {
+	Action     string `json:"action"`
+	ObjectType string `json:"objectType"`
+	Name       string `json:"name"`
+	// NewName 用于 rename 和 duplicate
+	NewName string `json:"newName"`
+	// WithData duplicate 时同时复制数据，否则只复制结构
+	WithData bool `json:"withData"`
+}
+
+// Destructive reports whether the operation removes or overwrites data.
+func (op *Operation) Destructive() bool {
+	return op.Action == ActionDrop || op.Action == ActionTruncate || op.Action == ActionRename
+}
+
+func (op *Operation) validate() error {
+	if strings.TrimSpace(op.Name) == "" {
+		return fmt.Errorf("object name is required")
+	}
+	if op.Action == ActionRename || op.Action == ActionDuplicate {
+		if strings.TrimSpace(op.NewName) == "" {
+			return fmt.Errorf("new name is required")
+		}
+		if op.NewName == op.Name {
+			return fmt.Errorf("new name must differ from '%s'", op.Name)
+		}
+	}
+	return nil
+}
+
+func unsupported(op *Operation, dialect string) error {
+	return fmt.Errorf("%s %s on %s: %w", op.Action, op.ObjectType, dialect, db.ErrNotSupportedByAdapter)
+}
+
+// MysqlStatements renders the SQL of an operation.
+func MysqlStatements(op *Operation) ([]string, error) {
+	if err := op.validate(); err != nil {
+		return nil, err
+	}
+	name := quoteIdentifier(op.Name)
+	newName := quoteIdentifier(op.NewName)
+	switch op.ObjectType + "/" + op.Action {
+	case TypeDatabase + "/" + ActionDrop:
+		return []string{"DROP DATABASE " + name}, nil
+	case TypeTable + "/" + ActionDrop:
+		return []string{"DROP TABLE " + name}, nil
+	case TypeTable + "/" + ActionTruncate:
+		return []string{"TRUNCATE TABLE " + name}, nil
+	case TypeTable + "/" + ActionRename, TypeView + "/" + ActionRename:
+		return []string{"RENAME TABLE " + name + " TO " + newName}, nil
+	case TypeTable + "/" + ActionDuplicate:
+		statements := []string{"CREATE TABLE " + newName + " LIKE " + name}
+		if op.WithData {
+			statements = append(statements, "INSERT INTO "+newName+" SELECT * FROM "+name)
+		}
+		return statements, nil
+	case TypeView + "/" + ActionDrop:
+		return []string{"DROP VIEW " + name}, nil
+	case TypeProcedure + "/" + ActionDrop:
+		return []string{"DROP PROCEDURE " + name}, nil
+	case TypeFunction + "/" + ActionDrop:
+		return []string{"DROP FUNCTION " + name}, nil
+	default:
+		return nil, unsupported(op, "mysql")
+	}
+}
+
+// MongoStatements renders an operation as shell commands, they are only used
+// as a preview.
+func MongoStatements(op *Operation) ([]string, error) {
+	if err := op.validate(); err != nil {
+		return nil, err
+	}
+	collection := fmt.Sprintf("db.getCollection(%q)", op.Name)
+	switch op.ObjectType + "/" + op.Action {
+	case TypeDatabase + "/" + ActionDrop:
+		return []string{fmt.Sprintf("db.getSiblingDB(%q).dropDatabase()", op.Name)}, nil
+	case TypeCollection + "/" + ActionDrop, TypeView + "/" + ActionDrop:
+		return []string{collection + ".drop()"}, nil
+	case TypeCollection + "/" + ActionRename:
+		return []string{fmt.Sprintf("%s.renameCollection(%q)", collection, op.NewName)}, nil
+	case TypeCollection + "/" + ActionDuplicate:
+		statements := []string{fmt.Sprintf("db.createCollection(%q)", op.NewName)}
+		if op.WithData {
+			statements = append(statements, fmt.Sprintf(`%s.aggregate([{"$out": %q}])`, collection, op.NewName))
+		}
+		return append(statements, fmt.Sprintf("// copy the indexes of %q", op.Name)), nil
+	default:
+		return nil, unsupported(op, "mongo")
+	}
+}
+
+// Statements returns the preview of an operation for the engine of session.
+func Statements(session db.Session, op *Operation) ([]string, error) {
+	switch session.(type) {
+	case *mysql.Source:
+		return MysqlStatements(op)
+	case *mongo.Source:
+		return MongoStatements(op)
+	default:
+		return nil, unsupported(op, session.Dialect())
+	}
+}
+
+// Apply runs an operation, database is the current database of a Mongo
+// session. The executed statements are returned.
+func Apply(ctx context.Context, session db.Session, database string, op *Operation) ([]string, error) {
+	statements, err := Statements(session, op)
+	if err != nil {
+		return nil, err
+	}
+	switch s := session.(type) {
+	case *mysql.Source:
+		conn, err := s.Conn(ctx)
+		if err != nil {
+			return nil, err
+		}
+		defer conn.Close()
+		for i, statement := range statements {
+			if _, err = conn.ExecContext(ctx, statement); err != nil {
+				return statements[:i], fmt.Errorf("%s: %w", statement, err)
+			}
+		}
+		return statements, nil
+	case *mongo.Source:
+		switch op.ObjectType + "/" + op.Action {
+		case TypeDatabase + "/" + ActionDrop:
+			err = s.DropDatabase(ctx, op.Name)
+		case TypeCollection + "/" + ActionDrop, TypeView + "/" + ActionDrop:
+			err = s.DropCollection(ctx, database, op.Name)
+		case TypeCollection + "/" + ActionRename:
+			err = s.RenameCollection(ctx, database, op.Name, op.NewName)
+		case TypeCollection + "/" + ActionDuplicate:
+			err = s.CopyCollection(ctx, database, op.Name, op.NewName, op.WithData)
+		}
+		if err != nil {
+			return nil, err
+		}
+		return statements, nil
+	}
+	return nil, unsupported(op, session.Dialect())
+}
+
+func quoteIdentifier(name string) string {
+	return "`" + strings.ReplaceAll(name, "`", "``") + "`"
+}
@@ -0,0 +1,55 @@
+package objects
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"tinydb/app/db"
+)
+
+func TestMysqlStatements(t *testing.T) {
+	cases := []struct {
+		op       *Operation
+		expected []string
+	}{
+		{&Operation{Action: ActionDrop, ObjectType: TypeDatabase, Name: "shop"}, []string{"DROP DATABASE `shop`"}},
+		{&Operation{Action: ActionTruncate, ObjectType: TypeTable, Name: "or`ders"}, []string{"TRUNCATE TABLE `or``ders`"}},
+		{&Operation{Action: ActionRename, ObjectType: TypeView, Name: "v1", NewName: "v2"}, []string{"RENAME TABLE `v1` TO `v2`"}},
+		{&Operation{Action: ActionDuplicate, ObjectType: TypeTable, Name: "a", NewName: "b"}, []string{"CREATE TABLE `b` LIKE `a`"}},
+		{&Operation{Action: ActionDuplicate, ObjectType: TypeTable, Name: "a", NewName: "b", WithData: true},
+			[]string{"CREATE TABLE `b` LIKE `a`", "INSERT INTO `b` SELECT * FROM `a`"}},
+		{&Operation{Action: ActionDrop, ObjectType: TypeFunction, Name: "f"}, []string{"DROP FUNCTION `f`"}},
+	}
+	for i, c := range cases {
+		statements, err := MysqlStatements(c.op)
+		if err != nil {
+			t.Fatalf("case %d: %v", i, err)
+		}
+		if !reflect.DeepEqual(statements, c.expected) {
+			t.Fatalf("case %d: unexpected statements %q", i, statements)
+		}
+	}
+}
+
+func TestStatementsRejectsInvalidInput(t *testing.T) {
+	if _, err := MysqlStatements(&Operation{Action: ActionRename, ObjectType: TypeTable, Name: "a", NewName: "a"}); err == nil {
+		t.Fatal("renaming to the same name should fail")
+	}
+	if _, err := MysqlStatements(&Operation{Action: ActionDrop, ObjectType: TypeTable}); err == nil {
+		t.Fatal("an empty name should fail")
+	}
+	if _, err := MysqlStatements(&Operation{Action: ActionTruncate, ObjectType: TypeView, Name: "v"}); !errors.Is(err, db.ErrNotSupportedByAdapter) {
+		t.Fatalf("truncating a view should not be supported: %v", err)
+	}
+	if _, err := MongoStatements(&Operation{Action: ActionTruncate, ObjectType: TypeCollection, Name: "c"}); !errors.Is(err, db.ErrNotSupportedByAdapter) {
+		t.Fatalf("truncating a collection should not be supported: %v", err)
+	}
+}
+
+func TestMongoStatements(t *testing.T) {
+	statements, err := MongoStatements(&Operation{Action: ActionRename, ObjectType: TypeCollection, Name: "a", NewName: "b"})
+	if err != nil || statements[0] != `db.getCollection("a").renameCollection("b")` {
+		t.Fatalf("unexpected statements %q %v", statements, err)
+	}
+}
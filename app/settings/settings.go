@@ -0,0 +1,263 @@
+package settings
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/samber/lo"
+	"tinydb/app/db"
+	"tinydb/app/pkg/logger"
+	"tinydb/app/utility"
+)
+
+const fileName = "settings.json"
+
+// 写入确认策略
+const (
+	// ConfirmProduction 只有生产环境的可写连接需要确认
+	ConfirmProduction = "production"
+	ConfirmAlways     = "always"
+	ConfirmNever      = "never"
+)
+
+const (
+	DefaultMaxRows          = 2000
+	DefaultConfirmAlterRows = 100000
+)
+
+var themes = []string{"system", "light", "dark"}
+
+type Editor struct {
+	FontSize int  `json:"fontSize"`
+	TabSize  int  `json:"tabSize"`
+	WordWrap bool `json:"wordWrap"`
+	Minimap  bool `json:"minimap"`
+}
+
+// Settings 应用设置，保存在数据目录的 settings.json 中
+type Settings struct {
+	UseNativeMenu bool `json:"useNativeMenu"`
+	// MaxRows 单次查询返回的最大行数
+	MaxRows int `json:"maxRows"`
+	// QueryTimeout 查询超时秒数，0 不限制
+	QueryTimeout int `json:"queryTimeout"`
+	// AnalyserSampleSize 推断导入文件的列类型时读取的行数
+	AnalyserSampleSize int `json:"analyserSampleSize"`
+	// AutoRefreshInterval 自动刷新间隔秒数，0 关闭
+	AutoRefreshInterval int `json:"autoRefreshInterval"`
+	// ConfirmWrites 写入语句的确认策略
+	ConfirmWrites string `json:"confirmWrites"`
+	// PreparedStatementCache 参数化查询复用预处理语句
+	PreparedStatementCache bool `json:"preparedStatementCache"`
+	// ConfirmAlterRows 超过该行数的表执行 ALTER TABLE 前需要确认
+	ConfirmAlterRows int64  `json:"confirmAlterRows"`
+	Theme            string `json:"theme"`
+	Editor           Editor `json:"editor"`
+}
+
+func Defaults() Settings {
+	return Settings{
+		MaxRows:                DefaultMaxRows,
+		QueryTimeout:           0,
+		AnalyserSampleSize:     1000,
+		AutoRefreshInterval:    30,
+		ConfirmWrites:          ConfirmProduction,
+		PreparedStatementCache: true,
+		ConfirmAlterRows:       DefaultConfirmAlterRows,
+		Theme:                  "system",
+		Editor:                 Editor{FontSize: 14, TabSize: 2, Minimap: true},
+	}
+}
+
+func (s *Settings) Validate() error {
+	ranges := []struct {
+		key      string
+		value    int64
+		min, max int64
+	}{
+		{"maxRows", int64(s.MaxRows), 1, 1000000},
+		{"queryTimeout", int64(s.QueryTimeout), 0, 86400},
+		{"analyserSampleSize", int64(s.AnalyserSampleSize), 1, 100000},
+		{"autoRefreshInterval", int64(s.AutoRefreshInterval), 0, 86400},
+		{"confirmAlterRows", s.ConfirmAlterRows, 0, 1 << 50},
+		{"editor.fontSize", int64(s.Editor.FontSize), 8, 48},
+		{"editor.tabSize", int64(s.Editor.TabSize), 1, 16},
+	}
+	for _, r := range ranges {
+		if r.value < r.min || r.value > r.max {
+			return fmt.Errorf("%s must be between %d and %d", r.key, r.min, r.max)
+		}
+	}
+	if !lo.Contains([]string{ConfirmProduction, ConfirmAlways, ConfirmNever}, s.ConfirmWrites) {
+		return fmt.Errorf("invalid confirmWrites '%s'", s.ConfirmWrites)
+	}
+	if !lo.Contains(themes, s.Theme) {
+		return fmt.Errorf("invalid theme '%s'", s.Theme)
+	}
+	return nil
+}
+
+// Store 读写一个设置文件，读取结果会缓存
+type Store struct {
+	Path     string
+	mu       sync.Mutex
+	settings *Settings
+}
+
+var (
+	defaultStore *Store
+	storeOnce    sync.Once
+)
+
+func store() *Store {
+	storeOnce.Do(func() {
+		defaultStore = &Store{Path: filepath.Join(utility.DataDir(), fileName)}
+	})
+	return defaultStore
+}
+
+// Load 启动时读取设置并应用到数据库会话
+func Load() Settings {
+	loaded := store().Get()
+	applyDatabase(loaded)
+	return loaded
+}
+
+// Get 返回当前设置
+func Get() Settings {
+	return store().Get()
+}
+
+// Update 合并 patch 并保存，键名与 Settings 的 json 字段一致
+func Update(patch map[string]interface{}) (Settings, error) {
+	updated, err := store().Update(patch)
+	if err == nil {
+		applyDatabase(updated)
+	}
+	return updated, err
+}
+
+// applyDatabase 同步行数上限和查询超时，已打开的会话同样生效
+func applyDatabase(s Settings) {
+	db.DefaultSettings.SetMaxRows(s.MaxRows)
+	db.DefaultSettings.SetQueryTimeout(time.Duration(s.QueryTimeout) * time.Second)
+	db.DefaultSettings.SetPreparedStatementCache(s.PreparedStatementCache)
+}
+
+func (s *Store) Get() Settings {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.current()
+}
+
+func (s *Store) Update(patch map[string]interface{}) (Settings, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	current := s.current()
+	updated, err := apply(current, patch)
+	if err != nil {
+		return current, err
+	}
+	data, err := json.MarshalIndent(updated, "", "  ")
+	if err != nil {
+		return current, err
+	}
+	if err := writeFile(s.Path, data); err != nil {
+		return current, err
+	}
+	s.settings = &updated
+	return updated, nil
+}
+
+func (s *Store) current() Settings {
+	if s.settings == nil {
+		loaded := s.load()
+		s.settings = &loaded
+	}
+	return *s.settings
+}
+
+// load 以默认值为基础读取文件，缺少的键使用默认值，无效的键忽略
+func (s *Store) load() Settings {
+	defaults := Defaults()
+	data, err := os.ReadFile(s.Path)
+	if os.IsNotExist(err) {
+		return defaults
+	}
+	saved := map[string]interface{}{}
+	if err == nil {
+		err = json.Unmarshal(data, &saved)
+	}
+	if err != nil {
+		logger.Errorf("load %s failed err: %v", s.Path, err)
+		return defaults
+	}
+	if loaded, err := apply(defaults, saved); err == nil {
+		return loaded
+	}
+	keys := lo.Keys(saved)
+	sort.Strings(keys)
+	loaded := defaults
+	for _, key := range keys {
+		next, err := apply(loaded, map[string]interface{}{key: saved[key]})
+		if err != nil {
+			logger.Errorf("ignore setting %s: %v", key, err)
+			continue
+		}
+		loaded = next
+	}
+	return loaded
+}
+
+// apply 将 patch 合并到 base 上，editor 等对象按字段合并，未知的键返回错误
+func apply(base Settings, patch map[string]interface{}) (Settings, error) {
+	merged, err := toMap(base)
+	if err != nil {
+		return base, err
+	}
+	for key, value := range patch {
+		nested, isMap := value.(map[string]interface{})
+		current, wasMap := merged[key].(map[string]interface{})
+		if isMap && wasMap {
+			merged[key] = lo.Assign(current, nested)
+		} else {
+			merged[key] = value
+		}
+	}
+	data, err := json.Marshal(merged)
+	if err != nil {
+		return base, err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	result := Settings{}
+	if err := decoder.Decode(&result); err != nil {
+		return base, fmt.Errorf("invalid settings: %w", err)
+	}
+	if err := result.Validate(); err != nil {
+		return base, err
+	}
+	return result, nil
+}
+
+func toMap(settings Settings) (map[string]interface{}, error) {
+	data, err := json.Marshal(settings)
+	if err != nil {
+		return nil, err
+	}
+	result := map[string]interface{}{}
+	return result, json.Unmarshal(data, &result)
+}
+
+func writeFile(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	return utility.WriteFileAtomic(path, data)
+}
@@ -0,0 +1,82 @@
+package settings
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"tinydb/app/db"
+)
+
+func TestStoreDefaults(t *testing.T) {
+	store := &Store{Path: filepath.Join(t.TempDir(), fileName)}
+	if got := store.Get(); got != Defaults() {
+		t.Fatalf("a missing file should give the defaults, got %+v", got)
+	}
+	defaults := Defaults()
+	if err := defaults.Validate(); err != nil {
+		t.Fatalf("the defaults should be valid: %v", err)
+	}
+}
+
+func TestStoreUpdate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), fileName)
+	store := &Store{Path: path}
+	updated, err := store.Update(map[string]interface{}{"maxRows": 500, "editor": map[string]interface{}{"fontSize": 16}})
+	if err != nil {
+		t.Fatalf("update failed: %v", err)
+	}
+	if updated.MaxRows != 500 || updated.Editor.FontSize != 16 || updated.Editor.TabSize != Defaults().Editor.TabSize {
+		t.Fatalf("unexpected settings %+v", updated)
+	}
+	if reloaded := (&Store{Path: path}).Get(); reloaded != updated {
+		t.Fatalf("the settings should be saved, got %+v", reloaded)
+	}
+
+	for _, patch := range []map[string]interface{}{
+		{"maxRows": 0},
+		{"maxRows": "many"},
+		{"theme": "blue"},
+		{"confirmWrites": "sometimes"},
+		{"editor": map[string]interface{}{"tabSize": 100}},
+		{"unknown": true},
+		{"editor": map[string]interface{}{"unknown": true}},
+	} {
+		if _, err := store.Update(patch); err == nil {
+			t.Fatalf("%v should be rejected", patch)
+		}
+	}
+	if store.Get() != updated {
+		t.Fatalf("a rejected update should not change the settings")
+	}
+}
+
+func TestStoreLoadInvalidKeys(t *testing.T) {
+	path := filepath.Join(t.TempDir(), fileName)
+	if err := os.WriteFile(path, []byte(`{"maxRows": 10, "theme": "blue", "removed": 1, "editor": {"wordWrap": true}}`), 0600); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+	loaded := (&Store{Path: path}).Get()
+	if loaded.MaxRows != 10 || loaded.Theme != Defaults().Theme || !loaded.Editor.WordWrap || loaded.Editor.FontSize != Defaults().Editor.FontSize {
+		t.Fatalf("valid keys should be kept and the rest filled with defaults, got %+v", loaded)
+	}
+}
+
+func TestApplyDatabase(t *testing.T) {
+	maxRows, timeout, cache := db.DefaultSettings.MaxRows(), db.DefaultSettings.QueryTimeout(), db.DefaultSettings.PreparedStatementCacheEnabled()
+	defer func() {
+		db.DefaultSettings.SetMaxRows(maxRows)
+		db.DefaultSettings.SetQueryTimeout(timeout)
+		db.DefaultSettings.SetPreparedStatementCache(cache)
+	}()
+	session := db.NewSettings()
+	applyDatabase(Settings{MaxRows: 50, QueryTimeout: 3, PreparedStatementCache: false})
+	if session.MaxRows() != 50 || session.QueryTimeout() != 3*time.Second || session.PreparedStatementCacheEnabled() {
+		t.Fatalf("open sessions should follow the defaults, got %d %s", session.MaxRows(), session.QueryTimeout())
+	}
+	session.SetMaxRows(7)
+	if session.MaxRows() != 7 {
+		t.Fatalf("a session value should override the default")
+	}
+}
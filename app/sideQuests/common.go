@@ -1,8 +1,14 @@
 package sideQuests
 
 import (
+	"sync"
+	"time"
+
 	"tinydb/app/db"
 	"tinydb/app/db/standard/modules"
+	"tinydb/app/internal/schema"
+	"tinydb/app/settings"
+	"tinydb/app/utility"
 )
 
 func readVersion(driver db.Session) (*modules.Version, error) {
@@ -13,3 +19,134 @@ func readVersion(driver db.Session) (*modules.Version, error) {
 
 	return version, nil
 }
+
+// worker 一个连接的后台 goroutine：先连接，之后等待 refresh 直到 stop。
+// 消息通过 messages 发出，goroutine 退出时关闭 messages
+type worker struct {
+	messages chan *schema.EchoMessage
+	refresh  chan struct{}
+	stop     chan struct{}
+	done     chan struct{}
+	stopOnce sync.Once
+
+	statusMu      sync.Mutex
+	lastStatus    string
+	statusCounter int
+	lastPing      utility.UnixTime
+}
+
+func newWorker() worker {
+	return worker{
+		messages: make(chan *schema.EchoMessage),
+		refresh:  make(chan struct{}, 1),
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+		lastPing: utility.NewUnixTime(),
+	}
+}
+
+// Messages 连接、刷新产生的消息，worker 停止后关闭
+func (w *worker) Messages() <-chan *schema.EchoMessage {
+	return w.messages
+}
+
+// Refresh 请求重新读取，已有请求等待处理时忽略
+func (w *worker) Refresh() {
+	select {
+	case w.refresh <- struct{}{}:
+	default:
+	}
+}
+
+// Stop 通知 worker 停止后立即返回，不等待正在进行的读取，可以重复调用。
+// 之后的消息不再发送，goroutine 在当前读取结束后退出并关闭 Messages
+func (w *worker) Stop() {
+	w.stopOnce.Do(func() {
+		close(w.stop)
+	})
+}
+
+// Wait 等待 goroutine 退出
+func (w *worker) Wait() {
+	<-w.done
+}
+
+func (w *worker) Ping() {
+	w.statusMu.Lock()
+	defer w.statusMu.Unlock()
+	w.lastPing = utility.NewUnixTime()
+}
+
+func (w *worker) LastPing() utility.UnixTime {
+	w.statusMu.Lock()
+	defer w.statusMu.Unlock()
+	return w.lastPing
+}
+
+// send 停止后不再阻塞等待接收方
+func (w *worker) send(message *schema.EchoMessage) bool {
+	select {
+	case w.messages <- message:
+		return true
+	case <-w.stop:
+		return false
+	}
+}
+
+// setStatus 与上一次相同的状态不重复发送，计数器用于丢弃过期的状态
+func (w *worker) setStatus(status *schema.OpenedStatus) {
+	w.statusMu.Lock()
+	statusString := utility.ToJsonStr(status)
+	if w.lastStatus == statusString {
+		w.statusMu.Unlock()
+		return
+	}
+	w.lastStatus = statusString
+	w.statusCounter++
+	status.Counter = w.statusCounter
+	w.statusMu.Unlock()
+	w.send(&schema.EchoMessage{MsgType: "status", Payload: status})
+}
+
+// autoRefreshInterval 两次自动刷新之间的间隔，0 不自动刷新，测试中可以替换
+var autoRefreshInterval = func() time.Duration {
+	return time.Duration(settings.Get().AutoRefreshInterval) * time.Second
+}
+
+// nextAutoRefresh 下一次自动刷新的计时器，不自动刷新时返回 nil，nil channel 永远不会就绪
+func nextAutoRefresh() (*time.Timer, <-chan time.Time) {
+	interval := autoRefreshInterval()
+	if interval <= 0 {
+		return nil, nil
+	}
+	timer := time.NewTimer(interval)
+	return timer, timer.C
+}
+
+// run connect 返回 false 时直接退出，exit 在关闭 messages 之前调用。
+// 除了 Refresh 请求，每隔 autoRefreshInterval 也会刷新一次，修改设置后从下一次开始生效
+func (w *worker) run(connect func() bool, refresh func(), exit func()) {
+	defer close(w.done)
+	defer close(w.messages)
+	defer exit()
+	if !connect() {
+		return
+	}
+	for {
+		timer, tick := nextAutoRefresh()
+		select {
+		case <-w.refresh:
+			refresh()
+		case <-tick:
+			refresh()
+		case <-w.stop:
+			if timer != nil {
+				timer.Stop()
+			}
+			return
+		}
+		if timer != nil {
+			timer.Stop()
+		}
+	}
+}
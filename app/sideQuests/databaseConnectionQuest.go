@@ -1,174 +1,212 @@
 package sideQuests
 
 import (
+	"context"
 	"errors"
+	"fmt"
 	"sync"
 	"time"
 
 	"github.com/samber/lo"
-	"github.com/wailsapp/wails/v3/pkg/application"
+	"go.mongodb.org/mongo-driver/bson"
 	"tinydb/app/db"
 	"tinydb/app/db/adapter"
 	"tinydb/app/db/adapter/mongo"
+	"tinydb/app/db/adapter/mysql"
+	"tinydb/app/db/script"
 	"tinydb/app/db/standard/modules"
 	"tinydb/app/db/stash"
+	"tinydb/app/environment"
+	"tinydb/app/guard"
+	"tinydb/app/history"
+	"tinydb/app/internal"
 	"tinydb/app/internal/schema"
 	"tinydb/app/pkg/serializer"
 	"tinydb/app/utility"
 )
 
-var databaseLast utility.UnixTime
-
-var analysedStructure map[string]interface{}
-
-var analysedTime utility.UnixTime = 0
-var loadingModel bool
-var statusCounter int
-var lastStatusString string
-
-func getStatusCounter() int {
-	statusCounter += 1
-	return statusCounter
+// DatabaseWorker 一个 conid/database 的连接，状态、结构和 goroutine 都属于它自己
+type DatabaseWorker struct {
+	worker
+	Conid    string
+	Database string
+
+	owner      *DatabaseConnection
+	connection map[string]interface{}
+	// initial 上次关闭时保留的结构，有结构时不再显示 pending
+	initial interface{}
+	driver  db.Session
+
+	mu           sync.Mutex
+	structure    map[string]interface{}
+	analysedTime utility.UnixTime
+	loading      bool
 }
 
-type DatabaseConnection struct {
+// Structure 最近一次分析得到的结构
+func (w *DatabaseWorker) Structure() (map[string]interface{}, utility.UnixTime) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.structure, w.analysedTime
 }
 
-func NewDatabaseConnection() *DatabaseConnection {
-	return &DatabaseConnection{}
+func (w *DatabaseWorker) Loading() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.loading
 }
 
-func (msg *DatabaseConnection) ResetVars() {
-	lastStatusString = ""
+// Refresh 正在分析结构时忽略
+func (w *DatabaseWorker) Refresh() {
+	if !w.Loading() {
+		w.worker.Refresh()
+	}
 }
 
-func (msg *DatabaseConnection) Connect(ch chan *schema.EchoMessage, newOpened *schema.OpenedDatabaseConnection, structure interface{}) {
-	defer close(ch)
-	databaseLast = utility.NewUnixTime()
-	if structure == nil {
-		msg.setStatus(ch, func() (*schema.OpenedStatus, error) {
-			return &schema.OpenedStatus{Name: "pending"}, nil
-		})
+func (w *DatabaseWorker) connect() bool {
+	if w.initial == nil {
+		w.setStatus(&schema.OpenedStatus{Name: "pending"})
 	}
-	driver, err := stash.GetStorageSession().Scanner(
-		newOpened.Conid,
-		lo.Assign(newOpened.Connection, map[string]interface{}{"database": newOpened.Database}),
-	)
-
+	driver, err := w.owner.Open(w.Conid, lo.Assign(w.connection, map[string]interface{}{"database": w.Database}))
 	if err != nil {
-		msg.setStatus(ch, func() (*schema.OpenedStatus, error) {
-			return &schema.OpenedStatus{Name: "error", Message: err.Error()}, err
-		})
-		return
+		w.setStatus(&schema.OpenedStatus{Name: "error", Message: err.Error()})
+		return false
 	}
 
 	version, err := readVersion(driver)
-
 	if err != nil {
-		msg.setStatus(ch, func() (*schema.OpenedStatus, error) {
-			return &schema.OpenedStatus{Name: "error", Message: err.Error()}, err
-		})
-		return
-	} else {
-		ch <- &schema.EchoMessage{
-			Payload: version,
-			MsgType: "version",
-		}
+		w.setStatus(&schema.OpenedStatus{Name: "error", Message: err.Error()})
+		return false
 	}
+	w.send(&schema.EchoMessage{Payload: version, MsgType: "version"})
 
-	if structure != nil {
-		msg.handleIncrementalRefresh(ch, true, driver, newOpened.Database)
-	} else {
-	}
-
-	msg.handleFullRefresh(ch, driver, newOpened.Database)
+	w.driver = driver
+	w.fullRefresh()
+	return true
 }
 
-func (msg *DatabaseConnection) setStatus(ch chan *schema.EchoMessage, data func() (*schema.OpenedStatus, error)) {
-	status, err := data()
-	if err != nil {
-		setStatus(ch, func() (*schema.OpenedStatus, error) {
-			return &schema.OpenedStatus{Name: "error", Message: err.Error()}, nil
-		})
-		return
-	}
-	statusString := utility.ToJsonStr(status)
-	if lastStatusString != statusString {
-		status.Counter = getStatusCounter()
-		ch <- &schema.EchoMessage{MsgType: "status", Payload: status}
-		lastStatusString = statusString
-	}
+func (w *DatabaseWorker) fullRefresh() {
+	w.mu.Lock()
+	w.loading = true
+	w.mu.Unlock()
+	defer func() {
+		w.mu.Lock()
+		w.loading = false
+		w.mu.Unlock()
+	}()
+
+	w.setStatus(&schema.OpenedStatus{Name: "loadStructure"})
+
+	structure := w.owner.Analyse(w.driver, w.Database)
+	analysedTime := utility.NewUnixTime()
+	w.mu.Lock()
+	w.structure, w.analysedTime = structure, analysedTime
+	w.mu.Unlock()
+	w.send(&schema.EchoMessage{MsgType: "structure", Payload: structure})
+	w.send(&schema.EchoMessage{MsgType: "structureTime", Payload: analysedTime})
+	w.setStatus(&schema.OpenedStatus{Name: "ok"})
 }
 
-func (msg *DatabaseConnection) readVersion(ch chan *schema.EchoMessage, driver db.Session) error {
-	version, err := driver.Version()
-	if err != nil {
-		setStatus(ch, func() (*schema.OpenedStatus, error) {
-			return &schema.OpenedStatus{Name: "error", Message: err.Error()}, nil
-		})
-		return err
-	}
+// DatabaseConnection 管理每个 conid/database 的 DatabaseWorker，并处理查询请求
+type DatabaseConnection struct {
+	// Open 打开 conid/database 的会话，Analyse 读取数据库结构，测试中可以替换
+	Open    func(conid string, connection map[string]interface{}) (db.Session, error)
+	Analyse func(driver db.Session, database string) map[string]interface{}
+	// SelectToSql 由前端把 Select 转换成 SQL，只用于旧的查询方式
+	SelectToSql func(selectParams interface{}) (string, error)
+
+	mu      sync.Mutex
+	workers map[string]*DatabaseWorker
+}
 
-	ch <- &schema.EchoMessage{
-		Payload: version,
-		MsgType: "version",
-		Dialect: driver.Dialect(),
+func NewDatabaseConnection() *DatabaseConnection {
+	return &DatabaseConnection{
+		Open: func(conid string, connection map[string]interface{}) (db.Session, error) {
+			return stash.GetStorageSession().Scanner(conid, connection)
+		},
+		Analyse: adapter.AnalyseFull,
+		workers: make(map[string]*DatabaseWorker),
 	}
+}
 
-	return nil
+func workerKey(conid, database string) string {
+	return conid + "/" + database
 }
 
-func (msg *DatabaseConnection) handleFullRefresh(ch chan *schema.EchoMessage, driver db.Session, database string) {
-	loadingModel = true
+// Start 启动 conid/database 的 worker，已有的 worker 会先停止。连接失败时
+// 发送 error 状态后关闭 Messages
+func (msg *DatabaseConnection) Start(opened *schema.OpenedDatabaseConnection, structure interface{}) *DatabaseWorker {
+	w := &DatabaseWorker{
+		worker:     newWorker(),
+		Conid:      opened.Conid,
+		Database:   opened.Database,
+		owner:      msg,
+		connection: opened.Connection,
+		initial:    structure,
+	}
+	key := workerKey(opened.Conid, opened.Database)
+	msg.mu.Lock()
+	previous := msg.workers[key]
+	msg.workers[key] = w
+	msg.mu.Unlock()
+	if previous != nil {
+		previous.Stop()
+	}
 
-	msg.setStatus(ch, func() (*schema.OpenedStatus, error) {
-		return &schema.OpenedStatus{Name: "loadStructure"}, nil
+	go w.run(w.connect, w.fullRefresh, func() {
+		msg.mu.Lock()
+		defer msg.mu.Unlock()
+		if msg.workers[key] == w {
+			delete(msg.workers, key)
+		}
 	})
+	return w
+}
 
-	analysedStructure = adapter.AnalyseFull(driver, database)
-	analysedTime = utility.NewUnixTime()
-	ch <- &schema.EchoMessage{MsgType: "structure", Payload: analysedStructure}
-	ch <- &schema.EchoMessage{MsgType: "structureTime", Payload: analysedTime}
-	msg.setStatus(ch, func() (*schema.OpenedStatus, error) {
-		return &schema.OpenedStatus{Name: "ok"}, nil
-	})
+func (msg *DatabaseConnection) Worker(conid, database string) *DatabaseWorker {
+	msg.mu.Lock()
+	defer msg.mu.Unlock()
+	return msg.workers[workerKey(conid, database)]
+}
 
-	loadingModel = false
+// Refresh 重新分析结构，没有对应的 worker 时返回 false
+func (msg *DatabaseConnection) Refresh(conid, database string) bool {
+	w := msg.Worker(conid, database)
+	if w == nil {
+		return false
+	}
+	w.Refresh()
+	return true
 }
 
-func (msg *DatabaseConnection) handleIncrementalRefresh(ch chan *schema.EchoMessage, forceSend bool, pool db.Session, database string) {
-	/*loadingModel = true
-	msg.setStatus(ch, func() (*containers.OpenedStatus, error) {
-		return &containers.OpenedStatus{Name: "checkStructure", Counter: getStatusCounter()}, nil
-	})
+func (msg *DatabaseConnection) Ping(conid, database string) {
+	if w := msg.Worker(conid, database); w != nil {
+		w.Ping()
+	}
+}
 
-	tables, err := pool.Tables(args...)
-	if err != nil {
-		setStatus(ch, func() (*containers.OpenedStatus, error) {
-			return &containers.OpenedStatus{Name: "error", Message: err.Error()}, err
-		})
-		return
-	}
-	analysedTime = utility.NewUnixTime()
-
-	if forceSend || tables != nil {
-		ch <- &containers.EchoMessage{
-			MsgType: "structure",
-			Payload: map[string]interface{}{
-				"collections": tables,
-				"engine":      pool.Dialect(),
-			},
-		}
+func (msg *DatabaseConnection) Stop(conid, database string) {
+	if w := msg.Worker(conid, database); w != nil {
+		w.Stop()
 	}
+}
 
-	ch <- &containers.EchoMessage{
-		MsgType: "structureTime",
-		Payload: analysedTime,
+// StopAll 停止全部 worker 并等待它们退出，应用退出时使用
+func (msg *DatabaseConnection) StopAll() {
+	msg.mu.Lock()
+	workers := lo.Values(msg.workers)
+	msg.mu.Unlock()
+	for _, w := range workers {
+		w.Stop()
+	}
+	for _, w := range workers {
+		w.Wait()
 	}
+}
 
-	msg.setStatus(ch, func() (*containers.OpenedStatus, error) {
-		return &containers.OpenedStatus{Name: "ok"}, nil
-	})*/
+// session 查询使用的会话，连接刚打开、worker 还没有连接完成时直接打开
+func (msg *DatabaseConnection) session(conn *schema.OpenedDatabaseConnection) (db.Session, error) {
+	return msg.Open(conn.Conid, lo.Assign(conn.Connection, map[string]interface{}{"database": conn.Database}))
 }
 
 func (msg *DatabaseConnection) HandleSqlSelect(conn *schema.OpenedDatabaseConnection, selectParams interface{}) *schema.EchoMessage {
@@ -177,90 +215,93 @@ func (msg *DatabaseConnection) HandleSqlSelect(conn *schema.OpenedDatabaseConnec
 	case map[string]interface{}:
 		if raw, ok := v["sql"]; ok {
 			if sqlStr, ok2 := raw.(string); ok2 && sqlStr != "" {
-				driver, err := stash.GetStorageSession().GetItem(conn.Conid, conn.Database)
+				driver, err := msg.session(conn)
 				if err != nil {
 					return &schema.EchoMessage{MsgType: "response", Err: err}
 				}
-				return msg.handleQueryData(driver, sqlStr, true)
+				token, _ := v["confirmToken"].(string)
+				if confirm := msg.confirmDangers(conn, driver, sqlStr, token); confirm != nil {
+					return confirm
+				}
+				if params, ok := v["params"]; ok && params != nil {
+					return msg.handleParamQuery(conn, driver, sqlStr, params)
+				}
+				return msg.handleQueryData(conn, driver, sqlStr, false)
 			}
 		}
 	case string:
 		if v != "" {
-			driver, err := stash.GetStorageSession().GetItem(conn.Conid, conn.Database)
+			driver, err := msg.session(conn)
 			if err != nil {
 				return &schema.EchoMessage{MsgType: "response", Err: err}
 			}
-			return msg.handleQueryData(driver, v, true)
-		}
-	}
-
-	// Legacy path: ask frontend to convert Select -> SQL via runtime event.
-	ch := make(chan *schema.EchoMessage, 1)
-	var chClosed bool
-	var chMutex sync.Mutex
-	
-	safeSend := func(msg *schema.EchoMessage) {
-		chMutex.Lock()
-		defer chMutex.Unlock()
-		if !chClosed {
-			select {
-			case ch <- msg:
-			default:
-				// Channel is full, skip
+			if confirm := msg.confirmDangers(conn, driver, v, ""); confirm != nil {
+				return confirm
 			}
+			return msg.handleQueryData(conn, driver, v, false)
 		}
 	}
-	
-	app := application.Get()
-	if app == nil || app.Event == nil {
+
+	// Legacy path: ask frontend to convert Select -> SQL.
+	if msg.SelectToSql == nil {
 		return &schema.EchoMessage{MsgType: "response", Err: errors.New("application not ready")}
 	}
-	app.Event.Emit("handleSqlSelect", selectParams)
-	cancel := app.Event.On("handleSqlSelectReturn", func(e *application.CustomEvent) {
-		var sqlStr string
-		if e != nil && e.Data != nil {
-			if s, ok := e.Data.(string); ok {
-				sqlStr = s
-			}
+	sqlStr, err := msg.SelectToSql(selectParams)
+	if err != nil {
+		return &schema.EchoMessage{MsgType: "response", Err: err}
+	}
+	var response *schema.EchoMessage
+	utility.WithRecover(func() {
+		driver, err := msg.session(conn)
+		if err != nil {
+			response = &schema.EchoMessage{MsgType: "response", Err: err}
+			return
 		}
-		utility.WithRecover(func() {
-			driver, err := stash.GetStorageSession().GetItem(conn.Conid, conn.Database)
-			if err != nil {
-				safeSend(&schema.EchoMessage{MsgType: "response", Err: err})
-				return
-			}
-			safeSend(msg.handleQueryData(driver, sqlStr, true))
-		}, func(err error) {
-			safeSend(&schema.EchoMessage{
-				MsgType: "response",
-				Err:     errors.New(serializer.ErrNil),
-			})
-		})
-	})
-	defer cancel()
-
-	// Never hang forever: timeout if frontend doesn't respond.
-	select {
-	case resp := <-ch:
-		chMutex.Lock()
-		chClosed = true
-		close(ch)
-		chMutex.Unlock()
-		return resp
-	case <-time.After(8 * time.Second):
-		chMutex.Lock()
-		chClosed = true
-		close(ch)
-		chMutex.Unlock()
-		return &schema.EchoMessage{
+		response = msg.handleQueryData(conn, driver, sqlStr, false)
+	}, func(err error) {
+		response = &schema.EchoMessage{
 			MsgType: "response",
-			Err:     errors.New("sql select timeout: frontend did not return SQL"),
+			Err:     errors.New(serializer.ErrNil),
 		}
-	}
+	})
+	return response
 }
 
-func (msg *DatabaseConnection) handleQueryData(driver db.Session, sql string, skipReadonlyCheck bool) *schema.EchoMessage {
+func (msg *DatabaseConnection) handleQueryData(conn *schema.OpenedDatabaseConnection, driver db.Session, sql string, skipReadonlyCheck bool) *schema.EchoMessage {
+	if !skipReadonlyCheck && readOnly(conn) && script.Classify(sql) == script.StatementWrite {
+		return &schema.EchoMessage{MsgType: "response", Err: db.ErrReadOnly}
+	}
+	started := time.Now()
 	res, err := driver.Query(sql)
+	history.Record(conn.Conid, conn.Database, sql, started, queryRowCount(res), err)
+	return &schema.EchoMessage{
+		Payload: res,
+		MsgType: "response",
+		Err:     err,
+	}
+}
+
+// handleParamQuery 绑定参数后通过驱动的预处理语句执行，参数值不会拼接进 SQL
+func (msg *DatabaseConnection) handleParamQuery(conn *schema.OpenedDatabaseConnection, driver db.Session, sql string, rawParams interface{}) *schema.EchoMessage {
+	source, ok := driver.(*mysql.Source)
+	if !ok {
+		return &schema.EchoMessage{MsgType: "response", Err: fmt.Errorf("query parameters: %w", db.ErrNotSupportedByAdapter)}
+	}
+	if readOnly(conn) && script.Classify(sql) == script.StatementWrite {
+		return &schema.EchoMessage{MsgType: "response", Err: db.ErrReadOnly}
+	}
+	params, err := script.ParseParamValues(rawParams)
+	if err != nil {
+		return &schema.EchoMessage{MsgType: "response", Err: err}
+	}
+	bound, args, err := script.Bind(sql, params)
+	if err != nil {
+		return &schema.EchoMessage{MsgType: "response", Err: err}
+	}
+
+	started := time.Now()
+	res, err := source.QueryWithArgs(bound, args...)
+	history.Record(conn.Conid, conn.Database, sql, started, queryRowCount(res), err)
 	return &schema.EchoMessage{
 		Payload: res,
 		MsgType: "response",
@@ -268,9 +309,52 @@ func (msg *DatabaseConnection) handleQueryData(driver db.Session, sql string, sk
 	}
 }
 
+// confirmDangers 危险语句先返回 confirm 消息和确认令牌，带着有效令牌重新提交时才执行
+func (msg *DatabaseConnection) confirmDangers(conn *schema.OpenedDatabaseConnection, driver db.Session, sql, token string) *schema.EchoMessage {
+	if readOnly(conn) {
+		// 只读连接直接拒绝写入，不需要确认
+		return nil
+	}
+	items := guard.CheckSql(context.Background(), driver, sql, func(table string) int64 {
+		return guard.TableRows(conn.Structure, table)
+	}, environment.ConfirmWrites(savedConnection(conn)))
+	if len(items) == 0 {
+		return nil
+	}
+	subject := guard.Subject(conn.Conid, conn.Database, sql)
+	if token != "" && utility.Confirmations.Consume(token, subject) {
+		return nil
+	}
+	return &schema.EchoMessage{
+		MsgType: "confirm",
+		Payload: &guard.Confirmation{Token: utility.Confirmations.Issue(subject), Items: items},
+	}
+}
+
+// savedConnection 以保存的连接为准，修改只读、环境设置后不需要重新打开连接
+func savedConnection(conn *schema.OpenedDatabaseConnection) map[string]interface{} {
+	if stored := internal.GetCore(conn.Conid, false); stored != nil {
+		return stored
+	}
+	return conn.Connection
+}
+
+func readOnly(conn *schema.OpenedDatabaseConnection) bool {
+	return internal.IsReadOnly(savedConnection(conn))
+}
+
+func queryRowCount(res interface{}) int {
+	if result, ok := res.(*modules.MysqlRowsResult); ok && result != nil {
+		if rows, ok := result.Rows.([]map[string]interface{}); ok {
+			return len(rows)
+		}
+	}
+	return 0
+}
+
 func (msg *DatabaseConnection) HandleCollectionData(conn *schema.OpenedDatabaseConnection,
 	options *modules.CollectionDataOptions) *schema.EchoMessage {
-	driver, err := stash.GetStorageSession().GetItem(conn.Conid, conn.Database)
+	driver, err := msg.session(conn)
 	if err != nil {
 		return &schema.EchoMessage{
 			MsgType: "response",
@@ -278,7 +362,17 @@ func (msg *DatabaseConnection) HandleCollectionData(conn *schema.OpenedDatabaseC
 		}
 	}
 
+	if options != nil && options.Aggregate != nil && readOnly(conn) && script.MongoPipelineWrites(options.Aggregate) {
+		return &schema.EchoMessage{MsgType: "response", Err: db.ErrReadOnly}
+	}
+
+	started := time.Now()
 	collection, err := driver.(*mongo.Source).ReadCollection(conn.Database, options)
+	rowCount := 0
+	if docs, ok := collection.([]bson.M); ok {
+		rowCount = len(docs)
+	}
+	history.Record(conn.Conid, conn.Database, collectionStatement(options), started, rowCount, err)
 	if err != nil {
 		return &schema.EchoMessage{
 			MsgType: "response",
@@ -299,6 +393,35 @@ func (msg *DatabaseConnection) HandleCollectionData(conn *schema.OpenedDatabaseC
 	}
 }
 
+// collectionStatement 把集合查询参数还原成 shell 语句，便于在历史记录中查看
+func collectionStatement(options *modules.CollectionDataOptions) string {
+	if options == nil {
+		return ""
+	}
+	condition := options.Condition
+	if condition == nil {
+		condition = map[string]interface{}{}
+	}
+	statement := fmt.Sprintf("db.getCollection(%q)", options.PureName)
+	switch {
+	case options.CountDocuments:
+		return fmt.Sprintf("%s.countDocuments(%s)", statement, utility.ToJsonStr(condition))
+	case options.Aggregate != nil:
+		return fmt.Sprintf("%s.aggregate(%s)", statement, utility.ToJsonStr(options.Aggregate))
+	}
+	statement = fmt.Sprintf("%s.find(%s)", statement, utility.ToJsonStr(condition))
+	if len(options.Sort) > 0 {
+		statement = fmt.Sprintf("%s.sort(%s)", statement, utility.ToJsonStr(options.Sort))
+	}
+	if options.Skip > 0 {
+		statement = fmt.Sprintf("%s.skip(%d)", statement, options.Skip)
+	}
+	if options.Limit > 0 {
+		statement = fmt.Sprintf("%s.limit(%d)", statement, options.Limit)
+	}
+	return statement
+}
+
 func (msg *DatabaseConnection) ReadVersion(ch chan *schema.EchoMessage, driver db.Session) error {
 	version, err := driver.Version()
 	if err != nil {
@@ -317,16 +440,6 @@ func (msg *DatabaseConnection) QueryData() {
 
 }
 
-func (msg *DatabaseConnection) SyncModel() {
-	if loadingModel {
-		return
-	}
-}
-
-func (msg *DatabaseConnection) Ping() {
-	databaseLast = utility.NewUnixTime()
-}
-
 func handleDriverDataCore() {
 
 }
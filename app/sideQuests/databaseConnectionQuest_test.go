@@ -0,0 +1,246 @@
+package sideQuests
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"tinydb/app/db"
+	"tinydb/app/db/standard/modules"
+	"tinydb/app/internal/schema"
+)
+
+// fakeSession 不连接数据库的会话，数据库列表即 databases
+type fakeSession struct {
+	db.Settings
+	name      string
+	databases []string
+	closed    atomic.Bool
+}
+
+func newFakeSession(name string, databases ...string) *fakeSession {
+	return &fakeSession{Settings: db.NewSettings(), name: name, databases: databases}
+}
+
+func (f *fakeSession) Dialect() string { return "fake" }
+func (f *fakeSession) Ping() error     { return nil }
+func (f *fakeSession) Version() (*modules.Version, error) {
+	return &modules.Version{Version: f.name}, nil
+}
+func (f *fakeSession) Close() error {
+	f.closed.Store(true)
+	return nil
+}
+func (f *fakeSession) ListDatabases() (interface{}, error) { return f.databases, nil }
+func (f *fakeSession) Query(string) (interface{}, error)   { return nil, nil }
+func (f *fakeSession) CreateDatabase(string) error         { return nil }
+
+func newFakeDatabaseConnection(analysed *atomic.Int32) *DatabaseConnection {
+	connection := NewDatabaseConnection()
+	connection.Open = func(conid string, connection map[string]interface{}) (db.Session, error) {
+		if conid == "broken" {
+			return nil, errors.New("cannot connect")
+		}
+		return newFakeSession(conid), nil
+	}
+	connection.Analyse = func(driver db.Session, database string) map[string]interface{} {
+		analysed.Add(1)
+		time.Sleep(time.Millisecond)
+		version, _ := driver.Version()
+		return map[string]interface{}{"conid": version.Version, "database": database}
+	}
+	return connection
+}
+
+// collect 读取消息直到 until 返回 true 或 worker 退出
+func collect(t *testing.T, messages <-chan *schema.EchoMessage, until func(message *schema.EchoMessage) bool) []*schema.EchoMessage {
+	t.Helper()
+	var result []*schema.EchoMessage
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case message, ok := <-messages:
+			if !ok {
+				return result
+			}
+			result = append(result, message)
+			if until(message) {
+				return result
+			}
+		case <-timeout:
+			t.Fatalf("worker did not finish connecting")
+		}
+	}
+}
+
+// statusOk 数据库 worker 连接并分析完成后发送 ok 状态
+func statusOk(message *schema.EchoMessage) bool {
+	status, ok := message.Payload.(*schema.OpenedStatus)
+	return ok && message.MsgType == "status" && status.Name == "ok"
+}
+
+func statusNames(messages []*schema.EchoMessage) []string {
+	var names []string
+	counter := 0
+	for _, message := range messages {
+		if status, ok := message.Payload.(*schema.OpenedStatus); ok && message.MsgType == "status" {
+			if status.Counter <= counter {
+				return append(names, "counter not increasing")
+			}
+			counter = status.Counter
+			names = append(names, status.Name)
+		}
+	}
+	return names
+}
+
+func TestDatabaseWorkersParallel(t *testing.T) {
+	var analysed atomic.Int32
+	connection := newFakeDatabaseConnection(&analysed)
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		for _, database := range []string{"a", "b"} {
+			wg.Add(1)
+			go func(conid, database string) {
+				defer wg.Done()
+				w := connection.Start(&schema.OpenedDatabaseConnection{Conid: conid, Database: database}, nil)
+				messages := collect(t, w.Messages(), statusOk)
+				if names := fmt.Sprint(statusNames(messages)); names != "[pending loadStructure ok]" {
+					t.Errorf("%s/%s unexpected statuses %s", conid, database, names)
+				}
+				for _, message := range messages {
+					if message.MsgType != "structure" {
+						continue
+					}
+					structure := message.Payload.(map[string]interface{})
+					if structure["conid"] != conid || structure["database"] != database {
+						t.Errorf("%s/%s got the structure of %v", conid, database, structure)
+					}
+				}
+				if structure, _ := w.Structure(); structure["database"] != database {
+					t.Errorf("%s/%s keeps the structure of %v", conid, database, structure)
+				}
+			}(fmt.Sprintf("conid-%d", i), database)
+		}
+	}
+	wg.Wait()
+	if analysed.Load() != 16 {
+		t.Fatalf("expected 16 analyses, got %d", analysed.Load())
+	}
+	connection.StopAll()
+	if connection.Worker("conid-0", "a") != nil {
+		t.Fatalf("stopped workers should be removed")
+	}
+}
+
+func TestDatabaseWorkerLifecycle(t *testing.T) {
+	var analysed atomic.Int32
+	connection := newFakeDatabaseConnection(&analysed)
+	w := connection.Start(&schema.OpenedDatabaseConnection{Conid: "c", Database: "d"}, map[string]interface{}{})
+	if names := fmt.Sprint(statusNames(collect(t, w.Messages(), statusOk))); names != "[loadStructure ok]" {
+		t.Fatalf("a known structure should skip pending, got %s", names)
+	}
+	_, firstTime := w.Structure()
+
+	if !connection.Refresh("c", "d") {
+		t.Fatalf("refresh should find the worker")
+	}
+	var structures int
+	for message := range w.Messages() {
+		if message.MsgType == "structure" {
+			structures++
+		}
+		if status, ok := message.Payload.(*schema.OpenedStatus); ok && status.Name == "ok" {
+			break
+		}
+	}
+	if _, analysedTime := w.Structure(); structures != 1 || analysedTime < firstTime {
+		t.Fatalf("refresh should analyse again, got %d structures", structures)
+	}
+
+	restarted := connection.Start(&schema.OpenedDatabaseConnection{Conid: "c", Database: "d"}, nil)
+	if _, ok := <-w.Messages(); ok {
+		t.Fatalf("starting again should stop the previous worker")
+	}
+	collect(t, restarted.Messages(), statusOk)
+	connection.Stop("c", "d")
+	connection.Stop("c", "d")
+	if _, ok := <-restarted.Messages(); ok || connection.Worker("c", "d") != nil || connection.Refresh("c", "d") {
+		t.Fatalf("a stopped worker should close its messages and be removed")
+	}
+}
+
+func TestDatabaseWorkerOpenError(t *testing.T) {
+	var analysed atomic.Int32
+	connection := newFakeDatabaseConnection(&analysed)
+	w := connection.Start(&schema.OpenedDatabaseConnection{Conid: "broken", Database: "d"}, nil)
+	messages := collect(t, w.Messages(), statusOk)
+	status := messages[len(messages)-1].Payload.(*schema.OpenedStatus)
+	if status.Name != "error" || status.Message != "cannot connect" || status.Counter != 2 {
+		t.Fatalf("unexpected status %+v", status)
+	}
+	w.Stop()
+	if connection.Worker("broken", "d") != nil || analysed.Load() != 0 {
+		t.Fatalf("a failed worker should exit without analysing")
+	}
+}
+
+func TestDatabaseWorkerStopDuringAnalyse(t *testing.T) {
+	var analysed atomic.Int32
+	connection := newFakeDatabaseConnection(&analysed)
+	started, release := make(chan struct{}), make(chan struct{})
+	connection.Analyse = func(driver db.Session, database string) map[string]interface{} {
+		close(started)
+		<-release
+		return map[string]interface{}{}
+	}
+	w := connection.Start(&schema.OpenedDatabaseConnection{Conid: "c", Database: "d"}, map[string]interface{}{})
+	go func() {
+		for range w.Messages() {
+		}
+	}()
+	<-started
+
+	stopped := make(chan struct{})
+	go func() {
+		w.Stop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Fatalf("stop should not wait for the analysis")
+	}
+	close(release)
+	w.Wait()
+	if connection.Worker("c", "d") != nil {
+		t.Fatalf("the worker should exit once the analysis returns")
+	}
+}
+
+func TestDatabaseWorkerAutoRefresh(t *testing.T) {
+	saved := autoRefreshInterval
+	defer func() { autoRefreshInterval = saved }()
+	autoRefreshInterval = func() time.Duration { return 5 * time.Millisecond }
+
+	var analysed atomic.Int32
+	connection := newFakeDatabaseConnection(&analysed)
+	w := connection.Start(&schema.OpenedDatabaseConnection{Conid: "c", Database: "d"}, map[string]interface{}{})
+	structures := 0
+	timeout := time.After(5 * time.Second)
+	for structures < 3 {
+		select {
+		case message := <-w.Messages():
+			if message.MsgType == "structure" {
+				structures++
+			}
+		case <-timeout:
+			t.Fatalf("the structure should be refreshed periodically, got %d analyses", analysed.Load())
+		}
+	}
+	w.Stop()
+	w.Wait()
+}
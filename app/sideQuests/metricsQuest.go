@@ -0,0 +1,169 @@
+package sideQuests
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"tinydb/app/db"
+	"tinydb/app/db/adapter/mongo"
+	"tinydb/app/db/adapter/mysql"
+	"tinydb/app/metrics"
+	"tinydb/app/pkg/logger"
+	"tinydb/app/utility"
+)
+
+// metricsRingSize 默认 5 秒一次时保留最近 30 分钟的采样
+const metricsRingSize = 360
+
+// metricsVariablesRefresh SHOW VARIABLES 变化很少，不需要每次采样都读取
+const metricsVariablesRefresh = 5 * time.Minute
+
+type metricsWatcher struct {
+	interval      time.Duration
+	lastSeen      time.Time
+	ring          *metrics.Ring
+	prev          metrics.Counters
+	prevTime      time.Time
+	variables     map[string]string
+	variablesTime time.Time
+	stop          chan struct{}
+}
+
+// MetricsMonitor samples server status counters at an interval, keeps them in
+// a ring buffer and emits server-metrics-changed-<conid> after every sample.
+type MetricsMonitor struct {
+	mu       sync.Mutex
+	watchers map[string]*metricsWatcher
+}
+
+func NewMetricsMonitor() *MetricsMonitor {
+	return &MetricsMonitor{watchers: make(map[string]*metricsWatcher)}
+}
+
+// Watch starts sampling conid, changing the interval keeps the samples taken so far.
+func (m *MetricsMonitor) Watch(conid string, interval time.Duration, open func() (db.Session, error)) {
+	if interval < minProcessInterval {
+		interval = minProcessInterval
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	watcher := &metricsWatcher{ring: metrics.NewRing(metricsRingSize)}
+	if existing, ok := m.watchers[conid]; ok {
+		existing.lastSeen = time.Now()
+		if existing.interval == interval {
+			return
+		}
+		close(existing.stop)
+		watcher.ring = existing.ring
+	}
+	watcher.interval = interval
+	watcher.lastSeen = time.Now()
+	watcher.stop = make(chan struct{})
+	m.watchers[conid] = watcher
+	go m.poll(conid, watcher, open)
+}
+
+func (m *MetricsMonitor) Unwatch(conid string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if existing, ok := m.watchers[conid]; ok {
+		close(existing.stop)
+		delete(m.watchers, conid)
+	}
+}
+
+// Samples returns the samples of conid taken after since and the last read server variables.
+func (m *MetricsMonitor) Samples(conid string, since utility.UnixTime) ([]*metrics.Sample, map[string]string) {
+	m.mu.Lock()
+	existing, ok := m.watchers[conid]
+	if !ok {
+		m.mu.Unlock()
+		return make([]*metrics.Sample, 0), nil
+	}
+	existing.lastSeen = time.Now()
+	variables := existing.variables
+	m.mu.Unlock()
+	return existing.ring.Since(since), variables
+}
+
+func (m *MetricsMonitor) poll(conid string, watcher *metricsWatcher, open func() (db.Session, error)) {
+	ticker := time.NewTicker(watcher.interval)
+	defer ticker.Stop()
+	for {
+		m.mu.Lock()
+		idle := time.Since(watcher.lastSeen) > processIdleTimeout
+		if idle && m.watchers[conid] == watcher {
+			delete(m.watchers, conid)
+		}
+		m.mu.Unlock()
+		if idle {
+			return
+		}
+
+		if sample, err := m.sample(watcher, open); err != nil {
+			logger.Errorf("sample server metrics of %s failed: %v", conid, err)
+		} else {
+			utility.EmitChanged(fmt.Sprintf("server-metrics-changed-%s", conid), sample)
+		}
+
+		select {
+		case <-watcher.stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// sample 读取一次服务器状态，返回加入缓冲区的采样
+func (m *MetricsMonitor) sample(watcher *metricsWatcher, open func() (db.Session, error)) (*metrics.Sample, error) {
+	driver, err := open()
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), watcher.interval)
+	defer cancel()
+
+	now := time.Now()
+	var counters metrics.Counters
+	var values map[string]float64
+	variables := watcher.variables
+	refreshed := false
+	switch s := driver.(type) {
+	case *mysql.Source:
+		status, err := s.GlobalStatus(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if variables == nil || now.Sub(watcher.variablesTime) > metricsVariablesRefresh {
+			if variables, err = s.GlobalVariables(ctx); err != nil {
+				return nil, err
+			}
+			refreshed = true
+		}
+		counters = metrics.MysqlCounters(status)
+		values = metrics.MysqlSample(watcher.prev, counters, variables, now.Sub(watcher.prevTime))
+	case *mongo.Source:
+		status, err := s.ServerStatus(ctx)
+		if err != nil {
+			return nil, err
+		}
+		counters = metrics.MongoCounters(status)
+		variables, refreshed = metrics.MongoVariables(status), true
+		values = metrics.MongoSample(watcher.prev, counters, now.Sub(watcher.prevTime))
+	default:
+		return nil, fmt.Errorf("server metrics on %s: %w", driver.Dialect(), db.ErrNotSupportedByAdapter)
+	}
+
+	m.mu.Lock()
+	if refreshed {
+		watcher.variables, watcher.variablesTime = variables, now
+	}
+	watcher.prev, watcher.prevTime = counters, now
+	m.mu.Unlock()
+	sample := &metrics.Sample{Time: utility.GetUnixTime(now.Unix()), Values: values}
+	watcher.ring.Add(sample)
+	return sample, nil
+}
@@ -0,0 +1,153 @@
+package sideQuests
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"tinydb/app/db"
+	"tinydb/app/db/adapter/mongo"
+	"tinydb/app/db/adapter/mysql"
+	"tinydb/app/db/standard/modules"
+	"tinydb/app/pkg/logger"
+	"tinydb/app/utility"
+)
+
+const minProcessInterval = time.Second
+
+// processIdleTimeout 前端超过这个时间没有再请求进程列表时停止轮询
+const processIdleTimeout = 5 * time.Minute
+
+// ReadProcessList returns the sessions of the server behind driver.
+func ReadProcessList(ctx context.Context, driver db.Session) ([]*modules.ServerProcess, error) {
+	switch s := driver.(type) {
+	case *mysql.Source:
+		return s.ProcessList(ctx)
+	case *mongo.Source:
+		return s.ProcessList(ctx)
+	default:
+		return nil, fmt.Errorf("process list on %s: %w", driver.Dialect(), db.ErrNotSupportedByAdapter)
+	}
+}
+
+// KillProcess terminates a session, or only its running statement when queryOnly is set.
+func KillProcess(ctx context.Context, driver db.Session, id string, queryOnly bool) error {
+	switch s := driver.(type) {
+	case *mysql.Source:
+		return s.KillProcess(ctx, id, queryOnly)
+	case *mongo.Source:
+		return s.KillProcess(ctx, id, queryOnly)
+	default:
+		return fmt.Errorf("kill process on %s: %w", driver.Dialect(), db.ErrNotSupportedByAdapter)
+	}
+}
+
+type processWatcher struct {
+	interval  time.Duration
+	lastSeen  time.Time
+	lastValue string
+	processes []*modules.ServerProcess
+	stop      chan struct{}
+}
+
+// ProcessMonitor polls the process list of the servers the frontend is looking
+// at and emits server-process-list-changed-<conid> when it changes.
+type ProcessMonitor struct {
+	mu       sync.Mutex
+	watchers map[string]*processWatcher
+}
+
+func NewProcessMonitor() *ProcessMonitor {
+	return &ProcessMonitor{watchers: make(map[string]*processWatcher)}
+}
+
+// Watch starts polling conid, or changes the interval of an existing poller.
+func (m *ProcessMonitor) Watch(conid string, interval time.Duration, open func() (db.Session, error)) {
+	if interval < minProcessInterval {
+		interval = minProcessInterval
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if existing, ok := m.watchers[conid]; ok {
+		existing.lastSeen = time.Now()
+		if existing.interval == interval {
+			return
+		}
+		close(existing.stop)
+	}
+	watcher := &processWatcher{interval: interval, lastSeen: time.Now(), stop: make(chan struct{})}
+	m.watchers[conid] = watcher
+	go m.poll(conid, watcher, open)
+}
+
+func (m *ProcessMonitor) Unwatch(conid string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if existing, ok := m.watchers[conid]; ok {
+		close(existing.stop)
+		delete(m.watchers, conid)
+	}
+}
+
+// Latest returns the last list read by the poller of conid, nil when it is not watched.
+func (m *ProcessMonitor) Latest(conid string) []*modules.ServerProcess {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if existing, ok := m.watchers[conid]; ok {
+		existing.lastSeen = time.Now()
+		return existing.processes
+	}
+	return nil
+}
+
+func (m *ProcessMonitor) poll(conid string, watcher *processWatcher, open func() (db.Session, error)) {
+	ticker := time.NewTicker(watcher.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-watcher.stop:
+			return
+		case <-ticker.C:
+		}
+
+		m.mu.Lock()
+		idle := time.Since(watcher.lastSeen) > processIdleTimeout
+		if idle && m.watchers[conid] == watcher {
+			delete(m.watchers, conid)
+		}
+		m.mu.Unlock()
+		if idle {
+			return
+		}
+
+		processes, err := m.read(watcher.interval, open)
+		if err != nil {
+			logger.Errorf("poll process list of %s failed: %v", conid, err)
+			continue
+		}
+		value := utility.ToJsonStr(processes)
+
+		m.mu.Lock()
+		changed := m.watchers[conid] == watcher && watcher.lastValue != value
+		if changed {
+			watcher.lastValue = value
+			watcher.processes = processes
+		}
+		m.mu.Unlock()
+		if changed {
+			utility.EmitChanged(fmt.Sprintf("server-process-list-changed-%s", conid))
+		}
+	}
+}
+
+func (m *ProcessMonitor) read(timeout time.Duration, open func() (db.Session, error)) ([]*modules.ServerProcess, error) {
+	driver, err := open()
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	return ReadProcessList(ctx, driver)
+}
@@ -1,176 +1,164 @@
 package sideQuests
 
 import (
-	"time"
+	"sync"
+
+	"github.com/samber/lo"
 	"tinydb/app/db"
 	"tinydb/app/db/adapter"
 	"tinydb/app/internal/schema"
+	"tinydb/app/pkg/logger"
 	"tinydb/app/utility"
 )
 
-var serverLastStatus string
-var serverLastDatabases string
-var serverLastPing utility.UnixTime
-var storedConnection = make(map[string]interface{})
-
 type StatusMessage struct {
 	Name    string `json:"name"`
 	Message string `json:"message"`
 }
 
+// ServerWorker 一个服务器连接，打开的驱动、数据库列表和状态都属于它自己
+type ServerWorker struct {
+	worker
+	Conid string
+
+	owner         *ServerConnection
+	connection    map[string]interface{}
+	driver        db.Session
+	lastDatabases string
+}
+
+// ServerConnection 管理每个 conid 的 ServerWorker
 type ServerConnection struct {
+	// Open 打开服务器级别的会话，测试中可以替换
+	Open func(connection map[string]interface{}) (db.Session, error)
+
+	mu      sync.Mutex
+	workers map[string]*ServerWorker
 }
 
 func NewServerConnection() *ServerConnection {
-	// setInterval(func() {
-	// 	logger.Info("Server connection not alive, exiting")
-	// 	ch <- &modules.EchoMessage{
-	// 		MsgType: "exit",
-	// 	}
-	// })
-
-	return &ServerConnection{}
+	return &ServerConnection{
+		Open:    adapter.NewCompatDriver().Open,
+		workers: make(map[string]*ServerWorker),
+	}
 }
 
-/*
-定时器 是当你想要在未来某一刻执行一次时使用的
-打点器 则是当你想要在固定的时间间隔重复执行准备的。这里是一个打点器的例子，它将定时的执行，直到我们将它停止。
-*/
-func setInterval(fn func()) {
-	ticker := time.NewTicker(time.Minute)
-	go func(ticker *time.Ticker) {
-		for range ticker.C {
-			nowTime := utility.NewUnixTime()
-			if nowTime-serverLastPing > utility.UnixTime(120*1000) {
-				fn()
-				ticker.Stop()
-			}
-		}
-	}(ticker)
-}
+// Start 启动 conid 的 worker，已有的 worker 会先停止
+func (msg *ServerConnection) Start(conid string, connection map[string]interface{}) *ServerWorker {
+	w := &ServerWorker{worker: newWorker(), Conid: conid, owner: msg, connection: connection}
+	msg.mu.Lock()
+	previous := msg.workers[conid]
+	msg.workers[conid] = w
+	msg.mu.Unlock()
+	if previous != nil {
+		previous.Stop()
+	}
 
-func setStatus(ch chan *schema.EchoMessage, data func() (*schema.OpenedStatus, error)) {
-	status, err := data()
-	if err != nil {
-		ch <- &schema.EchoMessage{
-			Err: err,
+	go w.run(w.connect, func() {
+		w.handleRefresh()
+	}, func() {
+		w.close()
+		msg.mu.Lock()
+		defer msg.mu.Unlock()
+		if msg.workers[conid] == w {
+			delete(msg.workers, conid)
 		}
-		return
-	}
-	statusString := utility.ToJsonStr(status)
-	if serverLastStatus != statusString {
-		ch <- &schema.EchoMessage{Payload: status, MsgType: "status"}
-		serverLastStatus = statusString
-	}
+	})
+	return w
 }
 
-func (msg *ServerConnection) ResetVars() {
-	serverLastStatus = ""
-	serverLastDatabases = ""
+func (msg *ServerConnection) Worker(conid string) *ServerWorker {
+	msg.mu.Lock()
+	defer msg.mu.Unlock()
+	return msg.workers[conid]
 }
 
-func (msg *ServerConnection) Connect(ch chan *schema.EchoMessage, connection map[string]interface{}) {
-	storedConnection = connection
-	defer close(ch)
-
-	setStatus(ch, func() (*schema.OpenedStatus, error) {
-		return &schema.OpenedStatus{Name: "pending"}, nil
-	})
-
-	driver, err := adapter.NewCompatDriver().Open(connection)
-	if err != nil {
-		setStatus(ch, func() (*schema.OpenedStatus, error) {
-			return &schema.OpenedStatus{Name: "error", Message: err.Error()}, err
-		})
-		return
+// Refresh 重新读取数据库列表，没有对应的 worker 时返回 false
+func (msg *ServerConnection) Refresh(conid string) bool {
+	w := msg.Worker(conid)
+	if w == nil {
+		return false
 	}
-	//targetDriver, err := internal.RequireEngineDriver(connection)
-	//if err != nil {
-	//
-	//}
-	//internal.ConnectUtility1(targetDriver, storedConnection, "app")
-
-	//driver, err := internal.TargetStoragePool(conid, connection)
-	//connectUtility
+	w.Refresh()
+	return true
+}
 
-	if err != nil {
-		setStatus(ch, func() (*schema.OpenedStatus, error) {
-			return &schema.OpenedStatus{Name: "error", Message: err.Error()}, err
-		})
-		return
+func (msg *ServerConnection) Ping(conid string) {
+	if w := msg.Worker(conid); w != nil {
+		w.Ping()
 	}
+}
 
-	if err = msg.readVersion(ch, driver); err != nil {
-		return
+func (msg *ServerConnection) Stop(conid string) {
+	if w := msg.Worker(conid); w != nil {
+		w.Stop()
 	}
+}
 
-	if err = msg.handleRefresh(ch, driver); err != nil {
-		return
+// StopAll 停止全部 worker 并等待它们退出，应用退出时使用
+func (msg *ServerConnection) StopAll() {
+	msg.mu.Lock()
+	workers := lo.Values(msg.workers)
+	msg.mu.Unlock()
+	for _, w := range workers {
+		w.Stop()
+	}
+	for _, w := range workers {
+		w.Wait()
 	}
-
-	//tasks.SetDriverPool(connection[conidkey].(string), sqlDriver)
-	// ticker := time.NewTicker(2 * time.Second)
-	// go func(ticker *time.Ticker) {
-	// 	for range ticker.C {
-	// 		if err := sqlDriver.Ping(); err != nil {
-	// 			ch <- &modules.EchoMessage{
-	// 				Payload: nil,
-	// 				MsgType: "exit",
-	// 				Dialect: sqlDriver.Dialect(),
-	// 				Conid:   conid,
-	// 			}
-	// 			ticker.Stop()
-	// 		}
-	// 	}
-	// }(ticker)
 }
 
-func (msg *ServerConnection) Ping() {
-	serverLastPing = utility.NewUnixTime()
+// fail 服务器连接出错时发送带 Err 的消息，接收方会关闭连接
+func (w *ServerWorker) fail(err error) {
+	w.send(&schema.EchoMessage{Err: err})
 }
 
-func (msg *ServerConnection) CreateDatabase() {
-
-}
+func (w *ServerWorker) connect() bool {
+	w.setStatus(&schema.OpenedStatus{Name: "pending"})
 
-func (msg *ServerConnection) readVersion(ch chan *schema.EchoMessage, driver db.Session) error {
-	version, err := driver.Version()
+	driver, err := w.owner.Open(w.connection)
 	if err != nil {
-		setStatus(ch, func() (*schema.OpenedStatus, error) {
-			return &schema.OpenedStatus{Name: "error", Message: err.Error()}, err
-		})
-		return err
+		w.fail(err)
+		return false
 	}
+	w.driver = driver
 
-	ch <- &schema.EchoMessage{
-		Payload: version,
-		MsgType: "version",
+	version, err := driver.Version()
+	if err != nil {
+		w.fail(err)
+		return false
 	}
+	w.send(&schema.EchoMessage{Payload: version, MsgType: "version"})
 
-	return nil
+	return w.handleRefresh()
 }
 
-func (msg *ServerConnection) handleRefresh(ch chan *schema.EchoMessage, driver db.Session) error {
-	databases, err := driver.ListDatabases()
+func (w *ServerWorker) handleRefresh() bool {
+	databases, err := w.driver.ListDatabases()
 	if err != nil {
-		setStatus(ch, func() (*schema.OpenedStatus, error) {
-			return &schema.OpenedStatus{Name: "error", Message: err.Error()}, err
-		})
-		return err
+		w.fail(err)
+		return false
 	}
 
-	setStatus(ch, func() (*schema.OpenedStatus, error) {
-		return &schema.OpenedStatus{Name: "ok"}, nil
-	})
+	w.setStatus(&schema.OpenedStatus{Name: "ok"})
 
 	databasesString := utility.ToJsonStr(databases)
-	if serverLastDatabases != databasesString {
-		ch <- &schema.EchoMessage{
+	if w.lastDatabases != databasesString {
+		w.send(&schema.EchoMessage{
 			Payload: databases,
 			MsgType: "databases",
-			Dialect: driver.Dialect(),
-		}
-		serverLastDatabases = databasesString
+			Dialect: w.driver.Dialect(),
+		})
+		w.lastDatabases = databasesString
+	}
+	return true
+}
+
+func (w *ServerWorker) close() {
+	if w.driver == nil {
+		return
+	}
+	if err := w.driver.Close(); err != nil {
+		logger.Errorf("close server connection %s failed: %v", w.Conid, err)
 	}
-	return nil
 }
@@ -0,0 +1,72 @@
+package sideQuests
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+
+	"tinydb/app/db"
+	"tinydb/app/internal/schema"
+)
+
+func TestServerWorkersParallel(t *testing.T) {
+	var mu sync.Mutex
+	sessions := map[string]*fakeSession{}
+	connection := NewServerConnection()
+	connection.Open = func(c map[string]interface{}) (db.Session, error) {
+		conid := c["conid"].(string)
+		if conid == "broken" {
+			return nil, errors.New("cannot connect")
+		}
+		session := newFakeSession(conid, conid+"-db")
+		mu.Lock()
+		sessions[conid] = session
+		mu.Unlock()
+		return session, nil
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(conid string) {
+			defer wg.Done()
+			w := connection.Start(conid, map[string]interface{}{"conid": conid})
+			messages := collect(t, w.Messages(), func(message *schema.EchoMessage) bool {
+				return message.MsgType == "databases"
+			})
+			if names := fmt.Sprint(statusNames(messages)); names != "[pending ok]" {
+				t.Errorf("%s unexpected statuses %s", conid, names)
+			}
+			last := messages[len(messages)-1]
+			if last.MsgType != "databases" || fmt.Sprint(last.Payload) != fmt.Sprintf("[%s-db]", conid) {
+				t.Errorf("%s got databases %v", conid, last.Payload)
+			}
+
+			connection.Refresh(conid)
+			connection.Ping(conid)
+			connection.Stop(conid)
+			if _, ok := <-w.Messages(); ok {
+				t.Errorf("%s the databases did not change, nothing more should be sent", conid)
+			}
+		}(fmt.Sprintf("conid-%d", i))
+	}
+	wg.Wait()
+
+	for conid, session := range sessions {
+		if !session.closed.Load() || connection.Worker(conid) != nil {
+			t.Fatalf("stopping %s should close its driver", conid)
+		}
+	}
+
+	w := connection.Start("broken", map[string]interface{}{"conid": "broken"})
+	var failed bool
+	for message := range w.Messages() {
+		if message.Err != nil {
+			failed = true
+		}
+	}
+	if !failed || connection.Worker("broken") != nil {
+		t.Fatalf("a failed connection should send the error and exit")
+	}
+}
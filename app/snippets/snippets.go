@@ -0,0 +1,267 @@
+package snippets
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/samber/lo"
+	"tinydb/app/utility"
+)
+
+const (
+	KindSql   = "sql"
+	KindMongo = "mongo"
+)
+
+// SavedQuery is a named query or snippet kept in queries.jsonl of the data directory.
+type SavedQuery struct {
+	Id   string `json:"_id"`
+	Name string `json:"name"`
+	// Folder 使用 / 分隔的多级目录，空字符串表示根目录
+	Folder      string           `json:"folder"`
+	Kind        string           `json:"kind"`
+	Content     string           `json:"content"`
+	Description string           `json:"description"`
+	Conid       string           `json:"conid"`
+	Database    string           `json:"database"`
+	IsSnippet   bool             `json:"isSnippet"`
+	CreatedAt   utility.UnixTime `json:"createdAt"`
+	UpdatedAt   utility.UnixTime `json:"updatedAt"`
+}
+
+// Filter selects saved queries, zero values match everything.
+type Filter struct {
+	Folder   string `json:"folder"`
+	Conid    string `json:"conid"`
+	Database string `json:"database"`
+	Text     string `json:"text"`
+	// Snippets 为 nil 时同时返回查询和片段
+	Snippets *bool `json:"snippets"`
+}
+
+var (
+	mu           sync.Mutex
+	database     *utility.JsonLinesDatabase
+	databaseFile string
+)
+
+func store() *utility.JsonLinesDatabase {
+	filename := filepath.Join(utility.DataDir(), "queries.jsonl")
+	if database == nil || databaseFile != filename {
+		database = utility.NewJsonLinesDatabase(filename)
+		databaseFile = filename
+	}
+	return database
+}
+
+// List returns the saved queries matching the filter ordered by folder and name.
+func List(filter *Filter) []*SavedQuery {
+	mu.Lock()
+	defer mu.Unlock()
+
+	if filter == nil {
+		filter = &Filter{}
+	}
+	folder := normalizeFolder(filter.Folder)
+	terms := strings.Fields(strings.ToLower(filter.Text))
+	result := make([]*SavedQuery, 0)
+	for _, obj := range store().Find() {
+		query := toQuery(obj)
+		if folder != "" && query.Folder != folder && !strings.HasPrefix(query.Folder, folder+"/") {
+			continue
+		}
+		if filter.Conid != "" && query.Conid != filter.Conid {
+			continue
+		}
+		if filter.Database != "" && query.Database != filter.Database {
+			continue
+		}
+		if filter.Snippets != nil && query.IsSnippet != *filter.Snippets {
+			continue
+		}
+		if !matches(query, terms) {
+			continue
+		}
+		result = append(result, query)
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		if result[i].Folder != result[j].Folder {
+			return result[i].Folder < result[j].Folder
+		}
+		return strings.ToLower(result[i].Name) < strings.ToLower(result[j].Name)
+	})
+	return result
+}
+
+// matches 每个搜索词都需要出现在名称、目录、描述或内容中
+func matches(query *SavedQuery, terms []string) bool {
+	if len(terms) == 0 {
+		return true
+	}
+	text := strings.ToLower(strings.Join([]string{query.Name, query.Folder, query.Description, query.Content}, "\n"))
+	for _, term := range terms {
+		if !strings.Contains(text, term) {
+			return false
+		}
+	}
+	return true
+}
+
+// Folders returns every folder that contains at least one saved query, including parents.
+func Folders() []string {
+	seen := make(map[string]bool)
+	for _, query := range List(nil) {
+		parts := strings.Split(query.Folder, "/")
+		for i := range parts {
+			if folder := strings.Join(parts[:i+1], "/"); folder != "" {
+				seen[folder] = true
+			}
+		}
+	}
+	folders := make([]string, 0, len(seen))
+	for folder := range seen {
+		folders = append(folders, folder)
+	}
+	sort.Strings(folders)
+	return folders
+}
+
+func Get(id string) *SavedQuery {
+	mu.Lock()
+	defer mu.Unlock()
+	obj := store().Get(id)
+	if obj == nil {
+		return nil
+	}
+	return toQuery(obj)
+}
+
+// Save creates the query when it has no id and updates it otherwise.
+func Save(query *SavedQuery) (*SavedQuery, error) {
+	if strings.TrimSpace(query.Name) == "" {
+		return nil, fmt.Errorf("name is required")
+	}
+	if query.Kind == "" {
+		query.Kind = KindSql
+	}
+	if query.Kind != KindSql && query.Kind != KindMongo {
+		return nil, fmt.Errorf("unsupported query kind '%s'", query.Kind)
+	}
+	query.Name = strings.TrimSpace(query.Name)
+	query.Folder = normalizeFolder(query.Folder)
+	query.UpdatedAt = utility.NewUnixTime()
+
+	mu.Lock()
+	defer mu.Unlock()
+	records := store()
+	if query.Id == "" {
+		query.CreatedAt = query.UpdatedAt
+		obj := toMap(query)
+		delete(obj, "_id")
+		inserted, err := records.Insert(obj)
+		if err != nil {
+			return nil, err
+		}
+		return toQuery(inserted), nil
+	}
+
+	existing := records.Get(query.Id)
+	if existing == nil {
+		return nil, fmt.Errorf("saved query '%s' not found", query.Id)
+	}
+	query.CreatedAt = toQuery(existing).CreatedAt
+	if _, err := records.Update(toMap(query)); err != nil {
+		return nil, err
+	}
+	return query, nil
+}
+
+func Delete(id string) error {
+	mu.Lock()
+	defer mu.Unlock()
+	_, err := store().Remove(id)
+	return err
+}
+
+func normalizeFolder(folder string) string {
+	parts := strings.FieldsFunc(strings.ReplaceAll(folder, "\\", "/"), func(r rune) bool {
+		return r == '/'
+	})
+	for i, part := range parts {
+		parts[i] = strings.TrimSpace(part)
+	}
+	return strings.Join(parts, "/")
+}
+
+var placeholderPattern = regexp.MustCompile(`\$\{\s*([A-Za-z_][A-Za-z0-9_.]*)\s*(?::([^}]*))?\}`)
+
+// Placeholder is a ${name} or ${name:default} variable found in a snippet.
+type Placeholder struct {
+	Name       string `json:"name"`
+	Default    string `json:"default"`
+	HasDefault bool   `json:"hasDefault"`
+}
+
+// Placeholders lists the distinct variables of a snippet in order of appearance.
+func Placeholders(content string) []*Placeholder {
+	seen := make(map[string]bool)
+	result := make([]*Placeholder, 0)
+	for _, match := range placeholderPattern.FindAllStringSubmatchIndex(content, -1) {
+		name := content[match[2]:match[3]]
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+		placeholder := &Placeholder{Name: name}
+		if match[4] >= 0 {
+			placeholder.Default = content[match[4]:match[5]]
+			placeholder.HasDefault = true
+		}
+		result = append(result, placeholder)
+	}
+	return result
+}
+
+// Render fills the placeholders with values, falling back to their defaults.
+func Render(content string, values map[string]string) (string, error) {
+	var missing []string
+	rendered := placeholderPattern.ReplaceAllStringFunc(content, func(match string) string {
+		groups := placeholderPattern.FindStringSubmatch(match)
+		if value, ok := values[groups[1]]; ok {
+			return value
+		}
+		if strings.Contains(match, ":") {
+			return groups[2]
+		}
+		if !lo.Contains(missing, groups[1]) {
+			missing = append(missing, groups[1])
+		}
+		return match
+	})
+	if len(missing) > 0 {
+		return "", fmt.Errorf("missing values for %s", strings.Join(missing, ", "))
+	}
+	return rendered, nil
+}
+
+func toQuery(obj map[string]interface{}) *SavedQuery {
+	query := &SavedQuery{}
+	if marshal, err := json.Marshal(obj); err == nil {
+		_ = json.Unmarshal(marshal, query)
+	}
+	return query
+}
+
+func toMap(query *SavedQuery) map[string]interface{} {
+	obj := make(map[string]interface{})
+	if marshal, err := json.Marshal(query); err == nil {
+		_ = json.Unmarshal(marshal, &obj)
+	}
+	return obj
+}
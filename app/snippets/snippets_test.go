@@ -0,0 +1,64 @@
+package snippets
+
+import "testing"
+
+func TestSaveListDelete(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("USERPROFILE", t.TempDir())
+
+	saved, err := Save(&SavedQuery{Name: "Top orders", Folder: "/reports//daily/", Content: "select * from orders order by total desc", Conid: "c1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if saved.Id == "" || saved.Folder != "reports/daily" || saved.Kind != KindSql {
+		t.Fatalf("unexpected saved query %+v", saved)
+	}
+	if _, err = Save(&SavedQuery{Name: "users by id", Kind: KindMongo, Content: `{"_id": "${id}"}`, IsSnippet: true}); err != nil {
+		t.Fatal(err)
+	}
+
+	if list := List(&Filter{Folder: "reports"}); len(list) != 1 || list[0].Id != saved.Id {
+		t.Fatalf("unexpected folder list %+v", list)
+	}
+	if list := List(&Filter{Text: "ORDERS total"}); len(list) != 1 {
+		t.Fatalf("unexpected search result %+v", list)
+	}
+	if folders := Folders(); len(folders) != 2 || folders[0] != "reports" || folders[1] != "reports/daily" {
+		t.Fatalf("unexpected folders %v", folders)
+	}
+
+	saved.Name = "Top orders v2"
+	if _, err = Save(saved); err != nil {
+		t.Fatal(err)
+	}
+	if got := Get(saved.Id); got == nil || got.Name != "Top orders v2" || got.CreatedAt != saved.CreatedAt {
+		t.Fatalf("unexpected updated query %+v", got)
+	}
+
+	if err = Delete(saved.Id); err != nil {
+		t.Fatal(err)
+	}
+	if list := List(nil); len(list) != 1 {
+		t.Fatalf("expected 1 query after delete, got %d", len(list))
+	}
+}
+
+func TestRender(t *testing.T) {
+	content := "select * from ${table} where status = '${status:active}' and id = ${ id } and owner = ${id}"
+	placeholders := Placeholders(content)
+	if len(placeholders) != 3 || placeholders[1].Name != "status" || !placeholders[1].HasDefault || placeholders[1].Default != "active" {
+		t.Fatalf("unexpected placeholders %+v", placeholders)
+	}
+
+	rendered, err := Render(content, map[string]string{"table": "users", "id": "7"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rendered != "select * from users where status = 'active' and id = 7 and owner = 7" {
+		t.Fatalf("unexpected rendered %q", rendered)
+	}
+
+	if _, err = Render(content, map[string]string{"table": "users"}); err == nil || err.Error() != "missing values for id" {
+		t.Fatalf("unexpected error %v", err)
+	}
+}
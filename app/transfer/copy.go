@@ -0,0 +1,348 @@
+package transfer
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/samber/lo"
+	"go.mongodb.org/mongo-driver/bson"
+	"tinydb/app/analyser/mysqlAnalyser"
+	"tinydb/app/db"
+	"tinydb/app/db/adapter/mongo"
+	"tinydb/app/db/adapter/mysql"
+	"tinydb/app/db/standard/modules"
+)
+
+const (
+	CopyModeAppend   = "append"
+	CopyModeTruncate = "truncate"
+	CopyModeUpsert   = "upsert"
+)
+
+const defaultCopyBatchSize = 1000
+
+type CopyEndpoint struct {
+	Conid    string `json:"conid"`
+	Database string `json:"database"`
+	PureName string `json:"pureName"`
+}
+
+type CopyOptions struct {
+	Source CopyEndpoint `json:"source"`
+	Target CopyEndpoint `json:"target"`
+	// Condition 仅在源为 Mongo 集合时生效
+	Condition map[string]interface{} `json:"condition"`
+	Mode      string                 `json:"mode"`
+	// KeyColumns 为空时使用源表主键，Mongo 源默认为 _id
+	KeyColumns []string `json:"keyColumns"`
+	BatchSize  int      `json:"batchSize"`
+}
+
+// copySchema is what is known about the source before the target is prepared.
+type copySchema struct {
+	// columns comes from the analyser for MySQL sources and is nil for collections.
+	columns    []*modules.TransformColumnInfo
+	primaryKey []string
+	sample     []map[string]interface{}
+}
+
+func (s *copySchema) columnNames() []string {
+	if s.columns != nil {
+		return lo.Map(s.columns, func(column *modules.TransformColumnInfo, _ int) string {
+			return column.ColumnName
+		})
+	}
+	return DocumentColumns(s.sample)
+}
+
+type copyTarget interface {
+	prepare(ctx context.Context, schema *copySchema) error
+	write(ctx context.Context, rows []map[string]interface{}) error
+	close() error
+}
+
+// Copy streams a table or collection into another one, possibly on another
+// connection or engine, creating the target when it does not exist.
+func Copy(ctx context.Context, job *Job, source, target db.Session, opt *CopyOptions) error {
+	mode := opt.Mode
+	if mode == "" {
+		mode = CopyModeAppend
+	}
+	if mode != CopyModeAppend && mode != CopyModeTruncate && mode != CopyModeUpsert {
+		return fmt.Errorf("unsupported copy mode '%s'", mode)
+	}
+	batchSize := opt.BatchSize
+	if batchSize <= 0 {
+		batchSize = defaultCopyBatchSize
+	}
+
+	schema := &copySchema{}
+	if mysqlSource, ok := source.(*mysql.Source); ok {
+		columns, primaryKey, err := mysqlAnalyser.NewAnalyser(mysqlSource, opt.Source.Database).TableInfo(opt.Source.PureName)
+		if err != nil {
+			return err
+		}
+		if len(columns) == 0 {
+			return fmt.Errorf("table '%s' not found", opt.Source.PureName)
+		}
+		schema.columns, schema.primaryKey = columns, primaryKey
+	} else {
+		schema.primaryKey = []string{"_id"}
+	}
+
+	keys := opt.KeyColumns
+	if len(keys) == 0 {
+		keys = schema.primaryKey
+	}
+	if mode == CopyModeUpsert && len(keys) == 0 {
+		return fmt.Errorf("key columns are required for upsert")
+	}
+
+	var writer copyTarget
+	switch t := target.(type) {
+	case *mysql.Source:
+		writer = &mysqlCopyTarget{source: t, endpoint: &opt.Target, mode: mode, keys: opt.KeyColumns}
+	case *mongo.Source:
+		writer = &mongoCopyTarget{source: t, endpoint: &opt.Target, mode: mode, keys: keys}
+	default:
+		return fmt.Errorf("copy into %s: %w", target.Dialect(), db.ErrNotSupportedByAdapter)
+	}
+	defer writer.close()
+
+	var rows int64
+	prepared := false
+	batch := make([]map[string]interface{}, 0, batchSize)
+	flush := func() error {
+		if !prepared {
+			prepared = true
+			schema.sample = batch
+			if err := writer.prepare(ctx, schema); err != nil {
+				return err
+			}
+		}
+		if len(batch) == 0 {
+			return nil
+		}
+		if err := writer.write(ctx, batch); err != nil {
+			return err
+		}
+		rows += int64(len(batch))
+		batch = make([]map[string]interface{}, 0, batchSize)
+		done := rows
+		job.Update(func(p *Progress) {
+			p.Rows = done
+		})
+		return nil
+	}
+	add := func(row map[string]interface{}) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		batch = append(batch, row)
+		if len(batch) >= batchSize {
+			return flush()
+		}
+		return nil
+	}
+
+	var err error
+	switch s := source.(type) {
+	case *mysql.Source:
+		err = s.Stream(ctx, "SELECT * FROM "+QuoteIdentifier(opt.Source.PureName), func(_ []string, row map[string]interface{}) error {
+			return add(row)
+		})
+	case *mongo.Source:
+		err = s.StreamCollection(ctx, opt.Source.Database, &modules.CollectionDataOptions{
+			PureName:  opt.Source.PureName,
+			Condition: opt.Condition,
+		}, func(doc bson.M) error {
+			return add(doc)
+		})
+	default:
+		err = fmt.Errorf("copy from %s: %w", source.Dialect(), db.ErrNotSupportedByAdapter)
+	}
+	if err != nil {
+		return err
+	}
+	return flush()
+}
+
+type mysqlCopyTarget struct {
+	source   *mysql.Source
+	endpoint *CopyEndpoint
+	mode     string
+	// keys 用户指定的 KeyColumns，新建的表以它为主键。MySQL 的 upsert 按目标表的
+	// 主键匹配，已有的表只接受与主键相同的 keys
+	keys    []string
+	conn    *sql.Conn
+	columns []string
+}
+
+func (t *mysqlCopyTarget) prepare(ctx context.Context, schema *copySchema) error {
+	conn, err := t.source.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	t.conn = conn
+
+	columns, primaryKey, err := mysqlAnalyser.NewAnalyser(t.source, t.endpoint.Database).TableInfo(t.endpoint.PureName)
+	if err != nil {
+		return err
+	}
+
+	sourceColumns := schema.columnNames()
+	if len(sourceColumns) == 0 {
+		// 空集合无法推断表结构，也没有数据需要写入
+		return nil
+	}
+	if missing := lo.Without(t.keys, sourceColumns...); len(missing) > 0 {
+		return fmt.Errorf("key columns %s are not in the source", strings.Join(missing, ", "))
+	}
+	if len(columns) == 0 {
+		createSql := t.createSql(schema)
+		if _, err = conn.ExecContext(ctx, createSql); err != nil {
+			return fmt.Errorf("failed to create table '%s': %w", t.endpoint.PureName, err)
+		}
+		t.columns = sourceColumns
+		return nil
+	}
+
+	// 目标表已存在时只写入两边都有的列
+	t.columns = lo.Intersect(sourceColumns, lo.Map(columns, func(column *modules.TransformColumnInfo, _ int) string {
+		return column.ColumnName
+	}))
+	if len(t.columns) == 0 {
+		return fmt.Errorf("table '%s' has no column in common with the source", t.endpoint.PureName)
+	}
+	if t.mode == CopyModeUpsert && len(t.keys) > 0 && !lo.ElementsMatch(t.keys, primaryKey) {
+		return fmt.Errorf("upsert into '%s' matches rows on its primary key (%s), key columns %s are not supported",
+			t.endpoint.PureName, strings.Join(primaryKey, ", "), strings.Join(t.keys, ", "))
+	}
+	if t.mode == CopyModeTruncate {
+		_, err = conn.ExecContext(ctx, "TRUNCATE TABLE "+QuoteIdentifier(t.endpoint.PureName))
+	}
+	return err
+}
+
+func (t *mysqlCopyTarget) createSql(schema *copySchema) string {
+	if schema.columns != nil {
+		primaryKey := schema.primaryKey
+		if len(t.keys) > 0 {
+			primaryKey = t.keys
+		}
+		return CreateTableSql(t.endpoint.PureName, schema.columns, primaryKey)
+	}
+
+	// 集合没有固定结构，按样本推断列类型
+	inferred := InferColumns(DocumentColumns(schema.sample), schema.sample)
+	columns := lo.Map(inferred, func(column *InferredColumn, _ int) *modules.TransformColumnInfo {
+		dataType := column.DataType
+		if column.CharMaxLength > 0 {
+			dataType = fmt.Sprintf("%s(%d)", dataType, column.CharMaxLength)
+		}
+		return &modules.TransformColumnInfo{ColumnName: column.ColumnName, DataType: dataType}
+	})
+	var primaryKey []string
+	if len(t.keys) > 0 {
+		primaryKey = t.keys
+	} else if lo.ContainsBy(columns, func(column *modules.TransformColumnInfo) bool { return column.ColumnName == "_id" }) {
+		primaryKey = []string{"_id"}
+	}
+	return CreateTableSql(t.endpoint.PureName, columns, primaryKey)
+}
+
+func (t *mysqlCopyTarget) write(ctx context.Context, rows []map[string]interface{}) error {
+	size := maxPlaceholders / len(t.columns)
+	for _, chunk := range lo.Chunk(rows, size) {
+		query, args := insertSql(t.endpoint.PureName, t.columns, chunk, t.mode == CopyModeUpsert)
+		if _, err := t.conn.ExecContext(ctx, query, args...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (t *mysqlCopyTarget) close() error {
+	if t.conn == nil {
+		return nil
+	}
+	return t.conn.Close()
+}
+
+type mongoCopyTarget struct {
+	source   *mongo.Source
+	endpoint *CopyEndpoint
+	mode     string
+	keys     []string
+}
+
+func (t *mongoCopyTarget) prepare(ctx context.Context, _ *copySchema) error {
+	if err := t.source.CreateCollection(ctx, t.endpoint.Database, t.endpoint.PureName); err != nil {
+		return err
+	}
+	if t.mode == CopyModeTruncate {
+		_, err := t.source.DeleteDocuments(ctx, t.endpoint.Database, t.endpoint.PureName, bson.M{})
+		return err
+	}
+	return nil
+}
+
+func (t *mongoCopyTarget) write(ctx context.Context, rows []map[string]interface{}) error {
+	docs := lo.Map(rows, func(row map[string]interface{}, _ int) bson.M {
+		doc := make(bson.M, len(row))
+		for key, value := range row {
+			if b, ok := value.([]byte); ok {
+				value = string(b)
+			}
+			doc[key] = value
+		}
+		return doc
+	})
+
+	if t.mode == CopyModeUpsert {
+		return t.source.UpsertDocuments(ctx, t.endpoint.Database, t.endpoint.PureName, t.keys, docs)
+	}
+	return t.source.InsertDocuments(ctx, t.endpoint.Database, t.endpoint.PureName, lo.ToAnySlice(docs))
+}
+
+func (t *mongoCopyTarget) close() error {
+	return nil
+}
+
+// CreateTableSql renders a CREATE TABLE statement from analyser column info.
+func CreateTableSql(table string, columns []*modules.TransformColumnInfo, primaryKey []string) string {
+	var query strings.Builder
+	query.WriteString("CREATE TABLE " + QuoteIdentifier(table) + " (\n")
+	for i, column := range columns {
+		if i > 0 {
+			query.WriteString(",\n")
+		}
+		dataType := column.ColumnType
+		if dataType == "" {
+			dataType = column.DataType
+			if column.IsUnsigned {
+				dataType += " unsigned"
+			}
+		}
+		query.WriteString("  " + QuoteIdentifier(column.ColumnName) + " " + dataType)
+		if column.NotNull || lo.Contains(primaryKey, column.ColumnName) {
+			query.WriteString(" NOT NULL")
+		}
+		if column.AutoIncrement {
+			query.WriteString(" AUTO_INCREMENT")
+		}
+		if column.ColumnComment != "" {
+			query.WriteString(" COMMENT " + SqlLiteral(column.ColumnComment))
+		}
+	}
+	if len(primaryKey) > 0 {
+		keys := lo.Map(primaryKey, func(key string, _ int) string {
+			return QuoteIdentifier(key)
+		})
+		query.WriteString(",\n  PRIMARY KEY (" + strings.Join(keys, ", ") + ")")
+	}
+	query.WriteString("\n) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4")
+	return query.String()
+}
@@ -0,0 +1,43 @@
+package transfer
+
+import (
+	"strings"
+	"testing"
+
+	"tinydb/app/db/standard/modules"
+)
+
+func TestCreateTableSql(t *testing.T) {
+	columns := []*modules.TransformColumnInfo{
+		{ColumnName: "id", DataType: "int", ColumnType: "int unsigned", NotNull: true, AutoIncrement: true},
+		{ColumnName: "status", DataType: "enum", ColumnType: "enum('on','off')", ColumnComment: "it's"},
+		{ColumnName: "code", DataType: "varchar(32)"},
+	}
+	expected := "CREATE TABLE `user` (\n" +
+		"  `id` int unsigned NOT NULL AUTO_INCREMENT,\n" +
+		"  `status` enum('on','off') COMMENT 'it''s',\n" +
+		"  `code` varchar(32) NOT NULL,\n" +
+		"  PRIMARY KEY (`id`, `code`)\n" +
+		") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4"
+	if sql := CreateTableSql("user", columns, []string{"id", "code"}); sql != expected {
+		t.Fatalf("unexpected create sql %q", sql)
+	}
+}
+
+func TestCopyCreateSqlKeys(t *testing.T) {
+	schema := &copySchema{
+		columns: []*modules.TransformColumnInfo{
+			{ColumnName: "id", DataType: "int", NotNull: true},
+			{ColumnName: "code", DataType: "varchar(32)"},
+		},
+		primaryKey: []string{"id"},
+	}
+	target := &mysqlCopyTarget{endpoint: &CopyEndpoint{PureName: "copy"}}
+	if sql := target.createSql(schema); !strings.Contains(sql, "PRIMARY KEY (`id`)") {
+		t.Fatalf("the source primary key should be used, got %q", sql)
+	}
+	target.keys = []string{"code"}
+	if sql := target.createSql(schema); !strings.Contains(sql, "PRIMARY KEY (`code`)") {
+		t.Fatalf("the key columns should become the primary key, got %q", sql)
+	}
+}
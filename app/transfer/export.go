@@ -0,0 +1,158 @@
+package transfer
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"tinydb/app/db"
+	"tinydb/app/db/adapter/mongo"
+	"tinydb/app/db/adapter/mysql"
+	"tinydb/app/db/script"
+	"tinydb/app/db/standard/modules"
+)
+
+// progressInterval 每写出多少行通知一次前端
+const progressInterval = 1000
+
+type ExportOptions struct {
+	WriterOptions
+	Sql       string                 `json:"sql"`
+	PureName  string                 `json:"pureName"`
+	Condition map[string]interface{} `json:"condition"`
+}
+
+// Export streams a query result, a whole table or a collection into a file.
+func Export(ctx context.Context, job *Job, driver db.Session, database string, opt *ExportOptions) error {
+	writerOptions := opt.WriterOptions
+	if writerOptions.TableName == "" {
+		writerOptions.TableName = opt.PureName
+	}
+	writer, err := NewRowWriter(&writerOptions)
+	if err != nil {
+		return err
+	}
+
+	sink := &rowSink{writer: writer, job: job}
+	switch source := driver.(type) {
+	case *mysql.Source:
+		query := opt.Sql
+		if query == "" {
+			query = "SELECT * FROM " + QuoteIdentifier(opt.PureName)
+		} else if err = CheckExportSql(query); err != nil {
+			break
+		}
+		err = source.Stream(ctx, query, func(columns []string, row map[string]interface{}) error {
+			return sink.write(ctx, columns, row)
+		})
+	case *mongo.Source:
+		collection := &modules.CollectionDataOptions{
+			PureName:  opt.PureName,
+			Condition: opt.Condition,
+		}
+		// 文档的键不固定，先在服务端汇总全部文档的键作为表头，避免丢掉后面才出现的字段
+		var keys []string
+		if keys, err = source.CollectionKeys(ctx, database, collection); err == nil {
+			columns := sortColumns(keys)
+			err = source.StreamCollection(ctx, database, collection, func(doc bson.M) error {
+				return sink.write(ctx, columns, doc)
+			})
+		}
+	default:
+		err = fmt.Errorf("export from %s: %w", driver.Dialect(), db.ErrNotSupportedByAdapter)
+	}
+
+	if closeErr := sink.close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// CheckExportSql 导出只执行单条只读查询，写入语句不能绕过只读连接和写入确认的检查
+func CheckExportSql(sql string) error {
+	if statements := script.Split(sql); len(statements) != 1 {
+		return fmt.Errorf("export runs a single query, got %d statements", len(statements))
+	}
+	if script.Classify(sql) != script.StatementRead {
+		return fmt.Errorf("export only runs read queries")
+	}
+	return nil
+}
+
+// rowSink opens the writer lazily once the columns are known and reports progress.
+type rowSink struct {
+	writer  RowWriter
+	job     *Job
+	opened  bool
+	columns []string
+	rows    int64
+}
+
+func (s *rowSink) write(ctx context.Context, columns []string, row map[string]interface{}) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if !s.opened {
+		if err := s.open(columns); err != nil {
+			return err
+		}
+	}
+	if err := s.writer.Write(row); err != nil {
+		return err
+	}
+	s.rows++
+	if s.rows%progressInterval == 0 {
+		rows := s.rows
+		s.job.Update(func(p *Progress) {
+			p.Rows = rows
+		})
+	}
+	return nil
+}
+
+func (s *rowSink) open(columns []string) error {
+	s.opened = true
+	s.columns = columns
+	return s.writer.Open(columns)
+}
+
+func (s *rowSink) close() error {
+	if !s.opened {
+		if err := s.open(nil); err != nil {
+			_ = s.writer.Close()
+			return err
+		}
+	}
+	rows := s.rows
+	s.job.Update(func(p *Progress) {
+		p.Rows = rows
+	})
+	return s.writer.Close()
+}
+
+// DocumentColumns returns the union of the keys of the documents, with _id first.
+func DocumentColumns(docs []map[string]interface{}) []string {
+	seen := make(map[string]bool)
+	var columns []string
+	for _, doc := range docs {
+		for key := range doc {
+			if !seen[key] {
+				seen[key] = true
+				columns = append(columns, key)
+			}
+		}
+	}
+	return sortColumns(columns)
+}
+
+// sortColumns orders document keys by name with _id first.
+func sortColumns(columns []string) []string {
+	sort.SliceStable(columns, func(i, j int) bool {
+		if columns[i] == "_id" || columns[j] == "_id" {
+			return columns[i] == "_id"
+		}
+		return columns[i] < columns[j]
+	})
+	return columns
+}
@@ -0,0 +1,22 @@
+package transfer
+
+import "testing"
+
+func TestCheckExportSql(t *testing.T) {
+	for _, sql := range []string{"select * from users", "with c as (select 1) select * from c", "select 1;"} {
+		if err := CheckExportSql(sql); err != nil {
+			t.Fatalf("%q should be exported, got %v", sql, err)
+		}
+	}
+	for _, sql := range []string{
+		"delete from users",
+		"drop table users",
+		"update users set name = 'a'",
+		"select 1; delete from users",
+		"",
+	} {
+		if err := CheckExportSql(sql); err == nil {
+			t.Fatalf("%q should be refused", sql)
+		}
+	}
+}
@@ -0,0 +1,465 @@
+package transfer
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	mongodriver "go.mongodb.org/mongo-driver/mongo"
+	"tinydb/app/db"
+	"tinydb/app/db/adapter/mongo"
+	"tinydb/app/db/adapter/mysql"
+	"tinydb/app/settings"
+)
+
+const defaultImportBatchSize = 500
+
+const defaultPreviewRows = 20
+
+// maxPlaceholders MySQL 单条语句允许的最大占位符数量
+const maxPlaceholders = 65535
+
+// FieldMapping maps a field of the file to a target column, DataType is
+// optional and converts the raw value before it is inserted.
+type FieldMapping struct {
+	Source   string `json:"source"`
+	Target   string `json:"target"`
+	DataType string `json:"dataType"`
+}
+
+type ImportOptions struct {
+	ReaderOptions
+	PureName string          `json:"pureName"`
+	Mapping  []*FieldMapping `json:"mapping"`
+	// CreateTable 为 true 时先按 Columns 建表（Mongo 为建集合）
+	CreateTable bool                     `json:"createTable"`
+	Columns     []map[string]interface{} `json:"columns"`
+	// CreateSql is filled by the caller from Columns for MySQL targets.
+	CreateSql string `json:"-"`
+	BatchSize int    `json:"batchSize"`
+	OnError   string `json:"onError"`
+	// DryRun 只校验不落库，MySQL 在事务里执行后回滚
+	DryRun bool `json:"dryRun"`
+}
+
+type ImportPreview struct {
+	Columns []string                 `json:"columns"`
+	Rows    []map[string]interface{} `json:"rows"`
+	Types   []*InferredColumn        `json:"types"`
+}
+
+type ImportResult struct {
+	Inserted int64 `json:"inserted"`
+	Failed   int64 `json:"failed"`
+	DryRun   bool  `json:"dryRun"`
+}
+
+// Preview returns the first rows of a file together with the inferred column types.
+func Preview(opt *ReaderOptions, limit int) (*ImportPreview, error) {
+	if limit <= 0 {
+		limit = defaultPreviewRows
+	}
+	reader, err := NewRowReader(opt)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	// 推断列类型时最多读取的行数来自设置
+	sampleSize := settings.Get().AnalyserSampleSize
+	sample := make([]map[string]interface{}, 0, limit)
+	for len(sample) < sampleSize {
+		row, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		sample = append(sample, row)
+	}
+
+	columns := reader.Columns()
+	if columns == nil {
+		columns = DocumentColumns(sample)
+	}
+	rows := sample
+	if len(rows) > limit {
+		rows = rows[:limit]
+	}
+	return &ImportPreview{
+		Columns: columns,
+		Rows:    rows,
+		Types:   InferColumns(columns, sample),
+	}, nil
+}
+
+// importRow is a converted record waiting to be inserted.
+type importRow struct {
+	index  int64
+	values map[string]interface{}
+}
+
+type importTarget interface {
+	prepare(ctx context.Context) error
+	// insert writes the batch and returns the rows that failed.
+	insert(ctx context.Context, rows []*importRow) (map[int64]error, error)
+	finish(commit bool) error
+}
+
+// Import reads a CSV, JSON or XLSX file and bulk inserts it into a MySQL table
+// or a Mongo collection, reporting every rejected row on the job.
+func Import(ctx context.Context, job *Job, driver db.Session, database string, opt *ImportOptions) error {
+	reader, err := NewRowReader(&opt.ReaderOptions)
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	if stat, err := os.Stat(opt.FilePath); err == nil && opt.Format != FormatXlsx {
+		job.Update(func(p *Progress) {
+			p.BytesTotal = stat.Size()
+		})
+	}
+
+	mapping := opt.Mapping
+	if len(mapping) == 0 {
+		for _, column := range reader.Columns() {
+			mapping = append(mapping, &FieldMapping{Source: column, Target: column})
+		}
+	}
+
+	var target importTarget
+	switch source := driver.(type) {
+	case *mysql.Source:
+		if len(mapping) == 0 {
+			return fmt.Errorf("field mapping is required to import documents into a table")
+		}
+		target = &mysqlImportTarget{source: source, opt: opt, mapping: mapping}
+	case *mongo.Source:
+		target = &mongoImportTarget{source: source, database: database, opt: opt}
+	default:
+		return fmt.Errorf("import into %s: %w", driver.Dialect(), db.ErrNotSupportedByAdapter)
+	}
+
+	batchSize := opt.BatchSize
+	if batchSize <= 0 {
+		batchSize = defaultImportBatchSize
+	}
+	if len(mapping) > 0 && batchSize*len(mapping) > maxPlaceholders {
+		batchSize = maxPlaceholders / len(mapping)
+	}
+
+	if err = target.prepare(ctx); err != nil {
+		return err
+	}
+
+	result := &ImportResult{DryRun: opt.DryRun}
+	committed := false
+	defer func() {
+		if !committed {
+			_ = target.finish(false)
+		}
+	}()
+
+	var index int64
+	batch := make([]*importRow, 0, batchSize)
+	rejected := func(index int64, raw interface{}, cause error) error {
+		result.Failed++
+		job.AddError(&ItemError{
+			Index:     index,
+			Statement: truncate(FormatValue(raw), maxErrorStatementLength),
+			Message:   cause.Error(),
+		})
+		if opt.OnError != ErrorPolicyContinue {
+			return fmt.Errorf("row %d failed: %w", index, cause)
+		}
+		return nil
+	}
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		failed, err := target.insert(ctx, batch)
+		if err != nil {
+			return err
+		}
+		for _, row := range batch {
+			if cause, ok := failed[row.index]; ok {
+				if err = rejected(row.index, row.values, cause); err != nil {
+					return err
+				}
+				continue
+			}
+			result.Inserted++
+		}
+		batch = batch[:0]
+		inserted, offset := result.Inserted, reader.Offset()
+		job.Update(func(p *Progress) {
+			p.Rows = inserted
+			p.BytesDone = offset
+		})
+		return nil
+	}
+
+	for {
+		if err = ctx.Err(); err != nil {
+			return err
+		}
+		raw, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		index++
+
+		values, err := mapRow(raw, mapping)
+		if err != nil {
+			if err = rejected(index, raw, err); err != nil {
+				return err
+			}
+			continue
+		}
+		batch = append(batch, &importRow{index: index, values: values})
+		if len(batch) >= batchSize {
+			if err = flush(); err != nil {
+				return err
+			}
+		}
+	}
+	if err = flush(); err != nil {
+		return err
+	}
+
+	committed = true
+	if err = target.finish(!opt.DryRun); err != nil {
+		return err
+	}
+	job.Update(func(p *Progress) {
+		p.BytesDone = p.BytesTotal
+		p.Result = result
+	})
+	return nil
+}
+
+// mapRow applies the field mapping, without mapping the record is kept as is.
+func mapRow(raw map[string]interface{}, mapping []*FieldMapping) (map[string]interface{}, error) {
+	if len(mapping) == 0 {
+		return raw, nil
+	}
+	values := make(map[string]interface{}, len(mapping))
+	for _, field := range mapping {
+		target := field.Target
+		if target == "" {
+			target = field.Source
+		}
+		value, err := ConvertValue(raw[field.Source], field.DataType)
+		if err != nil {
+			return nil, fmt.Errorf("field '%s': %w", field.Source, err)
+		}
+		values[target] = value
+	}
+	return values, nil
+}
+
+type mysqlImportTarget struct {
+	source  *mysql.Source
+	opt     *ImportOptions
+	mapping []*FieldMapping
+	conn    *sql.Conn
+	tx      *sql.Tx
+	// validateOnly 试运行且目标表尚未创建时只做类型转换校验
+	validateOnly bool
+	columns      []string
+}
+
+func (t *mysqlImportTarget) prepare(ctx context.Context) error {
+	for _, field := range t.mapping {
+		target := field.Target
+		if target == "" {
+			target = field.Source
+		}
+		t.columns = append(t.columns, target)
+	}
+
+	if t.opt.CreateTable && t.opt.CreateSql == "" {
+		return fmt.Errorf("columns are required to create table '%s'", t.opt.PureName)
+	}
+	if t.opt.CreateTable && t.opt.DryRun {
+		t.validateOnly = true
+		return nil
+	}
+
+	conn, err := t.source.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	t.conn = conn
+	if t.opt.CreateTable {
+		if _, err = conn.ExecContext(ctx, t.opt.CreateSql); err != nil {
+			return fmt.Errorf("failed to create table: %w", err)
+		}
+	}
+	t.tx, err = conn.BeginTx(ctx, nil)
+	return err
+}
+
+func (t *mysqlImportTarget) insert(ctx context.Context, rows []*importRow) (map[int64]error, error) {
+	if t.validateOnly {
+		return nil, nil
+	}
+	if _, err := t.tx.ExecContext(ctx, "SAVEPOINT tinydb_import"); err != nil {
+		return nil, err
+	}
+	if err := t.exec(ctx, rows); err == nil {
+		return nil, nil
+	}
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+
+	// 整批失败后逐行重试，定位具体出错的行
+	if _, err := t.tx.ExecContext(ctx, "ROLLBACK TO SAVEPOINT tinydb_import"); err != nil {
+		return nil, err
+	}
+	failed := make(map[int64]error)
+	for _, row := range rows {
+		if _, err := t.tx.ExecContext(ctx, "SAVEPOINT tinydb_import_row"); err != nil {
+			return nil, err
+		}
+		if err := t.exec(ctx, []*importRow{row}); err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			failed[row.index] = err
+			if _, err = t.tx.ExecContext(ctx, "ROLLBACK TO SAVEPOINT tinydb_import_row"); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return failed, nil
+}
+
+func (t *mysqlImportTarget) exec(ctx context.Context, rows []*importRow) error {
+	values := make([]map[string]interface{}, 0, len(rows))
+	for _, row := range rows {
+		values = append(values, row.values)
+	}
+	query, args := insertSql(t.opt.PureName, t.columns, values, false)
+	_, err := t.tx.ExecContext(ctx, query, args...)
+	return err
+}
+
+func (t *mysqlImportTarget) finish(commit bool) error {
+	if t.conn == nil {
+		return nil
+	}
+	defer t.conn.Close()
+	if t.tx == nil {
+		return nil
+	}
+	if commit {
+		return t.tx.Commit()
+	}
+	return t.tx.Rollback()
+}
+
+// insertSql builds a multi row INSERT with placeholders, upsert adds an
+// ON DUPLICATE KEY UPDATE clause for every column.
+func insertSql(table string, columns []string, rows []map[string]interface{}, upsert bool) (string, []interface{}) {
+	var query strings.Builder
+	query.WriteString("INSERT INTO ")
+	query.WriteString(QuoteIdentifier(table))
+	query.WriteString(" (")
+	for i, column := range columns {
+		if i > 0 {
+			query.WriteString(", ")
+		}
+		query.WriteString(QuoteIdentifier(column))
+	}
+	query.WriteString(") VALUES ")
+
+	placeholders := "(" + strings.TrimSuffix(strings.Repeat("?, ", len(columns)), ", ") + ")"
+	args := make([]interface{}, 0, len(rows)*len(columns))
+	for i, row := range rows {
+		if i > 0 {
+			query.WriteString(", ")
+		}
+		query.WriteString(placeholders)
+		for _, column := range columns {
+			args = append(args, sqlArgument(row[column]))
+		}
+	}
+
+	if upsert {
+		query.WriteString(" ON DUPLICATE KEY UPDATE ")
+		for i, column := range columns {
+			if i > 0 {
+				query.WriteString(", ")
+			}
+			query.WriteString(QuoteIdentifier(column) + " = VALUES(" + QuoteIdentifier(column) + ")")
+		}
+	}
+	return query.String(), args
+}
+
+// sqlArgument 嵌套对象以 JSON 文本写入，bson 类型先转换成普通值
+func sqlArgument(value interface{}) interface{} {
+	switch v := normalizeDocument(value).(type) {
+	case map[string]interface{}, []interface{}:
+		marshal, err := json.Marshal(v)
+		if err != nil {
+			return FormatValue(v)
+		}
+		return string(marshal)
+	default:
+		return v
+	}
+}
+
+type mongoImportTarget struct {
+	source   *mongo.Source
+	database string
+	opt      *ImportOptions
+}
+
+func (t *mongoImportTarget) prepare(ctx context.Context) error {
+	if !t.opt.CreateTable || t.opt.DryRun {
+		return nil
+	}
+	return t.source.CreateCollection(ctx, t.database, t.opt.PureName)
+}
+
+func (t *mongoImportTarget) insert(ctx context.Context, rows []*importRow) (map[int64]error, error) {
+	if t.opt.DryRun {
+		return nil, nil
+	}
+	docs := make([]interface{}, 0, len(rows))
+	for _, row := range rows {
+		docs = append(docs, row.values)
+	}
+
+	err := t.source.InsertDocuments(ctx, t.database, t.opt.PureName, docs)
+	var bulkErr mongodriver.BulkWriteException
+	if !errors.As(err, &bulkErr) || len(bulkErr.WriteErrors) == 0 {
+		return nil, err
+	}
+	failed := make(map[int64]error, len(bulkErr.WriteErrors))
+	for _, writeErr := range bulkErr.WriteErrors {
+		if writeErr.Index < len(rows) {
+			failed[rows[writeErr.Index].index] = errors.New(writeErr.Message)
+		}
+	}
+	return failed, nil
+}
+
+func (t *mongoImportTarget) finish(_ bool) error {
+	return nil
+}
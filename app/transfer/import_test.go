@@ -0,0 +1,17 @@
+package transfer
+
+import "testing"
+
+func TestInsertSql(t *testing.T) {
+	query, args := insertSql("user", []string{"id", "tags"}, []map[string]interface{}{
+		{"id": int64(1), "tags": []interface{}{"a"}},
+		{"id": int64(2)},
+	}, true)
+	expected := "INSERT INTO `user` (`id`, `tags`) VALUES (?, ?), (?, ?) ON DUPLICATE KEY UPDATE `id` = VALUES(`id`), `tags` = VALUES(`tags`)"
+	if query != expected {
+		t.Fatalf("unexpected query %q", query)
+	}
+	if len(args) != 4 || args[1] != `["a"]` || args[3] != nil {
+		t.Fatalf("unexpected args %v", args)
+	}
+}
@@ -0,0 +1,228 @@
+package transfer
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	TypeInt      = "INT"
+	TypeBigint   = "BIGINT"
+	TypeDouble   = "DOUBLE"
+	TypeBoolean  = "TINYINT"
+	TypeDate     = "DATE"
+	TypeDatetime = "DATETIME"
+	TypeVarchar  = "VARCHAR"
+	TypeText     = "TEXT"
+	TypeJson     = "JSON"
+)
+
+// maxVarcharLength 超过该长度的字符串列推断为 TEXT
+const maxVarcharLength = 4096
+
+var dateLayouts = []string{"2006-01-02", "2006/01/02"}
+
+var datetimeLayouts = []string{
+	timeLayout,
+	"2006-01-02T15:04:05",
+	time.RFC3339,
+	time.RFC3339Nano,
+	"2006/01/02 15:04:05",
+	"2006-01-02 15:04",
+}
+
+// InferredColumn uses the same keys as the columns accepted by CreateTable, so
+// a preview can be sent back unchanged to create the target table.
+type InferredColumn struct {
+	ColumnName       string `json:"columnName"`
+	DataType         string `json:"dataType"`
+	CharMaxLength    int    `json:"charMaxLength,omitempty"`
+	NumericPrecision int    `json:"numericPrecision,omitempty"`
+	IsNullable       string `json:"isNullable"`
+}
+
+// InferColumns guesses a MySQL type for every column from the sampled rows.
+func InferColumns(columns []string, rows []map[string]interface{}) []*InferredColumn {
+	result := make([]*InferredColumn, 0, len(columns))
+	for _, column := range columns {
+		values := make([]interface{}, 0, len(rows))
+		for _, row := range rows {
+			values = append(values, row[column])
+		}
+		inferred := InferType(values)
+		inferred.ColumnName = column
+		result = append(result, inferred)
+	}
+	return result
+}
+
+// InferType picks the narrowest type every non empty value can be converted to.
+func InferType(values []interface{}) *InferredColumn {
+	candidates := []string{TypeBoolean, TypeInt, TypeBigint, TypeDouble, TypeDate, TypeDatetime}
+	nullable := false
+	seen := false
+	maxLength := 0
+	for _, value := range values {
+		if isEmptyValue(value) {
+			nullable = true
+			continue
+		}
+		seen = true
+		switch value.(type) {
+		case map[string]interface{}, []interface{}:
+			return &InferredColumn{DataType: TypeJson, IsNullable: "YES"}
+		}
+		if length := len([]rune(FormatValue(value))); length > maxLength {
+			maxLength = length
+		}
+		candidates = filterCandidates(candidates, value)
+	}
+
+	column := &InferredColumn{IsNullable: "NO"}
+	if nullable || !seen {
+		column.IsNullable = "YES"
+	}
+	if seen && len(candidates) > 0 {
+		column.DataType = candidates[0]
+		if column.DataType == TypeBoolean {
+			column.NumericPrecision = 1
+		}
+		return column
+	}
+	if maxLength > maxVarcharLength {
+		column.DataType = TypeText
+		return column
+	}
+	column.DataType = TypeVarchar
+	column.CharMaxLength = varcharLength(maxLength)
+	return column
+}
+
+func filterCandidates(candidates []string, value interface{}) []string {
+	result := candidates[:0:0]
+	for _, candidate := range candidates {
+		if _, err := ConvertValue(value, candidate); err == nil {
+			result = append(result, candidate)
+		}
+	}
+	return result
+}
+
+// varcharLength 按 2 的幂向上取整，给后续数据留出余量
+func varcharLength(length int) int {
+	size := 32
+	for size < length {
+		size *= 2
+	}
+	if size > maxVarcharLength {
+		return maxVarcharLength
+	}
+	return size
+}
+
+func isEmptyValue(value interface{}) bool {
+	if value == nil {
+		return true
+	}
+	if s, ok := value.(string); ok {
+		return strings.TrimSpace(s) == ""
+	}
+	return false
+}
+
+// ConvertValue converts a raw file value to the Go value matching dataType.
+// Empty strings become nil for every type except the string ones.
+func ConvertValue(value interface{}, dataType string) (interface{}, error) {
+	dataType = strings.ToUpper(dataType)
+	switch dataType {
+	case "", TypeVarchar, "CHAR", TypeText, "MEDIUMTEXT", "LONGTEXT":
+		if value == nil {
+			return nil, nil
+		}
+		if s, ok := value.(string); ok {
+			return s, nil
+		}
+		return FormatValue(value), nil
+	}
+	if isEmptyValue(value) {
+		return nil, nil
+	}
+
+	text := strings.TrimSpace(FormatValue(value))
+	switch dataType {
+	case TypeInt, TypeBigint, "SMALLINT", "MEDIUMINT":
+		switch v := value.(type) {
+		case int64:
+			return checkIntRange(v, dataType)
+		case float64:
+			if v != math.Trunc(v) {
+				return nil, fmt.Errorf("'%v' is not an integer", v)
+			}
+			return checkIntRange(int64(v), dataType)
+		}
+		i, err := strconv.ParseInt(text, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("'%s' is not an integer", text)
+		}
+		return checkIntRange(i, dataType)
+	case TypeDouble, "FLOAT", "DECIMAL":
+		if f, ok := value.(float64); ok {
+			return f, nil
+		}
+		f, err := strconv.ParseFloat(text, 64)
+		if err != nil {
+			return nil, fmt.Errorf("'%s' is not a number", text)
+		}
+		return f, nil
+	case TypeBoolean, "BOOLEAN", "BOOL":
+		if b, ok := value.(bool); ok {
+			return b, nil
+		}
+		switch strings.ToLower(text) {
+		case "true", "1", "yes":
+			return true, nil
+		case "false", "0", "no":
+			return false, nil
+		}
+		return nil, fmt.Errorf("'%s' is not a boolean", text)
+	case TypeDate:
+		return parseTime(text, dateLayouts)
+	case TypeDatetime, "TIMESTAMP":
+		if t, ok := value.(time.Time); ok {
+			return t, nil
+		}
+		return parseTime(text, datetimeLayouts)
+	case TypeJson:
+		switch value.(type) {
+		case map[string]interface{}, []interface{}:
+			return value, nil
+		}
+		var parsed interface{}
+		if err := json.Unmarshal([]byte(text), &parsed); err != nil {
+			return nil, fmt.Errorf("'%s' is not valid json", truncate(text, 50))
+		}
+		return parsed, nil
+	default:
+		return nil, fmt.Errorf("unsupported data type '%s'", dataType)
+	}
+}
+
+func checkIntRange(value int64, dataType string) (interface{}, error) {
+	if dataType == TypeInt && (value > math.MaxInt32 || value < math.MinInt32) {
+		return nil, fmt.Errorf("%d is out of range for %s", value, dataType)
+	}
+	return value, nil
+}
+
+func parseTime(text string, layouts []string) (interface{}, error) {
+	for _, layout := range layouts {
+		if t, err := time.ParseInLocation(layout, text, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	return nil, fmt.Errorf("'%s' is not a valid time", text)
+}
@@ -0,0 +1,179 @@
+package transfer
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/samber/lo"
+	uuid "github.com/satori/go.uuid"
+	"tinydb/app/pkg/logger"
+	"tinydb/app/utility"
+)
+
+const (
+	JobStatusRunning   = "running"
+	JobStatusDone      = "done"
+	JobStatusError     = "error"
+	JobStatusCancelled = "cancelled"
+)
+
+const (
+	ErrorPolicyStop     = "stop"
+	ErrorPolicyContinue = "continue"
+)
+
+// maxJobErrors 限制单个任务保留的错误条数，避免大文件导入时内存无限增长
+const maxJobErrors = 1000
+
+const maxFinishedJobs = 50
+
+// ItemError describes a statement or row that failed inside a job.
+type ItemError struct {
+	Index     int64  `json:"index"`
+	Offset    int64  `json:"offset,omitempty"`
+	Line      int    `json:"line,omitempty"`
+	Statement string `json:"statement,omitempty"`
+	Message   string `json:"message"`
+}
+
+// Progress is the snapshot of a job returned to the frontend.
+type Progress struct {
+	Id         string           `json:"id"`
+	Kind       string           `json:"kind"`
+	Status     string           `json:"status"`
+	BytesTotal int64            `json:"bytesTotal"`
+	BytesDone  int64            `json:"bytesDone"`
+	Statements int64            `json:"statements"`
+	Rows       int64            `json:"rows"`
+	ErrorCount int64            `json:"errorCount"`
+	Errors     []*ItemError     `json:"errors"`
+	Message    string           `json:"message"`
+	Result     interface{}      `json:"result,omitempty"`
+	StartTime  utility.UnixTime `json:"startTime"`
+	EndTime    utility.UnixTime `json:"endTime"`
+}
+
+type Job struct {
+	mu       sync.RWMutex
+	progress *Progress
+	cancel   context.CancelFunc
+}
+
+var (
+	jobs   = make(map[string]*Job)
+	jobsMu sync.RWMutex
+)
+
+// StartJob registers a new job and runs it in the background.
+func StartJob(kind string, run func(ctx context.Context, job *Job) error) *Job {
+	ctx, cancel := context.WithCancel(context.Background())
+	job := &Job{
+		cancel: cancel,
+		progress: &Progress{
+			Id:        uuid.NewV4().String(),
+			Kind:      kind,
+			Status:    JobStatusRunning,
+			Errors:    make([]*ItemError, 0),
+			StartTime: utility.NewUnixTime(),
+		},
+	}
+
+	jobsMu.Lock()
+	pruneFinishedJobs()
+	jobs[job.progress.Id] = job
+	jobsMu.Unlock()
+
+	go func() {
+		defer cancel()
+		var err error
+		utility.WithRecover(func() {
+			err = run(ctx, job)
+		}, func(e error) {
+			err = e
+		})
+		job.finish(ctx, err)
+	}()
+
+	return job
+}
+
+func GetJob(id string) *Job {
+	jobsMu.RLock()
+	defer jobsMu.RUnlock()
+	return jobs[id]
+}
+
+func CancelJob(id string) bool {
+	job := GetJob(id)
+	if job == nil {
+		return false
+	}
+	job.cancel()
+	return true
+}
+
+func (j *Job) Id() string {
+	return j.progress.Id
+}
+
+// Update changes the progress under the job lock and notifies the frontend.
+func (j *Job) Update(fn func(p *Progress)) {
+	j.mu.Lock()
+	fn(j.progress)
+	j.mu.Unlock()
+	utility.EmitChanged(fmt.Sprintf("transfer-job-changed-%s", j.progress.Id))
+}
+
+// AddError records a failed item, keeping at most maxJobErrors of them.
+func (j *Job) AddError(item *ItemError) {
+	logger.Errorf("%s job [%s] item %d failed: %s", j.progress.Kind, j.progress.Id, item.Index, item.Message)
+	j.mu.Lock()
+	j.progress.ErrorCount++
+	if len(j.progress.Errors) < maxJobErrors {
+		j.progress.Errors = append(j.progress.Errors, item)
+	}
+	j.mu.Unlock()
+}
+
+// Snapshot returns a copy of the current progress that is safe to marshal.
+func (j *Job) Snapshot() *Progress {
+	j.mu.RLock()
+	defer j.mu.RUnlock()
+	snapshot := *j.progress
+	snapshot.Errors = append(make([]*ItemError, 0, len(j.progress.Errors)), j.progress.Errors...)
+	return &snapshot
+}
+
+func (j *Job) finish(ctx context.Context, err error) {
+	j.Update(func(p *Progress) {
+		p.EndTime = utility.NewUnixTime()
+		switch {
+		case ctx.Err() == context.Canceled:
+			p.Status = JobStatusCancelled
+		case err != nil:
+			p.Status = JobStatusError
+			p.Message = err.Error()
+		default:
+			p.Status = JobStatusDone
+		}
+	})
+}
+
+func (j *Job) finished() bool {
+	j.mu.RLock()
+	defer j.mu.RUnlock()
+	return j.progress.Status != JobStatusRunning
+}
+
+func pruneFinishedJobs() {
+	finished := lo.Filter(lo.Values(jobs), func(job *Job, _ int) bool {
+		return job.finished()
+	})
+	if len(finished) < maxFinishedJobs {
+		return
+	}
+	for _, job := range finished {
+		delete(jobs, job.Id())
+	}
+}
@@ -0,0 +1,321 @@
+package transfer
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+)
+
+const FormatJson = "json"
+
+// ReaderOptions describes the source file of an import.
+type ReaderOptions struct {
+	Format    string `json:"format"`
+	FilePath  string `json:"filePath"`
+	Delimiter string `json:"delimiter"`
+	NoHeader  bool   `json:"noHeader"`
+	// Sheet 为空时读取 xlsx 的第一个工作表
+	Sheet string `json:"sheet"`
+}
+
+// RowReader yields the records of a file one by one and returns io.EOF at the end.
+type RowReader interface {
+	// Columns returns the header of tabular files, nil for JSON documents.
+	Columns() []string
+	Read() (map[string]interface{}, error)
+	// Offset returns how many bytes of the file have been consumed, 0 when unknown.
+	Offset() int64
+	Close() error
+}
+
+func NewRowReader(opt *ReaderOptions) (RowReader, error) {
+	switch opt.Format {
+	case FormatCsv:
+		return newCsvReader(opt)
+	case FormatJson, FormatJsonl:
+		return newJsonReader(opt)
+	case FormatXlsx:
+		return newXlsxReader(opt)
+	default:
+		return nil, fmt.Errorf("unsupported import format '%s'", opt.Format)
+	}
+}
+
+type countingReader struct {
+	reader io.Reader
+	count  int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.reader.Read(p)
+	c.count += int64(n)
+	return n, err
+}
+
+type csvReader struct {
+	file    *os.File
+	counter *countingReader
+	reader  *csv.Reader
+	columns []string
+	pending []string
+}
+
+func newCsvReader(opt *ReaderOptions) (*csvReader, error) {
+	file, err := os.Open(opt.FilePath)
+	if err != nil {
+		return nil, err
+	}
+	counter := &countingReader{reader: file}
+	reader := csv.NewReader(bufio.NewReaderSize(counter, 64*1024))
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+	reader.ReuseRecord = false
+	if opt.Delimiter != "" {
+		reader.Comma = []rune(opt.Delimiter)[0]
+	}
+
+	r := &csvReader{file: file, counter: counter, reader: reader}
+	first, err := reader.Read()
+	if err != nil && err != io.EOF {
+		_ = file.Close()
+		return nil, err
+	}
+	if len(first) > 0 {
+		first[0] = strings.TrimPrefix(first[0], "\ufeff")
+	}
+	if opt.NoHeader {
+		r.columns = headerColumns(make([]string, len(first)))
+		r.pending = first
+	} else {
+		r.columns = headerColumns(first)
+	}
+	return r, nil
+}
+
+func (r *csvReader) Columns() []string {
+	return r.columns
+}
+
+func (r *csvReader) Read() (map[string]interface{}, error) {
+	record := r.pending
+	r.pending = nil
+	if record == nil {
+		var err error
+		if record, err = r.reader.Read(); err != nil {
+			return nil, err
+		}
+	}
+	return recordRow(r.columns, record), nil
+}
+
+func (r *csvReader) Offset() int64 {
+	return r.counter.count
+}
+
+func (r *csvReader) Close() error {
+	return r.file.Close()
+}
+
+// jsonReader accepts both a JSON array of objects and JSON Lines.
+type jsonReader struct {
+	file    *os.File
+	counter *countingReader
+	decoder *json.Decoder
+	array   bool
+}
+
+func newJsonReader(opt *ReaderOptions) (*jsonReader, error) {
+	file, err := os.Open(opt.FilePath)
+	if err != nil {
+		return nil, err
+	}
+	counter := &countingReader{reader: file}
+	buffered := bufio.NewReaderSize(counter, 64*1024)
+	r := &jsonReader{file: file, counter: counter}
+
+	for {
+		b, err := buffered.ReadByte()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			_ = file.Close()
+			return nil, err
+		}
+		if b == ' ' || b == '\t' || b == '\r' || b == '\n' || b == 0xEF || b == 0xBB || b == 0xBF {
+			continue
+		}
+		r.array = b == '['
+		_ = buffered.UnreadByte()
+		break
+	}
+
+	r.decoder = json.NewDecoder(buffered)
+	r.decoder.UseNumber()
+	if r.array {
+		// 消费数组的起始符号后，Decode 会逐个读取数组元素
+		if _, err = r.decoder.Token(); err != nil {
+			_ = file.Close()
+			return nil, err
+		}
+	}
+	return r, nil
+}
+
+func (r *jsonReader) Columns() []string {
+	return nil
+}
+
+func (r *jsonReader) Read() (map[string]interface{}, error) {
+	if !r.decoder.More() {
+		return nil, io.EOF
+	}
+	var row map[string]interface{}
+	if err := r.decoder.Decode(&row); err != nil {
+		return nil, err
+	}
+	return normalizeNumbers(row).(map[string]interface{}), nil
+}
+
+func (r *jsonReader) Offset() int64 {
+	return r.counter.count
+}
+
+func (r *jsonReader) Close() error {
+	return r.file.Close()
+}
+
+type xlsxReader struct {
+	file    *excelize.File
+	rows    *excelize.Rows
+	columns []string
+	pending []string
+}
+
+func newXlsxReader(opt *ReaderOptions) (*xlsxReader, error) {
+	file, err := excelize.OpenFile(opt.FilePath)
+	if err != nil {
+		return nil, err
+	}
+	sheet := opt.Sheet
+	if sheet == "" {
+		sheet = file.GetSheetName(0)
+	}
+	rows, err := file.Rows(sheet)
+	if err != nil {
+		_ = file.Close()
+		return nil, err
+	}
+
+	r := &xlsxReader{file: file, rows: rows}
+	var first []string
+	if rows.Next() {
+		if first, err = rows.Columns(); err != nil {
+			_ = r.Close()
+			return nil, err
+		}
+	}
+	if opt.NoHeader {
+		r.columns = headerColumns(make([]string, len(first)))
+		r.pending = first
+	} else {
+		r.columns = headerColumns(first)
+	}
+	return r, nil
+}
+
+func (r *xlsxReader) Columns() []string {
+	return r.columns
+}
+
+func (r *xlsxReader) Read() (map[string]interface{}, error) {
+	record := r.pending
+	r.pending = nil
+	if record == nil {
+		if !r.rows.Next() {
+			if err := r.rows.Error(); err != nil {
+				return nil, err
+			}
+			return nil, io.EOF
+		}
+		var err error
+		if record, err = r.rows.Columns(); err != nil {
+			return nil, err
+		}
+	}
+	return recordRow(r.columns, record), nil
+}
+
+func (r *xlsxReader) Offset() int64 {
+	return 0
+}
+
+func (r *xlsxReader) Close() error {
+	_ = r.rows.Close()
+	return r.file.Close()
+}
+
+// headerColumns 补全空列名并给重复列名加序号
+func headerColumns(header []string) []string {
+	columns := make([]string, len(header))
+	seen := make(map[string]int)
+	for i, name := range header {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			name = "column" + strconv.Itoa(i+1)
+		}
+		if count := seen[name]; count > 0 {
+			seen[name] = count + 1
+			name = name + "_" + strconv.Itoa(count+1)
+		} else {
+			seen[name] = 1
+		}
+		columns[i] = name
+	}
+	return columns
+}
+
+func recordRow(columns []string, record []string) map[string]interface{} {
+	row := make(map[string]interface{}, len(columns))
+	for i, column := range columns {
+		if i < len(record) {
+			row[column] = record[i]
+		} else {
+			row[column] = nil
+		}
+	}
+	return row
+}
+
+// normalizeNumbers 把 json.Number 转成 int64 或 float64
+func normalizeNumbers(value interface{}) interface{} {
+	switch v := value.(type) {
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return i
+		}
+		if f, err := v.Float64(); err == nil {
+			return f
+		}
+		return v.String()
+	case map[string]interface{}:
+		for key, item := range v {
+			v[key] = normalizeNumbers(item)
+		}
+		return v
+	case []interface{}:
+		for i, item := range v {
+			v[i] = normalizeNumbers(item)
+		}
+		return v
+	default:
+		return v
+	}
+}
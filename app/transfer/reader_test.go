@@ -0,0 +1,116 @@
+package transfer
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func readRows(t *testing.T, opt *ReaderOptions, content string) ([]string, []map[string]interface{}) {
+	if content != "" {
+		opt.FilePath = filepath.Join(t.TempDir(), "import."+opt.Format)
+		if err := os.WriteFile(opt.FilePath, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	reader, err := NewRowReader(opt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reader.Close()
+
+	var rows []map[string]interface{}
+	for {
+		row, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		rows = append(rows, row)
+	}
+	return reader.Columns(), rows
+}
+
+func TestCsvReader(t *testing.T) {
+	columns, rows := readRows(t, &ReaderOptions{Format: FormatCsv, Delimiter: ";"}, "\ufeffid;name;;name\n1;\"a;b\";x\n")
+	if len(columns) != 4 || columns[0] != "id" || columns[2] != "column3" || columns[3] != "name_2" {
+		t.Fatalf("unexpected columns %v", columns)
+	}
+	if len(rows) != 1 || rows[0]["name"] != "a;b" || rows[0]["name_2"] != nil {
+		t.Fatalf("unexpected rows %v", rows)
+	}
+
+	columns, rows = readRows(t, &ReaderOptions{Format: FormatCsv, NoHeader: true}, "1,2\n3,4\n")
+	if len(columns) != 2 || columns[1] != "column2" || len(rows) != 2 || rows[0]["column1"] != "1" {
+		t.Fatalf("unexpected no header result %v %v", columns, rows)
+	}
+}
+
+func TestJsonReader(t *testing.T) {
+	_, rows := readRows(t, &ReaderOptions{Format: FormatJson}, ` [{"id": 1, "price": 1.5}, {"id": 9007199254740993, "tags": [1]}]`)
+	if len(rows) != 2 || rows[0]["id"] != int64(1) || rows[0]["price"] != 1.5 || rows[1]["id"] != int64(9007199254740993) {
+		t.Fatalf("unexpected json rows %v", rows)
+	}
+
+	_, rows = readRows(t, &ReaderOptions{Format: FormatJsonl}, "{\"a\": \"x\"}\n{\"a\": null}\n")
+	if len(rows) != 2 || rows[0]["a"] != "x" || rows[1]["a"] != nil {
+		t.Fatalf("unexpected jsonl rows %v", rows)
+	}
+}
+
+func TestXlsxReader(t *testing.T) {
+	opt := &WriterOptions{Format: FormatXlsx}
+	writeRows(t, opt, []string{"id", "name"},
+		map[string]interface{}{"id": 1, "name": "a"},
+		map[string]interface{}{"id": 2, "name": nil},
+	)
+	columns, rows := readRows(t, &ReaderOptions{Format: FormatXlsx, FilePath: opt.FilePath}, "")
+	if len(columns) != 2 || len(rows) != 2 || rows[0]["id"] != "1" || rows[1]["name"] != nil {
+		t.Fatalf("unexpected xlsx result %v %v", columns, rows)
+	}
+}
+
+func TestInferType(t *testing.T) {
+	cases := []struct {
+		values   []interface{}
+		dataType string
+		nullable string
+	}{
+		{[]interface{}{"1", "0", ""}, TypeBoolean, "YES"},
+		{[]interface{}{"1", "42"}, TypeInt, "NO"},
+		{[]interface{}{"1", "9999999999"}, TypeBigint, "NO"},
+		{[]interface{}{"1", "2.5"}, TypeDouble, "NO"},
+		{[]interface{}{"2024-01-02", nil}, TypeDate, "YES"},
+		{[]interface{}{"2024-01-02 03:04:05"}, TypeDatetime, "NO"},
+		{[]interface{}{"abc", "1"}, TypeVarchar, "NO"},
+		{[]interface{}{map[string]interface{}{"a": 1}}, TypeJson, "YES"},
+		{[]interface{}{nil}, TypeVarchar, "YES"},
+	}
+	for _, c := range cases {
+		inferred := InferType(c.values)
+		if inferred.DataType != c.dataType || inferred.IsNullable != c.nullable {
+			t.Errorf("InferType(%v) = %s %s, want %s %s", c.values, inferred.DataType, inferred.IsNullable, c.dataType, c.nullable)
+		}
+	}
+}
+
+func TestMapRow(t *testing.T) {
+	mapping := []*FieldMapping{
+		{Source: "id", Target: "user_id", DataType: TypeInt},
+		{Source: "name"},
+	}
+	values, err := mapRow(map[string]interface{}{"id": "7", "name": ""}, mapping)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if values["user_id"] != int64(7) || values["name"] != "" {
+		t.Fatalf("unexpected values %v", values)
+	}
+
+	if _, err = mapRow(map[string]interface{}{"id": "x"}, mapping); err == nil {
+		t.Fatal("expected conversion error")
+	}
+}
@@ -0,0 +1,122 @@
+package transfer
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"tinydb/app/db"
+	"tinydb/app/db/adapter/mysql"
+	"tinydb/app/db/script"
+)
+
+const defaultScriptBatchSize = 100
+
+const maxErrorStatementLength = 1000
+
+type ScriptOptions struct {
+	FilePath  string `json:"filePath"`
+	OnError   string `json:"onError"`
+	BatchSize int    `json:"batchSize"`
+}
+
+// RunScript streams a .sql file through the splitter and executes it on a
+// dedicated connection, publishing progress after every batch.
+func RunScript(ctx context.Context, job *Job, driver db.Session, opt *ScriptOptions) error {
+	source, ok := driver.(*mysql.Source)
+	if !ok || source == nil {
+		return fmt.Errorf("run script on %s: %w", driver.Dialect(), db.ErrNotSupportedByAdapter)
+	}
+
+	file, err := os.Open(opt.FilePath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	if stat, err := file.Stat(); err == nil {
+		job.Update(func(p *Progress) {
+			p.BytesTotal = stat.Size()
+		})
+	}
+
+	conn, err := source.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	batchSize := opt.BatchSize
+	if batchSize <= 0 {
+		batchSize = defaultScriptBatchSize
+	}
+
+	var index int64
+	batch := make([]*script.Statement, 0, batchSize)
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		for _, statement := range batch {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			index++
+			if _, err := conn.ExecContext(ctx, statement.Sql); err != nil {
+				if ctx.Err() != nil {
+					return ctx.Err()
+				}
+				job.AddError(&ItemError{
+					Index:     index,
+					Offset:    statement.Offset,
+					Line:      statement.Line,
+					Statement: truncate(statement.Sql, maxErrorStatementLength),
+					Message:   err.Error(),
+				})
+				if opt.OnError != ErrorPolicyContinue {
+					return fmt.Errorf("statement %d at line %d failed: %w", index, statement.Line, err)
+				}
+			}
+		}
+		last := batch[len(batch)-1]
+		job.Update(func(p *Progress) {
+			p.Statements = index
+			p.BytesDone = last.End
+		})
+		batch = batch[:0]
+		return nil
+	}
+
+	splitter := script.NewSplitter(file)
+	for {
+		statement, err := splitter.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		batch = append(batch, statement)
+		if len(batch) >= batchSize {
+			if err = flush(); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err = flush(); err != nil {
+		return err
+	}
+	job.Update(func(p *Progress) {
+		p.BytesDone = splitter.Offset()
+	})
+	return nil
+}
+
+func truncate(value string, size int) string {
+	if len(value) <= size {
+		return value
+	}
+	return value[:size] + "..."
+}
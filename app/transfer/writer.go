@@ -0,0 +1,428 @@
+package transfer
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/xuri/excelize/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"tinydb/app/utility"
+)
+
+const (
+	FormatCsv      = "csv"
+	FormatJsonl    = "jsonl"
+	FormatXlsx     = "xlsx"
+	FormatMarkdown = "markdown"
+	FormatSql      = "sql"
+)
+
+const (
+	QuoteMinimal = "minimal"
+	QuoteAll     = "all"
+	QuoteNone    = "none"
+)
+
+const timeLayout = "2006-01-02 15:04:05"
+
+// WriterOptions configures how rows are rendered by a RowWriter.
+type WriterOptions struct {
+	Format    string `json:"format"`
+	FilePath  string `json:"filePath"`
+	Delimiter string `json:"delimiter"`
+	Quote     string `json:"quote"`
+	NoHeader  bool   `json:"noHeader"`
+	TableName string `json:"tableName"`
+	// InsertBatchSize 为 SQL 格式时每条 INSERT 包含的行数
+	InsertBatchSize int `json:"insertBatchSize"`
+}
+
+// RowWriter receives the column list once and then every row in order.
+type RowWriter interface {
+	Open(columns []string) error
+	Write(row map[string]interface{}) error
+	Close() error
+}
+
+func NewRowWriter(opt *WriterOptions) (RowWriter, error) {
+	switch opt.Format {
+	case FormatCsv:
+		return &csvWriter{opt: opt}, nil
+	case FormatJsonl:
+		return &jsonlWriter{opt: opt}, nil
+	case FormatXlsx:
+		return &xlsxWriter{opt: opt}, nil
+	case FormatMarkdown:
+		return &markdownWriter{opt: opt}, nil
+	case FormatSql:
+		if opt.TableName == "" {
+			return nil, fmt.Errorf("table name is required for sql export")
+		}
+		return &sqlWriter{opt: opt}, nil
+	default:
+		return nil, fmt.Errorf("unsupported export format '%s'", opt.Format)
+	}
+}
+
+type fileWriter struct {
+	file   *os.File
+	writer *bufio.Writer
+}
+
+func (f *fileWriter) create(name string) error {
+	file, err := os.Create(name)
+	if err != nil {
+		return err
+	}
+	f.file = file
+	f.writer = bufio.NewWriterSize(file, 64*1024)
+	return nil
+}
+
+func (f *fileWriter) close() error {
+	if f.file == nil {
+		return nil
+	}
+	if err := f.writer.Flush(); err != nil {
+		_ = f.file.Close()
+		return err
+	}
+	return f.file.Close()
+}
+
+type csvWriter struct {
+	fileWriter
+	opt     *WriterOptions
+	columns []string
+}
+
+func (w *csvWriter) Open(columns []string) error {
+	w.columns = columns
+	if err := w.create(w.opt.FilePath); err != nil {
+		return err
+	}
+	if w.opt.NoHeader {
+		return nil
+	}
+	return w.writeRecord(columns)
+}
+
+func (w *csvWriter) Write(row map[string]interface{}) error {
+	record := make([]string, len(w.columns))
+	for i, column := range w.columns {
+		record[i] = FormatValue(row[column])
+	}
+	return w.writeRecord(record)
+}
+
+func (w *csvWriter) Close() error {
+	return w.close()
+}
+
+func (w *csvWriter) writeRecord(record []string) error {
+	delimiter := w.opt.Delimiter
+	if delimiter == "" {
+		delimiter = ","
+	}
+	for i, field := range record {
+		if i > 0 {
+			w.writer.WriteString(delimiter)
+		}
+		w.writer.WriteString(w.quote(field, delimiter))
+	}
+	_, err := w.writer.WriteString("\r\n")
+	return err
+}
+
+func (w *csvWriter) quote(field, delimiter string) string {
+	switch w.opt.Quote {
+	case QuoteNone:
+		return field
+	case QuoteAll:
+	default:
+		if field == "" || (!strings.Contains(field, delimiter) && !strings.ContainsAny(field, "\"\r\n") &&
+			field[0] != ' ' && field[len(field)-1] != ' ') {
+			return field
+		}
+	}
+	return `"` + strings.ReplaceAll(field, `"`, `""`) + `"`
+}
+
+type jsonlWriter struct {
+	fileWriter
+	opt *WriterOptions
+}
+
+func (w *jsonlWriter) Open(_ []string) error {
+	return w.create(w.opt.FilePath)
+}
+
+// Write 与 WriteFileAllPool 一样一行一个 JSON 对象
+func (w *jsonlWriter) Write(row map[string]interface{}) error {
+	marshal, err := utility.JsonMarshal(normalizeDocument(row))
+	if err != nil {
+		return err
+	}
+	w.writer.Write(marshal)
+	_, err = w.writer.WriteString("\n")
+	return err
+}
+
+func (w *jsonlWriter) Close() error {
+	return w.close()
+}
+
+type markdownWriter struct {
+	fileWriter
+	opt     *WriterOptions
+	columns []string
+}
+
+func (w *markdownWriter) Open(columns []string) error {
+	w.columns = columns
+	if err := w.create(w.opt.FilePath); err != nil {
+		return err
+	}
+	w.writeLine(columns)
+	separators := make([]string, len(columns))
+	for i := range separators {
+		separators[i] = "---"
+	}
+	return w.writeLine(separators)
+}
+
+func (w *markdownWriter) Write(row map[string]interface{}) error {
+	cells := make([]string, len(w.columns))
+	for i, column := range w.columns {
+		cells[i] = FormatValue(row[column])
+	}
+	return w.writeLine(cells)
+}
+
+func (w *markdownWriter) Close() error {
+	return w.close()
+}
+
+func (w *markdownWriter) writeLine(cells []string) error {
+	escaped := make([]string, len(cells))
+	for i, cell := range cells {
+		cell = strings.ReplaceAll(cell, "|", `\|`)
+		cell = strings.ReplaceAll(cell, "\r\n", "<br>")
+		escaped[i] = strings.ReplaceAll(cell, "\n", "<br>")
+	}
+	_, err := w.writer.WriteString("| " + strings.Join(escaped, " | ") + " |\n")
+	return err
+}
+
+type sqlWriter struct {
+	fileWriter
+	opt     *WriterOptions
+	columns []string
+	pending []string
+}
+
+func (w *sqlWriter) Open(columns []string) error {
+	w.columns = columns
+	return w.create(w.opt.FilePath)
+}
+
+func (w *sqlWriter) Write(row map[string]interface{}) error {
+	values := make([]string, len(w.columns))
+	for i, column := range w.columns {
+		values[i] = SqlLiteral(row[column])
+	}
+	w.pending = append(w.pending, "("+strings.Join(values, ", ")+")")
+	batchSize := w.opt.InsertBatchSize
+	if batchSize <= 0 {
+		batchSize = 1
+	}
+	if len(w.pending) >= batchSize {
+		return w.flush()
+	}
+	return nil
+}
+
+func (w *sqlWriter) Close() error {
+	if w.file != nil {
+		if err := w.flush(); err != nil {
+			_ = w.close()
+			return err
+		}
+	}
+	return w.close()
+}
+
+func (w *sqlWriter) flush() error {
+	if len(w.pending) == 0 {
+		return nil
+	}
+	columns := make([]string, len(w.columns))
+	for i, column := range w.columns {
+		columns[i] = QuoteIdentifier(column)
+	}
+	_, err := w.writer.WriteString(fmt.Sprintf("INSERT INTO %s (%s) VALUES %s;\n",
+		QuoteIdentifier(w.opt.TableName), strings.Join(columns, ", "), strings.Join(w.pending, ",\n  ")))
+	w.pending = w.pending[:0]
+	return err
+}
+
+type xlsxWriter struct {
+	opt     *WriterOptions
+	file    *excelize.File
+	stream  *excelize.StreamWriter
+	columns []string
+	row     int
+}
+
+const xlsxSheet = "Sheet1"
+
+// xlsxMaxRows Excel 单个工作表的行数上限
+const xlsxMaxRows = 1048576
+
+func (w *xlsxWriter) Open(columns []string) error {
+	w.columns = columns
+	w.file = excelize.NewFile()
+	stream, err := w.file.NewStreamWriter(xlsxSheet)
+	if err != nil {
+		return err
+	}
+	w.stream = stream
+	if w.opt.NoHeader {
+		return nil
+	}
+	header := make([]interface{}, len(columns))
+	for i, column := range columns {
+		header[i] = column
+	}
+	return w.writeRow(header)
+}
+
+func (w *xlsxWriter) Write(row map[string]interface{}) error {
+	cells := make([]interface{}, len(w.columns))
+	for i, column := range w.columns {
+		cells[i] = xlsxValue(row[column])
+	}
+	return w.writeRow(cells)
+}
+
+func (w *xlsxWriter) Close() error {
+	if w.file == nil {
+		return nil
+	}
+	defer w.file.Close()
+	if err := w.stream.Flush(); err != nil {
+		return err
+	}
+	return w.file.SaveAs(w.opt.FilePath)
+}
+
+func (w *xlsxWriter) writeRow(cells []interface{}) error {
+	if w.row >= xlsxMaxRows {
+		return fmt.Errorf("xlsx export exceeds %d rows", xlsxMaxRows)
+	}
+	w.row++
+	cell, err := excelize.CoordinatesToCellName(1, w.row)
+	if err != nil {
+		return err
+	}
+	return w.stream.SetRow(cell, cells)
+}
+
+func xlsxValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case string, bool, int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64, time.Time:
+		return v
+	default:
+		return FormatValue(v)
+	}
+}
+
+// FormatValue renders a value as plain text for the flat formats.
+func FormatValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case []byte:
+		return string(v)
+	case time.Time:
+		return v.Format(timeLayout)
+	case primitive.DateTime:
+		return v.Time().Format(timeLayout)
+	case primitive.ObjectID:
+		return v.Hex()
+	case primitive.Decimal128:
+		return v.String()
+	case bool, int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+		return fmt.Sprint(v)
+	default:
+		marshal, err := json.Marshal(normalizeDocument(v))
+		if err != nil {
+			return fmt.Sprint(v)
+		}
+		return string(marshal)
+	}
+}
+
+// SqlLiteral renders a value as a MySQL literal.
+func SqlLiteral(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "NULL"
+	case bool:
+		if v {
+			return "1"
+		}
+		return "0"
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+		return fmt.Sprint(v)
+	default:
+		escaped := strings.NewReplacer(`\`, `\\`, `'`, `''`, "\x00", `\0`, "\n", `\n`, "\r", `\r`, "\x1a", `\Z`).Replace(FormatValue(v))
+		return "'" + escaped + "'"
+	}
+}
+
+func QuoteIdentifier(name string) string {
+	return "`" + strings.ReplaceAll(name, "`", "``") + "`"
+}
+
+// normalizeDocument 把 bson 特有类型转换成普通 JSON 友好的值
+func normalizeDocument(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		result := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			result[key] = normalizeDocument(item)
+		}
+		return result
+	case primitive.M:
+		return normalizeDocument(map[string]interface{}(v))
+	case primitive.D:
+		return normalizeDocument(v.Map())
+	case primitive.A:
+		return normalizeDocument([]interface{}(v))
+	case []interface{}:
+		result := make([]interface{}, len(v))
+		for i, item := range v {
+			result[i] = normalizeDocument(item)
+		}
+		return result
+	case primitive.ObjectID:
+		return v.Hex()
+	case primitive.DateTime:
+		return v.Time()
+	case primitive.Decimal128:
+		return v.String()
+	case []byte:
+		return string(v)
+	default:
+		return v
+	}
+}
@@ -0,0 +1,93 @@
+package transfer
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/xuri/excelize/v2"
+)
+
+func writeRows(t *testing.T, opt *WriterOptions, columns []string, rows ...map[string]interface{}) string {
+	opt.FilePath = filepath.Join(t.TempDir(), "export."+opt.Format)
+	writer, err := NewRowWriter(opt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = writer.Open(columns); err != nil {
+		t.Fatal(err)
+	}
+	for _, row := range rows {
+		if err = writer.Write(row); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err = writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+	content, err := os.ReadFile(opt.FilePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(content)
+}
+
+func TestCsvWriter(t *testing.T) {
+	row := map[string]interface{}{"id": 1, "name": `a "b";c`, "note": nil}
+	content := writeRows(t, &WriterOptions{Format: FormatCsv, Delimiter: ";"}, []string{"id", "name", "note"}, row)
+	if content != "id;name;note\r\n1;\"a \"\"b\"\";c\";\r\n" {
+		t.Fatalf("unexpected csv %q", content)
+	}
+
+	content = writeRows(t, &WriterOptions{Format: FormatCsv, Quote: QuoteAll, NoHeader: true}, []string{"id"}, row)
+	if content != "\"1\"\r\n" {
+		t.Fatalf("unexpected csv %q", content)
+	}
+}
+
+func TestSqlWriter(t *testing.T) {
+	created := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	content := writeRows(t, &WriterOptions{Format: FormatSql, TableName: "user", InsertBatchSize: 2}, []string{"id", "name", "created"},
+		map[string]interface{}{"id": int64(1), "name": "O'Neil\\", "created": created},
+		map[string]interface{}{"id": int64(2), "name": nil, "created": nil},
+		map[string]interface{}{"id": int64(3), "name": "x", "created": nil},
+	)
+	expected := "INSERT INTO `user` (`id`, `name`, `created`) VALUES (1, 'O''Neil\\\\', '2024-01-02 03:04:05'),\n  (2, NULL, NULL);\n" +
+		"INSERT INTO `user` (`id`, `name`, `created`) VALUES (3, 'x', NULL);\n"
+	if content != expected {
+		t.Fatalf("unexpected sql %q", content)
+	}
+}
+
+func TestMarkdownWriter(t *testing.T) {
+	content := writeRows(t, &WriterOptions{Format: FormatMarkdown}, []string{"a", "b"},
+		map[string]interface{}{"a": "x|y", "b": map[string]interface{}{"k": []interface{}{1}}})
+	if content != "| a | b |\n| --- | --- |\n| x\\|y | {\"k\":[1]} |\n" {
+		t.Fatalf("unexpected markdown %q", content)
+	}
+}
+
+func TestDocumentColumns(t *testing.T) {
+	columns := DocumentColumns([]map[string]interface{}{{"b": 1, "_id": 1}, {"a": 1, "b": 2}})
+	if len(columns) != 3 || columns[0] != "_id" || columns[1] != "a" || columns[2] != "b" {
+		t.Fatalf("unexpected columns %v", columns)
+	}
+}
+
+func TestXlsxWriter(t *testing.T) {
+	opt := &WriterOptions{Format: FormatXlsx}
+	writeRows(t, opt, []string{"id", "name"}, map[string]interface{}{"id": 1, "name": "tiny"})
+	file, err := excelize.OpenFile(opt.FilePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	rows, err := file.GetRows(xlsxSheet)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 2 || rows[1][0] != "1" || rows[1][1] != "tiny" {
+		t.Fatalf("unexpected rows %v", rows)
+	}
+}
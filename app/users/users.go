@@ -0,0 +1,321 @@
+package users
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/samber/lo"
+	"tinydb/app/db"
+	"tinydb/app/db/adapter/mysql"
+)
+
+const (
+	ActionCreate   = "create"
+	ActionDrop     = "drop"
+	ActionPassword = "password"
+	ActionGrant    = "grant"
+	ActionRevoke   = "revoke"
+)
+
+const (
+	LevelGlobal   = "global"
+	LevelDatabase = "database"
+	LevelTable    = "table"
+	LevelColumn   = "column"
+)
+
+// knownPrivileges 只允许这些权限名出现在生成的 GRANT/REVOKE 中
+var knownPrivileges = []string{
+	"ALL PRIVILEGES", "ALTER", "ALTER ROUTINE", "CREATE", "CREATE ROLE", "CREATE ROUTINE",
+	"CREATE TABLESPACE", "CREATE TEMPORARY TABLES", "CREATE USER", "CREATE VIEW", "DELETE",
+	"DROP", "DROP ROLE", "EVENT", "EXECUTE", "FILE", "GRANT OPTION", "INDEX", "INSERT",
+	"LOCK TABLES", "PROCESS", "REFERENCES", "RELOAD", "REPLICATION CLIENT", "REPLICATION SLAVE",
+	"SELECT", "SHOW DATABASES", "SHOW VIEW", "SHUTDOWN", "SUPER", "TRIGGER", "UPDATE", "USAGE",
+}
+
+// columnPrivileges 列级别只支持这几种权限
+var columnPrivileges = []string{"INSERT", "REFERENCES", "SELECT", "UPDATE"}
+
+type Account struct {
+	User string `json:"user"`
+	Host string `json:"host"`
+}
+
+// String renders the account as 'user'@'host', an empty host means '%'.
+func (a Account) String() string {
+	host := a.Host
+	if host == "" {
+		host = "%"
+	}
+	return quoteString(a.User) + "@" + quoteString(host)
+}
+
+// User is a row of mysql.user.
+type User struct {
+	Account
+	Plugin          string `json:"plugin"`
+	AccountLocked   bool   `json:"accountLocked"`
+	PasswordExpired bool   `json:"passwordExpired"`
+}
+
+type Privilege struct {
+	Privileges      []string `json:"privileges"`
+	Level           string   `json:"level"`
+	Database        string   `json:"database"`
+	Table           string   `json:"table"`
+	Columns         []string `json:"columns"`
+	WithGrantOption bool     `json:"withGrantOption"`
+}
+
+// Change describes one user management operation.
+type Change struct {
+	Action  string  `json:"action"`
+	Account Account `json:"account"`
+	// Password 用于 create 和 password，Plugin 为空时使用服务器默认的认证插件
+	Password  string     `json:"password"`
+	Plugin    string     `json:"plugin"`
+	Privilege *Privilege `json:"privilege"`
+}
+
+// Statements generates the SQL of a change, the password is masked when mask
+// is set so the statements can be shown as a preview.
+func Statements(change *Change, mask bool) ([]string, error) {
+	if change == nil || strings.TrimSpace(change.Account.User) == "" {
+		return nil, fmt.Errorf("user is required")
+	}
+	account := change.Account.String()
+	password := quoteString(change.Password)
+	if mask {
+		password = "'***'"
+	}
+	identified := "IDENTIFIED BY " + password
+	if change.Plugin != "" {
+		if !isIdentifierWord(change.Plugin) {
+			return nil, fmt.Errorf("invalid authentication plugin '%s'", change.Plugin)
+		}
+		identified = "IDENTIFIED WITH " + change.Plugin + " BY " + password
+	}
+
+	switch change.Action {
+	case ActionCreate:
+		statements := []string{"CREATE USER " + account + " " + identified}
+		if change.Privilege != nil && len(change.Privilege.Privileges) > 0 {
+			grant, err := grantStatement(ActionGrant, account, change.Privilege)
+			if err != nil {
+				return nil, err
+			}
+			statements = append(statements, grant)
+		}
+		return statements, nil
+	case ActionDrop:
+		return []string{"DROP USER " + account}, nil
+	case ActionPassword:
+		if change.Password == "" {
+			return nil, fmt.Errorf("password is required")
+		}
+		return []string{"ALTER USER " + account + " " + identified}, nil
+	case ActionGrant, ActionRevoke:
+		if change.Privilege == nil {
+			return nil, fmt.Errorf("privilege is required")
+		}
+		statement, err := grantStatement(change.Action, account, change.Privilege)
+		if err != nil {
+			return nil, err
+		}
+		return []string{statement}, nil
+	default:
+		return nil, fmt.Errorf("unsupported action '%s'", change.Action)
+	}
+}
+
+func grantStatement(action, account string, privilege *Privilege) (string, error) {
+	privileges, err := normalizePrivileges(privilege)
+	if err != nil {
+		return "", err
+	}
+	target, err := grantTarget(privilege)
+	if err != nil {
+		return "", err
+	}
+
+	if action == ActionRevoke {
+		if privilege.WithGrantOption && !lo.Contains(privileges, "GRANT OPTION") {
+			privileges = append(privileges, "GRANT OPTION")
+		}
+		return "REVOKE " + strings.Join(privileges, ", ") + " ON " + target + " FROM " + account, nil
+	}
+
+	statement := "GRANT " + strings.Join(privileges, ", ") + " ON " + target + " TO " + account
+	if privilege.WithGrantOption {
+		statement += " WITH GRANT OPTION"
+	}
+	return statement, nil
+}
+
+func normalizePrivileges(privilege *Privilege) ([]string, error) {
+	if len(privilege.Privileges) == 0 {
+		return nil, fmt.Errorf("at least one privilege is required")
+	}
+	var columns string
+	if privilege.Level == LevelColumn {
+		if len(privilege.Columns) == 0 {
+			return nil, fmt.Errorf("columns are required for column privileges")
+		}
+		quoted := lo.Map(privilege.Columns, func(column string, _ int) string {
+			return quoteIdentifier(column)
+		})
+		columns = " (" + strings.Join(quoted, ", ") + ")"
+	}
+
+	result := make([]string, 0, len(privilege.Privileges))
+	for _, name := range privilege.Privileges {
+		name = strings.Join(strings.Fields(strings.ToUpper(name)), " ")
+		if name == "ALL" {
+			name = "ALL PRIVILEGES"
+		}
+		if !lo.Contains(knownPrivileges, name) {
+			return nil, fmt.Errorf("unknown privilege '%s'", name)
+		}
+		if privilege.Level == LevelColumn {
+			if !lo.Contains(columnPrivileges, name) {
+				return nil, fmt.Errorf("privilege '%s' cannot be granted on columns", name)
+			}
+			name += columns
+		}
+		if !lo.Contains(result, name) {
+			result = append(result, name)
+		}
+	}
+	return result, nil
+}
+
+func grantTarget(privilege *Privilege) (string, error) {
+	switch privilege.Level {
+	case "", LevelGlobal:
+		return "*.*", nil
+	case LevelDatabase:
+		if privilege.Database == "" {
+			return "", fmt.Errorf("database is required")
+		}
+		return quoteIdentifier(privilege.Database) + ".*", nil
+	case LevelTable, LevelColumn:
+		if privilege.Database == "" || privilege.Table == "" {
+			return "", fmt.Errorf("database and table are required")
+		}
+		return quoteIdentifier(privilege.Database) + "." + quoteIdentifier(privilege.Table), nil
+	default:
+		return "", fmt.Errorf("unsupported privilege level '%s'", privilege.Level)
+	}
+}
+
+func source(session db.Session) (*mysql.Source, error) {
+	s, ok := session.(*mysql.Source)
+	if !ok {
+		return nil, fmt.Errorf("user management on %s: %w", session.Dialect(), db.ErrNotSupportedByAdapter)
+	}
+	return s, nil
+}
+
+// List returns the accounts of mysql.user.
+func List(ctx context.Context, session db.Session) ([]*User, error) {
+	s, err := source(session)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]*User, 0)
+	// 不同版本和 MariaDB 的 mysql.user 列不完全相同，按列名读取
+	err = s.Stream(ctx, "SELECT * FROM mysql.user", func(_ []string, row map[string]interface{}) error {
+		result = append(result, &User{
+			Account:         Account{User: text(row["User"]), Host: text(row["Host"])},
+			Plugin:          text(row["plugin"]),
+			AccountLocked:   strings.EqualFold(text(row["account_locked"]), "Y"),
+			PasswordExpired: strings.EqualFold(text(row["password_expired"]), "Y"),
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		if result[i].User != result[j].User {
+			return result[i].User < result[j].User
+		}
+		return result[i].Host < result[j].Host
+	})
+	return result, nil
+}
+
+// Grants returns the SHOW GRANTS output of an account.
+func Grants(ctx context.Context, session db.Session, account Account) ([]string, error) {
+	s, err := source(session)
+	if err != nil {
+		return nil, err
+	}
+	grants := make([]string, 0)
+	err = s.Stream(ctx, "SHOW GRANTS FOR "+account.String(), func(columns []string, row map[string]interface{}) error {
+		if len(columns) > 0 {
+			grants = append(grants, text(row[columns[0]]))
+		}
+		return nil
+	})
+	return grants, err
+}
+
+// Apply runs the statements of a change on one connection and returns them
+// with the password masked.
+func Apply(ctx context.Context, session db.Session, change *Change) ([]string, error) {
+	s, err := source(session)
+	if err != nil {
+		return nil, err
+	}
+	statements, err := Statements(change, false)
+	if err != nil {
+		return nil, err
+	}
+	preview, _ := Statements(change, true)
+
+	conn, err := s.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	for i, statement := range statements {
+		if _, err = conn.ExecContext(ctx, statement); err != nil {
+			return preview[:i], fmt.Errorf("%s: %w", preview[i], err)
+		}
+	}
+	return preview, nil
+}
+
+func text(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case []byte:
+		return string(v)
+	case string:
+		return v
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+func quoteString(value string) string {
+	value = strings.ReplaceAll(value, "\\", "\\\\")
+	return "'" + strings.ReplaceAll(value, "'", "''") + "'"
+}
+
+func quoteIdentifier(name string) string {
+	return "`" + strings.ReplaceAll(name, "`", "``") + "`"
+}
+
+func isIdentifierWord(value string) bool {
+	for _, r := range value {
+		if !(r == '_' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9')) {
+			return false
+		}
+	}
+	return value != ""
+}
@@ -0,0 +1,84 @@
+package users
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestStatementsCreate(t *testing.T) {
+	change := &Change{
+		Action:   ActionCreate,
+		Account:  Account{User: "app", Host: "10.0.%"},
+		Password: "it's",
+		Privilege: &Privilege{
+			Privileges: []string{"select", "insert", "Select"},
+			Level:      LevelDatabase,
+			Database:   "shop",
+		},
+	}
+	statements, err := Statements(change, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{
+		"CREATE USER 'app'@'10.0.%' IDENTIFIED BY 'it''s'",
+		"GRANT SELECT, INSERT ON `shop`.* TO 'app'@'10.0.%'",
+	}
+	if !reflect.DeepEqual(statements, expected) {
+		t.Fatalf("unexpected statements %q", statements)
+	}
+
+	preview, _ := Statements(change, true)
+	if preview[0] != "CREATE USER 'app'@'10.0.%' IDENTIFIED BY '***'" {
+		t.Fatalf("password should be masked in the preview: %q", preview[0])
+	}
+}
+
+func TestStatementsGrantRevoke(t *testing.T) {
+	grant, err := Statements(&Change{
+		Action:  ActionGrant,
+		Account: Account{User: "report"},
+		Privilege: &Privilege{
+			Privileges:      []string{"SELECT", "update"},
+			Level:           LevelColumn,
+			Database:        "shop",
+			Table:           "orders",
+			Columns:         []string{"id", "total"},
+			WithGrantOption: true,
+		},
+	}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if grant[0] != "GRANT SELECT (`id`, `total`), UPDATE (`id`, `total`) ON `shop`.`orders` TO 'report'@'%' WITH GRANT OPTION" {
+		t.Fatalf("unexpected grant %q", grant[0])
+	}
+
+	revoke, err := Statements(&Change{
+		Action:    ActionRevoke,
+		Account:   Account{User: "report", Host: "localhost"},
+		Privilege: &Privilege{Privileges: []string{"all"}, WithGrantOption: true},
+	}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if revoke[0] != "REVOKE ALL PRIVILEGES, GRANT OPTION ON *.* FROM 'report'@'localhost'" {
+		t.Fatalf("unexpected revoke %q", revoke[0])
+	}
+}
+
+func TestStatementsRejectsInvalidInput(t *testing.T) {
+	cases := []*Change{
+		{Action: ActionGrant, Account: Account{User: "a"}, Privilege: &Privilege{Privileges: []string{"SELECT; DROP TABLE x"}}},
+		{Action: ActionGrant, Account: Account{User: "a"}, Privilege: &Privilege{Privileges: []string{"DELETE"}, Level: LevelColumn, Database: "d", Table: "t", Columns: []string{"c"}}},
+		{Action: ActionGrant, Account: Account{User: "a"}, Privilege: &Privilege{Privileges: []string{"SELECT"}, Level: LevelTable, Database: "d"}},
+		{Action: ActionPassword, Account: Account{User: "a"}},
+		{Action: ActionCreate, Account: Account{User: "a"}, Plugin: "caching_sha2_password BY 'x'"},
+		{Action: ActionDrop},
+	}
+	for i, change := range cases {
+		if _, err := Statements(change, false); err == nil {
+			t.Fatalf("case %d should fail", i)
+		}
+	}
+}
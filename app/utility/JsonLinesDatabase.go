@@ -4,27 +4,73 @@ import (
 	"errors"
 	"fmt"
 	uuid "github.com/satori/go.uuid"
+	"os"
+	"path/filepath"
 	"sync"
+	"time"
 	"tinydb/app/pkg/logger"
 )
 
-var lock sync.RWMutex
+var ErrRecordNotFound = errors.New("id in not a valid")
 
+var (
+	databasesMu sync.Mutex
+	databases   = map[string]*JsonLinesDatabase{}
+)
+
+// JsonLinesDatabase 一行一个 JSON 对象的文件存储。同一文件在进程内只有一个实例，
+// 写入时持有文件锁并先写临时文件再改名，其他进程修改文件后会自动重新加载
 type JsonLinesDatabase struct {
 	Filename      string                   `json:"filename"`
 	LoadedOk      bool                     `json:"loadedOk"`
 	LoadPerformed bool                     `json:"loadPerformed"`
 	Data          []map[string]interface{} `json:"data"`
+
+	mu        sync.Mutex
+	modTime   time.Time
+	size      int64
+	listeners map[int]func()
+	nextId    int
 }
 
+// NewJsonLinesDatabase 返回 filename 对应的共享实例
 func NewJsonLinesDatabase(filename string) *JsonLinesDatabase {
+	key := filename
+	if abs, err := filepath.Abs(filename); err == nil {
+		key = abs
+	}
+	databasesMu.Lock()
+	defer databasesMu.Unlock()
+	if database, ok := databases[key]; ok {
+		return database
+	}
+	database := newJsonLinesDatabase(filename)
+	databases[key] = database
+	return database
+}
+
+func newJsonLinesDatabase(filename string) *JsonLinesDatabase {
 	return &JsonLinesDatabase{
-		Filename: filename,
+		Filename:  filename,
+		listeners: map[int]func(){},
+	}
+}
+
+// OnChange 每次写入成功后调用 fn，返回取消订阅的函数
+func (j *JsonLinesDatabase) OnChange(fn func()) func() {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	id := j.nextId
+	j.nextId++
+	j.listeners[id] = fn
+	return func() {
+		j.mu.Lock()
+		defer j.mu.Unlock()
+		delete(j.listeners, id)
 	}
 }
 
 func (j *JsonLinesDatabase) Insert(obj map[string]interface{}) (map[string]interface{}, error) {
-	j.ensureLoaded()
 	dynamicId, ok := obj[database_key]
 	if ok && dynamicId.(string) != "" {
 		return nil, fmt.Errorf("cannot insert duplicate ID %s into %s", dynamicId.(string), j.Filename)
@@ -32,99 +78,213 @@ func (j *JsonLinesDatabase) Insert(obj map[string]interface{}) (map[string]inter
 
 	elem := DeepCopyUnknownMap(obj)
 	elem[database_key] = uuid.NewV4().String()
-	//unique
-	j.Data = append(j.Data, elem)
-	if err := j.save(); err != nil {
+	err := j.modify(func(data []map[string]interface{}) ([]map[string]interface{}, error) {
+		return append(data, elem), nil
+	})
+	if err != nil {
 		logger.Errorf("insert database failed %v", err)
 		return nil, err
 	}
-	return elem, nil
+	return DeepCopyUnknownMap(elem), nil
 }
 
+// Get 返回记录的副本，修改它不会影响已保存的数据
 func (j *JsonLinesDatabase) Get(id string) map[string]interface{} {
+	j.mu.Lock()
+	defer j.mu.Unlock()
 	j.ensureLoaded()
-	for _, obj := range j.Data {
-		if obj[database_key] != nil && obj[database_key].(string) == id {
-			return obj
-		}
+	if index := indexOf(j.Data, id); index >= 0 {
+		return DeepCopyUnknownMap(j.Data[index])
 	}
-
 	return nil
 }
 
+// Find 返回全部记录的副本
 func (j *JsonLinesDatabase) Find() []map[string]interface{} {
+	j.mu.Lock()
+	defer j.mu.Unlock()
 	j.ensureLoaded()
-	return j.Data
+	list := make([]map[string]interface{}, 0, len(j.Data))
+	for _, obj := range j.Data {
+		list = append(list, DeepCopyUnknownMap(obj))
+	}
+	return list
 }
 
+// Update 用 obj 整体替换 _id 相同的记录
 func (j *JsonLinesDatabase) Update(obj map[string]interface{}) (map[string]interface{}, error) {
-	j.ensureLoaded()
-	for _, x := range j.Data {
-		if obj[database_key] != nil && x[database_key] != nil && x[database_key] == obj[database_key] {
-			x = obj
+	id, _ := obj[database_key].(string)
+	elem := DeepCopyUnknownMap(obj)
+	err := j.modify(func(data []map[string]interface{}) ([]map[string]interface{}, error) {
+		index := indexOf(data, id)
+		if index < 0 {
+			return nil, ErrRecordNotFound
 		}
+		data[index] = elem
+		return data, nil
+	})
+	if err != nil {
+		logger.Errorf("update database failed %v", err)
+		return nil, err
 	}
 
-	if err := j.save(); err != nil {
-		logger.Errorf("update database failed %v", err)
+	return DeepCopyUnknownMap(elem), nil
+}
+
+// Patch 将 values 合并到记录中，值为 nil 的字段会被删除
+func (j *JsonLinesDatabase) Patch(id string, values map[string]interface{}) (map[string]interface{}, error) {
+	var patched map[string]interface{}
+	err := j.modify(func(data []map[string]interface{}) ([]map[string]interface{}, error) {
+		index := indexOf(data, id)
+		if index < 0 {
+			return nil, ErrRecordNotFound
+		}
+		patched = DeepCopyUnknownMap(data[index])
+		for k, v := range values {
+			if k == database_key {
+				continue
+			}
+			if v == nil {
+				delete(patched, k)
+			} else {
+				patched[k] = v
+			}
+		}
+		data[index] = patched
+		return data, nil
+	})
+	if err != nil {
 		return nil, err
 	}
+	return DeepCopyUnknownMap(patched), nil
+}
 
-	return obj, nil
+func (j *JsonLinesDatabase) Remove(id string) (map[string]interface{}, error) {
+	removed, err := j.RemoveBy(func(obj map[string]interface{}) bool {
+		return obj[database_key] == id
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(removed) == 0 {
+		return nil, ErrRecordNotFound
+	}
+	return removed[0], nil
 }
 
-func (j *JsonLinesDatabase) Path(id string, values ...interface{}) {
+// RemoveBy removes every record matched by fn with a single write.
+func (j *JsonLinesDatabase) RemoveBy(fn func(obj map[string]interface{}) bool) ([]map[string]interface{}, error) {
+	var removed []map[string]interface{}
+	err := j.modify(func(data []map[string]interface{}) ([]map[string]interface{}, error) {
+		kept := make([]map[string]interface{}, 0, len(data))
+		for _, obj := range data {
+			if fn(obj) {
+				removed = append(removed, obj)
+			} else {
+				kept = append(kept, obj)
+			}
+		}
+		if len(removed) == 0 {
+			return nil, nil
+		}
+		return kept, nil
+	})
+	return removed, err
+}
 
+// Rewrite 在文件锁内以磁盘上的最新内容调用 fn，并一次性写回 fn 返回的全部记录。
+// fn 返回 nil 时不写入，返回错误时文件保持不变
+func (j *JsonLinesDatabase) Rewrite(fn func(data []map[string]interface{}) ([]map[string]interface{}, error)) error {
+	return j.modify(fn)
 }
 
-func (j *JsonLinesDatabase) Remove(id string) (map[string]interface{}, error) {
-	j.ensureLoaded()
-	var removed map[string]interface{}
-	var match bool
-	var err error
-	for i, obj := range j.Data {
-		if obj[database_key] != nil && obj[database_key].(string) == id {
-			match = true
-			removed = obj
-			j.Data = append(j.Data[:i], j.Data[i+1:]...) // 删除中间N个元素
+// modify 在文件锁内以磁盘上的最新内容为准修改并保存，fn 返回 nil 时不写入
+func (j *JsonLinesDatabase) modify(fn func(data []map[string]interface{}) ([]map[string]interface{}, error)) error {
+	j.mu.Lock()
+	unlock, err := lockFile(j.Filename + ".lock")
+	if err != nil {
+		j.mu.Unlock()
+		return err
+	}
+	changed, err := j.modifyLocked(fn)
+	unlock()
+	listeners := make([]func(), 0, len(j.listeners))
+	for _, listener := range j.listeners {
+		listeners = append(listeners, listener)
+	}
+	j.mu.Unlock()
+
+	if changed {
+		for _, listener := range listeners {
+			listener()
 		}
 	}
+	return err
+}
 
-	if !match {
-		err = errors.New("id in not a valid")
-		return nil, err
+func (j *JsonLinesDatabase) modifyLocked(fn func(data []map[string]interface{}) ([]map[string]interface{}, error)) (bool, error) {
+	j.ensureLoaded()
+	if !j.LoadedOk {
+		return false, fmt.Errorf("not laded")
+	}
+	data := make([]map[string]interface{}, len(j.Data))
+	copy(data, j.Data)
+	data, err := fn(data)
+	if err != nil || data == nil {
+		return false, err
+	}
+	if err := WriteFileAllPool(j.Filename, data); err != nil {
+		return false, err
 	}
-	return removed, j.save()
+	j.Data = data
+	j.stat()
+	return true, nil
 }
 
+// ensureLoaded 首次访问或文件被其他进程改写后重新读取
 func (j *JsonLinesDatabase) ensureLoaded() {
-	if !j.LoadPerformed {
-		lock.Lock()
-		defer lock.Unlock()
-
-		if !IsExist(j.Filename) {
-			j.LoadedOk = true
-			j.LoadPerformed = true
-			return
+	info, err := os.Stat(j.Filename)
+	if os.IsNotExist(err) {
+		if !j.LoadPerformed || !j.modTime.IsZero() {
+			j.Data = nil
+			j.modTime, j.size = time.Time{}, 0
 		}
-
-		line, err := ReadFileAllPool(j.Filename)
-		if err != nil {
-			return
-		}
-		j.Data = line
 		j.LoadedOk = true
 		j.LoadPerformed = true
+		return
+	}
+	if err == nil && j.LoadPerformed && j.LoadedOk && info.ModTime().Equal(j.modTime) && info.Size() == j.size {
+		return
+	}
+
+	line, err := ReadFileAllPool(j.Filename)
+	j.LoadPerformed = true
+	if err != nil {
+		logger.Errorf("load %s failed %v", j.Filename, err)
+		j.LoadedOk = false
+		return
 	}
+	j.Data = line
+	j.LoadedOk = true
+	j.stat()
 }
 
-func (j *JsonLinesDatabase) save() error {
-	if !j.LoadedOk {
-		return fmt.Errorf("not laded")
+func (j *JsonLinesDatabase) stat() {
+	if info, err := os.Stat(j.Filename); err == nil {
+		j.modTime, j.size = info.ModTime(), info.Size()
+	}
+}
+
+func indexOf(data []map[string]interface{}, id string) int {
+	if id == "" {
+		return -1
+	}
+	for i, obj := range data {
+		if obj[database_key] == id {
+			return i
+		}
 	}
-	lock.Lock()
-	defer lock.Unlock()
-	return WriteFileAllPool(j.Filename, j.Data)
+	return -1
 }
 
 func (j *JsonLinesDatabase) EnsureOpened(conid string) {
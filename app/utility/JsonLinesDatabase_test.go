@@ -0,0 +1,109 @@
+package utility
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestJsonLinesDatabaseShared(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "shared.jsonl")
+	if NewJsonLinesDatabase(filename) != NewJsonLinesDatabase(filename) {
+		t.Fatalf("the same file should share one instance")
+	}
+}
+
+func TestJsonLinesDatabaseUpdatePatchRemove(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "records.jsonl")
+	database := newJsonLinesDatabase(filename)
+	first, err := database.Insert(map[string]interface{}{"name": "a", "host": "h"})
+	if err != nil {
+		t.Fatalf("insert failed: %v", err)
+	}
+	second, _ := database.Insert(map[string]interface{}{"name": "b"})
+	third, _ := database.Insert(map[string]interface{}{"name": "c"})
+
+	first["name"] = "changed"
+	if database.Get(first["_id"].(string))["name"] != "a" {
+		t.Fatalf("changing a returned record should not change the database")
+	}
+	if _, err := database.Update(first); err != nil {
+		t.Fatalf("update failed: %v", err)
+	}
+	if _, err := database.Update(map[string]interface{}{"_id": "missing"}); err == nil {
+		t.Fatalf("updating a missing record should fail")
+	}
+	patched, err := database.Patch(second["_id"].(string), map[string]interface{}{"host": "x", "name": nil, "_id": "other"})
+	if err != nil || patched["host"] != "x" || patched["name"] != nil || patched["_id"] != second["_id"] {
+		t.Fatalf("unexpected patch %v %v", patched, err)
+	}
+	if _, err := database.Remove(third["_id"].(string)); err != nil {
+		t.Fatalf("remove failed: %v", err)
+	}
+	if _, err := database.Remove(third["_id"].(string)); err == nil {
+		t.Fatalf("removing twice should fail")
+	}
+
+	reloaded := newJsonLinesDatabase(filename).Find()
+	if len(reloaded) != 2 || reloaded[0]["name"] != "changed" || reloaded[1]["host"] != "x" {
+		t.Fatalf("unexpected records on disk %v", reloaded)
+	}
+	if matches, _ := filepath.Glob(filepath.Join(filepath.Dir(filename), "*.tmp")); len(matches) != 0 {
+		t.Fatalf("temporary files should be removed: %v", matches)
+	}
+}
+
+func TestJsonLinesDatabaseOnChange(t *testing.T) {
+	database := newJsonLinesDatabase(filepath.Join(t.TempDir(), "records.jsonl"))
+	count := 0
+	cancel := database.OnChange(func() { count++ })
+	saved, _ := database.Insert(map[string]interface{}{"name": "a"})
+	if _, err := database.RemoveBy(func(obj map[string]interface{}) bool { return false }); err != nil || count != 1 {
+		t.Fatalf("a write without changes should not notify, got %d %v", count, err)
+	}
+	cancel()
+	database.Remove(saved["_id"].(string))
+	if count != 1 {
+		t.Fatalf("a cancelled listener should not be called, got %d", count)
+	}
+}
+
+func TestJsonLinesDatabaseReload(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "records.jsonl")
+	if err := os.WriteFile(filename, []byte("{\"_id\":\"1\"}\nbroken\n{\"_id\":\"2\"}"), 0600); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+	database := newJsonLinesDatabase(filename)
+	if len(database.Find()) != 2 {
+		t.Fatalf("invalid lines should be skipped and the last line kept: %v", database.Find())
+	}
+	other := newJsonLinesDatabase(filename)
+	if _, err := other.Insert(map[string]interface{}{"name": "from another process"}); err != nil {
+		t.Fatalf("insert failed: %v", err)
+	}
+	if len(database.Find()) != 3 {
+		t.Fatalf("changes of another instance should be reloaded: %v", database.Find())
+	}
+}
+
+func TestJsonLinesDatabaseConcurrentWrites(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "records.jsonl")
+	instances := []*JsonLinesDatabase{newJsonLinesDatabase(filename), newJsonLinesDatabase(filename)}
+	var wg sync.WaitGroup
+	for i := 0; i < 40; i++ {
+		wg.Add(1)
+		go func(database *JsonLinesDatabase) {
+			defer wg.Done()
+			if _, err := database.Insert(map[string]interface{}{"name": "x"}); err != nil {
+				t.Errorf("insert failed: %v", err)
+			}
+		}(instances[i%2])
+	}
+	wg.Wait()
+	data, _ := os.ReadFile(filename)
+	if lines := strings.Count(string(data), "\n"); lines != 40 {
+		t.Fatalf("expected 40 records, got %d", lines)
+	}
+}
@@ -0,0 +1,60 @@
+package utility
+
+import (
+	"sync"
+	"time"
+
+	uuid "github.com/satori/go.uuid"
+)
+
+// Confirmations 破坏性操作的确认令牌，dry-run 时签发，执行时核对后作废
+var Confirmations = NewConfirmStore(5 * Minute)
+
+type confirmEntry struct {
+	subject string
+	expires time.Time
+}
+
+// ConfirmStore hands out one-time tokens bound to a subject, a destructive
+// call must present the token of a previous preview of the same operation.
+type ConfirmStore struct {
+	mu     sync.Mutex
+	ttl    time.Duration
+	tokens map[string]*confirmEntry
+}
+
+func NewConfirmStore(ttl time.Duration) *ConfirmStore {
+	return &ConfirmStore{ttl: ttl, tokens: make(map[string]*confirmEntry)}
+}
+
+func (c *ConfirmStore) Issue(subject string) string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.purge(time.Now())
+	token := uuid.NewV4().String()
+	c.tokens[token] = &confirmEntry{subject: subject, expires: time.Now().Add(c.ttl)}
+	return token
+}
+
+// Consume reports whether the token was issued for the subject and has not
+// expired. A token can only be consumed once, even when the subject differs.
+func (c *ConfirmStore) Consume(token, subject string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := time.Now()
+	c.purge(now)
+	entry, ok := c.tokens[token]
+	if !ok {
+		return false
+	}
+	delete(c.tokens, token)
+	return entry.subject == subject && now.Before(entry.expires)
+}
+
+func (c *ConfirmStore) purge(now time.Time) {
+	for token, entry := range c.tokens {
+		if !now.Before(entry.expires) {
+			delete(c.tokens, token)
+		}
+	}
+}
@@ -0,0 +1,37 @@
+package utility
+
+import (
+	"testing"
+	"time"
+)
+
+func TestConfirmStore(t *testing.T) {
+	store := NewConfirmStore(time.Minute)
+	token := store.Issue("drop table a")
+	if store.Consume(token, "drop table b") {
+		t.Fatal("a token must not confirm another subject")
+	}
+	if store.Consume(token, "drop table a") {
+		t.Fatal("a rejected token must not be reusable")
+	}
+
+	token = store.Issue("drop table a")
+	if !store.Consume(token, "drop table a") {
+		t.Fatal("expected the token to be accepted")
+	}
+	if store.Consume(token, "drop table a") {
+		t.Fatal("a token can only be used once")
+	}
+	if store.Consume("", "drop table a") {
+		t.Fatal("an empty token must be rejected")
+	}
+}
+
+func TestConfirmStoreExpires(t *testing.T) {
+	store := NewConfirmStore(time.Millisecond)
+	token := store.Issue("truncate a")
+	time.Sleep(5 * time.Millisecond)
+	if store.Consume(token, "truncate a") {
+		t.Fatal("an expired token must be rejected")
+	}
+}
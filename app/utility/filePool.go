@@ -4,7 +4,9 @@ import (
 	"bufio"
 	"io"
 	"os"
+	"path/filepath"
 	"strings"
+	"tinydb/app/pkg/logger"
 )
 
 //读取所有文件读连接池
@@ -29,34 +31,70 @@ func ReadFileAllPool(name string) ([]map[string]interface{}, error) {
 	//循环的读取文件的内容
 	for {
 		str, err := reader.ReadString('\n') // 读到一个换行就结束
-		if err == io.EOF {                  // io.EOF表示文件的末尾
-			break
+		if err != nil && err != io.EOF {
+			return nil, err
+		}
+		// 最后一行可能没有换行符，空行和损坏的行跳过
+		if text := strings.TrimSpace(str); text != "" {
+			if unmarshal, err := JsonUnmarshal([]byte(text)); err != nil {
+				logger.Errorf("skip invalid line in %s: %v", name, err)
+			} else {
+				list = append(list, unmarshal)
+			}
 		}
-		text := strings.TrimSpace(str)
-		if unmarshal, err := JsonUnmarshal([]byte(text)); err != nil {
+		if err == io.EOF { // io.EOF表示文件的末尾
 			break
-		} else {
-			list = append(list, unmarshal)
 		}
 	}
 	return list, nil
 }
 
+// WriteFileAllPool 先写入同目录下的临时文件再改名，写入中途退出不会损坏原文件
 func WriteFileAllPool(name string, dataSource []map[string]interface{}) error {
-	file, err := os.OpenFile(name, os.O_WRONLY|os.O_TRUNC|os.O_CREATE, os.ModePerm)
+	return writeFileAtomic(name, func(write *bufio.Writer) error {
+		for _, x := range dataSource {
+			if marshal, err := JsonMarshal(x); err == nil {
+				write.WriteString(string(marshal) + "\n")
+			}
+		}
+		return nil
+	})
+}
+
+// WriteFileAtomic 与 WriteFileAllPool 相同的方式整体写入 data
+func WriteFileAtomic(name string, data []byte) error {
+	return writeFileAtomic(name, func(write *bufio.Writer) error {
+		_, err := write.Write(data)
+		return err
+	})
+}
+
+func writeFileAtomic(name string, fn func(write *bufio.Writer) error) error {
+	file, err := os.CreateTemp(filepath.Dir(name), filepath.Base(name)+".*.tmp")
 	if err != nil {
 		return err
 	}
+	tmp := file.Name()
+	defer os.Remove(tmp)
 
-	defer file.Close()
 	//写入文件时，使用带缓存的 *Writer
 	write := bufio.NewWriter(file)
-	for _, x := range dataSource {
-		if marshal, err := JsonMarshal(x); err == nil {
-			write.WriteString(string(marshal) + "\n")
-		}
+	if err := fn(write); err != nil {
+		file.Close()
+		return err
 	}
 
 	//Flush将缓存的文件真正写入到文件中
-	return write.Flush()
+	if err := write.Flush(); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Sync(); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp, name)
 }
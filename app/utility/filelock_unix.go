@@ -0,0 +1,24 @@
+//go:build unix
+
+package utility
+
+import (
+	"os"
+	"syscall"
+)
+
+// lockFile 以独占方式锁定 name，返回解锁函数，其他进程会阻塞等待
+func lockFile(name string) (func(), error) {
+	file, err := os.OpenFile(name, os.O_RDWR|os.O_CREATE, 0600)
+	if err != nil {
+		return nil, err
+	}
+	if err := syscall.Flock(int(file.Fd()), syscall.LOCK_EX); err != nil {
+		file.Close()
+		return nil, err
+	}
+	return func() {
+		syscall.Flock(int(file.Fd()), syscall.LOCK_UN)
+		file.Close()
+	}, nil
+}
@@ -3,6 +3,7 @@ package utility
 import (
 	"github.com/samber/lo"
 	"os"
+	"strconv"
 )
 
 func IsExist(path string) bool {
@@ -51,3 +52,15 @@ func FilterUnknownMap(object map[string]interface{}, keys ...string) map[string]
 
 	return result
 }
+
+// IsTruthy 保存的连接字段都是字符串，"true"/"1" 与布尔值 true 同样视为开启
+func IsTruthy(value interface{}) bool {
+	switch v := value.(type) {
+	case bool:
+		return v
+	case string:
+		b, err := strconv.ParseBool(v)
+		return err == nil && b
+	}
+	return false
+}
@@ -0,0 +1,16 @@
+package utility
+
+import "testing"
+
+func TestIsTruthy(t *testing.T) {
+	for _, value := range []interface{}{true, "true", "1", "TRUE"} {
+		if !IsTruthy(value) {
+			t.Fatalf("%v should be truthy", value)
+		}
+	}
+	for _, value := range []interface{}{false, "false", "", "yes", nil, 1} {
+		if IsTruthy(value) {
+			t.Fatalf("%v should not be truthy", value)
+		}
+	}
+}
@@ -4,10 +4,11 @@ import (
 	"github.com/wailsapp/wails/v3/pkg/application"
 )
 
-func EmitChanged(key string) {
+// EmitChanged 通知前端 key 对应的缓存失效，data 会作为事件数据一起发送
+func EmitChanged(key string, data ...interface{}) {
 	app := application.Get()
 	if app != nil && app.Event != nil {
 		app.Event.Emit("changed-cache", key)
-		app.Event.Emit(key)
+		app.Event.Emit(key, data...)
 	}
 }
@@ -19,6 +19,18 @@ export function GetSettings() {
     }));
 }
 
+/**
+ * UpdateSettings merges values into the saved settings, unknown keys and
+ * invalid values are rejected without saving anything.
+ * @param {{ [_ in string]?: any }} values
+ * @returns {$CancellablePromise<serializer$0.Response | null>}
+ */
+export function UpdateSettings(values) {
+    return $Call.ByID(2401364516, values).then(/** @type {($result: any) => any} */(($result) => {
+        return $$createType1($result);
+    }));
+}
+
 // Private type creation functions
 const $$createType0 = serializer$0.Response.createFrom;
 const $$createType1 = $Create.Nullable($$createType0);
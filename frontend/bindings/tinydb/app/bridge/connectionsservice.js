@@ -24,6 +24,16 @@ export function Delete(connection) {
     }));
 }
 
+/**
+ * @param {$models.ExportConnectionsRequest | null} req
+ * @returns {$CancellablePromise<serializer$0.Response | null>}
+ */
+export function Export(req) {
+    return $Call.ByID(1590103045, req).then(/** @type {($result: any) => any} */(($result) => {
+        return $$createType1($result);
+    }));
+}
+
 /**
  * @param {$models.GetConnectionsRequest | null} req
  * @returns {$CancellablePromise<serializer$0.Response | null>}
@@ -35,6 +45,28 @@ export function Get(req) {
 }
 
 /**
+ * @param {$models.ImportConnectionsRequest | null} req
+ * @returns {$CancellablePromise<serializer$0.Response | null>}
+ */
+export function Import(req) {
+    return $Call.ByID(1023412214, req).then(/** @type {($result: any) => any} */(($result) => {
+        return $$createType1($result);
+    }));
+}
+
+/**
+ * ImportUri saves the connection of a uri unless the same connection exists.
+ * @param {$models.ParseUriRequest | null} req
+ * @returns {$CancellablePromise<serializer$0.Response | null>}
+ */
+export function ImportUri(req) {
+    return $Call.ByID(244703048, req).then(/** @type {($result: any) => any} */(($result) => {
+        return $$createType1($result);
+    }));
+}
+
+/**
+ * List returns the saved connections as a tree of folders.
  * @returns {$CancellablePromise<serializer$0.Response | null>}
  */
 export function List() {
@@ -43,6 +75,17 @@ export function List() {
     }));
 }
 
+/**
+ * ParseUri fills in the connection form from a mysql:// or mongodb:// uri.
+ * @param {$models.ParseUriRequest | null} req
+ * @returns {$CancellablePromise<serializer$0.Response | null>}
+ */
+export function ParseUri(req) {
+    return $Call.ByID(4134346636, req).then(/** @type {($result: any) => any} */(($result) => {
+        return $$createType1($result);
+    }));
+}
+
 /**
  * @param {{ [_ in string]?: string }} connection
  * @returns {$CancellablePromise<serializer$0.Response | null>}
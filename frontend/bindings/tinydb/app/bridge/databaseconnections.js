@@ -34,6 +34,19 @@ export function CreateTable(req) {
     }));
 }
 
+/**
+ * DeleteDocuments runs deleteMany on a collection, deleting without a condition
+ * first returns a confirmation with the number of documents, a filtered delete
+ * needs one when the connection confirms writes.
+ * @param {$models.DeleteDocumentsRequest | null} req
+ * @returns {$CancellablePromise<serializer$0.Response | null>}
+ */
+export function DeleteDocuments(req) {
+    return $Call.ByID(2564752098, req).then(/** @type {($result: any) => any} */(($result) => {
+        return $$createType1($result);
+    }));
+}
+
 /**
  * @param {$models.DatabaseRequest | null} req
  * @returns {$CancellablePromise<serializer$0.Response | null>}
@@ -44,6 +57,17 @@ export function Disconnect(req) {
     }));
 }
 
+/**
+ * Explain returns the normalised query plan of a statement or collection query.
+ * @param {$models.ExplainRequest | null} req
+ * @returns {$CancellablePromise<serializer$0.Response | null>}
+ */
+export function Explain(req) {
+    return $Call.ByID(2719667614, req).then(/** @type {($result: any) => any} */(($result) => {
+        return $$createType1($result);
+    }));
+}
+
 /**
  * @param {$models.DatabaseRequest | null} req
  * @returns {$CancellablePromise<serializer$0.Response | null>}
@@ -54,6 +78,17 @@ export function Ping(req) {
     }));
 }
 
+/**
+ * QueryParameters lists the placeholders of a statement so the editor can ask for their values.
+ * @param {$models.QueryParametersRequest | null} req
+ * @returns {$CancellablePromise<serializer$0.Response | null>}
+ */
+export function QueryParameters(req) {
+    return $Call.ByID(2475756545, req).then(/** @type {($result: any) => any} */(($result) => {
+        return $$createType1($result);
+    }));
+}
+
 /**
  * @param {$models.DatabaseKeepOpenRequest | null} req
  * @returns {$CancellablePromise<serializer$0.Response | null>}
@@ -95,6 +130,7 @@ export function Status(req) {
 }
 
 /**
+ * Structure 连接刚打开时返回空结构，分析完成后发送 database-structure-changed
  * @param {$models.DatabaseRequest | null} req
  * @returns {$CancellablePromise<serializer$0.Response | null>}
  */
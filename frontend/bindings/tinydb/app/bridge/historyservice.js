@@ -0,0 +1,52 @@
+// @ts-check
+// Cynhyrchwyd y ffeil hon yn awtomatig. PEIDIWCH Â MODIWL
+// This file is automatically generated. DO NOT EDIT
+
+// eslint-disable-next-line @typescript-eslint/ban-ts-comment
+// @ts-ignore: Unused imports
+import { Call as $Call, CancellablePromise as $CancellablePromise, Create as $Create } from "@wailsio/runtime";
+
+// eslint-disable-next-line @typescript-eslint/ban-ts-comment
+// @ts-ignore: Unused imports
+import * as history$0 from "../history/models.js";
+// eslint-disable-next-line @typescript-eslint/ban-ts-comment
+// @ts-ignore: Unused imports
+import * as serializer$0 from "../pkg/serializer/models.js";
+
+// eslint-disable-next-line @typescript-eslint/ban-ts-comment
+// @ts-ignore: Unused imports
+import * as $models from "./models.js";
+
+/**
+ * @param {$models.HistoryPinRequest | null} req
+ * @returns {$CancellablePromise<serializer$0.Response | null>}
+ */
+export function Pin(req) {
+    return $Call.ByID(1443956205, req).then(/** @type {($result: any) => any} */(($result) => {
+        return $$createType1($result);
+    }));
+}
+
+/**
+ * @param {$models.HistoryPruneRequest | null} req
+ * @returns {$CancellablePromise<serializer$0.Response | null>}
+ */
+export function Prune(req) {
+    return $Call.ByID(830855474, req).then(/** @type {($result: any) => any} */(($result) => {
+        return $$createType1($result);
+    }));
+}
+
+/**
+ * @param {history$0.Query | null} req
+ * @returns {$CancellablePromise<serializer$0.Response | null>}
+ */
+export function Search(req) {
+    return $Call.ByID(1191595878, req).then(/** @type {($result: any) => any} */(($result) => {
+        return $$createType1($result);
+    }));
+}
+
+// Private type creation functions
+const $$createType0 = serializer$0.Response.createFrom;
+const $$createType1 = $Create.Nullable($$createType0);
@@ -6,26 +6,69 @@ import * as AppService from "./appservice.js";
 import * as Configs from "./configs.js";
 import * as ConnectionsService from "./connectionsservice.js";
 import * as DatabaseConnections from "./databaseconnections.js";
+import * as HistoryService from "./historyservice.js";
+import * as IndexesService from "./indexesservice.js";
+import * as KeyStoreService from "./keystoreservice.js";
+import * as ObjectsService from "./objectsservice.js";
 import * as PluginsService from "./pluginsservice.js";
+import * as SavedQueriesService from "./savedqueriesservice.js";
 import * as ServerConnections from "./serverconnections.js";
+import * as TransferService from "./transferservice.js";
+import * as UsersService from "./usersservice.js";
 export {
     AppService,
     Configs,
     ConnectionsService,
     DatabaseConnections,
+    HistoryService,
+    IndexesService,
+    KeyStoreService,
+    ObjectsService,
     PluginsService,
-    ServerConnections
+    SavedQueriesService,
+    ServerConnections,
+    TransferService,
+    UsersService
 };
 
 export {
     CollectionDataRequest,
+    CopyRequest,
     CreateDatabaseRequest,
+    CreateIndexRequest,
     CreateTableRequest,
     DatabaseKeepOpenRequest,
     DatabaseRequest,
+    DeleteDocumentsRequest,
+    DropIndexRequest,
+    ExplainRequest,
+    ExportConnectionsRequest,
+    ExportRequest,
     GetConnectionsRequest,
+    HideIndexRequest,
+    HistoryPinRequest,
+    HistoryPruneRequest,
+    ImportConnectionsRequest,
+    ImportRequest,
+    IndexesRequest,
+    KillProcessRequest,
+    ObjectRequest,
+    ParseUriRequest,
+    PreviewImportRequest,
+    ProcessListRequest,
+    QueryParametersRequest,
+    RenderSnippetRequest,
+    RunScriptRequest,
+    SavedQueryRequest,
     ScriptRequest,
+    ServerMetricsRequest,
     ServerPingRequest,
     ServerRefreshRequest,
-    SqlSelectRequest
+    SqlSelectRequest,
+    SwitchKeyStoreRequest,
+    TransferJobRequest,
+    UnlockKeyStoreRequest,
+    UserChangeRequest,
+    UserGrantsRequest,
+    UsersRequest
 } from "./models.js";
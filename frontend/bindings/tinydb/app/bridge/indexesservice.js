@@ -0,0 +1,60 @@
+// @ts-check
+// Cynhyrchwyd y ffeil hon yn awtomatig. PEIDIWCH Â MODIWL
+// This file is automatically generated. DO NOT EDIT
+
+// eslint-disable-next-line @typescript-eslint/ban-ts-comment
+// @ts-ignore: Unused imports
+import { Call as $Call, CancellablePromise as $CancellablePromise, Create as $Create } from "@wailsio/runtime";
+
+// eslint-disable-next-line @typescript-eslint/ban-ts-comment
+// @ts-ignore: Unused imports
+import * as serializer$0 from "../pkg/serializer/models.js";
+
+// eslint-disable-next-line @typescript-eslint/ban-ts-comment
+// @ts-ignore: Unused imports
+import * as $models from "./models.js";
+
+/**
+ * Create creates an index, with preview set only the statement is returned.
+ * @param {$models.CreateIndexRequest | null} req
+ * @returns {$CancellablePromise<serializer$0.Response | null>}
+ */
+export function Create(req) {
+    return $Call.ByID(3755894922, req).then(/** @type {($result: any) => any} */(($result) => {
+        return $$createType1($result);
+    }));
+}
+
+/**
+ * @param {$models.DropIndexRequest | null} req
+ * @returns {$CancellablePromise<serializer$0.Response | null>}
+ */
+export function Drop(req) {
+    return $Call.ByID(679199347, req).then(/** @type {($result: any) => any} */(($result) => {
+        return $$createType1($result);
+    }));
+}
+
+/**
+ * @param {$models.HideIndexRequest | null} req
+ * @returns {$CancellablePromise<serializer$0.Response | null>}
+ */
+export function Hide(req) {
+    return $Call.ByID(1460729262, req).then(/** @type {($result: any) => any} */(($result) => {
+        return $$createType1($result);
+    }));
+}
+
+/**
+ * @param {$models.IndexesRequest | null} req
+ * @returns {$CancellablePromise<serializer$0.Response | null>}
+ */
+export function List(req) {
+    return $Call.ByID(3943995898, req).then(/** @type {($result: any) => any} */(($result) => {
+        return $$createType1($result);
+    }));
+}
+
+// Private type creation functions
+const $$createType0 = serializer$0.Response.createFrom;
+const $$createType1 = $Create.Nullable($$createType0);
@@ -0,0 +1,51 @@
+// @ts-check
+// Cynhyrchwyd y ffeil hon yn awtomatig. PEIDIWCH Â MODIWL
+// This file is automatically generated. DO NOT EDIT
+
+// eslint-disable-next-line @typescript-eslint/ban-ts-comment
+// @ts-ignore: Unused imports
+import { Call as $Call, CancellablePromise as $CancellablePromise, Create as $Create } from "@wailsio/runtime";
+
+// eslint-disable-next-line @typescript-eslint/ban-ts-comment
+// @ts-ignore: Unused imports
+import * as serializer$0 from "../pkg/serializer/models.js";
+
+// eslint-disable-next-line @typescript-eslint/ban-ts-comment
+// @ts-ignore: Unused imports
+import * as $models from "./models.js";
+
+/**
+ * Status returns the key store mode and whether the master password is needed.
+ * @returns {$CancellablePromise<serializer$0.Response | null>}
+ */
+export function Status() {
+    return $Call.ByID(1940982018).then(/** @type {($result: any) => any} */(($result) => {
+        return $$createType1($result);
+    }));
+}
+
+/**
+ * Switch moves the encryption key to another store and re-encrypts the saved
+ * passwords with a new key.
+ * @param {$models.SwitchKeyStoreRequest | null} req
+ * @returns {$CancellablePromise<serializer$0.Response | null>}
+ */
+export function Switch(req) {
+    return $Call.ByID(3422271276, req).then(/** @type {($result: any) => any} */(($result) => {
+        return $$createType1($result);
+    }));
+}
+
+/**
+ * @param {$models.UnlockKeyStoreRequest | null} req
+ * @returns {$CancellablePromise<serializer$0.Response | null>}
+ */
+export function Unlock(req) {
+    return $Call.ByID(837313468, req).then(/** @type {($result: any) => any} */(($result) => {
+        return $$createType1($result);
+    }));
+}
+
+// Private type creation functions
+const $$createType0 = serializer$0.Response.createFrom;
+const $$createType1 = $Create.Nullable($$createType0);
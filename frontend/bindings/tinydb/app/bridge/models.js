@@ -6,9 +6,21 @@
 // @ts-ignore: Unused imports
 import { Create as $Create } from "@wailsio/runtime";
 
+// eslint-disable-next-line @typescript-eslint/ban-ts-comment
+// @ts-ignore: Unused imports
+import * as script$0 from "../db/script/models.js";
 // eslint-disable-next-line @typescript-eslint/ban-ts-comment
 // @ts-ignore: Unused imports
 import * as modules$0 from "../db/standard/modules/models.js";
+// eslint-disable-next-line @typescript-eslint/ban-ts-comment
+// @ts-ignore: Unused imports
+import * as transfer$0 from "../transfer/models.js";
+// eslint-disable-next-line @typescript-eslint/ban-ts-comment
+// @ts-ignore: Unused imports
+import * as users$0 from "../users/models.js";
+// eslint-disable-next-line @typescript-eslint/ban-ts-comment
+// @ts-ignore: Unused imports
+import * as utility$0 from "../utility/models.js";
 
 export class CollectionDataRequest {
     /**
@@ -56,6 +68,94 @@ export class CollectionDataRequest {
     }
 }
 
+export class CopyRequest {
+    /**
+     * Creates a new CopyRequest instance.
+     * @param {Partial<CopyRequest>} [$$source = {}] - The source object to create the CopyRequest.
+     */
+    constructor($$source = {}) {
+        if (!("source" in $$source)) {
+            /**
+             * @member
+             * @type {transfer$0.CopyEndpoint}
+             */
+            this["source"] = (new transfer$0.CopyEndpoint());
+        }
+        if (!("target" in $$source)) {
+            /**
+             * @member
+             * @type {transfer$0.CopyEndpoint}
+             */
+            this["target"] = (new transfer$0.CopyEndpoint());
+        }
+        if (!("condition" in $$source)) {
+            /**
+             * Condition 仅在源为 Mongo 集合时生效
+             * @member
+             * @type {{ [_ in string]?: any }}
+             */
+            this["condition"] = {};
+        }
+        if (!("mode" in $$source)) {
+            /**
+             * @member
+             * @type {string}
+             */
+            this["mode"] = "";
+        }
+        if (!("keyColumns" in $$source)) {
+            /**
+             * KeyColumns 为空时使用源表主键，Mongo 源默认为 _id
+             * @member
+             * @type {string[]}
+             */
+            this["keyColumns"] = [];
+        }
+        if (!("batchSize" in $$source)) {
+            /**
+             * @member
+             * @type {number}
+             */
+            this["batchSize"] = 0;
+        }
+        if (!("confirmToken" in $$source)) {
+            /**
+             * @member
+             * @type {string}
+             */
+            this["confirmToken"] = "";
+        }
+
+        Object.assign(this, $$source);
+    }
+
+    /**
+     * Creates a new CopyRequest instance from a string or object.
+     * @param {any} [$$source = {}]
+     * @returns {CopyRequest}
+     */
+    static createFrom($$source = {}) {
+        const $$createField0_0 = $$createType2;
+        const $$createField1_0 = $$createType2;
+        const $$createField2_0 = $$createType3;
+        const $$createField4_0 = $$createType4;
+        let $$parsedSource = typeof $$source === 'string' ? JSON.parse($$source) : $$source;
+        if ("source" in $$parsedSource) {
+            $$parsedSource["source"] = $$createField0_0($$parsedSource["source"]);
+        }
+        if ("target" in $$parsedSource) {
+            $$parsedSource["target"] = $$createField1_0($$parsedSource["target"]);
+        }
+        if ("condition" in $$parsedSource) {
+            $$parsedSource["condition"] = $$createField2_0($$parsedSource["condition"]);
+        }
+        if ("keyColumns" in $$parsedSource) {
+            $$parsedSource["keyColumns"] = $$createField4_0($$parsedSource["keyColumns"]);
+        }
+        return new CopyRequest(/** @type {Partial<CopyRequest>} */($$parsedSource));
+    }
+}
+
 export class CreateDatabaseRequest {
     /**
      * Creates a new CreateDatabaseRequest instance.
@@ -91,6 +191,90 @@ export class CreateDatabaseRequest {
     }
 }
 
+export class CreateIndexRequest {
+    /**
+     * Creates a new CreateIndexRequest instance.
+     * @param {Partial<CreateIndexRequest>} [$$source = {}] - The source object to create the CreateIndexRequest.
+     */
+    constructor($$source = {}) {
+        if (!("conid" in $$source)) {
+            /**
+             * @member
+             * @type {string}
+             */
+            this["conid"] = "";
+        }
+        if (!("database" in $$source)) {
+            /**
+             * @member
+             * @type {string}
+             */
+            this["database"] = "";
+        }
+        if (!("pureName" in $$source)) {
+            /**
+             * @member
+             * @type {string}
+             */
+            this["pureName"] = "";
+        }
+        if (!("spec" in $$source)) {
+            /**
+             * Spec Mongo 索引定义的 Extended JSON 文本，与 createIndexes 命令中的单个索引相同
+             * @member
+             * @type {string}
+             */
+            this["spec"] = "";
+        }
+        if (!("index" in $$source)) {
+            /**
+             * Index 与 Unique 用于 MySQL，Index 为分析器生成的 indexes/uniques 结构
+             * @member
+             * @type {{ [_ in string]?: any }}
+             */
+            this["index"] = {};
+        }
+        if (!("unique" in $$source)) {
+            /**
+             * @member
+             * @type {boolean}
+             */
+            this["unique"] = false;
+        }
+        if (!("preview" in $$source)) {
+            /**
+             * Preview 只返回将要执行的语句
+             * @member
+             * @type {boolean}
+             */
+            this["preview"] = false;
+        }
+        if (!("confirmToken" in $$source)) {
+            /**
+             * @member
+             * @type {string}
+             */
+            this["confirmToken"] = "";
+        }
+
+        Object.assign(this, $$source);
+    }
+
+    /**
+     * Creates a new CreateIndexRequest instance from a string or object.
+     * @param {any} [$$source = {}]
+     * @returns {CreateIndexRequest}
+     */
+    static createFrom($$source = {}) {
+        const $$createField4_0 = $$createType3;
+        let $$parsedSource = typeof $$source === 'string' ? JSON.parse($$source) : $$source;
+        if ("index" in $$parsedSource) {
+            $$parsedSource["index"] = $$createField4_0($$parsedSource["index"]);
+        }
+        return new CreateIndexRequest(/** @type {Partial<CreateIndexRequest>} */($$parsedSource));
+    }
+}
+
 export class CreateTableRequest {
     /**
      * Creates a new CreateTableRequest instance.
@@ -125,6 +309,14 @@ export class CreateTableRequest {
              */
             this["columns"] = [];
         }
+        if (!("confirmToken" in $$source)) {
+            /**
+             * ConfirmToken 连接需要确认写入时，重新提交携带的确认令牌
+             * @member
+             * @type {string}
+             */
+            this["confirmToken"] = "";
+        }
 
         Object.assign(this, $$source);
     }
@@ -135,7 +327,7 @@ export class CreateTableRequest {
      * @returns {CreateTableRequest}
      */
     static createFrom($$source = {}) {
-        const $$createField3_0 = $$createType3;
+        const $$createField3_0 = $$createType5;
         let $$parsedSource = typeof $$source === 'string' ? JSON.parse($$source) : $$source;
         if ("columns" in $$parsedSource) {
             $$parsedSource["columns"] = $$createField3_0($$parsedSource["columns"]);
@@ -221,10 +413,10 @@ export class DatabaseRequest {
     }
 }
 
-export class GetConnectionsRequest {
+export class DeleteDocumentsRequest {
     /**
-     * Creates a new GetConnectionsRequest instance.
-     * @param {Partial<GetConnectionsRequest>} [$$source = {}] - The source object to create the GetConnectionsRequest.
+     * Creates a new DeleteDocumentsRequest instance.
+     * @param {Partial<DeleteDocumentsRequest>} [$$source = {}] - The source object to create the DeleteDocumentsRequest.
      */
     constructor($$source = {}) {
         if (!("conid" in $$source)) {
@@ -234,120 +426,227 @@ export class GetConnectionsRequest {
              */
             this["conid"] = "";
         }
+        if (!("database" in $$source)) {
+            /**
+             * @member
+             * @type {string}
+             */
+            this["database"] = "";
+        }
+        if (!("pureName" in $$source)) {
+            /**
+             * @member
+             * @type {string}
+             */
+            this["pureName"] = "";
+        }
+        if (!("condition" in $$source)) {
+            /**
+             * @member
+             * @type {{ [_ in string]?: any }}
+             */
+            this["condition"] = {};
+        }
+        if (!("confirmToken" in $$source)) {
+            /**
+             * @member
+             * @type {string}
+             */
+            this["confirmToken"] = "";
+        }
 
         Object.assign(this, $$source);
     }
 
     /**
-     * Creates a new GetConnectionsRequest instance from a string or object.
+     * Creates a new DeleteDocumentsRequest instance from a string or object.
      * @param {any} [$$source = {}]
-     * @returns {GetConnectionsRequest}
+     * @returns {DeleteDocumentsRequest}
      */
     static createFrom($$source = {}) {
+        const $$createField3_0 = $$createType3;
         let $$parsedSource = typeof $$source === 'string' ? JSON.parse($$source) : $$source;
-        return new GetConnectionsRequest(/** @type {Partial<GetConnectionsRequest>} */($$parsedSource));
+        if ("condition" in $$parsedSource) {
+            $$parsedSource["condition"] = $$createField3_0($$parsedSource["condition"]);
+        }
+        return new DeleteDocumentsRequest(/** @type {Partial<DeleteDocumentsRequest>} */($$parsedSource));
     }
 }
 
-export class ScriptRequest {
+export class DropIndexRequest {
     /**
-     * Creates a new ScriptRequest instance.
-     * @param {Partial<ScriptRequest>} [$$source = {}] - The source object to create the ScriptRequest.
+     * Creates a new DropIndexRequest instance.
+     * @param {Partial<DropIndexRequest>} [$$source = {}] - The source object to create the DropIndexRequest.
      */
     constructor($$source = {}) {
-        if (!("packageName" in $$source)) {
+        if (!("conid" in $$source)) {
             /**
              * @member
              * @type {string}
              */
-            this["packageName"] = "";
+            this["conid"] = "";
+        }
+        if (!("database" in $$source)) {
+            /**
+             * @member
+             * @type {string}
+             */
+            this["database"] = "";
+        }
+        if (!("pureName" in $$source)) {
+            /**
+             * @member
+             * @type {string}
+             */
+            this["pureName"] = "";
+        }
+        if (!("name" in $$source)) {
+            /**
+             * @member
+             * @type {string}
+             */
+            this["name"] = "";
+        }
+        if (!("preview" in $$source)) {
+            /**
+             * @member
+             * @type {boolean}
+             */
+            this["preview"] = false;
+        }
+        if (!("confirmToken" in $$source)) {
+            /**
+             * @member
+             * @type {string}
+             */
+            this["confirmToken"] = "";
         }
 
         Object.assign(this, $$source);
     }
 
     /**
-     * Creates a new ScriptRequest instance from a string or object.
+     * Creates a new DropIndexRequest instance from a string or object.
      * @param {any} [$$source = {}]
-     * @returns {ScriptRequest}
+     * @returns {DropIndexRequest}
      */
     static createFrom($$source = {}) {
         let $$parsedSource = typeof $$source === 'string' ? JSON.parse($$source) : $$source;
-        return new ScriptRequest(/** @type {Partial<ScriptRequest>} */($$parsedSource));
+        return new DropIndexRequest(/** @type {Partial<DropIndexRequest>} */($$parsedSource));
     }
 }
 
-export class ServerPingRequest {
+export class ExplainRequest {
     /**
-     * Creates a new ServerPingRequest instance.
-     * @param {Partial<ServerPingRequest>} [$$source = {}] - The source object to create the ServerPingRequest.
+     * Creates a new ExplainRequest instance.
+     * @param {Partial<ExplainRequest>} [$$source = {}] - The source object to create the ExplainRequest.
      */
     constructor($$source = {}) {
-        if (!("connections" in $$source)) {
+        if (!("conid" in $$source)) {
             /**
              * @member
-             * @type {string[]}
+             * @type {string}
              */
-            this["connections"] = [];
+            this["conid"] = "";
+        }
+        if (!("database" in $$source)) {
+            /**
+             * @member
+             * @type {string}
+             */
+            this["database"] = "";
+        }
+        if (!("sql" in $$source)) {
+            /**
+             * @member
+             * @type {string}
+             */
+            this["sql"] = "";
+        }
+        if (!("analyze" in $$source)) {
+            /**
+             * @member
+             * @type {boolean}
+             */
+            this["analyze"] = false;
+        }
+        if (!("mongo" in $$source)) {
+            /**
+             * @member
+             * @type {modules$0.CollectionDataOptions | null}
+             */
+            this["mongo"] = null;
         }
 
         Object.assign(this, $$source);
     }
 
     /**
-     * Creates a new ServerPingRequest instance from a string or object.
+     * Creates a new ExplainRequest instance from a string or object.
      * @param {any} [$$source = {}]
-     * @returns {ServerPingRequest}
+     * @returns {ExplainRequest}
      */
     static createFrom($$source = {}) {
-        const $$createField0_0 = $$createType4;
+        const $$createField4_0 = $$createType1;
         let $$parsedSource = typeof $$source === 'string' ? JSON.parse($$source) : $$source;
-        if ("connections" in $$parsedSource) {
-            $$parsedSource["connections"] = $$createField0_0($$parsedSource["connections"]);
+        if ("mongo" in $$parsedSource) {
+            $$parsedSource["mongo"] = $$createField4_0($$parsedSource["mongo"]);
         }
-        return new ServerPingRequest(/** @type {Partial<ServerPingRequest>} */($$parsedSource));
+        return new ExplainRequest(/** @type {Partial<ExplainRequest>} */($$parsedSource));
     }
 }
 
-export class ServerRefreshRequest {
+export class ExportConnectionsRequest {
     /**
-     * Creates a new ServerRefreshRequest instance.
-     * @param {Partial<ServerRefreshRequest>} [$$source = {}] - The source object to create the ServerRefreshRequest.
+     * Creates a new ExportConnectionsRequest instance.
+     * @param {Partial<ExportConnectionsRequest>} [$$source = {}] - The source object to create the ExportConnectionsRequest.
      */
     constructor($$source = {}) {
-        if (!("conid" in $$source)) {
+        if (!("conids" in $$source)) {
+            /**
+             * Conids 为空时导出全部连接
+             * @member
+             * @type {string[]}
+             */
+            this["conids"] = [];
+        }
+        if (!("passphrase" in $$source)) {
             /**
              * @member
              * @type {string}
              */
-            this["conid"] = "";
+            this["passphrase"] = "";
         }
-        if (!("keepOpen" in $$source)) {
+        if (!("filePath" in $$source)) {
             /**
              * @member
-             * @type {boolean}
+             * @type {string}
              */
-            this["keepOpen"] = false;
+            this["filePath"] = "";
         }
 
         Object.assign(this, $$source);
     }
 
     /**
-     * Creates a new ServerRefreshRequest instance from a string or object.
+     * Creates a new ExportConnectionsRequest instance from a string or object.
      * @param {any} [$$source = {}]
-     * @returns {ServerRefreshRequest}
+     * @returns {ExportConnectionsRequest}
      */
     static createFrom($$source = {}) {
+        const $$createField0_0 = $$createType4;
         let $$parsedSource = typeof $$source === 'string' ? JSON.parse($$source) : $$source;
-        return new ServerRefreshRequest(/** @type {Partial<ServerRefreshRequest>} */($$parsedSource));
+        if ("conids" in $$parsedSource) {
+            $$parsedSource["conids"] = $$createField0_0($$parsedSource["conids"]);
+        }
+        return new ExportConnectionsRequest(/** @type {Partial<ExportConnectionsRequest>} */($$parsedSource));
     }
 }
 
-export class SqlSelectRequest {
+export class ExportRequest {
     /**
-     * Creates a new SqlSelectRequest instance.
-     * @param {Partial<SqlSelectRequest>} [$$source = {}] - The source object to create the SqlSelectRequest.
+     * Creates a new ExportRequest instance.
+     * @param {Partial<ExportRequest>} [$$source = {}] - The source object to create the ExportRequest.
      */
     constructor($$source = {}) {
         if (!("conid" in $$source)) {
@@ -364,15 +663,1086 @@ export class SqlSelectRequest {
              */
             this["database"] = "";
         }
-        if (!("Select" in $$source)) {
+        if (!("format" in $$source)) {
             /**
              * @member
-             * @type {any}
+             * @type {string}
              */
-            this["Select"] = null;
+            this["format"] = "";
         }
-
-        Object.assign(this, $$source);
+        if (!("filePath" in $$source)) {
+            /**
+             * @member
+             * @type {string}
+             */
+            this["filePath"] = "";
+        }
+        if (!("delimiter" in $$source)) {
+            /**
+             * @member
+             * @type {string}
+             */
+            this["delimiter"] = "";
+        }
+        if (!("quote" in $$source)) {
+            /**
+             * @member
+             * @type {string}
+             */
+            this["quote"] = "";
+        }
+        if (!("noHeader" in $$source)) {
+            /**
+             * @member
+             * @type {boolean}
+             */
+            this["noHeader"] = false;
+        }
+        if (!("tableName" in $$source)) {
+            /**
+             * @member
+             * @type {string}
+             */
+            this["tableName"] = "";
+        }
+        if (!("insertBatchSize" in $$source)) {
+            /**
+             * InsertBatchSize 为 SQL 格式时每条 INSERT 包含的行数
+             * @member
+             * @type {number}
+             */
+            this["insertBatchSize"] = 0;
+        }
+        if (!("sql" in $$source)) {
+            /**
+             * @member
+             * @type {string}
+             */
+            this["sql"] = "";
+        }
+        if (!("pureName" in $$source)) {
+            /**
+             * @member
+             * @type {string}
+             */
+            this["pureName"] = "";
+        }
+        if (!("condition" in $$source)) {
+            /**
+             * @member
+             * @type {{ [_ in string]?: any }}
+             */
+            this["condition"] = {};
+        }
+
+        Object.assign(this, $$source);
+    }
+
+    /**
+     * Creates a new ExportRequest instance from a string or object.
+     * @param {any} [$$source = {}]
+     * @returns {ExportRequest}
+     */
+    static createFrom($$source = {}) {
+        const $$createField11_0 = $$createType3;
+        let $$parsedSource = typeof $$source === 'string' ? JSON.parse($$source) : $$source;
+        if ("condition" in $$parsedSource) {
+            $$parsedSource["condition"] = $$createField11_0($$parsedSource["condition"]);
+        }
+        return new ExportRequest(/** @type {Partial<ExportRequest>} */($$parsedSource));
+    }
+}
+
+export class GetConnectionsRequest {
+    /**
+     * Creates a new GetConnectionsRequest instance.
+     * @param {Partial<GetConnectionsRequest>} [$$source = {}] - The source object to create the GetConnectionsRequest.
+     */
+    constructor($$source = {}) {
+        if (!("conid" in $$source)) {
+            /**
+             * @member
+             * @type {string}
+             */
+            this["conid"] = "";
+        }
+
+        Object.assign(this, $$source);
+    }
+
+    /**
+     * Creates a new GetConnectionsRequest instance from a string or object.
+     * @param {any} [$$source = {}]
+     * @returns {GetConnectionsRequest}
+     */
+    static createFrom($$source = {}) {
+        let $$parsedSource = typeof $$source === 'string' ? JSON.parse($$source) : $$source;
+        return new GetConnectionsRequest(/** @type {Partial<GetConnectionsRequest>} */($$parsedSource));
+    }
+}
+
+export class HideIndexRequest {
+    /**
+     * Creates a new HideIndexRequest instance.
+     * @param {Partial<HideIndexRequest>} [$$source = {}] - The source object to create the HideIndexRequest.
+     */
+    constructor($$source = {}) {
+        if (!("conid" in $$source)) {
+            /**
+             * @member
+             * @type {string}
+             */
+            this["conid"] = "";
+        }
+        if (!("database" in $$source)) {
+            /**
+             * @member
+             * @type {string}
+             */
+            this["database"] = "";
+        }
+        if (!("pureName" in $$source)) {
+            /**
+             * @member
+             * @type {string}
+             */
+            this["pureName"] = "";
+        }
+        if (!("name" in $$source)) {
+            /**
+             * @member
+             * @type {string}
+             */
+            this["name"] = "";
+        }
+        if (!("hidden" in $$source)) {
+            /**
+             * @member
+             * @type {boolean}
+             */
+            this["hidden"] = false;
+        }
+        if (!("confirmToken" in $$source)) {
+            /**
+             * @member
+             * @type {string}
+             */
+            this["confirmToken"] = "";
+        }
+
+        Object.assign(this, $$source);
+    }
+
+    /**
+     * Creates a new HideIndexRequest instance from a string or object.
+     * @param {any} [$$source = {}]
+     * @returns {HideIndexRequest}
+     */
+    static createFrom($$source = {}) {
+        let $$parsedSource = typeof $$source === 'string' ? JSON.parse($$source) : $$source;
+        return new HideIndexRequest(/** @type {Partial<HideIndexRequest>} */($$parsedSource));
+    }
+}
+
+export class HistoryPinRequest {
+    /**
+     * Creates a new HistoryPinRequest instance.
+     * @param {Partial<HistoryPinRequest>} [$$source = {}] - The source object to create the HistoryPinRequest.
+     */
+    constructor($$source = {}) {
+        if (!("conid" in $$source)) {
+            /**
+             * @member
+             * @type {string}
+             */
+            this["conid"] = "";
+        }
+        if (!("id" in $$source)) {
+            /**
+             * @member
+             * @type {string}
+             */
+            this["id"] = "";
+        }
+        if (!("pinned" in $$source)) {
+            /**
+             * @member
+             * @type {boolean}
+             */
+            this["pinned"] = false;
+        }
+
+        Object.assign(this, $$source);
+    }
+
+    /**
+     * Creates a new HistoryPinRequest instance from a string or object.
+     * @param {any} [$$source = {}]
+     * @returns {HistoryPinRequest}
+     */
+    static createFrom($$source = {}) {
+        let $$parsedSource = typeof $$source === 'string' ? JSON.parse($$source) : $$source;
+        return new HistoryPinRequest(/** @type {Partial<HistoryPinRequest>} */($$parsedSource));
+    }
+}
+
+export class HistoryPruneRequest {
+    /**
+     * Creates a new HistoryPruneRequest instance.
+     * @param {Partial<HistoryPruneRequest>} [$$source = {}] - The source object to create the HistoryPruneRequest.
+     */
+    constructor($$source = {}) {
+        if (!("conid" in $$source)) {
+            /**
+             * @member
+             * @type {string}
+             */
+            this["conid"] = "";
+        }
+        if (!("before" in $$source)) {
+            /**
+             * Before 为空时删除全部未固定的记录
+             * @member
+             * @type {utility$0.UnixTime}
+             */
+            this["before"] = 0;
+        }
+
+        Object.assign(this, $$source);
+    }
+
+    /**
+     * Creates a new HistoryPruneRequest instance from a string or object.
+     * @param {any} [$$source = {}]
+     * @returns {HistoryPruneRequest}
+     */
+    static createFrom($$source = {}) {
+        let $$parsedSource = typeof $$source === 'string' ? JSON.parse($$source) : $$source;
+        return new HistoryPruneRequest(/** @type {Partial<HistoryPruneRequest>} */($$parsedSource));
+    }
+}
+
+export class ImportConnectionsRequest {
+    /**
+     * Creates a new ImportConnectionsRequest instance.
+     * @param {Partial<ImportConnectionsRequest>} [$$source = {}] - The source object to create the ImportConnectionsRequest.
+     */
+    constructor($$source = {}) {
+        if (!("passphrase" in $$source)) {
+            /**
+             * @member
+             * @type {string}
+             */
+            this["passphrase"] = "";
+        }
+        if (!("filePath" in $$source)) {
+            /**
+             * @member
+             * @type {string}
+             */
+            this["filePath"] = "";
+        }
+
+        Object.assign(this, $$source);
+    }
+
+    /**
+     * Creates a new ImportConnectionsRequest instance from a string or object.
+     * @param {any} [$$source = {}]
+     * @returns {ImportConnectionsRequest}
+     */
+    static createFrom($$source = {}) {
+        let $$parsedSource = typeof $$source === 'string' ? JSON.parse($$source) : $$source;
+        return new ImportConnectionsRequest(/** @type {Partial<ImportConnectionsRequest>} */($$parsedSource));
+    }
+}
+
+export class ImportRequest {
+    /**
+     * Creates a new ImportRequest instance.
+     * @param {Partial<ImportRequest>} [$$source = {}] - The source object to create the ImportRequest.
+     */
+    constructor($$source = {}) {
+        if (!("conid" in $$source)) {
+            /**
+             * @member
+             * @type {string}
+             */
+            this["conid"] = "";
+        }
+        if (!("database" in $$source)) {
+            /**
+             * @member
+             * @type {string}
+             */
+            this["database"] = "";
+        }
+        if (!("format" in $$source)) {
+            /**
+             * @member
+             * @type {string}
+             */
+            this["format"] = "";
+        }
+        if (!("filePath" in $$source)) {
+            /**
+             * @member
+             * @type {string}
+             */
+            this["filePath"] = "";
+        }
+        if (!("delimiter" in $$source)) {
+            /**
+             * @member
+             * @type {string}
+             */
+            this["delimiter"] = "";
+        }
+        if (!("noHeader" in $$source)) {
+            /**
+             * @member
+             * @type {boolean}
+             */
+            this["noHeader"] = false;
+        }
+        if (!("sheet" in $$source)) {
+            /**
+             * Sheet 为空时读取 xlsx 的第一个工作表
+             * @member
+             * @type {string}
+             */
+            this["sheet"] = "";
+        }
+        if (!("pureName" in $$source)) {
+            /**
+             * @member
+             * @type {string}
+             */
+            this["pureName"] = "";
+        }
+        if (!("mapping" in $$source)) {
+            /**
+             * @member
+             * @type {(transfer$0.FieldMapping | null)[]}
+             */
+            this["mapping"] = [];
+        }
+        if (!("createTable" in $$source)) {
+            /**
+             * CreateTable 为 true 时先按 Columns 建表（Mongo 为建集合）
+             * @member
+             * @type {boolean}
+             */
+            this["createTable"] = false;
+        }
+        if (!("columns" in $$source)) {
+            /**
+             * @member
+             * @type {{ [_ in string]?: any }[]}
+             */
+            this["columns"] = [];
+        }
+        if (!("batchSize" in $$source)) {
+            /**
+             * @member
+             * @type {number}
+             */
+            this["batchSize"] = 0;
+        }
+        if (!("onError" in $$source)) {
+            /**
+             * @member
+             * @type {string}
+             */
+            this["onError"] = "";
+        }
+        if (!("dryRun" in $$source)) {
+            /**
+             * DryRun 只校验不落库，MySQL 在事务里执行后回滚
+             * @member
+             * @type {boolean}
+             */
+            this["dryRun"] = false;
+        }
+        if (!("confirmToken" in $$source)) {
+            /**
+             * @member
+             * @type {string}
+             */
+            this["confirmToken"] = "";
+        }
+
+        Object.assign(this, $$source);
+    }
+
+    /**
+     * Creates a new ImportRequest instance from a string or object.
+     * @param {any} [$$source = {}]
+     * @returns {ImportRequest}
+     */
+    static createFrom($$source = {}) {
+        const $$createField8_0 = $$createType8;
+        const $$createField10_0 = $$createType5;
+        let $$parsedSource = typeof $$source === 'string' ? JSON.parse($$source) : $$source;
+        if ("mapping" in $$parsedSource) {
+            $$parsedSource["mapping"] = $$createField8_0($$parsedSource["mapping"]);
+        }
+        if ("columns" in $$parsedSource) {
+            $$parsedSource["columns"] = $$createField10_0($$parsedSource["columns"]);
+        }
+        return new ImportRequest(/** @type {Partial<ImportRequest>} */($$parsedSource));
+    }
+}
+
+export class IndexesRequest {
+    /**
+     * Creates a new IndexesRequest instance.
+     * @param {Partial<IndexesRequest>} [$$source = {}] - The source object to create the IndexesRequest.
+     */
+    constructor($$source = {}) {
+        if (!("conid" in $$source)) {
+            /**
+             * @member
+             * @type {string}
+             */
+            this["conid"] = "";
+        }
+        if (!("database" in $$source)) {
+            /**
+             * @member
+             * @type {string}
+             */
+            this["database"] = "";
+        }
+        if (!("pureName" in $$source)) {
+            /**
+             * @member
+             * @type {string}
+             */
+            this["pureName"] = "";
+        }
+
+        Object.assign(this, $$source);
+    }
+
+    /**
+     * Creates a new IndexesRequest instance from a string or object.
+     * @param {any} [$$source = {}]
+     * @returns {IndexesRequest}
+     */
+    static createFrom($$source = {}) {
+        let $$parsedSource = typeof $$source === 'string' ? JSON.parse($$source) : $$source;
+        return new IndexesRequest(/** @type {Partial<IndexesRequest>} */($$parsedSource));
+    }
+}
+
+export class KillProcessRequest {
+    /**
+     * Creates a new KillProcessRequest instance.
+     * @param {Partial<KillProcessRequest>} [$$source = {}] - The source object to create the KillProcessRequest.
+     */
+    constructor($$source = {}) {
+        if (!("conid" in $$source)) {
+            /**
+             * @member
+             * @type {string}
+             */
+            this["conid"] = "";
+        }
+        if (!("id" in $$source)) {
+            /**
+             * @member
+             * @type {string}
+             */
+            this["id"] = "";
+        }
+        if (!("queryOnly" in $$source)) {
+            /**
+             * QueryOnly 只终止正在执行的语句，保留连接（仅 MySQL）
+             * @member
+             * @type {boolean}
+             */
+            this["queryOnly"] = false;
+        }
+        if (!("confirmToken" in $$source)) {
+            /**
+             * @member
+             * @type {string}
+             */
+            this["confirmToken"] = "";
+        }
+
+        Object.assign(this, $$source);
+    }
+
+    /**
+     * Creates a new KillProcessRequest instance from a string or object.
+     * @param {any} [$$source = {}]
+     * @returns {KillProcessRequest}
+     */
+    static createFrom($$source = {}) {
+        let $$parsedSource = typeof $$source === 'string' ? JSON.parse($$source) : $$source;
+        return new KillProcessRequest(/** @type {Partial<KillProcessRequest>} */($$parsedSource));
+    }
+}
+
+export class ObjectRequest {
+    /**
+     * Creates a new ObjectRequest instance.
+     * @param {Partial<ObjectRequest>} [$$source = {}] - The source object to create the ObjectRequest.
+     */
+    constructor($$source = {}) {
+        if (!("conid" in $$source)) {
+            /**
+             * @member
+             * @type {string}
+             */
+            this["conid"] = "";
+        }
+        if (!("database" in $$source)) {
+            /**
+             * @member
+             * @type {string}
+             */
+            this["database"] = "";
+        }
+        if (!("action" in $$source)) {
+            /**
+             * @member
+             * @type {string}
+             */
+            this["action"] = "";
+        }
+        if (!("objectType" in $$source)) {
+            /**
+             * @member
+             * @type {string}
+             */
+            this["objectType"] = "";
+        }
+        if (!("name" in $$source)) {
+            /**
+             * @member
+             * @type {string}
+             */
+            this["name"] = "";
+        }
+        if (!("newName" in $$source)) {
+            /**
+             * NewName 用于 rename 和 duplicate
+             * @member
+             * @type {string}
+             */
+            this["newName"] = "";
+        }
+        if (!("withData" in $$source)) {
+            /**
+             * WithData duplicate 时同时复制数据，否则只复制结构
+             * @member
+             * @type {boolean}
+             */
+            this["withData"] = false;
+        }
+        if (!("token" in $$source)) {
+            /**
+             * Token Preview 返回的确认令牌，破坏性操作和需要确认写入的连接必须携带
+             * @member
+             * @type {string}
+             */
+            this["token"] = "";
+        }
+
+        Object.assign(this, $$source);
+    }
+
+    /**
+     * Creates a new ObjectRequest instance from a string or object.
+     * @param {any} [$$source = {}]
+     * @returns {ObjectRequest}
+     */
+    static createFrom($$source = {}) {
+        let $$parsedSource = typeof $$source === 'string' ? JSON.parse($$source) : $$source;
+        return new ObjectRequest(/** @type {Partial<ObjectRequest>} */($$parsedSource));
+    }
+}
+
+export class ParseUriRequest {
+    /**
+     * Creates a new ParseUriRequest instance.
+     * @param {Partial<ParseUriRequest>} [$$source = {}] - The source object to create the ParseUriRequest.
+     */
+    constructor($$source = {}) {
+        if (!("uri" in $$source)) {
+            /**
+             * @member
+             * @type {string}
+             */
+            this["uri"] = "";
+        }
+
+        Object.assign(this, $$source);
+    }
+
+    /**
+     * Creates a new ParseUriRequest instance from a string or object.
+     * @param {any} [$$source = {}]
+     * @returns {ParseUriRequest}
+     */
+    static createFrom($$source = {}) {
+        let $$parsedSource = typeof $$source === 'string' ? JSON.parse($$source) : $$source;
+        return new ParseUriRequest(/** @type {Partial<ParseUriRequest>} */($$parsedSource));
+    }
+}
+
+export class PreviewImportRequest {
+    /**
+     * Creates a new PreviewImportRequest instance.
+     * @param {Partial<PreviewImportRequest>} [$$source = {}] - The source object to create the PreviewImportRequest.
+     */
+    constructor($$source = {}) {
+        if (!("format" in $$source)) {
+            /**
+             * @member
+             * @type {string}
+             */
+            this["format"] = "";
+        }
+        if (!("filePath" in $$source)) {
+            /**
+             * @member
+             * @type {string}
+             */
+            this["filePath"] = "";
+        }
+        if (!("delimiter" in $$source)) {
+            /**
+             * @member
+             * @type {string}
+             */
+            this["delimiter"] = "";
+        }
+        if (!("noHeader" in $$source)) {
+            /**
+             * @member
+             * @type {boolean}
+             */
+            this["noHeader"] = false;
+        }
+        if (!("sheet" in $$source)) {
+            /**
+             * Sheet 为空时读取 xlsx 的第一个工作表
+             * @member
+             * @type {string}
+             */
+            this["sheet"] = "";
+        }
+        if (!("limit" in $$source)) {
+            /**
+             * @member
+             * @type {number}
+             */
+            this["limit"] = 0;
+        }
+
+        Object.assign(this, $$source);
+    }
+
+    /**
+     * Creates a new PreviewImportRequest instance from a string or object.
+     * @param {any} [$$source = {}]
+     * @returns {PreviewImportRequest}
+     */
+    static createFrom($$source = {}) {
+        let $$parsedSource = typeof $$source === 'string' ? JSON.parse($$source) : $$source;
+        return new PreviewImportRequest(/** @type {Partial<PreviewImportRequest>} */($$parsedSource));
+    }
+}
+
+export class ProcessListRequest {
+    /**
+     * Creates a new ProcessListRequest instance.
+     * @param {Partial<ProcessListRequest>} [$$source = {}] - The source object to create the ProcessListRequest.
+     */
+    constructor($$source = {}) {
+        if (!("conid" in $$source)) {
+            /**
+             * @member
+             * @type {string}
+             */
+            this["conid"] = "";
+        }
+        if (!("interval" in $$source)) {
+            /**
+             * Interval 自动刷新间隔（秒），为 0 时停止刷新
+             * @member
+             * @type {number}
+             */
+            this["interval"] = 0;
+        }
+
+        Object.assign(this, $$source);
+    }
+
+    /**
+     * Creates a new ProcessListRequest instance from a string or object.
+     * @param {any} [$$source = {}]
+     * @returns {ProcessListRequest}
+     */
+    static createFrom($$source = {}) {
+        let $$parsedSource = typeof $$source === 'string' ? JSON.parse($$source) : $$source;
+        return new ProcessListRequest(/** @type {Partial<ProcessListRequest>} */($$parsedSource));
+    }
+}
+
+export class QueryParametersRequest {
+    /**
+     * Creates a new QueryParametersRequest instance.
+     * @param {Partial<QueryParametersRequest>} [$$source = {}] - The source object to create the QueryParametersRequest.
+     */
+    constructor($$source = {}) {
+        if (!("sql" in $$source)) {
+            /**
+             * @member
+             * @type {string}
+             */
+            this["sql"] = "";
+        }
+
+        Object.assign(this, $$source);
+    }
+
+    /**
+     * Creates a new QueryParametersRequest instance from a string or object.
+     * @param {any} [$$source = {}]
+     * @returns {QueryParametersRequest}
+     */
+    static createFrom($$source = {}) {
+        let $$parsedSource = typeof $$source === 'string' ? JSON.parse($$source) : $$source;
+        return new QueryParametersRequest(/** @type {Partial<QueryParametersRequest>} */($$parsedSource));
+    }
+}
+
+export class RenderSnippetRequest {
+    /**
+     * Creates a new RenderSnippetRequest instance.
+     * @param {Partial<RenderSnippetRequest>} [$$source = {}] - The source object to create the RenderSnippetRequest.
+     */
+    constructor($$source = {}) {
+        if (!("id" in $$source)) {
+            /**
+             * @member
+             * @type {string}
+             */
+            this["id"] = "";
+        }
+        if (!("content" in $$source)) {
+            /**
+             * @member
+             * @type {string}
+             */
+            this["content"] = "";
+        }
+        if (!("values" in $$source)) {
+            /**
+             * @member
+             * @type {{ [_ in string]?: string }}
+             */
+            this["values"] = {};
+        }
+
+        Object.assign(this, $$source);
+    }
+
+    /**
+     * Creates a new RenderSnippetRequest instance from a string or object.
+     * @param {any} [$$source = {}]
+     * @returns {RenderSnippetRequest}
+     */
+    static createFrom($$source = {}) {
+        const $$createField2_0 = $$createType9;
+        let $$parsedSource = typeof $$source === 'string' ? JSON.parse($$source) : $$source;
+        if ("values" in $$parsedSource) {
+            $$parsedSource["values"] = $$createField2_0($$parsedSource["values"]);
+        }
+        return new RenderSnippetRequest(/** @type {Partial<RenderSnippetRequest>} */($$parsedSource));
+    }
+}
+
+export class RunScriptRequest {
+    /**
+     * Creates a new RunScriptRequest instance.
+     * @param {Partial<RunScriptRequest>} [$$source = {}] - The source object to create the RunScriptRequest.
+     */
+    constructor($$source = {}) {
+        if (!("conid" in $$source)) {
+            /**
+             * @member
+             * @type {string}
+             */
+            this["conid"] = "";
+        }
+        if (!("database" in $$source)) {
+            /**
+             * @member
+             * @type {string}
+             */
+            this["database"] = "";
+        }
+        if (!("filePath" in $$source)) {
+            /**
+             * @member
+             * @type {string}
+             */
+            this["filePath"] = "";
+        }
+        if (!("onError" in $$source)) {
+            /**
+             * @member
+             * @type {string}
+             */
+            this["onError"] = "";
+        }
+        if (!("batchSize" in $$source)) {
+            /**
+             * @member
+             * @type {number}
+             */
+            this["batchSize"] = 0;
+        }
+        if (!("confirmToken" in $$source)) {
+            /**
+             * @member
+             * @type {string}
+             */
+            this["confirmToken"] = "";
+        }
+
+        Object.assign(this, $$source);
+    }
+
+    /**
+     * Creates a new RunScriptRequest instance from a string or object.
+     * @param {any} [$$source = {}]
+     * @returns {RunScriptRequest}
+     */
+    static createFrom($$source = {}) {
+        let $$parsedSource = typeof $$source === 'string' ? JSON.parse($$source) : $$source;
+        return new RunScriptRequest(/** @type {Partial<RunScriptRequest>} */($$parsedSource));
+    }
+}
+
+export class SavedQueryRequest {
+    /**
+     * Creates a new SavedQueryRequest instance.
+     * @param {Partial<SavedQueryRequest>} [$$source = {}] - The source object to create the SavedQueryRequest.
+     */
+    constructor($$source = {}) {
+        if (!("id" in $$source)) {
+            /**
+             * @member
+             * @type {string}
+             */
+            this["id"] = "";
+        }
+
+        Object.assign(this, $$source);
+    }
+
+    /**
+     * Creates a new SavedQueryRequest instance from a string or object.
+     * @param {any} [$$source = {}]
+     * @returns {SavedQueryRequest}
+     */
+    static createFrom($$source = {}) {
+        let $$parsedSource = typeof $$source === 'string' ? JSON.parse($$source) : $$source;
+        return new SavedQueryRequest(/** @type {Partial<SavedQueryRequest>} */($$parsedSource));
+    }
+}
+
+export class ScriptRequest {
+    /**
+     * Creates a new ScriptRequest instance.
+     * @param {Partial<ScriptRequest>} [$$source = {}] - The source object to create the ScriptRequest.
+     */
+    constructor($$source = {}) {
+        if (!("packageName" in $$source)) {
+            /**
+             * @member
+             * @type {string}
+             */
+            this["packageName"] = "";
+        }
+
+        Object.assign(this, $$source);
+    }
+
+    /**
+     * Creates a new ScriptRequest instance from a string or object.
+     * @param {any} [$$source = {}]
+     * @returns {ScriptRequest}
+     */
+    static createFrom($$source = {}) {
+        let $$parsedSource = typeof $$source === 'string' ? JSON.parse($$source) : $$source;
+        return new ScriptRequest(/** @type {Partial<ScriptRequest>} */($$parsedSource));
+    }
+}
+
+export class ServerMetricsRequest {
+    /**
+     * Creates a new ServerMetricsRequest instance.
+     * @param {Partial<ServerMetricsRequest>} [$$source = {}] - The source object to create the ServerMetricsRequest.
+     */
+    constructor($$source = {}) {
+        if (!("conid" in $$source)) {
+            /**
+             * @member
+             * @type {string}
+             */
+            this["conid"] = "";
+        }
+        if (!("interval" in $$source)) {
+            /**
+             * Interval 采样间隔（秒），默认 5 秒
+             * @member
+             * @type {number}
+             */
+            this["interval"] = 0;
+        }
+        if (!("since" in $$source)) {
+            /**
+             * Since 只返回这个时间之后的采样，为 0 时返回缓冲区中的全部采样
+             * @member
+             * @type {utility$0.UnixTime}
+             */
+            this["since"] = 0;
+        }
+
+        Object.assign(this, $$source);
+    }
+
+    /**
+     * Creates a new ServerMetricsRequest instance from a string or object.
+     * @param {any} [$$source = {}]
+     * @returns {ServerMetricsRequest}
+     */
+    static createFrom($$source = {}) {
+        let $$parsedSource = typeof $$source === 'string' ? JSON.parse($$source) : $$source;
+        return new ServerMetricsRequest(/** @type {Partial<ServerMetricsRequest>} */($$parsedSource));
+    }
+}
+
+export class ServerPingRequest {
+    /**
+     * Creates a new ServerPingRequest instance.
+     * @param {Partial<ServerPingRequest>} [$$source = {}] - The source object to create the ServerPingRequest.
+     */
+    constructor($$source = {}) {
+        if (!("connections" in $$source)) {
+            /**
+             * @member
+             * @type {string[]}
+             */
+            this["connections"] = [];
+        }
+
+        Object.assign(this, $$source);
+    }
+
+    /**
+     * Creates a new ServerPingRequest instance from a string or object.
+     * @param {any} [$$source = {}]
+     * @returns {ServerPingRequest}
+     */
+    static createFrom($$source = {}) {
+        const $$createField0_0 = $$createType4;
+        let $$parsedSource = typeof $$source === 'string' ? JSON.parse($$source) : $$source;
+        if ("connections" in $$parsedSource) {
+            $$parsedSource["connections"] = $$createField0_0($$parsedSource["connections"]);
+        }
+        return new ServerPingRequest(/** @type {Partial<ServerPingRequest>} */($$parsedSource));
+    }
+}
+
+export class ServerRefreshRequest {
+    /**
+     * Creates a new ServerRefreshRequest instance.
+     * @param {Partial<ServerRefreshRequest>} [$$source = {}] - The source object to create the ServerRefreshRequest.
+     */
+    constructor($$source = {}) {
+        if (!("conid" in $$source)) {
+            /**
+             * @member
+             * @type {string}
+             */
+            this["conid"] = "";
+        }
+        if (!("keepOpen" in $$source)) {
+            /**
+             * @member
+             * @type {boolean}
+             */
+            this["keepOpen"] = false;
+        }
+
+        Object.assign(this, $$source);
+    }
+
+    /**
+     * Creates a new ServerRefreshRequest instance from a string or object.
+     * @param {any} [$$source = {}]
+     * @returns {ServerRefreshRequest}
+     */
+    static createFrom($$source = {}) {
+        let $$parsedSource = typeof $$source === 'string' ? JSON.parse($$source) : $$source;
+        return new ServerRefreshRequest(/** @type {Partial<ServerRefreshRequest>} */($$parsedSource));
+    }
+}
+
+export class SqlSelectRequest {
+    /**
+     * Creates a new SqlSelectRequest instance.
+     * @param {Partial<SqlSelectRequest>} [$$source = {}] - The source object to create the SqlSelectRequest.
+     */
+    constructor($$source = {}) {
+        if (!("conid" in $$source)) {
+            /**
+             * @member
+             * @type {string}
+             */
+            this["conid"] = "";
+        }
+        if (!("database" in $$source)) {
+            /**
+             * @member
+             * @type {string}
+             */
+            this["database"] = "";
+        }
+        if (!("Select" in $$source)) {
+            /**
+             * @member
+             * @type {any}
+             */
+            this["Select"] = null;
+        }
+        if (!("params" in $$source)) {
+            /**
+             * Params 编辑器中 ?、:name、@name 占位符的取值，由驱动绑定
+             * @member
+             * @type {(script$0.ParamValue | null)[]}
+             */
+            this["params"] = [];
+        }
+        if (!("confirmToken" in $$source)) {
+            /**
+             * ConfirmToken 危险语句返回 confirm 后，重新提交时携带的确认令牌
+             * @member
+             * @type {string}
+             */
+            this["confirmToken"] = "";
+        }
+
+        Object.assign(this, $$source);
     }
 
     /**
@@ -381,14 +1751,271 @@ export class SqlSelectRequest {
      * @returns {SqlSelectRequest}
      */
     static createFrom($$source = {}) {
+        const $$createField3_0 = $$createType12;
         let $$parsedSource = typeof $$source === 'string' ? JSON.parse($$source) : $$source;
+        if ("params" in $$parsedSource) {
+            $$parsedSource["params"] = $$createField3_0($$parsedSource["params"]);
+        }
         return new SqlSelectRequest(/** @type {Partial<SqlSelectRequest>} */($$parsedSource));
     }
 }
 
+export class SwitchKeyStoreRequest {
+    /**
+     * Creates a new SwitchKeyStoreRequest instance.
+     * @param {Partial<SwitchKeyStoreRequest>} [$$source = {}] - The source object to create the SwitchKeyStoreRequest.
+     */
+    constructor($$source = {}) {
+        if (!("mode" in $$source)) {
+            /**
+             * Mode file、password 或 keyring
+             * @member
+             * @type {string}
+             */
+            this["mode"] = "";
+        }
+        if (!("password" in $$source)) {
+            /**
+             * Password 切换到 password 时的新主密码
+             * @member
+             * @type {string}
+             */
+            this["password"] = "";
+        }
+
+        Object.assign(this, $$source);
+    }
+
+    /**
+     * Creates a new SwitchKeyStoreRequest instance from a string or object.
+     * @param {any} [$$source = {}]
+     * @returns {SwitchKeyStoreRequest}
+     */
+    static createFrom($$source = {}) {
+        let $$parsedSource = typeof $$source === 'string' ? JSON.parse($$source) : $$source;
+        return new SwitchKeyStoreRequest(/** @type {Partial<SwitchKeyStoreRequest>} */($$parsedSource));
+    }
+}
+
+export class TransferJobRequest {
+    /**
+     * Creates a new TransferJobRequest instance.
+     * @param {Partial<TransferJobRequest>} [$$source = {}] - The source object to create the TransferJobRequest.
+     */
+    constructor($$source = {}) {
+        if (!("id" in $$source)) {
+            /**
+             * @member
+             * @type {string}
+             */
+            this["id"] = "";
+        }
+
+        Object.assign(this, $$source);
+    }
+
+    /**
+     * Creates a new TransferJobRequest instance from a string or object.
+     * @param {any} [$$source = {}]
+     * @returns {TransferJobRequest}
+     */
+    static createFrom($$source = {}) {
+        let $$parsedSource = typeof $$source === 'string' ? JSON.parse($$source) : $$source;
+        return new TransferJobRequest(/** @type {Partial<TransferJobRequest>} */($$parsedSource));
+    }
+}
+
+export class UnlockKeyStoreRequest {
+    /**
+     * Creates a new UnlockKeyStoreRequest instance.
+     * @param {Partial<UnlockKeyStoreRequest>} [$$source = {}] - The source object to create the UnlockKeyStoreRequest.
+     */
+    constructor($$source = {}) {
+        if (!("password" in $$source)) {
+            /**
+             * @member
+             * @type {string}
+             */
+            this["password"] = "";
+        }
+
+        Object.assign(this, $$source);
+    }
+
+    /**
+     * Creates a new UnlockKeyStoreRequest instance from a string or object.
+     * @param {any} [$$source = {}]
+     * @returns {UnlockKeyStoreRequest}
+     */
+    static createFrom($$source = {}) {
+        let $$parsedSource = typeof $$source === 'string' ? JSON.parse($$source) : $$source;
+        return new UnlockKeyStoreRequest(/** @type {Partial<UnlockKeyStoreRequest>} */($$parsedSource));
+    }
+}
+
+export class UserChangeRequest {
+    /**
+     * Creates a new UserChangeRequest instance.
+     * @param {Partial<UserChangeRequest>} [$$source = {}] - The source object to create the UserChangeRequest.
+     */
+    constructor($$source = {}) {
+        if (!("conid" in $$source)) {
+            /**
+             * @member
+             * @type {string}
+             */
+            this["conid"] = "";
+        }
+        if (!("action" in $$source)) {
+            /**
+             * @member
+             * @type {string}
+             */
+            this["action"] = "";
+        }
+        if (!("account" in $$source)) {
+            /**
+             * @member
+             * @type {users$0.Account}
+             */
+            this["account"] = (new users$0.Account());
+        }
+        if (!("password" in $$source)) {
+            /**
+             * Password 用于 create 和 password，Plugin 为空时使用服务器默认的认证插件
+             * @member
+             * @type {string}
+             */
+            this["password"] = "";
+        }
+        if (!("plugin" in $$source)) {
+            /**
+             * @member
+             * @type {string}
+             */
+            this["plugin"] = "";
+        }
+        if (!("privilege" in $$source)) {
+            /**
+             * @member
+             * @type {users$0.Privilege | null}
+             */
+            this["privilege"] = null;
+        }
+        if (!("confirmToken" in $$source)) {
+            /**
+             * @member
+             * @type {string}
+             */
+            this["confirmToken"] = "";
+        }
+
+        Object.assign(this, $$source);
+    }
+
+    /**
+     * Creates a new UserChangeRequest instance from a string or object.
+     * @param {any} [$$source = {}]
+     * @returns {UserChangeRequest}
+     */
+    static createFrom($$source = {}) {
+        const $$createField2_0 = $$createType13;
+        const $$createField5_0 = $$createType15;
+        let $$parsedSource = typeof $$source === 'string' ? JSON.parse($$source) : $$source;
+        if ("account" in $$parsedSource) {
+            $$parsedSource["account"] = $$createField2_0($$parsedSource["account"]);
+        }
+        if ("privilege" in $$parsedSource) {
+            $$parsedSource["privilege"] = $$createField5_0($$parsedSource["privilege"]);
+        }
+        return new UserChangeRequest(/** @type {Partial<UserChangeRequest>} */($$parsedSource));
+    }
+}
+
+export class UserGrantsRequest {
+    /**
+     * Creates a new UserGrantsRequest instance.
+     * @param {Partial<UserGrantsRequest>} [$$source = {}] - The source object to create the UserGrantsRequest.
+     */
+    constructor($$source = {}) {
+        if (!("conid" in $$source)) {
+            /**
+             * @member
+             * @type {string}
+             */
+            this["conid"] = "";
+        }
+        if (!("user" in $$source)) {
+            /**
+             * @member
+             * @type {string}
+             */
+            this["user"] = "";
+        }
+        if (!("host" in $$source)) {
+            /**
+             * @member
+             * @type {string}
+             */
+            this["host"] = "";
+        }
+
+        Object.assign(this, $$source);
+    }
+
+    /**
+     * Creates a new UserGrantsRequest instance from a string or object.
+     * @param {any} [$$source = {}]
+     * @returns {UserGrantsRequest}
+     */
+    static createFrom($$source = {}) {
+        let $$parsedSource = typeof $$source === 'string' ? JSON.parse($$source) : $$source;
+        return new UserGrantsRequest(/** @type {Partial<UserGrantsRequest>} */($$parsedSource));
+    }
+}
+
+export class UsersRequest {
+    /**
+     * Creates a new UsersRequest instance.
+     * @param {Partial<UsersRequest>} [$$source = {}] - The source object to create the UsersRequest.
+     */
+    constructor($$source = {}) {
+        if (!("conid" in $$source)) {
+            /**
+             * @member
+             * @type {string}
+             */
+            this["conid"] = "";
+        }
+
+        Object.assign(this, $$source);
+    }
+
+    /**
+     * Creates a new UsersRequest instance from a string or object.
+     * @param {any} [$$source = {}]
+     * @returns {UsersRequest}
+     */
+    static createFrom($$source = {}) {
+        let $$parsedSource = typeof $$source === 'string' ? JSON.parse($$source) : $$source;
+        return new UsersRequest(/** @type {Partial<UsersRequest>} */($$parsedSource));
+    }
+}
+
 // Private type creation functions
 const $$createType0 = modules$0.CollectionDataOptions.createFrom;
 const $$createType1 = $Create.Nullable($$createType0);
-const $$createType2 = $Create.Map($Create.Any, $Create.Any);
-const $$createType3 = $Create.Array($$createType2);
+const $$createType2 = transfer$0.CopyEndpoint.createFrom;
+const $$createType3 = $Create.Map($Create.Any, $Create.Any);
 const $$createType4 = $Create.Array($Create.Any);
+const $$createType5 = $Create.Array($$createType3);
+const $$createType6 = transfer$0.FieldMapping.createFrom;
+const $$createType7 = $Create.Nullable($$createType6);
+const $$createType8 = $Create.Array($$createType7);
+const $$createType9 = $Create.Map($Create.Any, $Create.Any);
+const $$createType10 = script$0.ParamValue.createFrom;
+const $$createType11 = $Create.Nullable($$createType10);
+const $$createType12 = $Create.Array($$createType11);
+const $$createType13 = users$0.Account.createFrom;
+const $$createType14 = users$0.Privilege.createFrom;
+const $$createType15 = $Create.Nullable($$createType14);
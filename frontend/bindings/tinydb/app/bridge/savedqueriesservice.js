@@ -0,0 +1,82 @@
+// @ts-check
+// Cynhyrchwyd y ffeil hon yn awtomatig. PEIDIWCH Â MODIWL
+// This file is automatically generated. DO NOT EDIT
+
+// eslint-disable-next-line @typescript-eslint/ban-ts-comment
+// @ts-ignore: Unused imports
+import { Call as $Call, CancellablePromise as $CancellablePromise, Create as $Create } from "@wailsio/runtime";
+
+// eslint-disable-next-line @typescript-eslint/ban-ts-comment
+// @ts-ignore: Unused imports
+import * as serializer$0 from "../pkg/serializer/models.js";
+// eslint-disable-next-line @typescript-eslint/ban-ts-comment
+// @ts-ignore: Unused imports
+import * as snippets$0 from "../snippets/models.js";
+
+// eslint-disable-next-line @typescript-eslint/ban-ts-comment
+// @ts-ignore: Unused imports
+import * as $models from "./models.js";
+
+/**
+ * @param {$models.SavedQueryRequest | null} req
+ * @returns {$CancellablePromise<serializer$0.Response | null>}
+ */
+export function Delete(req) {
+    return $Call.ByID(3154715866, req).then(/** @type {($result: any) => any} */(($result) => {
+        return $$createType1($result);
+    }));
+}
+
+/**
+ * @returns {$CancellablePromise<serializer$0.Response | null>}
+ */
+export function Folders() {
+    return $Call.ByID(1935000506).then(/** @type {($result: any) => any} */(($result) => {
+        return $$createType1($result);
+    }));
+}
+
+/**
+ * @param {$models.SavedQueryRequest | null} req
+ * @returns {$CancellablePromise<serializer$0.Response | null>}
+ */
+export function Get(req) {
+    return $Call.ByID(4005351495, req).then(/** @type {($result: any) => any} */(($result) => {
+        return $$createType1($result);
+    }));
+}
+
+/**
+ * @param {snippets$0.Filter | null} req
+ * @returns {$CancellablePromise<serializer$0.Response | null>}
+ */
+export function List(req) {
+    return $Call.ByID(2647757745, req).then(/** @type {($result: any) => any} */(($result) => {
+        return $$createType1($result);
+    }));
+}
+
+/**
+ * Render fills the ${var} placeholders of a saved snippet or of the given content.
+ * @param {$models.RenderSnippetRequest | null} req
+ * @returns {$CancellablePromise<serializer$0.Response | null>}
+ */
+export function Render(req) {
+    return $Call.ByID(1884896253, req).then(/** @type {($result: any) => any} */(($result) => {
+        return $$createType1($result);
+    }));
+}
+
+/**
+ * @param {snippets$0.SavedQuery | null} req
+ * @returns {$CancellablePromise<serializer$0.Response | null>}
+ */
+export function Save(req) {
+    return $Call.ByID(3229470520, req).then(/** @type {($result: any) => any} */(($result) => {
+        return $$createType1($result);
+    }));
+}
+
+// Private type creation functions
+const $$createType0 = serializer$0.Response.createFrom;
+const $$createType1 = $Create.Nullable($$createType0);
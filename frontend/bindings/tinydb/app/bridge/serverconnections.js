@@ -33,6 +33,16 @@ export function CreateDatabase(req) {
     }));
 }
 
+/**
+ * @param {$models.KillProcessRequest | null} req
+ * @returns {$CancellablePromise<serializer$0.Response | null>}
+ */
+export function KillProcess(req) {
+    return $Call.ByID(912128382, req).then(/** @type {($result: any) => any} */(($result) => {
+        return $$createType1($result);
+    }));
+}
+
 /**
  * @param {{ [_ in string]?: string }} request
  * @returns {$CancellablePromise<serializer$0.Response | null>}
@@ -43,6 +53,18 @@ export function ListDatabases(request) {
     }));
 }
 
+/**
+ * Metrics starts sampling the server status of conid if needed and returns the
+ * buffered samples, server-metrics-changed-<conid> is emitted with each new sample.
+ * @param {$models.ServerMetricsRequest | null} req
+ * @returns {$CancellablePromise<serializer$0.Response | null>}
+ */
+export function Metrics(req) {
+    return $Call.ByID(1779163422, req).then(/** @type {($result: any) => any} */(($result) => {
+        return $$createType1($result);
+    }));
+}
+
 /**
  * @param {$models.ServerPingRequest | null} req
  * @returns {$CancellablePromise<serializer$0.Response | null>}
@@ -53,6 +75,19 @@ export function Ping(req) {
     }));
 }
 
+/**
+ * ProcessList returns the sessions of the server. With an interval the list is
+ * polled in the background and server-process-list-changed-<conid> is emitted
+ * whenever it changes.
+ * @param {$models.ProcessListRequest | null} req
+ * @returns {$CancellablePromise<serializer$0.Response | null>}
+ */
+export function ProcessList(req) {
+    return $Call.ByID(4047800110, req).then(/** @type {($result: any) => any} */(($result) => {
+        return $$createType1($result);
+    }));
+}
+
 /**
  * @param {$models.ServerRefreshRequest | null} req
  * @returns {$CancellablePromise<serializer$0.Response | null>}
@@ -70,6 +105,16 @@ export function ServerStatus() {
     return $Call.ByID(1784323316);
 }
 
+/**
+ * @param {$models.ServerMetricsRequest | null} req
+ * @returns {$CancellablePromise<serializer$0.Response | null>}
+ */
+export function StopMetrics(req) {
+    return $Call.ByID(3730446158, req).then(/** @type {($result: any) => any} */(($result) => {
+        return $$createType1($result);
+    }));
+}
+
 // Private type creation functions
 const $$createType0 = serializer$0.Response.createFrom;
 const $$createType1 = $Create.Nullable($$createType0);
@@ -0,0 +1,52 @@
+// @ts-check
+// Cynhyrchwyd y ffeil hon yn awtomatig. PEIDIWCH Â MODIWL
+// This file is automatically generated. DO NOT EDIT
+
+// eslint-disable-next-line @typescript-eslint/ban-ts-comment
+// @ts-ignore: Unused imports
+import { Create as $Create } from "@wailsio/runtime";
+
+/**
+ * ParamValue is a value typed by the user for a parameter.
+ */
+export class ParamValue {
+    /**
+     * Creates a new ParamValue instance.
+     * @param {Partial<ParamValue>} [$$source = {}] - The source object to create the ParamValue.
+     */
+    constructor($$source = {}) {
+        if (!("name" in $$source)) {
+            /**
+             * @member
+             * @type {string}
+             */
+            this["name"] = "";
+        }
+        if (!("type" in $$source)) {
+            /**
+             * @member
+             * @type {string}
+             */
+            this["type"] = "";
+        }
+        if (!("value" in $$source)) {
+            /**
+             * @member
+             * @type {any}
+             */
+            this["value"] = null;
+        }
+
+        Object.assign(this, $$source);
+    }
+
+    /**
+     * Creates a new ParamValue instance from a string or object.
+     * @param {any} [$$source = {}]
+     * @returns {ParamValue}
+     */
+    static createFrom($$source = {}) {
+        let $$parsedSource = typeof $$source === 'string' ? JSON.parse($$source) : $$source;
+        return new ParamValue(/** @type {Partial<ParamValue>} */($$parsedSource));
+    }
+}
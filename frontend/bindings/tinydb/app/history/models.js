@@ -0,0 +1,84 @@
+// @ts-check
+// Cynhyrchwyd y ffeil hon yn awtomatig. PEIDIWCH Â MODIWL
+// This file is automatically generated. DO NOT EDIT
+
+// eslint-disable-next-line @typescript-eslint/ban-ts-comment
+// @ts-ignore: Unused imports
+import { Create as $Create } from "@wailsio/runtime";
+
+// eslint-disable-next-line @typescript-eslint/ban-ts-comment
+// @ts-ignore: Unused imports
+import * as utility$0 from "../utility/models.js";
+
+/**
+ * Query filters history entries, zero values match everything.
+ */
+export class Query {
+    /**
+     * Creates a new Query instance.
+     * @param {Partial<Query>} [$$source = {}] - The source object to create the Query.
+     */
+    constructor($$source = {}) {
+        if (!("conid" in $$source)) {
+            /**
+             * @member
+             * @type {string}
+             */
+            this["conid"] = "";
+        }
+        if (!("database" in $$source)) {
+            /**
+             * @member
+             * @type {string}
+             */
+            this["database"] = "";
+        }
+        if (!("text" in $$source)) {
+            /**
+             * @member
+             * @type {string}
+             */
+            this["text"] = "";
+        }
+        if (!("from" in $$source)) {
+            /**
+             * @member
+             * @type {utility$0.UnixTime}
+             */
+            this["from"] = 0;
+        }
+        if (!("to" in $$source)) {
+            /**
+             * @member
+             * @type {utility$0.UnixTime}
+             */
+            this["to"] = 0;
+        }
+        if (!("pinnedOnly" in $$source)) {
+            /**
+             * @member
+             * @type {boolean}
+             */
+            this["pinnedOnly"] = false;
+        }
+        if (!("limit" in $$source)) {
+            /**
+             * @member
+             * @type {number}
+             */
+            this["limit"] = 0;
+        }
+
+        Object.assign(this, $$source);
+    }
+
+    /**
+     * Creates a new Query instance from a string or object.
+     * @param {any} [$$source = {}]
+     * @returns {Query}
+     */
+    static createFrom($$source = {}) {
+        let $$parsedSource = typeof $$source === 'string' ? JSON.parse($$source) : $$source;
+        return new Query(/** @type {Partial<Query>} */($$parsedSource));
+    }
+}
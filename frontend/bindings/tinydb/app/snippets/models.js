@@ -0,0 +1,173 @@
+// @ts-check
+// Cynhyrchwyd y ffeil hon yn awtomatig. PEIDIWCH Â MODIWL
+// This file is automatically generated. DO NOT EDIT
+
+// eslint-disable-next-line @typescript-eslint/ban-ts-comment
+// @ts-ignore: Unused imports
+import { Create as $Create } from "@wailsio/runtime";
+
+// eslint-disable-next-line @typescript-eslint/ban-ts-comment
+// @ts-ignore: Unused imports
+import * as utility$0 from "../utility/models.js";
+
+/**
+ * Filter selects saved queries, zero values match everything.
+ */
+export class Filter {
+    /**
+     * Creates a new Filter instance.
+     * @param {Partial<Filter>} [$$source = {}] - The source object to create the Filter.
+     */
+    constructor($$source = {}) {
+        if (!("folder" in $$source)) {
+            /**
+             * @member
+             * @type {string}
+             */
+            this["folder"] = "";
+        }
+        if (!("conid" in $$source)) {
+            /**
+             * @member
+             * @type {string}
+             */
+            this["conid"] = "";
+        }
+        if (!("database" in $$source)) {
+            /**
+             * @member
+             * @type {string}
+             */
+            this["database"] = "";
+        }
+        if (!("text" in $$source)) {
+            /**
+             * @member
+             * @type {string}
+             */
+            this["text"] = "";
+        }
+        if (!("snippets" in $$source)) {
+            /**
+             * Snippets 为 nil 时同时返回查询和片段
+             * @member
+             * @type {boolean | null}
+             */
+            this["snippets"] = null;
+        }
+
+        Object.assign(this, $$source);
+    }
+
+    /**
+     * Creates a new Filter instance from a string or object.
+     * @param {any} [$$source = {}]
+     * @returns {Filter}
+     */
+    static createFrom($$source = {}) {
+        let $$parsedSource = typeof $$source === 'string' ? JSON.parse($$source) : $$source;
+        return new Filter(/** @type {Partial<Filter>} */($$parsedSource));
+    }
+}
+
+/**
+ * SavedQuery is a named query or snippet kept in queries.jsonl of the data directory.
+ */
+export class SavedQuery {
+    /**
+     * Creates a new SavedQuery instance.
+     * @param {Partial<SavedQuery>} [$$source = {}] - The source object to create the SavedQuery.
+     */
+    constructor($$source = {}) {
+        if (!("_id" in $$source)) {
+            /**
+             * @member
+             * @type {string}
+             */
+            this["_id"] = "";
+        }
+        if (!("name" in $$source)) {
+            /**
+             * @member
+             * @type {string}
+             */
+            this["name"] = "";
+        }
+        if (!("folder" in $$source)) {
+            /**
+             * Folder 使用 / 分隔的多级目录，空字符串表示根目录
+             * @member
+             * @type {string}
+             */
+            this["folder"] = "";
+        }
+        if (!("kind" in $$source)) {
+            /**
+             * @member
+             * @type {string}
+             */
+            this["kind"] = "";
+        }
+        if (!("content" in $$source)) {
+            /**
+             * @member
+             * @type {string}
+             */
+            this["content"] = "";
+        }
+        if (!("description" in $$source)) {
+            /**
+             * @member
+             * @type {string}
+             */
+            this["description"] = "";
+        }
+        if (!("conid" in $$source)) {
+            /**
+             * @member
+             * @type {string}
+             */
+            this["conid"] = "";
+        }
+        if (!("database" in $$source)) {
+            /**
+             * @member
+             * @type {string}
+             */
+            this["database"] = "";
+        }
+        if (!("isSnippet" in $$source)) {
+            /**
+             * @member
+             * @type {boolean}
+             */
+            this["isSnippet"] = false;
+        }
+        if (!("createdAt" in $$source)) {
+            /**
+             * @member
+             * @type {utility$0.UnixTime}
+             */
+            this["createdAt"] = 0;
+        }
+        if (!("updatedAt" in $$source)) {
+            /**
+             * @member
+             * @type {utility$0.UnixTime}
+             */
+            this["updatedAt"] = 0;
+        }
+
+        Object.assign(this, $$source);
+    }
+
+    /**
+     * Creates a new SavedQuery instance from a string or object.
+     * @param {any} [$$source = {}]
+     * @returns {SavedQuery}
+     */
+    static createFrom($$source = {}) {
+        let $$parsedSource = typeof $$source === 'string' ? JSON.parse($$source) : $$source;
+        return new SavedQuery(/** @type {Partial<SavedQuery>} */($$parsedSource));
+    }
+}
@@ -0,0 +1,95 @@
+// @ts-check
+// Cynhyrchwyd y ffeil hon yn awtomatig. PEIDIWCH Â MODIWL
+// This file is automatically generated. DO NOT EDIT
+
+// eslint-disable-next-line @typescript-eslint/ban-ts-comment
+// @ts-ignore: Unused imports
+import { Create as $Create } from "@wailsio/runtime";
+
+export class CopyEndpoint {
+    /**
+     * Creates a new CopyEndpoint instance.
+     * @param {Partial<CopyEndpoint>} [$$source = {}] - The source object to create the CopyEndpoint.
+     */
+    constructor($$source = {}) {
+        if (!("conid" in $$source)) {
+            /**
+             * @member
+             * @type {string}
+             */
+            this["conid"] = "";
+        }
+        if (!("database" in $$source)) {
+            /**
+             * @member
+             * @type {string}
+             */
+            this["database"] = "";
+        }
+        if (!("pureName" in $$source)) {
+            /**
+             * @member
+             * @type {string}
+             */
+            this["pureName"] = "";
+        }
+
+        Object.assign(this, $$source);
+    }
+
+    /**
+     * Creates a new CopyEndpoint instance from a string or object.
+     * @param {any} [$$source = {}]
+     * @returns {CopyEndpoint}
+     */
+    static createFrom($$source = {}) {
+        let $$parsedSource = typeof $$source === 'string' ? JSON.parse($$source) : $$source;
+        return new CopyEndpoint(/** @type {Partial<CopyEndpoint>} */($$parsedSource));
+    }
+}
+
+/**
+ * FieldMapping maps a field of the file to a target column, DataType is
+ * optional and converts the raw value before it is inserted.
+ */
+export class FieldMapping {
+    /**
+     * Creates a new FieldMapping instance.
+     * @param {Partial<FieldMapping>} [$$source = {}] - The source object to create the FieldMapping.
+     */
+    constructor($$source = {}) {
+        if (!("source" in $$source)) {
+            /**
+             * @member
+             * @type {string}
+             */
+            this["source"] = "";
+        }
+        if (!("target" in $$source)) {
+            /**
+             * @member
+             * @type {string}
+             */
+            this["target"] = "";
+        }
+        if (!("dataType" in $$source)) {
+            /**
+             * @member
+             * @type {string}
+             */
+            this["dataType"] = "";
+        }
+
+        Object.assign(this, $$source);
+    }
+
+    /**
+     * Creates a new FieldMapping instance from a string or object.
+     * @param {any} [$$source = {}]
+     * @returns {FieldMapping}
+     */
+    static createFrom($$source = {}) {
+        let $$parsedSource = typeof $$source === 'string' ? JSON.parse($$source) : $$source;
+        return new FieldMapping(/** @type {Partial<FieldMapping>} */($$parsedSource));
+    }
+}
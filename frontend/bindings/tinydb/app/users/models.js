@@ -0,0 +1,116 @@
+// @ts-check
+// Cynhyrchwyd y ffeil hon yn awtomatig. PEIDIWCH Â MODIWL
+// This file is automatically generated. DO NOT EDIT
+
+// eslint-disable-next-line @typescript-eslint/ban-ts-comment
+// @ts-ignore: Unused imports
+import { Create as $Create } from "@wailsio/runtime";
+
+export class Account {
+    /**
+     * Creates a new Account instance.
+     * @param {Partial<Account>} [$$source = {}] - The source object to create the Account.
+     */
+    constructor($$source = {}) {
+        if (!("user" in $$source)) {
+            /**
+             * @member
+             * @type {string}
+             */
+            this["user"] = "";
+        }
+        if (!("host" in $$source)) {
+            /**
+             * @member
+             * @type {string}
+             */
+            this["host"] = "";
+        }
+
+        Object.assign(this, $$source);
+    }
+
+    /**
+     * Creates a new Account instance from a string or object.
+     * @param {any} [$$source = {}]
+     * @returns {Account}
+     */
+    static createFrom($$source = {}) {
+        let $$parsedSource = typeof $$source === 'string' ? JSON.parse($$source) : $$source;
+        return new Account(/** @type {Partial<Account>} */($$parsedSource));
+    }
+}
+
+export class Privilege {
+    /**
+     * Creates a new Privilege instance.
+     * @param {Partial<Privilege>} [$$source = {}] - The source object to create the Privilege.
+     */
+    constructor($$source = {}) {
+        if (!("privileges" in $$source)) {
+            /**
+             * @member
+             * @type {string[]}
+             */
+            this["privileges"] = [];
+        }
+        if (!("level" in $$source)) {
+            /**
+             * @member
+             * @type {string}
+             */
+            this["level"] = "";
+        }
+        if (!("database" in $$source)) {
+            /**
+             * @member
+             * @type {string}
+             */
+            this["database"] = "";
+        }
+        if (!("table" in $$source)) {
+            /**
+             * @member
+             * @type {string}
+             */
+            this["table"] = "";
+        }
+        if (!("columns" in $$source)) {
+            /**
+             * @member
+             * @type {string[]}
+             */
+            this["columns"] = [];
+        }
+        if (!("withGrantOption" in $$source)) {
+            /**
+             * @member
+             * @type {boolean}
+             */
+            this["withGrantOption"] = false;
+        }
+
+        Object.assign(this, $$source);
+    }
+
+    /**
+     * Creates a new Privilege instance from a string or object.
+     * @param {any} [$$source = {}]
+     * @returns {Privilege}
+     */
+    static createFrom($$source = {}) {
+        const $$createField0_0 = $$createType0;
+        const $$createField4_0 = $$createType0;
+        let $$parsedSource = typeof $$source === 'string' ? JSON.parse($$source) : $$source;
+        if ("privileges" in $$parsedSource) {
+            $$parsedSource["privileges"] = $$createField0_0($$parsedSource["privileges"]);
+        }
+        if ("columns" in $$parsedSource) {
+            $$parsedSource["columns"] = $$createField4_0($$parsedSource["columns"]);
+        }
+        return new Privilege(/** @type {Partial<Privilege>} */($$parsedSource));
+    }
+}
+
+// Private type creation functions
+const $$createType0 = $Create.Array($Create.Any);
@@ -0,0 +1,9 @@
+// @ts-check
+// Cynhyrchwyd y ffeil hon yn awtomatig. PEIDIWCH Â MODIWL
+// This file is automatically generated. DO NOT EDIT
+
+import * as $models from "./models.js";
+
+/**
+ * @typedef {$models.UnixTime} UnixTime
+ */
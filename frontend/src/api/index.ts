@@ -18,10 +18,17 @@ function safeEventsOn(eventName: string, callback: (...data: any) => void) {
 import {apiCall} from "/@/utils/tinydb/api"
 import {loadCachedValue} from "/@/utils/tinydb/cache"
 
+// Connections.List 返回文件夹树，列表视图只需要其中的连接
+function flattenConnectionTree(nodes) {
+  if (!isArray(nodes)) return nodes
+  return nodes.flatMap(node => node?.type === "folder" ? flattenConnectionTree(node.children ?? []) : [node?.connection ?? node])
+}
+
 const connectionListLoader = () => ({
   url: "bridge.Connections.List",
   params: {},
   reloadTrigger: "connection-list-changed",
+  transform: flattenConnectionTree,
 })
 
 const serverStatusLoader = () => ({
@@ -195,6 +202,10 @@ export async function connectionSaveApi(params) {
 }
 
 export async function connectionListApi() {
+  return flattenConnectionTree(await apiCall("bridge.Connections.List"))
+}
+
+export async function connectionTreeApi() {
   return await apiCall("bridge.Connections.List")
 }
 
@@ -231,6 +242,7 @@ export async function databaseConnectionsCreateTableApi(params: {
   database: string
   tableName: string
   columns: any[]
+  confirmToken?: string
 }) {
   return await apiCall("bridge.DatabaseConnections.CreateTable", params)
 }
@@ -243,6 +255,10 @@ export async function serverConnectionsCreateDatabaseApi(params: {conid: string;
   return await apiCall("bridge.ServerConnections.CreateDatabase", params)
 }
 
+export async function updateSettingsApi(params: Record<string, any>) {
+  return await apiCall("bridge.Configs.UpdateSettings", params)
+}
+
 export {subscribeConnectionPingers} from "/@/hooks/tinydb/useConnectionPingers"
 export {subscribeRecentDatabaseSwitch} from "/@/hooks/tinydb/useRecentDatabaseSwitch"
 export {subscribeCurrentDbByTab} from "/@/hooks/tinydb/useCurrentDbByTab"
@@ -22,10 +22,20 @@ const urlToBinding: Record<string, (params?: any) => Promise<unknown>> = {
   "Connections.List": () => Bridge.ConnectionsService.List(),
   "Connections.Get": (p) => Bridge.ConnectionsService.Get(p),
   "Connections.Delete": (p) => Bridge.ConnectionsService.Delete(p),
+  "Connections.ParseUri": (p) => Bridge.ConnectionsService.ParseUri(p),
+  "Connections.ImportUri": (p) => Bridge.ConnectionsService.ImportUri(p),
+  "Connections.Export": (p) => Bridge.ConnectionsService.Export(p),
+  "Connections.Import": (p) => Bridge.ConnectionsService.Import(p),
+  "KeyStore.Status": () => Bridge.KeyStoreService.Status(),
+  "KeyStore.Unlock": (p) => Bridge.KeyStoreService.Unlock(p),
+  "KeyStore.Switch": (p) => Bridge.KeyStoreService.Switch(p),
   "DatabaseConnections.Refresh": (p) => Bridge.DatabaseConnections.Refresh(p),
   "DatabaseConnections.Structure": (p) => Bridge.DatabaseConnections.Structure(p),
   "DatabaseConnections.SqlSelect": (p) => Bridge.DatabaseConnections.SqlSelect(p),
+  "DatabaseConnections.QueryParameters": (p) => Bridge.DatabaseConnections.QueryParameters(p),
+  "DatabaseConnections.Explain": (p) => Bridge.DatabaseConnections.Explain(p),
   "DatabaseConnections.CollectionData": (p) => Bridge.DatabaseConnections.CollectionData(p),
+  "DatabaseConnections.DeleteDocuments": (p) => Bridge.DatabaseConnections.DeleteDocuments(p),
   "DatabaseConnections.CreateTable": (p) => Bridge.DatabaseConnections.CreateTable(p),
   "DatabaseConnections.Status": (p) => Bridge.DatabaseConnections.Status(p),
   "DatabaseConnections.ServerVersion": (p) => Bridge.DatabaseConnections.ServerVersion(p),
@@ -34,9 +44,40 @@ const urlToBinding: Record<string, (params?: any) => Promise<unknown>> = {
   "ServerConnections.ListDatabases": (p) => Bridge.ServerConnections.ListDatabases(p ?? {}),
   "ServerConnections.ServerStatus": () => Bridge.ServerConnections.ServerStatus(),
   "ServerConnections.CreateDatabase": (p) => Bridge.ServerConnections.CreateDatabase(p),
+  "ServerConnections.ProcessList": (p) => Bridge.ServerConnections.ProcessList(p),
+  "ServerConnections.KillProcess": (p) => Bridge.ServerConnections.KillProcess(p),
+  "ServerConnections.Metrics": (p) => Bridge.ServerConnections.Metrics(p),
+  "ServerConnections.StopMetrics": (p) => Bridge.ServerConnections.StopMetrics(p),
   "Plugins.Installed": () => Bridge.PluginsService.Installed(),
   "Plugins.Script": (p) => Bridge.PluginsService.Script(p),
   "Configs.GetSettings": () => Bridge.Configs.GetSettings(),
+  "Configs.UpdateSettings": (p) => Bridge.Configs.UpdateSettings(p),
+  "Transfer.RunScript": (p) => Bridge.TransferService.RunScript(p),
+  "Transfer.Job": (p) => Bridge.TransferService.Job(p),
+  "Transfer.Cancel": (p) => Bridge.TransferService.Cancel(p),
+  "Transfer.Export": (p) => Bridge.TransferService.Export(p),
+  "Transfer.PreviewImport": (p) => Bridge.TransferService.PreviewImport(p),
+  "Transfer.Import": (p) => Bridge.TransferService.Import(p),
+  "Transfer.Copy": (p) => Bridge.TransferService.Copy(p),
+  "History.Search": (p) => Bridge.HistoryService.Search(p),
+  "History.Pin": (p) => Bridge.HistoryService.Pin(p),
+  "History.Prune": (p) => Bridge.HistoryService.Prune(p),
+  "SavedQueries.List": (p) => Bridge.SavedQueriesService.List(p),
+  "SavedQueries.Folders": () => Bridge.SavedQueriesService.Folders(),
+  "SavedQueries.Get": (p) => Bridge.SavedQueriesService.Get(p),
+  "SavedQueries.Save": (p) => Bridge.SavedQueriesService.Save(p),
+  "SavedQueries.Delete": (p) => Bridge.SavedQueriesService.Delete(p),
+  "SavedQueries.Render": (p) => Bridge.SavedQueriesService.Render(p),
+  "Users.List": (p) => Bridge.UsersService.List(p),
+  "Users.Grants": (p) => Bridge.UsersService.Grants(p),
+  "Users.Preview": (p) => Bridge.UsersService.Preview(p),
+  "Users.Apply": (p) => Bridge.UsersService.Apply(p),
+  "Indexes.List": (p) => Bridge.IndexesService.List(p),
+  "Indexes.Create": (p) => Bridge.IndexesService.Create(p),
+  "Indexes.Drop": (p) => Bridge.IndexesService.Drop(p),
+  "Indexes.Hide": (p) => Bridge.IndexesService.Hide(p),
+  "Objects.Preview": (p) => Bridge.ObjectsService.Preview(p),
+  "Objects.Apply": (p) => Bridge.ObjectsService.Apply(p),
 }
 
 export async function apiCall<T>(url: string, params?: any): Promise<T | void> {
@@ -46,6 +46,13 @@ func main() {
 	app.RegisterService(application.NewService(bridge.NewServerConnectionsService(app)))
 	app.RegisterService(application.NewService(bridge.NewPluginsService(app)))
 	app.RegisterService(application.NewService(bridge.NewConfigsService(app)))
+	app.RegisterService(application.NewService(bridge.NewTransferService(app)))
+	app.RegisterService(application.NewService(bridge.NewHistoryService(app)))
+	app.RegisterService(application.NewService(bridge.NewSavedQueriesService(app)))
+	app.RegisterService(application.NewService(bridge.NewUsersService(app)))
+	app.RegisterService(application.NewService(bridge.NewIndexesService(app)))
+	app.RegisterService(application.NewService(bridge.NewObjectsService(app)))
+	app.RegisterService(application.NewService(bridge.NewKeyStoreService(app)))
 
 	_ = app.Window.NewWithOptions(windowsWindowOptions())
 